@@ -136,7 +136,7 @@ func internalDo[T OperationRequest, R OperationResponse](ctx context.Context, ad
 
 	logger.DebugContext(ctx, "Sending request", slog.String("request", string(rawRequest)), slog.Int("size", len(rawRequest)))
 
-	rawResponse, err := roundTripper.RequestReply(ctx, address, rawRequest)
+	rawResponse, err := roundTripper.RequestReply(withReplyFramer(ctx, serde), address, rawRequest)
 	if err != nil {
 		logger.ErrorContext(ctx, "Error performing request", slog.String("error", err.Error()))
 		return err
@@ -144,6 +144,15 @@ func internalDo[T OperationRequest, R OperationResponse](ctx context.Context, ad
 
 	logger.DebugContext(ctx, "Received response", slog.String("response", string(rawResponse)), slog.Int("size", len(rawResponse)))
 
+	if logger.Enabled(ctx, slog.LevelDebug) {
+		if sent, received, ok := AccountExchange(serde, rawRequest, rawResponse); ok {
+			logger.DebugContext(ctx, "Wire breakdown",
+				slog.Group("sent", slog.Int("framing", sent.Framing), slog.Int("envelope", sent.Envelope), slog.Int("payload", sent.Payload)),
+				slog.Group("received", slog.Int("framing", received.Framing), slog.Int("envelope", received.Envelope), slog.Int("payload", received.Payload)),
+			)
+		}
+	}
+
 	appLayerResp := PresentationLayerResponse[R]{
 		Body: resp,
 	}
@@ -0,0 +1,292 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/taldoflemis/triprotocol-benchmark/protogenerated"
+)
+
+type appLayerFlowFixture struct {
+	protocol string
+
+	authRequest   []byte
+	authReply     []byte
+	echoRequest   []byte
+	echoReply     []byte
+	logoutRequest []byte
+	logoutReply   []byte
+	errorReply    []byte
+}
+
+func appLayerFlowFixtures(t testing.TB) []appLayerFlowFixture {
+	return []appLayerFlowFixture{
+		{
+			protocol:      ProtocolString,
+			authRequest:   []byte("AUTH|aluno_id=538349|timestamp=2025-10-10T14:30:00Z|FIM\n"),
+			authReply:     []byte("OK|token=tokenauth|nome=SAID CAVALCANTE RODRIGUES|matricula=538349|timestamp=2025-10-30T18:16:04.585339|FIM\n"),
+			echoRequest:   []byte("OP|token=tokenauth|operacao=echo|mensagem=ola mundo|FIM\n"),
+			echoReply:     []byte("OK|mensagem_original=ola mundo|mensagem_eco=ECO: ola mundo|timestamp_servidor=2025-10-30T21:12:41.305529|tamanho_mensagem=9|hash_md5=3b2613ff007c695c2d560d0e9c9ccbcf|timestamp=2025-10-30T21:12:41.304798|FIM\n"),
+			logoutRequest: []byte("LOGOUT|token=tokenauth|FIM\n"),
+			logoutReply:   []byte("OK|msg=Logout realizado com sucesso|timestamp=2025-10-30T21:32:25.038812|FIM\n"),
+			errorReply:    []byte("ERROR|msg=Token invalido|FIM\n"),
+		},
+		{
+			protocol:      ProtocolJSON,
+			authRequest:   []byte(`{"tipo":"autenticar","aluno_id":"538349"}`),
+			authReply:     []byte(`{"sucesso": true, "mensagem": "Autenticação realizada com sucesso", "timestamp": "2025-10-30T18:16:04.585339", "token": "tokenauth", "dados_aluno": {"nome": "SAID CAVALCANTE RODRIGUES"}}`),
+			echoRequest:   []byte(`{"tipo":"operacao","operacao":"echo","token":"tokenauth","parametros":{"mensagem":"ola mundo"}}`),
+			echoReply:     []byte(`{"sucesso": true, "mensagem": "Operação realizada com sucesso", "timestamp": "2025-10-30T21:12:41.304798", "resultado": {"mensagem_original": "ola mundo", "mensagem_eco": "ECO: ola mundo", "timestamp_servidor": "2025-10-30T21:12:41.305529", "tamanho_mensagem": 9, "hash_md5": "3b2613ff007c695c2d560d0e9c9ccbcf"}}`),
+			logoutRequest: []byte(`{"tipo":"logout","token":"tokenauth"}`),
+			logoutReply:   []byte(`{"sucesso": true, "mensagem": "Logout realizado com sucesso", "timestamp": "2025-10-30T21:32:25.038812"}`),
+			errorReply:    []byte(`{"sucesso": false, "mensagem": "Token invalido", "timestamp": "2025-10-30T21:32:25.038812"}`),
+		},
+		{
+			protocol: ProtocolProtobuf,
+			authRequest: protobufReply(t, &protogenerated.Requisicao{
+				Tipo: &protogenerated.Requisicao_Auth{Auth: &protogenerated.ComandoAuth{AlunoId: "538349"}},
+			}),
+			authReply: protobufReply(t, &protogenerated.Resposta{
+				Tipo: &protogenerated.Resposta_Ok{Ok: &protogenerated.RespostaOk{
+					Comando:   "AUTH",
+					Dados:     map[string]string{"token": "tokenauth", "nome": "SAID CAVALCANTE RODRIGUES", "matricula": "538349"},
+					Timestamp: "2025-10-30T18:16:04.585339",
+				}},
+			}),
+			echoRequest: protobufReply(t, &protogenerated.Requisicao{
+				Tipo: &protogenerated.Requisicao_Operacao{Operacao: &protogenerated.ComandoOperacao{
+					Token:      "tokenauth",
+					Operacao:   "echo",
+					Parametros: map[string]string{"mensagem": "ola mundo"},
+				}},
+			}),
+			echoReply: protobufReply(t, &protogenerated.Resposta{
+				Tipo: &protogenerated.Resposta_Ok{Ok: &protogenerated.RespostaOk{
+					Comando: "OPERACAO",
+					Dados: map[string]string{
+						"mensagem_original":  "ola mundo",
+						"mensagem_eco":       "ECO: ola mundo",
+						"timestamp_servidor": "2025-10-30T21:12:41.305529",
+						"tamanho_mensagem":   "9",
+						"hash_md5":           "3b2613ff007c695c2d560d0e9c9ccbcf",
+					},
+					Timestamp: "2025-10-30T21:12:41.304798",
+				}},
+			}),
+			logoutRequest: protobufReply(t, &protogenerated.Requisicao{
+				Tipo: &protogenerated.Requisicao_Logout{Logout: &protogenerated.ComandoLogout{Token: "tokenauth"}},
+			}),
+			logoutReply: protobufReply(t, &protogenerated.Resposta{
+				Tipo: &protogenerated.Resposta_Ok{Ok: &protogenerated.RespostaOk{
+					Comando:   "LOGOUT",
+					Dados:     map[string]string{"msg": "Logout realizado com sucesso"},
+					Timestamp: "2025-10-30T21:32:25.038812",
+				}},
+			}),
+			errorReply: protobufReply(t, &protogenerated.Resposta{
+				Tipo: &protogenerated.Resposta_Erro{Erro: &protogenerated.RespostaErro{
+					Comando:   "OPERACAO",
+					Mensagem:  "Token invalido",
+					Timestamp: "2025-10-30T21:32:25.038812",
+				}},
+			}),
+		},
+		{
+			protocol:    ProtocolMsgpack,
+			authRequest: msgpackReply(t, "tipo", "autenticar", "aluno_id", "538349"),
+			authReply: msgpackReply(t,
+				"sucesso", true,
+				"mensagem", "Autenticação realizada com sucesso",
+				"token", "tokenauth",
+				"dados_aluno", map[string]any{"nome": "SAID CAVALCANTE RODRIGUES"},
+				"timestamp", "2025-10-30T18:16:04.585339",
+			),
+			echoRequest: msgpackReply(t,
+				"tipo", "operacao",
+				"operacao", "echo",
+				"token", "tokenauth",
+				"parametros", map[string]any{"mensagem": "ola mundo"},
+			),
+			echoReply: msgpackReply(t,
+				"sucesso", true,
+				"mensagem", "Operação realizada com sucesso",
+				"resultado", map[string]any{
+					"mensagem_original":  "ola mundo",
+					"mensagem_eco":       "ECO: ola mundo",
+					"timestamp_servidor": "2025-10-30T21:12:41.305529",
+					"tamanho_mensagem":   9,
+					"hash_md5":           "3b2613ff007c695c2d560d0e9c9ccbcf",
+				},
+				"timestamp", "2025-10-30T21:12:41.304798",
+			),
+			logoutRequest: msgpackReply(t, "tipo", "logout", "token", "tokenauth"),
+			logoutReply: msgpackReply(t,
+				"sucesso", true,
+				"mensagem", "Logout realizado com sucesso",
+				"timestamp", "2025-10-30T21:32:25.038812",
+			),
+			errorReply: msgpackReply(t,
+				"sucesso", false,
+				"mensagem", "Token invalido",
+				"timestamp", "2025-10-30T21:32:25.038812",
+			),
+		},
+		{
+			protocol:    ProtocolCBOR,
+			authRequest: cborReply(t, "tipo", "autenticar", "aluno_id", "538349"),
+			authReply: cborReply(t,
+				"sucesso", true,
+				"mensagem", "Autenticação realizada com sucesso",
+				"token", "tokenauth",
+				"dados_aluno", map[string]any{"nome": "SAID CAVALCANTE RODRIGUES"},
+				"timestamp", cbor.Tag{Number: 0, Content: "2025-10-30T18:16:04.585339Z"},
+			),
+			echoRequest: cborReply(t,
+				"tipo", "operacao",
+				"operacao", "echo",
+				"token", "tokenauth",
+				"parametros", map[string]any{"mensagem": "ola mundo"},
+			),
+			echoReply: cborReply(t,
+				"sucesso", true,
+				"mensagem", "Operação realizada com sucesso",
+				"resultado", map[string]any{
+					"mensagem_original":  "ola mundo",
+					"mensagem_eco":       "ECO: ola mundo",
+					"timestamp_servidor": cbor.Tag{Number: 0, Content: "2025-10-30T21:12:41.305529Z"},
+					"tamanho_mensagem":   9,
+					"hash_md5":           "3b2613ff007c695c2d560d0e9c9ccbcf",
+				},
+				"timestamp", cbor.Tag{Number: 1, Content: 1761858761.304798},
+			),
+			logoutRequest: cborReply(t, "tipo", "logout", "token", "tokenauth"),
+			logoutReply: cborReply(t,
+				"sucesso", true,
+				"mensagem", "Logout realizado com sucesso",
+				"timestamp", cbor.Tag{Number: 0, Content: "2025-10-30T21:32:25.038812Z"},
+			),
+			errorReply: cborReply(t,
+				"sucesso", false,
+				"mensagem", "Token invalido",
+				// Untagged timestamps fall back to the layout of the JSON protocol
+				"timestamp", "2025-10-30T21:32:25.038812",
+			),
+		},
+	}
+}
+
+func newMockClient(t *testing.T, protocol string, readTimeout time.Duration) *AppLayerClient[OperationRequest, OperationResponse] {
+	t.Helper()
+
+	serde, err := NewSerde(protocol)
+	require.NoError(t, err)
+
+	roundTripper := NewTCPRoundTripper(time.Second, time.Second, readTimeout)
+
+	return NewAppLayerClient[OperationRequest, OperationResponse](serde, roundTripper, &AppSettings{})
+}
+
+func TestAppLayerClientFlow(t *testing.T) {
+	for _, fixture := range appLayerFlowFixtures(t) {
+		t.Run(fixture.protocol, func(t *testing.T) {
+			// Arrange
+			server := newMockServer(t, fixture.protocol,
+				mockExchange{Expect: fixture.authRequest, Reply: fixture.authReply},
+				mockExchange{Expect: fixture.echoRequest, Reply: fixture.echoReply},
+				mockExchange{Expect: fixture.logoutRequest, Reply: fixture.logoutReply},
+			)
+			client := newMockClient(t, fixture.protocol, time.Second)
+			ctx := context.Background()
+
+			// Act
+			authResp, authErr := client.Auth(ctx, server.Address(), &AuthRequest{
+				StudentID: "538349",
+				Timestamp: time.Date(2025, 10, 10, 14, 30, 0, 0, time.UTC),
+			})
+			require.NoError(t, authErr, "Auth should not return an error")
+
+			echoResp := &EchoResponse{}
+			echoErr := client.Do(ctx, server.Address(), EchoRequest{Message: "ola mundo"}, echoResp, authResp.Token)
+
+			logoutResp, logoutErr := client.Logout(ctx, server.Address(), &LogoutRequest{}, authResp.Token)
+
+			// Assert
+			assert.Equal(t, "tokenauth", authResp.Token)
+			assert.Equal(t, "SAID CAVALCANTE RODRIGUES", authResp.Name)
+
+			require.NoError(t, echoErr, "Do should not return an error")
+			assert.Equal(t, "ECO: ola mundo", echoResp.EchoMessage)
+			assert.Equal(t, 9, echoResp.MessageSize)
+
+			require.NoError(t, logoutErr, "Logout should not return an error")
+			assert.Equal(t, "Logout realizado com sucesso", logoutResp.Message)
+
+			server.AssertExhausted(t)
+		})
+	}
+}
+
+func TestAppLayerClientServerError(t *testing.T) {
+	for _, fixture := range appLayerFlowFixtures(t) {
+		t.Run(fixture.protocol, func(t *testing.T) {
+			// Arrange
+			server := newMockServer(t, fixture.protocol,
+				mockExchange{ExpectContains: []string{"echo", "expiredtoken"}, Reply: fixture.errorReply},
+			)
+			client := newMockClient(t, fixture.protocol, time.Second)
+
+			// Act
+			err := client.Do(context.Background(), server.Address(), EchoRequest{Message: "ola"}, &EchoResponse{}, "expiredtoken")
+
+			// Assert
+			var serverErr *PresentationLayerErrorResponse
+			require.ErrorAs(t, err, &serverErr, "server errors should be returned as PresentationLayerErrorResponse")
+			assert.Equal(t, "Token invalido", serverErr.Message)
+			server.AssertExhausted(t)
+		})
+	}
+}
+
+func TestAppLayerClientTransportFailures(t *testing.T) {
+	tests := []struct {
+		name     string
+		exchange mockExchange
+	}{
+		{
+			name:     "server disconnects without replying",
+			exchange: mockExchange{Disconnect: true},
+		},
+		{
+			name:     "server replies after the read timeout",
+			exchange: mockExchange{Delay: 300 * time.Millisecond},
+		},
+	}
+
+	for _, fixture := range appLayerFlowFixtures(t) {
+		for _, tt := range tests {
+			t.Run(fixture.protocol+"/"+tt.name, func(t *testing.T) {
+				// Arrange
+				exchange := tt.exchange
+				exchange.Expect = fixture.authRequest
+				exchange.Reply = fixture.authReply
+				server := newMockServer(t, fixture.protocol, exchange)
+				client := newMockClient(t, fixture.protocol, 100*time.Millisecond)
+
+				// Act
+				resp, err := client.Auth(context.Background(), server.Address(), &AuthRequest{
+					StudentID: "538349",
+					Timestamp: time.Date(2025, 10, 10, 14, 30, 0, 0, time.UTC),
+				})
+
+				// Assert
+				assert.Error(t, err, "Auth should fail when the transport fails")
+				assert.Nil(t, resp)
+				server.AssertRequestContains(t, 0, "538349")
+			})
+		}
+	}
+}
@@ -0,0 +1,1008 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+const authOperationName = "AUTH"
+
+type BenchmarkSample struct {
+	Protocol      string
+	Operation     string
+	Latency       time.Duration
+	RequestBytes  int
+	ResponseBytes int
+	Err           error
+	At            time.Time
+
+	// request and response are the messages of a successful exchange, they are broken down after
+	// the measured window, see sampleCollector.accountWire
+	request  []byte
+	response []byte
+
+	// Compression is only set when the protocol is compressed, see CompressedSerde
+	Compression CompressionStats
+
+	// Encryption is only set when the protocol is encrypted, see EncryptedSerde
+	Encryption EncryptionStats
+
+	// Transport is only set when TransportTimed is true, see TimedRoundTripper
+	Transport      TransportTiming
+	TransportTimed bool
+}
+
+type OperationResult struct {
+	Operation     string          `json:"operation"`
+	Requests      int             `json:"requests"`
+	Errors        int             `json:"errors"`
+	Throughput    float64         `json:"throughput"`
+	Latency       LatencySummary  `json:"latency"`
+	Latencies     []time.Duration `json:"latencies"`
+	RequestBytes  []int           `json:"request_bytes"`
+	ResponseBytes []int           `json:"response_bytes"`
+
+	// ThroughputSeries holds the successful requests per second of each interval of the run
+	ThroughputSeries []float64 `json:"throughput_series"`
+
+	// Sent and Received add up the breakdown of the WireMessages accounted exchanges
+	WireMessages int           `json:"wire_messages"`
+	Sent         WireBreakdown `json:"sent"`
+	Received     WireBreakdown `json:"received"`
+
+	// Compression and Encryption add up both messages of every exchange
+	Compression CompressionStats `json:"compression"`
+	Encryption  EncryptionStats  `json:"encryption"`
+
+	// Handshake and Request split the latency of the Handshakes timed exchanges, Resumed of them
+	// reused a TLS session
+	Handshakes int            `json:"handshakes"`
+	Resumed    int            `json:"resumed"`
+	Handshake  LatencySummary `json:"handshake"`
+	Request    LatencySummary `json:"request"`
+}
+
+type ProtocolResult struct {
+	Protocol         string            `json:"protocol"`
+	Elapsed          time.Duration     `json:"elapsed"`
+	Requests         int               `json:"requests"`
+	Errors           int               `json:"errors"`
+	Throughput       float64           `json:"throughput"`
+	Latency          LatencySummary    `json:"latency"`
+	Operations       []OperationResult `json:"operations"`
+	ThroughputSeries []float64         `json:"throughput_series"`
+	Compression      string            `json:"compression,omitempty"`
+	Encryption       string            `json:"encryption,omitempty"`
+	Transport        string            `json:"transport,omitempty"`
+
+	// AllocsSeries holds the heap allocations per request of each interval of the run.
+	// Operations share the heap, so allocations are only tracked per protocol.
+	AllocsSeries     []float64 `json:"allocs_series"`
+	AllocsPerRequest float64   `json:"allocs_per_request"`
+}
+
+// Name labels the result in reports, the transport is appended when the scenario compares transports
+func (r ProtocolResult) Name() string {
+	if r.Transport == "" {
+		return r.Protocol
+	}
+
+	return r.Protocol + "/" + r.Transport
+}
+
+type BenchmarkReport struct {
+	Scenario   Scenario         `json:"scenario"`
+	StartedAt  time.Time        `json:"started_at"`
+	FinishedAt time.Time        `json:"finished_at"`
+	Results    []ProtocolResult `json:"results"`
+}
+
+type BenchmarkRunner struct {
+	Scenario     *Scenario
+	Settings     *AppSettings
+	RoundTripper RoundTripper
+
+	// OnSample is called for every sample recorded after the warmup, it must be safe for concurrent use
+	OnSample func(BenchmarkSample)
+
+	// Control pauses and stops the run from another goroutine, it may be nil
+	Control *RunControl
+}
+
+func NewBenchmarkRunner(scenario *Scenario, settings *AppSettings, roundTripper RoundTripper) *BenchmarkRunner {
+	return &BenchmarkRunner{
+		Scenario:     scenario,
+		Settings:     settings,
+		RoundTripper: roundTripper,
+	}
+}
+
+// Run executes the scenario once per protocol and transport, one after another
+func (r *BenchmarkRunner) Run(ctx context.Context) (*BenchmarkReport, error) {
+	report := &BenchmarkReport{
+		Scenario:  *r.Scenario,
+		StartedAt: time.Now(),
+	}
+
+	// Without transports every protocol runs once on its configured address
+	transports := r.Scenario.Transports
+	if len(transports) == 0 {
+		transports = []string{""}
+	}
+
+protocols:
+	for _, protocol := range r.Scenario.Protocols {
+		for _, transport := range transports {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+
+			// A stopped run reports the protocols it measured
+			if r.Control.Stopped() {
+				break protocols
+			}
+
+			result, err := r.runProtocol(ctx, protocol, transport)
+			if err != nil {
+				return nil, err
+			}
+
+			report.Results = append(report.Results, *result)
+		}
+	}
+
+	report.FinishedAt = time.Now()
+
+	return report, nil
+}
+
+// newSerde returns the serde of the protocol with the codec, CBOR encoding, compression and
+// encryption of the scenario. Messages are compressed before they are sealed, ciphertext does not compress.
+func (r *BenchmarkRunner) newSerde(protocol string) (Serde, error) {
+	serde, err := NewCodecSerde(protocol, r.Scenario.Codec)
+	if err != nil {
+		return nil, err
+	}
+
+	if cbor, ok := serde.(*CBORSerde); ok {
+		cbor.Deterministic = r.Scenario.CBORDeterministic
+	}
+
+	if compression := r.Scenario.Compression[protocol]; compression != "" && compression != CompressionNone {
+		serde, err = NewCompressedSerde(serde, compression)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	encryption := r.Scenario.Encryption[protocol]
+	if encryption == "" || encryption == CipherNone {
+		return serde, nil
+	}
+
+	key, err := r.Settings.PreSharedKey()
+	if err != nil {
+		return nil, err
+	}
+
+	return NewEncryptedSerde(serde, encryption, key)
+}
+
+func (r *BenchmarkRunner) runProtocol(ctx context.Context, protocol string, transport string) (*ProtocolResult, error) {
+	// Every worker gets its own serde, so the stats of a CompressedSerde cover one exchange at a time
+	serdes := make([]Serde, r.Scenario.Concurrency)
+	for worker := range serdes {
+		serde, err := r.newSerde(protocol)
+		if err != nil {
+			return nil, err
+		}
+		serdes[worker] = serde
+	}
+
+	address, err := r.Settings.TransportAddress(protocol, transport)
+	if err != nil {
+		return nil, err
+	}
+
+	roundTrippers := make([]RoundTripper, r.Scenario.Concurrency)
+	for worker := range roundTrippers {
+		roundTrippers[worker], err = r.newRoundTripper(protocol, transport, address)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	slog.InfoContext(ctx, "Starting benchmark",
+		slog.String("scenario", r.Scenario.Name),
+		slog.String("protocol", protocol),
+		slog.String("address", address),
+	)
+
+	start := time.Now()
+	measureFrom := start.Add(r.Scenario.Warmup)
+	deadline := measureFrom.Add(r.Scenario.Duration)
+	collector := newSampleCollector(measureFrom, seriesInterval(r.Scenario.Duration))
+
+	allocsDone := make(chan []float64)
+	allocsCtx, stopAllocs := context.WithCancel(ctx)
+	go func() {
+		allocsDone <- collector.sampleAllocs(allocsCtx)
+	}()
+
+	var wg sync.WaitGroup
+	for worker := range r.Scenario.Concurrency {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r.runWorker(ctx, worker, protocol, serdes[worker], roundTrippers[worker], address, measureFrom, deadline, collector)
+		}()
+	}
+	wg.Wait()
+
+	stopAllocs()
+	allocsSeries := <-allocsDone
+	collector.accountWire(serdes[0])
+
+	elapsed := time.Since(measureFrom) - r.Control.pausedSince(measureFrom)
+	if elapsed > r.Scenario.Duration {
+		elapsed = r.Scenario.Duration
+	}
+
+	result := collector.result(protocol, elapsed)
+	result.Transport = transport
+	result.AllocsSeries = allocsSeries
+	result.AllocsPerRequest = mean(allocsSeries)
+	if compression := r.Scenario.Compression[protocol]; compression != CompressionNone {
+		result.Compression = compression
+	}
+	if encryption := r.Scenario.Encryption[protocol]; encryption != CipherNone {
+		result.Encryption = encryption
+	}
+
+	return result, nil
+}
+
+// newRoundTripper returns the round tripper of a worker. Over loopback every worker gets its own
+// LoopbackServer, with a serde like the client one, so workers only share the clock.
+func (r *BenchmarkRunner) newRoundTripper(protocol string, transport string, address string) (RoundTripper, error) {
+	if transport != TransportLoopback {
+		return r.RoundTripper, nil
+	}
+
+	serde, err := r.newSerde(protocol)
+	if err != nil {
+		return nil, err
+	}
+
+	serverSerde, ok := serde.(ServerSerde)
+	if !ok {
+		return nil, fmt.Errorf("the %s serde cannot serve loopback requests", protocol)
+	}
+
+	roundTripper := NewLoopbackRoundTripper()
+	roundTripper.Handle(address, NewLoopbackServer(serverSerde).Handle)
+
+	return roundTripper, nil
+}
+
+// seriesInterval splits the measured window in at most 20 intervals of at least 100ms
+func seriesInterval(duration time.Duration) time.Duration {
+	return max(min(time.Second, duration/20), 100*time.Millisecond)
+}
+
+func (r *BenchmarkRunner) runWorker(ctx context.Context,
+	worker int,
+	protocol string,
+	serde Serde,
+	inner RoundTripper,
+	address string,
+	measureFrom time.Time,
+	deadline time.Time,
+	collector *sampleCollector,
+) {
+	rng := rand.New(rand.NewPCG(r.Scenario.Seed, uint64(worker)))
+	roundTripper := &wireRecordingRoundTripper{inner: inner}
+	client := NewAppLayerClient[OperationRequest, OperationResponse](serde, roundTripper, r.Settings)
+	compressed, _ := serdeLayer[*CompressedSerde](serde)
+	encrypted, _ := serdeLayer[*EncryptedSerde](serde)
+
+	authStart := time.Now()
+	authResp, err := client.Auth(ctx, address, &AuthRequest{
+		StudentID: r.Scenario.StudentID,
+		Timestamp: authStart,
+	})
+	if err != nil {
+		r.record(collector, BenchmarkSample{
+			Protocol:  protocol,
+			Operation: authOperationName,
+			Latency:   time.Since(authStart),
+			Err:       err,
+			At:        authStart,
+		})
+		return
+	}
+
+	defer client.Logout(context.WithoutCancel(ctx), address, &LogoutRequest{}, authResp.Token)
+
+	end := deadline
+	for ctx.Err() == nil && time.Now().Before(deadline) && !r.Control.Stopped() {
+		// Paused time is not measured, the deadline moves by the measured part of every pause
+		if r.Control.Paused() {
+			r.Control.wait(ctx)
+			deadline = end.Add(r.Control.pausedSince(measureFrom))
+			continue
+		}
+
+		op := r.Scenario.PickOperation(rng)
+		req := op.NewRequest(rng)
+
+		resp, err := NewOperationResponse(op.Name)
+		if err != nil {
+			slog.ErrorContext(ctx, "Invalid scenario operation", slog.String("error", err.Error()))
+			return
+		}
+
+		roundTripper.reset()
+		if compressed != nil {
+			compressed.ResetStats()
+		}
+		if encrypted != nil {
+			encrypted.ResetStats()
+		}
+		start := time.Now()
+		err = client.Do(ctx, address, req, resp, authResp.Token)
+		sample := BenchmarkSample{
+			Protocol:      protocol,
+			Operation:     op.Name,
+			Latency:       time.Since(start),
+			RequestBytes:  len(roundTripper.request),
+			ResponseBytes: len(roundTripper.response),
+			Err:           err,
+			At:            start,
+		}
+
+		if err == nil {
+			sample.request, sample.response = roundTripper.request, roundTripper.response
+		}
+		if compressed != nil {
+			sample.Compression = compressed.Stats()
+		}
+		if encrypted != nil {
+			sample.Encryption = encrypted.Stats()
+		}
+		sample.Transport, sample.TransportTimed = roundTripper.timing, roundTripper.timed
+
+		if !start.Before(measureFrom) {
+			r.record(collector, sample)
+		}
+
+		if r.Scenario.ThinkTime > 0 {
+			select {
+			case <-ctx.Done():
+			case <-time.After(r.Scenario.ThinkTime):
+			}
+		}
+	}
+}
+
+// RunControl pauses, resumes and stops a BenchmarkRunner. Workers finish their exchange in flight
+// first, a stopped run reports what it measured so far. The zero value is ready to use and every
+// method is safe on a nil RunControl.
+type RunControl struct {
+	mu      sync.Mutex
+	pauses  []runPause
+	resumed chan struct{}
+	stopped bool
+}
+
+// runPause is a pause of the run, to is zero while it lasts
+type runPause struct {
+	from time.Time
+	to   time.Time
+}
+
+// Pause holds the workers before their next exchange
+func (c *RunControl) Pause() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.stopped || c.resumed != nil {
+		return
+	}
+
+	c.pauses = append(c.pauses, runPause{from: time.Now()})
+	c.resumed = make(chan struct{})
+}
+
+// Resume releases the workers of a paused run
+func (c *RunControl) Resume() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.resume()
+}
+
+func (c *RunControl) resume() {
+	if c.resumed == nil {
+		return
+	}
+
+	c.pauses[len(c.pauses)-1].to = time.Now()
+	close(c.resumed)
+	c.resumed = nil
+}
+
+// Stop ends the run after the exchanges in flight, paused workers are released to finish
+func (c *RunControl) Stop() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.resume()
+	c.stopped = true
+}
+
+func (c *RunControl) Paused() bool {
+	if c == nil {
+		return false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.resumed != nil
+}
+
+func (c *RunControl) Stopped() bool {
+	if c == nil {
+		return false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.stopped
+}
+
+// wait blocks until the run is resumed, stopped or ctx is done
+func (c *RunControl) wait(ctx context.Context) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	resumed := c.resumed
+	c.mu.Unlock()
+
+	if resumed == nil {
+		return
+	}
+
+	select {
+	case <-ctx.Done():
+	case <-resumed:
+	}
+}
+
+// pausedSince returns how long the run has been paused after since
+func (c *RunControl) pausedSince(since time.Time) time.Duration {
+	if c == nil {
+		return 0
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+
+	var total time.Duration
+	for _, p := range c.pauses {
+		to := p.to
+		if to.IsZero() {
+			to = now
+		}
+
+		from := p.from
+		if from.Before(since) {
+			from = since
+		}
+
+		if to.After(from) {
+			total += to.Sub(from)
+		}
+	}
+
+	return total
+}
+
+func (r *BenchmarkRunner) record(collector *sampleCollector, sample BenchmarkSample) {
+	collector.add(sample)
+
+	if r.OnSample != nil {
+		r.OnSample(sample)
+	}
+}
+
+// wireRecordingRoundTripper remembers the last exchange, and its timing when the inner round
+// tripper is a TimedRoundTripper. It is not safe for concurrent use.
+type wireRecordingRoundTripper struct {
+	inner    RoundTripper
+	request  []byte
+	response []byte
+	timing   TransportTiming
+	timed    bool
+}
+
+// RequestReply implements RoundTripper.
+func (s *wireRecordingRoundTripper) RequestReply(ctx context.Context, address string, req []byte) ([]byte, error) {
+	s.request = req
+
+	timed, ok := s.inner.(TimedRoundTripper)
+	if !ok {
+		resp, err := s.inner.RequestReply(ctx, address, req)
+		s.response = resp
+
+		return resp, err
+	}
+
+	resp, timing, err := timed.RequestReplyTimed(ctx, address, req)
+	s.response = resp
+	s.timing = timing
+	s.timed = true
+
+	return resp, err
+}
+
+func (s *wireRecordingRoundTripper) reset() {
+	s.request = nil
+	s.response = nil
+	s.timing = TransportTiming{}
+	s.timed = false
+}
+
+type operationAccumulator struct {
+	latencies     []time.Duration
+	requestBytes  []int
+	responseBytes []int
+	buckets       []int
+	errors        int
+	wireMessages  int
+	sent          WireBreakdown
+	received      WireBreakdown
+	compression   CompressionStats
+	encryption    EncryptionStats
+	handshakes    []time.Duration
+	requests      []time.Duration
+	resumed       int
+}
+
+// wireShape is an operation and the sizes of its messages, exchanges of the same shape are only
+// broken down once
+type wireShape struct {
+	operation string
+	request   int
+	response  int
+}
+
+// wireExchange is the first exchange of a wireShape and how many measured exchanges had that shape
+type wireExchange struct {
+	request  []byte
+	response []byte
+	count    int
+}
+
+type sampleCollector struct {
+	mu          sync.Mutex
+	operations  map[string]*operationAccumulator
+	exchanges   map[wireShape]*wireExchange
+	measureFrom time.Time
+	interval    time.Duration
+	requests    int
+}
+
+func newSampleCollector(measureFrom time.Time, interval time.Duration) *sampleCollector {
+	return &sampleCollector{
+		operations:  make(map[string]*operationAccumulator),
+		exchanges:   make(map[wireShape]*wireExchange),
+		measureFrom: measureFrom,
+		interval:    interval,
+	}
+}
+
+func (c *sampleCollector) add(sample BenchmarkSample) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	acc, ok := c.operations[sample.Operation]
+	if !ok {
+		acc = &operationAccumulator{}
+		c.operations[sample.Operation] = acc
+	}
+
+	c.requests++
+
+	if sample.Err != nil {
+		acc.errors++
+		return
+	}
+
+	// Failed requests are left out of the throughput, a run failing fast would look faster
+	bucket := max(int(sample.At.Sub(c.measureFrom)/c.interval), 0)
+	for len(acc.buckets) <= bucket {
+		acc.buckets = append(acc.buckets, 0)
+	}
+	acc.buckets[bucket]++
+
+	acc.latencies = append(acc.latencies, sample.Latency)
+	acc.requestBytes = append(acc.requestBytes, sample.RequestBytes)
+	acc.responseBytes = append(acc.responseBytes, sample.ResponseBytes)
+
+	if sample.request != nil {
+		shape := wireShape{operation: sample.Operation, request: len(sample.request), response: len(sample.response)}
+		exchange, ok := c.exchanges[shape]
+		if !ok {
+			exchange = &wireExchange{request: sample.request, response: sample.response}
+			c.exchanges[shape] = exchange
+		}
+		exchange.count++
+	}
+
+	acc.compression = acc.compression.Add(sample.Compression)
+	acc.encryption = acc.encryption.Add(sample.Encryption)
+
+	if sample.TransportTimed {
+		acc.handshakes = append(acc.handshakes, sample.Transport.Handshake)
+		acc.requests = append(acc.requests, sample.Latency-sample.Transport.Handshake)
+		if sample.Transport.Resumed {
+			acc.resumed++
+		}
+	}
+}
+
+// accountWire breaks down one exchange of every shape with serde and adds it up once per exchange
+// of that shape. It runs after the measured window, so the breakdown is not part of the latency
+// or allocations measured.
+func (c *sampleCollector) accountWire(serde Serde) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for shape, exchange := range c.exchanges {
+		sent, received, ok := AccountExchange(serde, exchange.request, exchange.response)
+		if !ok {
+			continue
+		}
+
+		acc := c.operations[shape.operation]
+		acc.wireMessages += exchange.count
+		acc.sent = acc.sent.Add(sent.Times(exchange.count))
+		acc.received = acc.received.Add(received.Times(exchange.count))
+	}
+}
+
+func (c *sampleCollector) requestCount() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.requests
+}
+
+// sampleAllocs records the heap allocations per request of every interval until ctx is done
+func (c *sampleCollector) sampleAllocs(ctx context.Context) []float64 {
+	series := []float64{}
+
+	select {
+	case <-ctx.Done():
+		return series
+	case <-time.After(time.Until(c.measureFrom)):
+	}
+
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	lastMallocs, lastRequests := stats.Mallocs, c.requestCount()
+
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return series
+		case <-ticker.C:
+		}
+
+		runtime.ReadMemStats(&stats)
+		requests := c.requestCount()
+
+		if requests > lastRequests {
+			series = append(series, float64(stats.Mallocs-lastMallocs)/float64(requests-lastRequests))
+		}
+
+		lastMallocs, lastRequests = stats.Mallocs, requests
+	}
+}
+
+func (c *sampleCollector) result(protocol string, elapsed time.Duration) *ProtocolResult {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	result := &ProtocolResult{
+		Protocol: protocol,
+		Elapsed:  elapsed,
+	}
+
+	names := make([]string, 0, len(c.operations))
+	for name := range c.operations {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	buckets := int(elapsed / c.interval)
+	totals := make([]int, buckets)
+
+	all := []time.Duration{}
+	for _, name := range names {
+		acc := c.operations[name]
+		requests := len(acc.latencies) + acc.errors
+
+		for i := range min(buckets, len(acc.buckets)) {
+			totals[i] += acc.buckets[i]
+		}
+
+		result.Operations = append(result.Operations, OperationResult{
+			Operation:        name,
+			Requests:         requests,
+			Errors:           acc.errors,
+			Throughput:       throughput(requests-acc.errors, elapsed),
+			Latency:          SummarizeLatencies(acc.latencies),
+			Latencies:        slices.Clone(acc.latencies),
+			RequestBytes:     slices.Clone(acc.requestBytes),
+			ResponseBytes:    slices.Clone(acc.responseBytes),
+			ThroughputSeries: throughputSeries(acc.buckets, buckets, c.interval),
+			WireMessages:     acc.wireMessages,
+			Sent:             acc.sent,
+			Received:         acc.received,
+			Compression:      acc.compression,
+			Encryption:       acc.encryption,
+			Handshakes:       len(acc.handshakes),
+			Resumed:          acc.resumed,
+			Handshake:        SummarizeLatencies(acc.handshakes),
+			Request:          SummarizeLatencies(acc.requests),
+		})
+
+		result.Requests += requests
+		result.Errors += acc.errors
+		all = append(all, acc.latencies...)
+	}
+
+	result.Throughput = throughput(result.Requests-result.Errors, elapsed)
+	result.Latency = SummarizeLatencies(all)
+	result.ThroughputSeries = throughputSeries(totals, buckets, c.interval)
+
+	return result
+}
+
+// throughputSeries converts the request count of the first n complete intervals to requests per second
+func throughputSeries(counts []int, n int, interval time.Duration) []float64 {
+	series := make([]float64, n)
+	for i := range min(n, len(counts)) {
+		series[i] = float64(counts[i]) / interval.Seconds()
+	}
+
+	return series
+}
+
+func throughput(requests int, elapsed time.Duration) float64 {
+	if elapsed <= 0 {
+		return 0
+	}
+
+	return float64(requests) / elapsed.Seconds()
+}
+
+// WriteText writes a human readable table of the report, preceded by the scenario that produced it
+func (r *BenchmarkReport) WriteText(w io.Writer) error {
+	s := r.Scenario
+
+	fmt.Fprintf(w, "Scenario: %s\n", s.Name)
+	if s.Description != "" {
+		fmt.Fprintf(w, "  %s\n", s.Description)
+	}
+	codec := s.Codec
+	if codec == "" {
+		codec = CodecReflect
+	}
+	fmt.Fprintf(w, "  protocols=%s codec=%s duration=%s warmup=%s concurrency=%d think-time=%s seed=%d\n",
+		strings.Join(s.Protocols, ","), codec, s.Duration, s.Warmup, s.Concurrency, s.ThinkTime, s.Seed)
+
+	ops := make([]string, 0, len(s.Operations))
+	for _, op := range s.Operations {
+		ops = append(ops, fmt.Sprintf("%s:%d", op.Name, op.Weight))
+	}
+	fmt.Fprintf(w, "  operations=%s\n", strings.Join(ops, ","))
+
+	if len(s.Transports) > 0 {
+		fmt.Fprintf(w, "  transports=%s\n", strings.Join(s.Transports, ","))
+	}
+
+	if len(s.Compression) > 0 {
+		compressions := make([]string, 0, len(s.Compression))
+		for _, protocol := range s.Protocols {
+			if compression, ok := s.Compression[protocol]; ok {
+				compressions = append(compressions, protocol+"="+compression)
+			}
+		}
+		fmt.Fprintf(w, "  compression=%s\n", strings.Join(compressions, ","))
+	}
+
+	if len(s.Encryption) > 0 {
+		encryptions := make([]string, 0, len(s.Encryption))
+		for _, protocol := range s.Protocols {
+			if encryption, ok := s.Encryption[protocol]; ok {
+				encryptions = append(encryptions, protocol+"="+encryption)
+			}
+		}
+		fmt.Fprintf(w, "  encryption=%s\n", strings.Join(encryptions, ","))
+	}
+	fmt.Fprintln(w)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "protocol\toperation\trequests\terrors\treq/s\tp50\tp90\tp99\tmax\t")
+
+	for _, result := range r.Results {
+		for _, op := range result.Operations {
+			writeResultRow(tw, result.Name(), op.Operation, op.Requests, op.Errors, op.Throughput, op.Latency)
+		}
+		writeResultRow(tw, result.Name(), "total", result.Requests, result.Errors, result.Throughput, result.Latency)
+	}
+
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if summaries := r.WireSummaries(); len(summaries) > 0 {
+		fmt.Fprint(w, "\nWire breakdown, average bytes per message\n\n")
+
+		if err := WriteWireText(w, summaries); err != nil {
+			return err
+		}
+	}
+
+	if summaries := r.CompressionSummaries(); len(summaries) > 0 {
+		fmt.Fprint(w, "\nCompression, average bytes and codec time per message\n\n")
+
+		if err := WriteCompressionText(w, summaries); err != nil {
+			return err
+		}
+	}
+
+	if summaries := r.EncryptionSummaries(); len(summaries) > 0 {
+		fmt.Fprint(w, "\nEncryption, average bytes and cipher time per message\n\n")
+
+		if err := WriteEncryptionText(w, summaries); err != nil {
+			return err
+		}
+	}
+
+	if summaries := r.HandshakeSummaries(); len(summaries) > 0 {
+		fmt.Fprint(w, "\nTLS, handshake and request time per exchange\n\n")
+
+		if err := WriteHandshakeText(w, summaries); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// WireSummaries returns the wire breakdown of every operation that had accounted exchanges
+func (r *BenchmarkReport) WireSummaries() []WireOperationSummary {
+	summaries := []WireOperationSummary{}
+
+	for _, result := range r.Results {
+		for _, op := range result.Operations {
+			if op.WireMessages == 0 {
+				continue
+			}
+
+			summaries = append(summaries, WireOperationSummary{
+				Protocol:  result.Name(),
+				Operation: op.Operation,
+				Messages:  op.WireMessages,
+				Sent:      op.Sent,
+				Received:  op.Received,
+			})
+		}
+	}
+
+	return summaries
+}
+
+// CompressionSummaries returns the compression of every operation of the compressed protocols
+func (r *BenchmarkReport) CompressionSummaries() []CompressionOperationSummary {
+	summaries := []CompressionOperationSummary{}
+
+	for _, result := range r.Results {
+		for _, op := range result.Operations {
+			if op.Compression.Messages() == 0 {
+				continue
+			}
+
+			summaries = append(summaries, CompressionOperationSummary{
+				Protocol:  result.Name(),
+				Codec:     result.Compression,
+				Operation: op.Operation,
+				Stats:     op.Compression,
+			})
+		}
+	}
+
+	return summaries
+}
+
+// EncryptionSummaries returns the encryption of every operation of the encrypted protocols
+func (r *BenchmarkReport) EncryptionSummaries() []EncryptionOperationSummary {
+	summaries := []EncryptionOperationSummary{}
+
+	for _, result := range r.Results {
+		for _, op := range result.Operations {
+			if op.Encryption.Messages() == 0 {
+				continue
+			}
+
+			summaries = append(summaries, EncryptionOperationSummary{
+				Protocol:  result.Name(),
+				Cipher:    result.Encryption,
+				Operation: op.Operation,
+				Stats:     op.Encryption,
+			})
+		}
+	}
+
+	return summaries
+}
+
+// HandshakeSummaries returns the handshake and request time of every operation with timed exchanges
+func (r *BenchmarkReport) HandshakeSummaries() []HandshakeOperationSummary {
+	summaries := []HandshakeOperationSummary{}
+
+	for _, result := range r.Results {
+		for _, op := range result.Operations {
+			if op.Handshakes == 0 {
+				continue
+			}
+
+			summaries = append(summaries, HandshakeOperationSummary{
+				Protocol:   result.Name(),
+				Operation:  op.Operation,
+				Handshakes: op.Handshakes,
+				Resumed:    op.Resumed,
+				Handshake:  op.Handshake,
+				Request:    op.Request,
+			})
+		}
+	}
+
+	return summaries
+}
+
+func writeResultRow(w io.Writer, protocol string, operation string, requests int, errors int, throughput float64, latency LatencySummary) {
+	fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%.1f\t%s\t%s\t%s\t%s\t\n",
+		protocol,
+		operation,
+		requests,
+		errors,
+		throughput,
+		latency.P50.Round(time.Microsecond),
+		latency.P90.Round(time.Microsecond),
+		latency.P99.Round(time.Microsecond),
+		latency.Max.Round(time.Microsecond),
+	)
+}
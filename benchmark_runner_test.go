@@ -0,0 +1,154 @@
+package main
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newLoopbackScenario(protocols []string, duration time.Duration) *Scenario {
+	return &Scenario{
+		Name:        "loopback",
+		Protocols:   protocols,
+		Transports:  []string{TransportLoopback},
+		StudentID:   "538349",
+		Duration:    duration,
+		Concurrency: 2,
+		Operations:  []ScenarioOperation{{Name: OperationEcho, Weight: 1}, {Name: OperationTimestamp, Weight: 1}},
+	}
+}
+
+func TestBenchmarkRunnerStop(t *testing.T) {
+	// Arrange
+	runner := NewBenchmarkRunner(newLoopbackScenario([]string{ProtocolJSON, ProtocolString}, time.Minute), &AppSettings{}, DefaultTCPRoundTripper)
+	runner.Control = &RunControl{}
+	time.AfterFunc(100*time.Millisecond, runner.Control.Stop)
+
+	// Act
+	start := time.Now()
+	report, err := runner.Run(context.Background())
+
+	// Assert
+	require.NoError(t, err)
+	assert.Less(t, time.Since(start), 10*time.Second, "the run should end when stopped")
+	require.Len(t, report.Results, 1, "protocols after the stop should not run")
+	assert.Equal(t, ProtocolJSON, report.Results[0].Protocol)
+	assert.Positive(t, report.Results[0].Requests)
+	assert.Less(t, report.Results[0].Elapsed, time.Minute, "the report should cover the measured time only")
+}
+
+func TestBenchmarkRunnerPause(t *testing.T) {
+	// Arrange
+	var samples atomic.Int64
+	runner := NewBenchmarkRunner(newLoopbackScenario([]string{ProtocolJSON}, 200*time.Millisecond), &AppSettings{}, DefaultTCPRoundTripper)
+	runner.Control = &RunControl{}
+	runner.OnSample = func(BenchmarkSample) { samples.Add(1) }
+	runner.Control.Pause()
+
+	done := make(chan *BenchmarkReport)
+	go func() {
+		report, err := runner.Run(context.Background())
+		assert.NoError(t, err)
+		done <- report
+	}()
+
+	// Act
+	time.Sleep(300 * time.Millisecond)
+	pausedSamples := samples.Load()
+	runner.Control.Resume()
+	report := <-done
+
+	// Assert
+	assert.Zero(t, pausedSamples, "paused workers should not send requests")
+	assert.False(t, runner.Control.Paused())
+	require.Len(t, report.Results, 1)
+	assert.Positive(t, report.Results[0].Requests, "the whole duration should run after the pause")
+	assert.Equal(t, 200*time.Millisecond, report.Results[0].Elapsed, "paused time should not be measured")
+}
+
+func TestRunControlPausedSince(t *testing.T) {
+	// Arrange
+	control := &RunControl{}
+	before := time.Now()
+
+	// Act
+	control.Pause()
+	time.Sleep(20 * time.Millisecond)
+	control.Resume()
+	after := time.Now()
+	control.Stop()
+	control.Pause()
+
+	// Assert
+	assert.GreaterOrEqual(t, control.pausedSince(before), 20*time.Millisecond)
+	assert.Zero(t, control.pausedSince(after), "pauses before since should not count")
+	assert.True(t, control.Stopped())
+	assert.False(t, control.Paused(), "a stopped run cannot be paused")
+}
+
+func TestRunControlNil(t *testing.T) {
+	// Arrange
+	var control *RunControl
+
+	// Act
+	control.wait(context.Background())
+
+	// Assert
+	assert.False(t, control.Paused())
+	assert.False(t, control.Stopped())
+	assert.Zero(t, control.pausedSince(time.Time{}))
+}
+
+func TestBenchmarkRunnerNewSerdeCBORDeterministic(t *testing.T) {
+	tests := []struct {
+		name          string
+		deterministic bool
+	}{
+		{name: "default", deterministic: false},
+		{name: "deterministic", deterministic: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			scenario := newLoopbackScenario([]string{ProtocolCBOR}, time.Second)
+			scenario.CBORDeterministic = tt.deterministic
+			runner := NewBenchmarkRunner(scenario, &AppSettings{}, DefaultTCPRoundTripper)
+
+			// Act
+			serde, err := runner.newSerde(ProtocolCBOR)
+
+			// Assert
+			require.NoError(t, err)
+			require.IsType(t, &CBORSerde{}, serde)
+			assert.Equal(t, tt.deterministic, serde.(*CBORSerde).Deterministic)
+		})
+	}
+}
+
+func TestBenchmarkRunnerAccountsWire(t *testing.T) {
+	// Arrange
+	runner := NewBenchmarkRunner(newLoopbackScenario([]string{ProtocolJSON}, 300*time.Millisecond), &AppSettings{}, DefaultTCPRoundTripper)
+
+	// Act
+	report, err := runner.Run(context.Background())
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, report.Results, 1)
+	for _, op := range report.Results[0].Operations {
+		requestBytes, responseBytes := 0, 0
+		for i := range op.RequestBytes {
+			requestBytes += op.RequestBytes[i]
+			responseBytes += op.ResponseBytes[i]
+		}
+
+		assert.Equal(t, op.Requests-op.Errors, op.WireMessages, "every successful exchange should be accounted")
+		assert.Equal(t, requestBytes, op.Sent.Total(), "the breakdown should cover every request byte")
+		assert.Equal(t, responseBytes, op.Received.Total(), "the breakdown should cover every response byte")
+	}
+}
@@ -0,0 +1,237 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/fxamacker/cbor/v2"
+)
+
+// CBORSerde sends the envelope of the JSON protocol encoded as CBOR (RFC 8949), behind the length
+// prefix of the protobuf protocol. Domain types are encoded through their json tags and timestamps
+// as the standard date/time tags. Deterministic switches to the core deterministic encoding of
+// RFC 8949 section 4.2.1: sorted map keys and the shortest form of every argument and float. Scenarios
+// turn it on with cbor-deterministic.
+type CBORSerde struct {
+	Deterministic bool
+}
+
+const (
+	// cborTagDateTime is the standard date/time string tag, an RFC 3339 timestamp
+	cborTagDateTime = 0
+	// cborTagEpoch is the epoch based date/time tag, seconds since 1970 as an integer or float
+	cborTagEpoch = 1
+	// cborMajorTag is the major type of tags, the top three bits of their initial byte
+	cborMajorTag = 6
+)
+
+var (
+	cborEncMode              = newCBOREncMode(false)
+	cborDeterministicEncMode = newCBOREncMode(true)
+	cborDecMode              = newCBORDecMode()
+)
+
+func newCBOREncMode(deterministic bool) cbor.EncMode {
+	opts := cbor.EncOptions{}
+	if deterministic {
+		opts = cbor.CoreDetEncOptions()
+	}
+	opts.Time = cbor.TimeRFC3339Nano
+	opts.TimeTag = cbor.EncTagRequired
+
+	mode, err := opts.EncMode()
+	if err != nil {
+		panic(err)
+	}
+
+	return mode
+}
+
+func newCBORDecMode() cbor.DecMode {
+	mode, err := cbor.DecOptions{
+		// Dynamic fields get string keyed maps and int64 numbers, like the MessagePack protocol
+		DefaultMapType: reflect.TypeOf(map[string]any(nil)),
+		IntDec:         cbor.IntDecConvertSigned,
+	}.DecMode()
+	if err != nil {
+		panic(err)
+	}
+
+	return mode
+}
+
+// cborResponseEnvelope is jsonResponseWrapper as the client reads it, the result is bound once the
+// envelope is read because decoding does not fill the body an interface points to
+type cborResponseEnvelope struct {
+	Message     string          `cbor:"mensagem"`
+	Token       string          `cbor:"token"`
+	Success     bool            `cbor:"sucesso"`
+	Result      cbor.RawMessage `cbor:"resultado"`
+	StudentData *studenData     `cbor:"dados_aluno"`
+	Timestamp   NonISO8601Time  `cbor:"timestamp"`
+}
+
+// cborRequestEnvelope is jsonRequestWrapper as the server reads it, parameters are bound once the operation is known
+type cborRequestEnvelope struct {
+	Kind      string          `cbor:"tipo"`
+	Operation string          `cbor:"operacao"`
+	Token     string          `cbor:"token"`
+	Params    cbor.RawMessage `cbor:"parametros"`
+	StudentID string          `cbor:"aluno_id"`
+}
+
+func (c CBORSerde) encMode() cbor.EncMode {
+	if c.Deterministic {
+		return cborDeterministicEncMode
+	}
+
+	return cborEncMode
+}
+
+// marshal encodes v behind its length prefix
+func (c CBORSerde) marshal(v any) ([]byte, error) {
+	data, err := c.encMode().Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return frameMessage(data), nil
+}
+
+// unmarshalCBOR decodes the message behind the length prefix into v
+func unmarshalCBOR(data []byte, v any) error {
+	data, err := unframeMessage(data)
+	if err != nil {
+		return err
+	}
+
+	return cborDecMode.Unmarshal(data, v)
+}
+
+// unmarshalCBORTime decodes a date/time tagged 0 or 1, untagged data items are handed to untagged
+func unmarshalCBORTime(data []byte, untagged func(data []byte) (time.Time, error)) (time.Time, error) {
+	if len(data) == 0 || data[0]>>5 != cborMajorTag {
+		return untagged(data)
+	}
+
+	var tag cbor.RawTag
+	if err := cborDecMode.Unmarshal(data, &tag); err != nil {
+		return time.Time{}, err
+	}
+
+	switch tag.Number {
+	case cborTagDateTime:
+		var s string
+		if err := cborDecMode.Unmarshal(tag.Content, &s); err != nil {
+			return time.Time{}, err
+		}
+
+		return time.Parse(time.RFC3339Nano, s)
+	case cborTagEpoch:
+		var seconds float64
+		if err := cborDecMode.Unmarshal(tag.Content, &seconds); err != nil {
+			return time.Time{}, err
+		}
+
+		return newUnixTimestamp(seconds).Time, nil
+	default:
+		return time.Time{}, fmt.Errorf("unexpected CBOR tag %d for a date/time", tag.Number)
+	}
+}
+
+// Marshal implements Serde.
+func (c CBORSerde) Marshal(v any) ([]byte, error) {
+	request, err := newJSONRequestWrapper(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.marshal(request)
+}
+
+// Unmarshal implements Serde.
+func (c CBORSerde) Unmarshal(data []byte, v any) error {
+	value, err := responseValue(v)
+	if err != nil {
+		return err
+	}
+
+	bodyElem, err := responseBody(value)
+	if err != nil {
+		return err
+	}
+
+	var envelope cborResponseEnvelope
+	if err := unmarshalCBOR(data, &envelope); err != nil {
+		return err
+	}
+
+	if len(envelope.Result) > 0 {
+		if err := cborDecMode.Unmarshal(envelope.Result, bodyElem.Addr().Interface()); err != nil {
+			return err
+		}
+	}
+
+	bindJSONResponseWrapper(value, bodyElem, jsonResponseWrapper{
+		Message:     envelope.Message,
+		Token:       envelope.Token,
+		Success:     envelope.Success,
+		StudentData: envelope.StudentData,
+		Timestamp:   envelope.Timestamp,
+	})
+
+	return nil
+}
+
+// AccountRequest implements WireAccountant.
+func (c CBORSerde) AccountRequest(data []byte) (WireBreakdown, error) {
+	return accountCBORMessage(data, wireControlFields)
+}
+
+// ReplyLength implements ReplyFramer.
+func (c CBORSerde) ReplyLength(data []byte) (int, bool) {
+	return framedMessageLength(data)
+}
+
+// AccountResponse implements WireAccountant.
+func (c CBORSerde) AccountResponse(data []byte) (WireBreakdown, error) {
+	return accountCBORMessage(data, wireControlFields)
+}
+
+// UnmarshalRequest implements ServerSerde.
+func (c CBORSerde) UnmarshalRequest(data []byte, req *PresentationLayerRequest) error {
+	if req == nil {
+		return fmt.Errorf("nil pointer provided")
+	}
+
+	var envelope cborRequestEnvelope
+	if err := unmarshalCBOR(data, &envelope); err != nil {
+		return err
+	}
+
+	body, err := newEnvelopeRequestBody(envelope.Kind, envelope.Operation, envelope.StudentID, func(body reflect.Value) error {
+		if len(envelope.Params) == 0 {
+			return nil
+		}
+		return cborDecMode.Unmarshal(envelope.Params, body.Addr().Interface())
+	})
+	if err != nil {
+		return err
+	}
+
+	req.Token = envelope.Token
+	req.Body = body
+
+	return nil
+}
+
+// MarshalResponse implements ServerSerde.
+func (c CBORSerde) MarshalResponse(v any) ([]byte, error) {
+	wrapper, err := newJSONResponseWrapper(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.marshal(wrapper)
+}
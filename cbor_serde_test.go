@@ -0,0 +1,136 @@
+package main
+
+import (
+	"bytes"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCBORDeterministicEncoding(t *testing.T) {
+	// Arrange
+	sessions := map[string]StatusResponseSessionDetails{}
+	for _, key := range []string{"zz", "aaa", "b", "ccc", "a", "dd"} {
+		sessions[key] = StatusResponseSessionDetails{IPClient: "127.0.0.1", Name: key}
+	}
+	resp := PresentationLayerResponse[OperationResponse]{StatusCode: http.StatusOK, Body: &StatusResponse{
+		Status:         "ATIVO",
+		TimeActive:     UnixTimestamp{time.Unix(1761860620, 500000000).UTC()},
+		Timestamp:      NonISO8601Time{time.Date(2025, 10, 30, 21, 43, 40, 584881000, time.UTC)},
+		SessionDetails: &sessions,
+		Metrics:        StatusResponseMetrics{SimulatedCPU: 34.5},
+	}}
+	serde := CBORSerde{Deterministic: true}
+
+	// Act
+	first, err := serde.MarshalResponse(resp)
+	require.NoError(t, err)
+
+	// Assert
+	for range 20 {
+		again, err := serde.MarshalResponse(resp)
+		require.NoError(t, err)
+		require.Equal(t, first, again, "deterministic encoding should not depend on map iteration order")
+	}
+
+	body, err := unframeMessage(first)
+	require.NoError(t, err)
+
+	// Core deterministic order compares the encoded keys, shorter keys come first
+	keys := []string{"a", "b", "dd", "zz", "aaa", "ccc"}
+	for i := 1; i < len(keys); i++ {
+		previous, err := cbor.Marshal(keys[i-1])
+		require.NoError(t, err)
+		next, err := cbor.Marshal(keys[i])
+		require.NoError(t, err)
+		assert.Less(t, bytes.Index(body, previous), bytes.Index(body, next), "%q should be encoded before %q", keys[i-1], keys[i])
+	}
+
+	// 34.5 is exact as a half precision float
+	assert.Contains(t, string(body), "\xf9\x50\x50", "34.5 should take its shortest float form")
+}
+
+func TestCBORTimeTags(t *testing.T) {
+	instant := time.Date(2025, 10, 30, 21, 12, 41, 304798000, time.UTC)
+
+	tests := []struct {
+		name     string
+		data     any
+		expected time.Time
+	}{
+		{name: "date/time string", data: cbor.Tag{Number: 0, Content: "2025-10-30T21:12:41.304798Z"}, expected: instant},
+		{name: "date/time string with offset", data: cbor.Tag{Number: 0, Content: "2025-10-30T18:12:41.304798-03:00"}, expected: instant},
+		{name: "epoch float", data: cbor.Tag{Number: 1, Content: 1761858761.5}, expected: time.Unix(1761858761, 500000000)},
+		{name: "epoch integer", data: cbor.Tag{Number: 1, Content: 1761858761}, expected: time.Unix(1761858761, 0)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			data, err := cbor.Marshal(tt.data)
+			require.NoError(t, err)
+
+			// Act
+			var nonISO NonISO8601Time
+			nonISOErr := nonISO.UnmarshalCBOR(data)
+			var unix UnixTimestamp
+			unixErr := unix.UnmarshalCBOR(data)
+
+			// Assert
+			require.NoError(t, nonISOErr)
+			require.NoError(t, unixErr)
+			assert.True(t, tt.expected.Equal(nonISO.Time), "expected %s, got %s", tt.expected, nonISO.Time)
+			assert.True(t, tt.expected.Equal(unix.Time), "expected %s, got %s", tt.expected, unix.Time)
+		})
+	}
+}
+
+func TestCBORTimeRoundTrip(t *testing.T) {
+	// Arrange
+	nonISO := NonISO8601Time{time.Date(2025, 10, 30, 21, 12, 41, 304798000, time.UTC)}
+	unix := UnixTimestamp{time.Unix(1761858761, 500000000).UTC()}
+
+	// Act
+	nonISOData, err := nonISO.MarshalCBOR()
+	require.NoError(t, err)
+	unixData, err := unix.MarshalCBOR()
+	require.NoError(t, err)
+
+	var decodedNonISO NonISO8601Time
+	require.NoError(t, decodedNonISO.UnmarshalCBOR(nonISOData))
+	var decodedUnix UnixTimestamp
+	require.NoError(t, decodedUnix.UnmarshalCBOR(unixData))
+
+	// Assert
+	assert.Equal(t, byte(0xc0), nonISOData[0], "NonISO8601Time should be tagged as a date/time string")
+	assert.Equal(t, byte(0xc1), unixData[0], "UnixTimestamp should be tagged as an epoch date/time")
+	assert.Equal(t, nonISO, decodedNonISO)
+	assert.Equal(t, unix, decodedUnix)
+}
+
+func TestCBORUntaggedTimes(t *testing.T) {
+	// Arrange
+	nonISOData, err := cbor.Marshal("2025-10-30T21:12:41.304798")
+	require.NoError(t, err)
+	unixData, err := cbor.Marshal(1761858761.5)
+	require.NoError(t, err)
+	otherTag, err := cbor.Marshal(cbor.Tag{Number: 100, Content: 1})
+	require.NoError(t, err)
+
+	// Act
+	var nonISO NonISO8601Time
+	nonISOErr := nonISO.UnmarshalCBOR(nonISOData)
+	var unix UnixTimestamp
+	unixErr := unix.UnmarshalCBOR(unixData)
+
+	// Assert
+	require.NoError(t, nonISOErr, "untagged strings should use the layout of the JSON protocol")
+	require.NoError(t, unixErr, "untagged numbers should be epoch seconds")
+	assert.Equal(t, time.Date(2025, 10, 30, 21, 12, 41, 304798000, time.UTC), nonISO.Time)
+	assert.Equal(t, time.Unix(1761858761, 500000000).UTC(), unix.Time)
+	assert.Error(t, nonISO.UnmarshalCBOR(otherTag), "tags other than 0 and 1 are not date/times")
+}
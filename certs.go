@@ -0,0 +1,167 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// CertificateFiles are the PEM files written by GenerateCertificates
+type CertificateFiles struct {
+	CA         string
+	ServerCert string
+	ServerKey  string
+	ClientCert string
+	ClientKey  string
+}
+
+// ServerSettings are the TLS settings of a listener presenting the server certificate and requiring
+// client certificates
+func (f CertificateFiles) ServerSettings() TLSSettings {
+	return TLSSettings{Enabled: true, CAFile: f.CA, CertFile: f.ServerCert, KeyFile: f.ServerKey}
+}
+
+// ClientSettings are the TLS settings of a client verifying the server and presenting the client certificate
+func (f CertificateFiles) ClientSettings() TLSSettings {
+	return TLSSettings{Enabled: true, CAFile: f.CA, CertFile: f.ClientCert, KeyFile: f.ClientKey}
+}
+
+type certificate struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+// GenerateCertificates writes a throwaway CA to dir, with a server certificate for hosts and a client
+// certificate for mutual TLS, all valid for the given duration
+func GenerateCertificates(dir string, hosts []string, valid time.Duration) (CertificateFiles, error) {
+	files := CertificateFiles{
+		CA:         filepath.Join(dir, "ca.pem"),
+		ServerCert: filepath.Join(dir, "server.pem"),
+		ServerKey:  filepath.Join(dir, "server-key.pem"),
+		ClientCert: filepath.Join(dir, "client.pem"),
+		ClientKey:  filepath.Join(dir, "client-key.pem"),
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return files, err
+	}
+
+	notAfter := time.Now().Add(valid)
+
+	ca, err := newCertificate(&x509.Certificate{
+		Subject:               pkix.Name{CommonName: "triprotocol-benchmark CA"},
+		NotAfter:              notAfter,
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+	}, nil)
+	if err != nil {
+		return files, err
+	}
+
+	server := &x509.Certificate{
+		Subject:     pkix.Name{CommonName: "triprotocol-benchmark server"},
+		NotAfter:    notAfter,
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	for _, host := range hosts {
+		if ip := net.ParseIP(host); ip != nil {
+			server.IPAddresses = append(server.IPAddresses, ip)
+		} else {
+			server.DNSNames = append(server.DNSNames, host)
+		}
+	}
+
+	serverCert, err := newCertificate(server, ca)
+	if err != nil {
+		return files, err
+	}
+
+	clientCert, err := newCertificate(&x509.Certificate{
+		Subject:     pkix.Name{CommonName: "triprotocol-benchmark client"},
+		NotAfter:    notAfter,
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}, ca)
+	if err != nil {
+		return files, err
+	}
+
+	if err := writePEM(files.CA, "CERTIFICATE", ca.cert.Raw); err != nil {
+		return files, err
+	}
+
+	for _, c := range []struct {
+		cert     *certificate
+		certFile string
+		keyFile  string
+	}{
+		{cert: serverCert, certFile: files.ServerCert, keyFile: files.ServerKey},
+		{cert: clientCert, certFile: files.ClientCert, keyFile: files.ClientKey},
+	} {
+		if err := writePEM(c.certFile, "CERTIFICATE", c.cert.cert.Raw); err != nil {
+			return files, err
+		}
+
+		key, err := x509.MarshalPKCS8PrivateKey(c.cert.key)
+		if err != nil {
+			return files, err
+		}
+		if err := writePEM(c.keyFile, "PRIVATE KEY", key); err != nil {
+			return files, err
+		}
+	}
+
+	return files, nil
+}
+
+// newCertificate signs template with parent, a nil parent makes it self-signed
+func newCertificate(template *x509.Certificate, parent *certificate) (*certificate, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, err
+	}
+	template.SerialNumber = serial
+	template.NotBefore = time.Now().Add(-time.Minute)
+
+	signer, signerKey := template, key
+	if parent != nil {
+		signer, signerKey = parent.cert, parent.key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	if err != nil {
+		return nil, err
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, err
+	}
+
+	return &certificate{cert: cert, key: key}, nil
+}
+
+func writePEM(path string, blockType string, der []byte) error {
+	data := pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der})
+	if data == nil {
+		return fmt.Errorf("failed to encode %s", path)
+	}
+
+	return os.WriteFile(path, data, 0o600)
+}
@@ -0,0 +1,512 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"time"
+)
+
+// RunCLI runs the headless commands, the TUI is used when no command is given
+func RunCLI(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("missing command")
+	}
+
+	switch args[0] {
+	case "bench":
+		return runBenchCommand(args[1:])
+	case "sweep":
+		return runSweepCommand(args[1:])
+	case "compare":
+		return runCompareCommand(args[1:])
+	case "wire":
+		return runWireCommand(args[1:])
+	case "certs":
+		return runCertsCommand(args[1:])
+	case "call":
+		return runCallCommand(args[1:])
+	case "serve":
+		return runServeCommand(args[1:])
+	case "tui":
+		return runTUICommand(args[1:])
+	default:
+		return fmt.Errorf("unknown command %s", args[0])
+	}
+}
+
+func setupCLILogger(level string) error {
+	var logLevel slog.Level
+	if err := logLevel.UnmarshalText([]byte(level)); err != nil {
+		return err
+	}
+
+	slog.SetDefault(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: logLevel})))
+
+	return nil
+}
+
+func newSettingsRoundTripper(settings *Settings) (RoundTripper, error) {
+	timeout := time.Duration(settings.App.TCPTimeoutInSeconds) * time.Second
+
+	if !settings.App.TLS.Enabled {
+		return NewTCPRoundTripper(timeout, timeout, timeout), nil
+	}
+
+	config, err := settings.App.TLS.ClientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("invalid TLS settings: %w", err)
+	}
+
+	return NewTLSRoundTripper(timeout, timeout, timeout, config), nil
+}
+
+func addComparisonFlags(fs *flag.FlagSet) *ComparisonOptions {
+	opts := DefaultComparisonOptions
+
+	fs.Float64Var(&opts.Alpha, "alpha", opts.Alpha, "significance level of the Mann-Whitney U tests")
+	fs.Float64Var(&opts.Confidence, "confidence", opts.Confidence, "confidence level of the bootstrap intervals")
+	fs.Float64Var(&opts.MinEffect, "min-effect", opts.MinEffect, "smallest relative change flagged as a regression")
+	fs.IntVar(&opts.Iterations, "bootstrap", opts.Iterations, "number of bootstrap resamples")
+
+	return &opts
+}
+
+func runBenchCommand(args []string) error {
+	fs := flag.NewFlagSet("bench", flag.ContinueOnError)
+	scenarioPath := fs.String("scenario", "", "path to the scenario yaml file")
+	output := fs.String("o", "", "write the JSON report to this file")
+	logLevel := fs.String("log-level", "warn", "log level (debug, info, warn, error)")
+	baselineDir := fs.String("baseline-dir", defaultBaselineDir, "directory where baselines are stored")
+	saveBaseline := fs.String("save-baseline", "", "save the report as a baseline with this name")
+	compareBaseline := fs.String("compare", "", "compare the report against the baseline with this name")
+	codec := fs.String("codec", "", "serde implementation to benchmark (reflect, generated), overrides the scenario")
+	compression := fs.String("compression", "", "compress protocols with none, gzip, zstd or snappy, as protocol=codec pairs or one codec for all, overrides the scenario")
+	transports := fs.String("transports", "", "comma separated transports every protocol runs on (tcp, unix, loopback), overrides the scenario")
+	useTLS := fs.Bool("tls", false, "connect over TLS with the app.tls settings, overrides app.tls.enabled")
+	encryption := fs.String("encryption", "", "seal protocols with none, aes-gcm or chacha20-poly1305 and app.encryption-key, as protocol=cipher pairs or one cipher for all, overrides the scenario")
+	cborDeterministic := fs.Bool("cbor-deterministic", false, "send CBOR in the core deterministic encoding of RFC 8949, overrides the scenario when set")
+	comparisonOpts := addComparisonFlags(fs)
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *scenarioPath == "" {
+		return fmt.Errorf("the -scenario flag is required")
+	}
+
+	if err := setupCLILogger(*logLevel); err != nil {
+		return err
+	}
+
+	settings, err := LoadConfig[Settings]("TUI", BaseSettings)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	scenario, err := LoadScenario(*scenarioPath)
+	if err != nil {
+		return fmt.Errorf("invalid scenario: %w", err)
+	}
+
+	if *codec != "" {
+		scenario.Codec = *codec
+	}
+
+	if *cborDeterministic {
+		scenario.CBORDeterministic = true
+	}
+
+	if *compression != "" {
+		scenario.Compression, err = ParseCompressions(*compression, scenario.Protocols)
+		if err != nil {
+			return err
+		}
+	}
+
+	if *transports != "" {
+		scenario.Transports = strings.Split(*transports, ",")
+		for _, transport := range scenario.Transports {
+			if !slices.Contains(Transports, transport) {
+				return fmt.Errorf("unknown transport %s", transport)
+			}
+		}
+	}
+
+	if *encryption != "" {
+		scenario.Encryption, err = ParseEncryptions(*encryption, scenario.Protocols)
+		if err != nil {
+			return err
+		}
+	}
+
+	store := NewBaselineStore(*baselineDir)
+
+	// Load the baseline before running, so a typo does not waste a whole run
+	var baseline *BenchmarkReport
+	if *compareBaseline != "" {
+		baseline, err = store.Load(*compareBaseline)
+		if err != nil {
+			return err
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if *useTLS {
+		settings.App.TLS.Enabled = true
+	}
+
+	roundTripper, err := newSettingsRoundTripper(settings)
+	if err != nil {
+		return err
+	}
+
+	runner := NewBenchmarkRunner(scenario, &settings.App, roundTripper)
+
+	report, err := runner.Run(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := report.WriteText(os.Stdout); err != nil {
+		return err
+	}
+
+	if *output != "" {
+		if err := WriteReportFile(*output, report); err != nil {
+			return err
+		}
+	}
+
+	if *saveBaseline != "" {
+		path, err := store.Save(*saveBaseline, report)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("\nBaseline %s saved to %s\n", *saveBaseline, path)
+	}
+
+	if baseline == nil {
+		return nil
+	}
+
+	fmt.Println()
+
+	return reportComparison(CompareReports(*compareBaseline, baseline, report, *comparisonOpts))
+}
+
+func runCompareCommand(args []string) error {
+	fs := flag.NewFlagSet("compare", flag.ContinueOnError)
+	baselineName := fs.String("baseline", "", "name of the baseline to compare against")
+	reportPath := fs.String("report", "", "path to the JSON report to compare")
+	baselineDir := fs.String("baseline-dir", defaultBaselineDir, "directory where baselines are stored")
+	output := fs.String("o", "", "write the JSON comparison to this file")
+	comparisonOpts := addComparisonFlags(fs)
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *baselineName == "" || *reportPath == "" {
+		return fmt.Errorf("the -baseline and -report flags are required")
+	}
+
+	baseline, err := NewBaselineStore(*baselineDir).Load(*baselineName)
+	if err != nil {
+		return err
+	}
+
+	report, err := ReadReportFile(*reportPath)
+	if err != nil {
+		return fmt.Errorf("failed to read report %s: %w", *reportPath, err)
+	}
+
+	comparison := CompareReports(*baselineName, baseline, report, *comparisonOpts)
+
+	if *output != "" {
+		data, err := json.MarshalIndent(comparison, "", "  ")
+		if err != nil {
+			return err
+		}
+
+		if err := os.WriteFile(*output, data, 0o644); err != nil {
+			return err
+		}
+	}
+
+	return reportComparison(comparison)
+}
+
+// reportComparison prints the comparison and fails with ErrRegression so the exit code can gate merges
+func reportComparison(comparison *BenchmarkComparison) error {
+	if err := comparison.WriteText(os.Stdout); err != nil {
+		return err
+	}
+
+	if len(comparison.Regressions()) > 0 {
+		return ErrRegression
+	}
+
+	return nil
+}
+
+func runSweepCommand(args []string) error {
+	fs := flag.NewFlagSet("sweep", flag.ContinueOnError)
+	benchTime := fs.Duration("benchtime", 100*time.Millisecond, "minimum time spent measuring each point")
+	output := fs.String("o", "", "write the CSV curves to this file instead of stdout")
+	protocols := fs.String("protocols", strings.Join(Protocols, ","), "comma-separated protocols to sweep")
+	codecs := fs.String("codecs", strings.Join(Codecs, ","), "comma-separated codecs to sweep (reflect, generated)")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	points, err := RunSweep(strings.Split(*protocols, ","), strings.Split(*codecs, ","), *benchTime)
+	if err != nil {
+		return err
+	}
+
+	if *output == "" {
+		return WriteSweepCSV(os.Stdout, points)
+	}
+
+	f, err := os.Create(*output)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return WriteSweepCSV(f, points)
+}
+
+func runWireCommand(args []string) error {
+	fs := flag.NewFlagSet("wire", flag.ContinueOnError)
+	reportPath := fs.String("report", "", "path to the JSON report produced by bench")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *reportPath == "" {
+		return fmt.Errorf("the -report flag is required")
+	}
+
+	report, err := ReadReportFile(*reportPath)
+	if err != nil {
+		return fmt.Errorf("failed to read report %s: %w", *reportPath, err)
+	}
+
+	return WriteWireText(os.Stdout, report.WireSummaries())
+}
+
+func runCertsCommand(args []string) error {
+	fs := flag.NewFlagSet("certs", flag.ContinueOnError)
+	dir := fs.String("dir", "certs", "directory the CA, server and client certificates are written to")
+	hosts := fs.String("hosts", "localhost,127.0.0.1", "comma separated names and addresses of the server certificate")
+	valid := fs.Duration("valid", 30*24*time.Hour, "validity of the certificates")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	files, err := GenerateCertificates(*dir, strings.Split(*hosts, ","), *valid)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("CA:     %s\nServer: %s %s\nClient: %s %s\n", files.CA, files.ServerCert, files.ServerKey, files.ClientCert, files.ClientKey)
+
+	return nil
+}
+
+func runCallCommand(args []string) error {
+	fs := flag.NewFlagSet("call", flag.ContinueOnError)
+	protocol := fs.String("protocol", ProtocolJSON, "protocol of the operation (json, string, protobuf, msgpack, cbor)")
+	compression := fs.String("compression", CompressionNone, "compression of the protocol (none, gzip, zstd, snappy)")
+	studentID := fs.String("student-id", defaultEnrollmentID, "enrollment ID to log in with")
+	operation := fs.String("operation", "", "operation to run (echo, soma, timestamp, historico, status)")
+	params := fs.String("params", "{}", `parameters of the operation as a JSON object of their wire names, like {"mensagem":"ola"}`)
+	useTLS := fs.Bool("tls", false, "connect over TLS with the app.tls settings, overrides app.tls.enabled")
+	logLevel := fs.String("log-level", "warn", "log level (debug, info, warn, error)")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *operation == "" {
+		return fmt.Errorf("the -operation flag is required")
+	}
+	if !slices.Contains(Protocols, *protocol) {
+		return fmt.Errorf("unknown protocol %s", *protocol)
+	}
+	if !slices.Contains(Compressions, *compression) {
+		return fmt.Errorf("unknown compression %s", *compression)
+	}
+
+	req, err := ParseOperationRequest(*operation, []byte(*params))
+	if err != nil {
+		return err
+	}
+
+	if err := setupCLILogger(*logLevel); err != nil {
+		return err
+	}
+
+	settings, err := LoadConfig[Settings]("TUI", BaseSettings)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	if *useTLS {
+		settings.App.TLS.Enabled = true
+	}
+
+	roundTripper, err := newSettingsRoundTripper(settings)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	session, _, err := openSession(ctx, settings, roundTripper, *protocol, *compression, *studentID)
+	if err != nil {
+		return fmt.Errorf("authentication failed: %w", err)
+	}
+
+	resp, err := NewOperationResponse(*operation)
+	if err != nil {
+		return err
+	}
+
+	client := NewAppLayerClient[OperationRequest, OperationResponse](session.serde, roundTripper, &settings.App)
+	err = client.Do(ctx, session.address, req, resp, session.token)
+
+	// Log out also after a failed operation
+	_, logoutErr := closeSession(ctx, settings, roundTripper, session)
+
+	if err != nil {
+		return fmt.Errorf("%s failed: %w", *operation, err)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(resp); err != nil {
+		return err
+	}
+
+	if logoutErr != nil {
+		return fmt.Errorf("logout failed: %w", logoutErr)
+	}
+
+	return nil
+}
+
+func runServeCommand(args []string) error {
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	protocol := fs.String("protocol", ProtocolJSON, "protocol to serve (json, string, protobuf, msgpack, cbor)")
+	address := fs.String("address", "", "host:port or unix:// address to listen on, defaults to the configured address of the protocol")
+	compression := fs.String("compression", CompressionNone, "compression of the protocol (none, gzip, zstd, snappy)")
+	encryption := fs.String("encryption", CipherNone, "seal the protocol with none, aes-gcm or chacha20-poly1305 and app.encryption-key")
+	useTLS := fs.Bool("tls", false, "serve TLS with the app.tls settings, overrides app.tls.enabled")
+	logLevel := fs.String("log-level", "info", "log level (debug, info, warn, error)")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if !slices.Contains(Protocols, *protocol) {
+		return fmt.Errorf("unknown protocol %s", *protocol)
+	}
+	if !slices.Contains(Compressions, *compression) {
+		return fmt.Errorf("unknown compression %s", *compression)
+	}
+	if !slices.Contains(Ciphers, *encryption) {
+		return fmt.Errorf("unknown cipher %s", *encryption)
+	}
+
+	if err := setupCLILogger(*logLevel); err != nil {
+		return err
+	}
+
+	settings, err := LoadConfig[Settings]("TUI", BaseSettings)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	if *address == "" {
+		*address, err = settings.App.ServerAddress(*protocol)
+		if err != nil {
+			return err
+		}
+	}
+	if !ValidAddress(*address) {
+		return fmt.Errorf("invalid address %s, expected host:port or unix:///absolute/path", *address)
+	}
+
+	serde, _, err := newClientSerde(*protocol, *compression)
+	if err != nil {
+		return err
+	}
+
+	if *encryption != CipherNone {
+		key, err := settings.App.PreSharedKey()
+		if err != nil {
+			return err
+		}
+
+		serde, err = NewEncryptedSerde(serde, *encryption, key)
+		if err != nil {
+			return err
+		}
+	}
+
+	if *useTLS {
+		settings.App.TLS.Enabled = true
+	}
+
+	var listener net.Listener
+	if settings.App.TLS.Enabled {
+		listener, err = NewTLSListener(*address, settings.App.TLS)
+	} else {
+		listener, err = Listen(*address)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", *address, err)
+	}
+
+	timeout := time.Duration(settings.App.TCPTimeoutInSeconds) * time.Second
+	server, err := NewProtocolServer(listener, serde, timeout)
+	if err != nil {
+		listener.Close()
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	slog.Info("Serving protocol", slog.String("protocol", *protocol), slog.String("address", server.Address()), slog.Bool("tls", settings.App.TLS.Enabled))
+
+	return server.Serve(ctx)
+}
+
+func runTUICommand(args []string) error {
+	fs := flag.NewFlagSet("tui", flag.ContinueOnError)
+	theme := fs.String("theme", "", "theme of the TUI, built in or from app.themes, overrides app.theme")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	return RunTUI(TUIOptions{Theme: *theme})
+}
@@ -0,0 +1,716 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+type typeKind int
+
+const (
+	kindString typeKind = iota
+	kindInt
+	kindFloat64
+	kindBool
+	kindAny
+	kindTime
+	kindNonISO8601Time
+	kindUnixTimestamp
+	kindStruct
+	kindPointer
+	kindSlice
+	kindMap
+)
+
+// basicKinds are the types the runtime of the main package has hand-written codecs for
+var basicKinds = map[string]typeKind{
+	"string":         kindString,
+	"int":            kindInt,
+	"float64":        kindFloat64,
+	"bool":           kindBool,
+	"any":            kindAny,
+	"NonISO8601Time": kindNonISO8601Time,
+	"UnixTimestamp":  kindUnixTimestamp,
+}
+
+// typeRef is a field type the generator can emit codecs for
+type typeRef struct {
+	kind typeKind
+	name string
+	elem *typeRef
+}
+
+func (t *typeRef) goType() string {
+	switch t.kind {
+	case kindString:
+		return "string"
+	case kindInt:
+		return "int"
+	case kindFloat64:
+		return "float64"
+	case kindBool:
+		return "bool"
+	case kindAny:
+		return "any"
+	case kindTime:
+		return "time.Time"
+	case kindNonISO8601Time:
+		return "NonISO8601Time"
+	case kindUnixTimestamp:
+		return "UnixTimestamp"
+	case kindPointer:
+		return "*" + t.elem.goType()
+	case kindSlice:
+		return "[]" + t.elem.goType()
+	case kindMap:
+		return "map[string]" + t.elem.goType()
+	default:
+		return t.name
+	}
+}
+
+// ident names the codec functions of the type, like decodeJSONSliceFloat64
+func (t *typeRef) ident() string {
+	switch t.kind {
+	case kindString:
+		return "String"
+	case kindInt:
+		return "Int"
+	case kindFloat64:
+		return "Float64"
+	case kindBool:
+		return "Bool"
+	case kindAny:
+		return "Any"
+	case kindTime:
+		return "Time"
+	case kindPointer:
+		return "Ptr" + t.elem.ident()
+	case kindSlice:
+		return "Slice" + t.elem.ident()
+	case kindMap:
+		return "Map" + t.elem.ident()
+	default:
+		return t.goType()
+	}
+}
+
+// composite types get generated codecs, the others use the runtime ones
+func (t *typeRef) composite() bool {
+	return t.kind >= kindStruct
+}
+
+// errorType names the type like encoding/json does in type errors
+func (t *typeRef) errorType() string {
+	if t.kind == kindStruct {
+		return "main." + t.name
+	}
+	return strings.ReplaceAll(t.goType(), "any", "interface {}")
+}
+
+type field struct {
+	name string
+	typ  *typeRef
+
+	// stringTag is the tag getFieldTagValue returns
+	stringTag      string
+	stringName     string
+	stringOptional bool
+
+	jsonName      string
+	jsonOmitEmpty bool
+	jsonSkip      bool
+}
+
+type domain struct {
+	structs   map[string]*ast.StructType
+	methods   map[string][]string
+	requests  []string
+	responses []string
+}
+
+func parseDomain(filename string, src []byte) (*domain, error) {
+	file, err := parser.ParseFile(token.NewFileSet(), filename, src, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &domain{
+		structs: map[string]*ast.StructType{},
+		methods: map[string][]string{},
+	}
+	order := []string{}
+
+	for _, decl := range file.Decls {
+		switch decl := decl.(type) {
+		case *ast.GenDecl:
+			if decl.Tok != token.TYPE {
+				continue
+			}
+
+			for _, spec := range decl.Specs {
+				typeSpec := spec.(*ast.TypeSpec)
+				structType, ok := typeSpec.Type.(*ast.StructType)
+				if !ok || typeSpec.TypeParams != nil {
+					continue
+				}
+
+				d.structs[typeSpec.Name.Name] = structType
+				order = append(order, typeSpec.Name.Name)
+			}
+		case *ast.FuncDecl:
+			if decl.Recv == nil || len(decl.Recv.List) == 0 {
+				continue
+			}
+
+			recv := decl.Recv.List[0].Type
+			if star, ok := recv.(*ast.StarExpr); ok {
+				recv = star.X
+			}
+			if ident, ok := recv.(*ast.Ident); ok {
+				d.methods[ident.Name] = append(d.methods[ident.Name], decl.Name.Name)
+			}
+		}
+	}
+
+	for _, name := range order {
+		if slices.Contains(d.methods[name], "CommandOrOperationName") {
+			d.requests = append(d.requests, name)
+		}
+		if slices.Contains(d.methods[name], "OperationResponseName") {
+			d.responses = append(d.responses, name)
+		}
+	}
+
+	if len(d.requests) == 0 && len(d.responses) == 0 {
+		return nil, fmt.Errorf("%s declares no request or response types", filename)
+	}
+
+	return d, nil
+}
+
+func (d *domain) resolve(expr ast.Expr) (*typeRef, error) {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		if kind, ok := basicKinds[e.Name]; ok {
+			return &typeRef{kind: kind}, nil
+		}
+
+		if _, ok := d.structs[e.Name]; !ok {
+			return nil, fmt.Errorf("unsupported type %s", e.Name)
+		}
+
+		if slices.Contains(d.methods[e.Name], "MarshalJSON") || slices.Contains(d.methods[e.Name], "UnmarshalJSON") {
+			return nil, fmt.Errorf("type %s has custom JSON methods, add it to the runtime codecs", e.Name)
+		}
+
+		return &typeRef{kind: kindStruct, name: e.Name}, nil
+	case *ast.SelectorExpr:
+		if pkg, ok := e.X.(*ast.Ident); ok && pkg.Name == "time" && e.Sel.Name == "Time" {
+			return &typeRef{kind: kindTime}, nil
+		}
+	case *ast.StarExpr:
+		elem, err := d.resolve(e.X)
+		if err != nil {
+			return nil, err
+		}
+		return &typeRef{kind: kindPointer, elem: elem}, nil
+	case *ast.ArrayType:
+		if e.Len != nil {
+			return nil, fmt.Errorf("arrays are not supported")
+		}
+		elem, err := d.resolve(e.Elt)
+		if err != nil {
+			return nil, err
+		}
+		return &typeRef{kind: kindSlice, elem: elem}, nil
+	case *ast.MapType:
+		if key, ok := e.Key.(*ast.Ident); !ok || key.Name != "string" {
+			return nil, fmt.Errorf("only maps with string keys are supported")
+		}
+		elem, err := d.resolve(e.Value)
+		if err != nil {
+			return nil, err
+		}
+		return &typeRef{kind: kindMap, elem: elem}, nil
+	case *ast.InterfaceType:
+		if len(e.Methods.List) == 0 {
+			return &typeRef{kind: kindAny}, nil
+		}
+	}
+
+	return nil, fmt.Errorf("unsupported type expression %T", expr)
+}
+
+func (d *domain) fields(name string) ([]field, error) {
+	fields := []field{}
+
+	for _, f := range d.structs[name].Fields.List {
+		if len(f.Names) == 0 {
+			return nil, fmt.Errorf("%s: embedded fields are not supported", name)
+		}
+
+		typ, err := d.resolve(f.Type)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+
+		tag := ""
+		if f.Tag != nil {
+			tag, err = strconv.Unquote(f.Tag.Value)
+			if err != nil {
+				return nil, err
+			}
+		}
+		structTag := reflect.StructTag(tag)
+
+		for _, fieldName := range f.Names {
+			if !fieldName.IsExported() {
+				return nil, fmt.Errorf("%s.%s: unexported fields are not supported", name, fieldName.Name)
+			}
+
+			stringTag := structTag.Get("strings")
+			if stringTag == "" {
+				stringTag = structTag.Get("json")
+			}
+			if stringTag == "" {
+				stringTag = fieldName.Name
+			}
+			stringName, _, _ := strings.Cut(stringTag, ",")
+
+			jsonTag := structTag.Get("json")
+			jsonName, jsonOptions, _ := strings.Cut(jsonTag, ",")
+			if jsonName == "" {
+				jsonName = fieldName.Name
+			}
+
+			fields = append(fields, field{
+				name:           fieldName.Name,
+				typ:            typ,
+				stringTag:      stringTag,
+				stringName:     stringName,
+				stringOptional: strings.Contains(stringTag, "omitempty"),
+				jsonName:       jsonName,
+				jsonOmitEmpty:  slices.Contains(strings.Split(jsonOptions, ","), "omitempty"),
+				jsonSkip:       jsonTag == "-",
+			})
+		}
+	}
+
+	return fields, nil
+}
+
+type generator struct {
+	domain *domain
+	done   map[string]bool
+	funcs  []string
+}
+
+// Generate returns the formatted source of the codecs for the types declared in src
+func Generate(filename string, src []byte) ([]byte, error) {
+	d, err := parseDomain(filename, src)
+	if err != nil {
+		return nil, err
+	}
+
+	g := &generator{domain: d, done: map[string]bool{}}
+
+	var dispatch bytes.Buffer
+	if err := g.dispatch(&dispatch); err != nil {
+		return nil, err
+	}
+
+	body := dispatch.String() + strings.Join(g.funcs, "\n")
+
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "// Code generated by serdegen from %s. DO NOT EDIT.\n\npackage main\n\n", filename)
+
+	out.WriteString("import (\n")
+	for _, pkg := range []string{"fmt", "maps", "slices", "strconv", "time"} {
+		if strings.Contains(body, pkg+".") {
+			fmt.Fprintf(&out, "%q\n", pkg)
+		}
+	}
+	out.WriteString(")\n\n")
+	out.WriteString(body)
+
+	code, err := format.Source(out.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("generated invalid code: %w\n%s", err, out.String())
+	}
+
+	return code, nil
+}
+
+// dispatch writes the functions the serdes call, they switch on the body type
+func (g *generator) dispatch(b *bytes.Buffer) error {
+	b.WriteString("// appendStringRequestBody appends the fields of body, ok is false when it has no generated codec\n")
+	b.WriteString("func appendStringRequestBody(buf []byte, body OperationRequest) ([]byte, bool) {\nswitch body := body.(type) {\n")
+	for _, name := range g.domain.requests {
+		fn, err := g.appendString(name)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(b, "case %s:\nreturn %s(buf, body), true\n", name, fn)
+	}
+	b.WriteString("default:\nreturn buf, false\n}\n}\n\n")
+
+	b.WriteString("// appendJSONRequestBody appends the parameters of body, ok is false when it has no generated codec\n")
+	b.WriteString("func appendJSONRequestBody(buf []byte, body OperationRequest) ([]byte, bool, error) {\nswitch body := body.(type) {\n")
+	for _, name := range g.domain.requests {
+		fn, err := g.appendJSON(&typeRef{kind: kindStruct, name: name})
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(b, "case %s:\nbuf, err := %s(buf, body)\nreturn buf, true, err\n", name, fn)
+	}
+	b.WriteString("default:\nreturn buf, false, nil\n}\n}\n\n")
+
+	b.WriteString("// generatedResponseTarget exposes the fields of v when its body has generated codecs\n")
+	b.WriteString("func generatedResponseTarget(v any) (responseTarget, bool) {\nswitch r := v.(type) {\n")
+	b.WriteString("case *PresentationLayerResponse[OperationResponse]:\nif r == nil || !hasGeneratedResponseCodec(r.Body) {\nreturn responseTarget{}, false\n}\n")
+	b.WriteString("return responseTarget{body: r.Body, statusCode: &r.StatusCode, err: &r.Err}, true\n")
+	for _, name := range g.domain.responses {
+		fmt.Fprintf(b, "case *PresentationLayerResponse[*%s]:\nif r == nil || r.Body == nil {\nreturn responseTarget{}, false\n}\n", name)
+		b.WriteString("return responseTarget{body: r.Body, statusCode: &r.StatusCode, err: &r.Err}, true\n")
+	}
+	b.WriteString("default:\nreturn responseTarget{}, false\n}\n}\n\n")
+
+	b.WriteString("func hasGeneratedResponseCodec(body OperationResponse) bool {\nswitch body := body.(type) {\n")
+	for _, name := range g.domain.responses {
+		fmt.Fprintf(b, "case *%s:\nreturn body != nil\n", name)
+	}
+	b.WriteString("default:\nreturn false\n}\n}\n\n")
+
+	b.WriteString("func bindStringResponseBody(body OperationResponse, properties map[string]string) error {\nswitch body := body.(type) {\n")
+	for _, name := range g.domain.responses {
+		fn, err := g.bindString(name)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(b, "case *%s:\nreturn %s(body, properties)\n", name, fn)
+	}
+	b.WriteString("default:\nreturn fmt.Errorf(\"no generated string codec for %T\", body)\n}\n}\n\n")
+
+	b.WriteString("func decodeJSONResponseBody(d *jsonDecoder, body OperationResponse) error {\nswitch body := body.(type) {\n")
+	for _, name := range g.domain.responses {
+		fn, err := g.decodeJSON(&typeRef{kind: kindStruct, name: name})
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(b, "case *%s:\nreturn %s(d, body)\n", name, fn)
+	}
+	b.WriteString("default:\nreturn fmt.Errorf(\"no generated JSON codec for %T\", body)\n}\n}\n\n")
+
+	b.WriteString("// setJSONResponseTimestamp stamps body with the timestamp of the reply envelope\n")
+	b.WriteString("func setJSONResponseTimestamp(body OperationResponse, timestamp NonISO8601Time) {\nswitch body := body.(type) {\n")
+	for _, name := range g.domain.responses {
+		fields, err := g.domain.fields(name)
+		if err != nil {
+			return err
+		}
+		if slices.ContainsFunc(fields, func(f field) bool { return f.name == "Timestamp" && f.typ.kind == kindNonISO8601Time }) {
+			fmt.Fprintf(b, "case *%s:\nbody.Timestamp = timestamp\n", name)
+		}
+	}
+	b.WriteString("}\n}\n\n")
+
+	return nil
+}
+
+// emit builds the function name once, even for recursive types
+func (g *generator) emit(name string, build func(b *bytes.Buffer) error) (string, error) {
+	if g.done[name] {
+		return name, nil
+	}
+	g.done[name] = true
+
+	var b bytes.Buffer
+	if err := build(&b); err != nil {
+		return "", err
+	}
+	g.funcs = append(g.funcs, b.String())
+
+	return name, nil
+}
+
+// appendString emits the string protocol encoder of a request, the field kinds
+// are the ones getStrFieldRepresentation prints
+func (g *generator) appendString(name string) (string, error) {
+	return g.emit("appendString"+name, func(b *bytes.Buffer) error {
+		fields, err := g.domain.fields(name)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(b, "func appendString%s(buf []byte, v %s) []byte {\n", name, name)
+		for _, f := range fields {
+			fmt.Fprintf(b, "buf = append(buf, %q...)\n", "|"+f.stringTag+"=")
+
+			expr := "v." + f.name
+			switch {
+			case f.typ.kind == kindString:
+				fmt.Fprintf(b, "buf = append(buf, %s...)\n", expr)
+			case f.typ.kind == kindInt:
+				fmt.Fprintf(b, "buf = strconv.AppendInt(buf, int64(%s), 10)\n", expr)
+			case f.typ.kind == kindBool:
+				fmt.Fprintf(b, "buf = strconv.AppendBool(buf, %s)\n", expr)
+			case f.typ.kind == kindSlice && f.typ.elem.kind == kindInt:
+				fmt.Fprintf(b, "buf = appendStringInts(buf, %s)\n", expr)
+			case f.typ.kind == kindUnixTimestamp:
+				fmt.Fprintf(b, "buf = appendStringUnixTimestamp(buf, %s)\n", expr)
+			case f.typ.kind == kindTime:
+				fmt.Fprintf(b, "buf = %s.AppendFormat(buf, time.RFC3339)\n", expr)
+			default:
+				return fmt.Errorf("%s.%s: the string protocol cannot encode %s", name, f.name, f.typ.goType())
+			}
+		}
+		b.WriteString("return buf\n}\n")
+
+		return nil
+	})
+}
+
+// bindString emits the binder of a struct from its string protocol properties, like bindStructFields
+func (g *generator) bindString(name string) (string, error) {
+	return g.emit("bindString"+name, func(b *bytes.Buffer) error {
+		fields, err := g.domain.fields(name)
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(b, "func bindString%s(v *%s, properties map[string]string) error {\n", name, name)
+		for _, f := range fields {
+			fn, err := g.parseString(f.typ)
+			if err != nil {
+				return err
+			}
+
+			if f.stringOptional {
+				fmt.Fprintf(b, "if valueStr, ok := properties[%q]; ok {\nif err := %s(valueStr, &v.%s); err != nil {\nreturn err\n}\n}\n", f.stringName, fn, f.name)
+			} else {
+				fmt.Fprintf(b, "if valueStr, ok := properties[%q]; !ok {\nreturn stringPropertyNotFound(%q)\n} else if err := %s(valueStr, &v.%s); err != nil {\nreturn err\n}\n", f.stringName, f.stringName, fn, f.name)
+			}
+		}
+		b.WriteString("\nreturn nil\n}\n")
+
+		return nil
+	})
+}
+
+// parseString emits the parser of a string protocol value, like setFieldValueFromString
+func (g *generator) parseString(t *typeRef) (string, error) {
+	name := "parseString" + t.ident()
+	if !t.composite() {
+		return name, nil
+	}
+
+	return g.emit(name, func(b *bytes.Buffer) error {
+		fmt.Fprintf(b, "func %s(valueStr string, v *%s) error {\n", name, t.goType())
+
+		switch t.kind {
+		case kindStruct:
+			bind, err := g.bindString(t.name)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(b, "properties, err := stringDictProperties(valueStr)\nif err != nil {\nreturn err\n}\n\nreturn %s(v, properties)\n}\n", bind)
+		case kindPointer:
+			elem, err := g.parseString(t.elem)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(b, "if *v == nil {\n*v = new(%s)\n}\n\nreturn %s(valueStr, *v)\n}\n", t.elem.goType(), elem)
+		case kindSlice:
+			elem, err := g.parseString(t.elem)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(b, "items, err := stringSliceItems(valueStr)\nif err != nil {\nreturn err\n}\n\n")
+			fmt.Fprintf(b, "values := make(%s, 0, len(items))\nfor _, item := range items {\nvar value %s\nif err := %s(item, &value); err != nil {\nreturn err\n}\nvalues = append(values, value)\n}\n*v = values\n\nreturn nil\n}\n", t.goType(), t.elem.goType(), elem)
+		case kindMap:
+			elem, err := g.parseString(t.elem)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(b, "properties, err := stringDictProperties(valueStr)\nif err != nil {\nreturn err\n}\n\n")
+			fmt.Fprintf(b, "values := make(%s, len(properties))\nfor key, item := range properties {\nvar value %s\nif err := %s(item, &value); err != nil {\nreturn err\n}\nvalues[key] = value\n}\n*v = values\n\nreturn nil\n}\n", t.goType(), t.elem.goType(), elem)
+		}
+
+		return nil
+	})
+}
+
+// decodeJSON emits the JSON decoder of a value, with encoding/json semantics
+func (g *generator) decodeJSON(t *typeRef) (string, error) {
+	name := "decodeJSON" + t.ident()
+	if !t.composite() {
+		return name, nil
+	}
+
+	return g.emit(name, func(b *bytes.Buffer) error {
+		fmt.Fprintf(b, "func %s(d *jsonDecoder, v *%s) error {\n", name, t.goType())
+
+		switch t.kind {
+		case kindStruct:
+			fields, err := g.domain.fields(t.name)
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintf(b, "if d.null() {\nreturn nil\n}\n\nif err := d.openObject(%q); err != nil {\nreturn err\n}\n\n", t.errorType())
+			b.WriteString("for first := true; ; first = false {\nkey, ok, err := d.nextKey(first)\nif err != nil || !ok {\nreturn err\n}\n\nswitch {\n")
+			for _, f := range fields {
+				if f.jsonSkip {
+					continue
+				}
+
+				fn, err := g.decodeJSON(f.typ)
+				if err != nil {
+					return err
+				}
+				fmt.Fprintf(b, "case jsonKeyIs(key, %q):\nerr = %s(d, &v.%s)\n", f.jsonName, fn, f.name)
+			}
+			b.WriteString("default:\nerr = d.skipValue()\n}\nif err != nil {\nreturn err\n}\n}\n}\n")
+		case kindPointer:
+			elem, err := g.decodeJSON(t.elem)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(b, "if d.null() {\n*v = nil\nreturn nil\n}\n\nif *v == nil {\n*v = new(%s)\n}\n\nreturn %s(d, *v)\n}\n", t.elem.goType(), elem)
+		case kindSlice:
+			elem, err := g.decodeJSON(t.elem)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(b, "if d.null() {\n*v = nil\nreturn nil\n}\n\nif err := d.openArray(%q); err != nil {\nreturn err\n}\n\n", t.errorType())
+			fmt.Fprintf(b, "items := (*v)[:0]\nfor first := true; ; first = false {\nmore, err := d.nextElement(first)\nif err != nil {\nreturn err\n}\nif !more {\nbreak\n}\n\n")
+			fmt.Fprintf(b, "var item %s\nif err := %s(d, &item); err != nil {\nreturn err\n}\nitems = append(items, item)\n}\n\n", t.elem.goType(), elem)
+			fmt.Fprintf(b, "if items == nil {\nitems = %s{}\n}\n*v = items\n\nreturn nil\n}\n", t.goType())
+		case kindMap:
+			elem, err := g.decodeJSON(t.elem)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(b, "if d.null() {\n*v = nil\nreturn nil\n}\n\nif err := d.openObject(%q); err != nil {\nreturn err\n}\n\n", t.errorType())
+			fmt.Fprintf(b, "if *v == nil {\n*v = %s{}\n}\n\n", t.goType())
+			fmt.Fprintf(b, "for first := true; ; first = false {\nkey, ok, err := d.nextKey(first)\nif err != nil || !ok {\nreturn err\n}\n\n")
+			fmt.Fprintf(b, "var item %s\nif err := %s(d, &item); err != nil {\nreturn err\n}\n(*v)[key] = item\n}\n}\n", t.elem.goType(), elem)
+		}
+
+		return nil
+	})
+}
+
+// appendJSON emits the JSON encoder of a value, with encoding/json output
+func (g *generator) appendJSON(t *typeRef) (string, error) {
+	name := "appendJSON" + t.ident()
+	if !t.composite() {
+		return name, nil
+	}
+
+	return g.emit(name, func(b *bytes.Buffer) error {
+		fmt.Fprintf(b, "func %s(buf []byte, v %s) ([]byte, error) {\n", name, t.goType())
+
+		switch t.kind {
+		case kindStruct:
+			fields, err := g.domain.fields(t.name)
+			if err != nil {
+				return err
+			}
+
+			// Every field is written after a comma, the first comma becomes the opening brace
+			b.WriteString("var err error\nstart := len(buf)\n")
+			for _, f := range fields {
+				if f.jsonSkip {
+					continue
+				}
+
+				key, err := json.Marshal(f.jsonName)
+				if err != nil {
+					return err
+				}
+
+				value, err := g.jsonValue(f.typ, "v."+f.name)
+				if err != nil {
+					return err
+				}
+
+				write := fmt.Sprintf("buf = append(buf, %q...)\n%s", ","+string(key)+":", value)
+				if condition := notEmpty(f.typ, "v."+f.name); f.jsonOmitEmpty && condition != "" {
+					write = fmt.Sprintf("if %s {\n%s}\n", condition, write)
+				}
+				b.WriteString(write)
+			}
+			b.WriteString("\nif len(buf) == start {\nbuf = append(buf, '{')\n} else {\nbuf[start] = '{'\n}\n\nreturn append(buf, '}'), err\n}\n")
+		case kindPointer:
+			value, err := g.jsonValue(t.elem, "*v")
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(b, "if v == nil {\nreturn append(buf, \"null\"...), nil\n}\n\nvar err error\n%s\nreturn buf, err\n}\n", value)
+		case kindSlice:
+			value, err := g.jsonValue(t.elem, "item")
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(b, "if v == nil {\nreturn append(buf, \"null\"...), nil\n}\n\nvar err error\nbuf = append(buf, '[')\n")
+			fmt.Fprintf(b, "for i, item := range v {\nif i > 0 {\nbuf = append(buf, ',')\n}\n%s}\n\nreturn append(buf, ']'), err\n}\n", value)
+		case kindMap:
+			value, err := g.jsonValue(t.elem, "v[key]")
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(b, "if v == nil {\nreturn append(buf, \"null\"...), nil\n}\n\nvar err error\nbuf = append(buf, '{')\n")
+			fmt.Fprintf(b, "for i, key := range slices.Sorted(maps.Keys(v)) {\nif i > 0 {\nbuf = append(buf, ',')\n}\nbuf = appendJSONString(buf, key)\nbuf = append(buf, ':')\n%s}\n\nreturn append(buf, '}'), err\n}\n", value)
+		}
+
+		return nil
+	})
+}
+
+// jsonValue returns the statements appending expr to buf
+func (g *generator) jsonValue(t *typeRef, expr string) (string, error) {
+	switch t.kind {
+	case kindString:
+		return fmt.Sprintf("buf = appendJSONString(buf, %s)\n", expr), nil
+	case kindInt:
+		return fmt.Sprintf("buf = strconv.AppendInt(buf, int64(%s), 10)\n", expr), nil
+	case kindBool:
+		return fmt.Sprintf("buf = strconv.AppendBool(buf, %s)\n", expr), nil
+	}
+
+	fn, err := g.appendJSON(t)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("if buf, err = %s(buf, %s); err != nil {\nreturn nil, err\n}\n", fn, expr), nil
+}
+
+// notEmpty returns the omitempty condition of expr, empty when encoding/json never omits the type
+func notEmpty(t *typeRef, expr string) string {
+	switch t.kind {
+	case kindString:
+		return expr + ` != ""`
+	case kindInt, kindFloat64:
+		return expr + " != 0"
+	case kindBool:
+		return expr
+	case kindSlice, kindMap:
+		return "len(" + expr + ") > 0"
+	case kindPointer, kindAny:
+		return expr + " != nil"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGeneratedCodecsAreUpToDate(t *testing.T) {
+	// Arrange
+	src, err := os.ReadFile("../../domain.go")
+	require.NoError(t, err)
+
+	expected, err := os.ReadFile("../../codec_generated.go")
+	require.NoError(t, err)
+
+	// Act
+	actual, err := Generate("domain.go", src)
+
+	// Assert
+	require.NoError(t, err, "Generate should not return an error")
+	assert.Equal(t, string(expected), string(actual), "codec_generated.go is stale, run go generate")
+}
+
+func TestGenerateRejectsUnsupportedTypes(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+	}{
+		{
+			name: "no domain types",
+			src:  "package main\n\ntype Plain struct{ Name string }\n",
+		},
+		{
+			name: "unsupported field type",
+			src:  "package main\n\ntype EchoRequest struct{ Channel chan int }\n\nfunc (EchoRequest) CommandOrOperationName() string { return \"echo\" }\n",
+		},
+		{
+			name: "embedded field",
+			src:  "package main\n\ntype Base struct{}\n\ntype EchoRequest struct{ Base }\n\nfunc (EchoRequest) CommandOrOperationName() string { return \"echo\" }\n",
+		},
+		{
+			name: "string protocol cannot encode the field",
+			src:  "package main\n\ntype EchoRequest struct{ Ratio float64 }\n\nfunc (EchoRequest) CommandOrOperationName() string { return \"echo\" }\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			_, err := Generate("domain.go", []byte(tt.src))
+
+			// Assert
+			assert.Error(t, err)
+		})
+	}
+}
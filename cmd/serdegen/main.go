@@ -0,0 +1,35 @@
+// Command serdegen generates reflection-free String and JSON codecs for the request
+// and response types declared in domain.go, run it through go generate.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+func main() {
+	input := flag.String("input", "domain.go", "file declaring the domain types")
+	output := flag.String("output", "codec_generated.go", "file the codecs are written to")
+	flag.Parse()
+
+	if err := run(*input, *output); err != nil {
+		fmt.Fprintf(os.Stderr, "serdegen: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(input, output string) error {
+	src, err := os.ReadFile(input)
+	if err != nil {
+		return err
+	}
+
+	code, err := Generate(filepath.Base(input), src)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(output, code, 0o644)
+}
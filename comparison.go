@@ -0,0 +1,360 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"regexp"
+	"text/tabwriter"
+	"time"
+)
+
+const (
+	defaultBaselineDir = ".benchmarks"
+	totalOperationName = "total"
+)
+
+var (
+	ErrRegression = errors.New("performance regression detected")
+
+	baselineNameRegex = regexp.MustCompile(`^[A-Za-z0-9._-]+$`)
+)
+
+type ComparisonOptions struct {
+	// Alpha is the significance level of the Mann-Whitney U tests
+	Alpha float64
+	// Confidence is the level of the bootstrap confidence intervals
+	Confidence float64
+	// MinEffect is the smallest relative change reported as a regression
+	MinEffect float64
+	// Iterations is the number of bootstrap resamples
+	Iterations int
+	Seed       uint64
+}
+
+var DefaultComparisonOptions = ComparisonOptions{
+	Alpha:      0.05,
+	Confidence: 0.95,
+	MinEffect:  0.05,
+	Iterations: 1000,
+}
+
+type ComparisonFinding struct {
+	Protocol   string  `json:"protocol"`
+	Operation  string  `json:"operation"`
+	Metric     string  `json:"metric"`
+	Baseline   float64 `json:"baseline"`
+	Current    float64 `json:"current"`
+	Change     float64 `json:"change"`
+	PValue     float64 `json:"p_value"`
+	CILow      float64 `json:"ci_low"`
+	CIHigh     float64 `json:"ci_high"`
+	Regression bool    `json:"regression"`
+	// Missing marks a protocol or operation of the baseline that the current run did not measure
+	Missing bool `json:"missing,omitempty"`
+}
+
+type BenchmarkComparison struct {
+	Baseline string              `json:"baseline"`
+	Scenario string              `json:"scenario"`
+	Findings []ComparisonFinding `json:"findings"`
+}
+
+func (c *BenchmarkComparison) Regressions() []ComparisonFinding {
+	regressions := []ComparisonFinding{}
+	for _, finding := range c.Findings {
+		if finding.Regression {
+			regressions = append(regressions, finding)
+		}
+	}
+	return regressions
+}
+
+type metricComparison struct {
+	name          string
+	baseline      []float64
+	current       []float64
+	stat          func([]float64) float64
+	higherIsWorse bool
+	// percentile metrics are judged by the bootstrap interval, the others by the Mann-Whitney U test
+	percentile bool
+}
+
+// CompareReports compares every protocol and operation of current against the baseline
+func CompareReports(baselineName string, baseline, current *BenchmarkReport, opts ComparisonOptions) *BenchmarkComparison {
+	rng := rand.New(rand.NewPCG(opts.Seed, 0))
+	comparison := &BenchmarkComparison{
+		Baseline: baselineName,
+		Scenario: current.Scenario.Name,
+	}
+
+	for _, cur := range current.Results {
+		base, ok := findProtocolResult(baseline, cur.Name())
+		if !ok {
+			continue
+		}
+
+		metrics := []metricComparison{
+			{name: "throughput", baseline: base.ThroughputSeries, current: cur.ThroughputSeries, stat: mean},
+			{name: "allocs_per_request", baseline: base.AllocsSeries, current: cur.AllocsSeries, stat: mean, higherIsWorse: true},
+		}
+		comparison.compare(cur.Name(), totalOperationName, metrics, opts, rng)
+
+		for _, curOp := range cur.Operations {
+			baseOp, ok := findOperationResult(base, curOp.Operation)
+			if !ok {
+				continue
+			}
+
+			baseLatencies := durationsToFloats(baseOp.Latencies)
+			curLatencies := durationsToFloats(curOp.Latencies)
+
+			metrics := []metricComparison{
+				{name: "latency_p50", baseline: baseLatencies, current: curLatencies, stat: quantile(50), higherIsWorse: true, percentile: true},
+				{name: "latency_p90", baseline: baseLatencies, current: curLatencies, stat: quantile(90), higherIsWorse: true, percentile: true},
+				{name: "latency_p99", baseline: baseLatencies, current: curLatencies, stat: quantile(99), higherIsWorse: true, percentile: true},
+				{name: "throughput", baseline: baseOp.ThroughputSeries, current: curOp.ThroughputSeries, stat: mean},
+				{name: "wire_bytes", baseline: wireSizes(baseOp), current: wireSizes(curOp), stat: mean, higherIsWorse: true},
+			}
+			comparison.compare(cur.Name(), curOp.Operation, metrics, opts, rng)
+		}
+	}
+
+	for _, base := range baseline.Results {
+		cur, ok := findProtocolResult(current, base.Name())
+		if !ok {
+			comparison.missing(base.Name(), totalOperationName, base.Requests)
+			continue
+		}
+
+		for _, baseOp := range base.Operations {
+			if _, ok := findOperationResult(cur, baseOp.Operation); !ok {
+				comparison.missing(base.Name(), baseOp.Operation, baseOp.Requests)
+			}
+		}
+	}
+
+	return comparison
+}
+
+// missing records a protocol or operation of the baseline left out of the current run, as a
+// regression since nothing of it was measured
+func (c *BenchmarkComparison) missing(protocol string, operation string, requests int) {
+	c.Findings = append(c.Findings, ComparisonFinding{
+		Protocol:   protocol,
+		Operation:  operation,
+		Metric:     "requests",
+		Baseline:   float64(requests),
+		Change:     -1,
+		Regression: true,
+		Missing:    true,
+	})
+}
+
+func (c *BenchmarkComparison) compare(protocol string, operation string, metrics []metricComparison, opts ComparisonOptions, rng *rand.Rand) {
+	for _, metric := range metrics {
+		if len(metric.baseline) < 2 || len(metric.current) < 2 {
+			continue
+		}
+
+		baseValue := metric.stat(append([]float64(nil), metric.baseline...))
+		curValue := metric.stat(append([]float64(nil), metric.current...))
+
+		_, pValue := MannWhitneyU(metric.baseline, metric.current)
+		low, high := BootstrapDiffCI(metric.baseline, metric.current, metric.stat, opts.Iterations, opts.Confidence, rng)
+
+		change := relativeChange(baseValue, curValue)
+
+		var worse, significant bool
+		if metric.higherIsWorse {
+			worse = change > opts.MinEffect
+			significant = low > 0
+		} else {
+			worse = change < -opts.MinEffect
+			significant = high < 0
+		}
+
+		if !metric.percentile {
+			significant = pValue < opts.Alpha
+		}
+
+		c.Findings = append(c.Findings, ComparisonFinding{
+			Protocol:   protocol,
+			Operation:  operation,
+			Metric:     metric.name,
+			Baseline:   baseValue,
+			Current:    curValue,
+			Change:     change,
+			PValue:     pValue,
+			CILow:      low,
+			CIHigh:     high,
+			Regression: worse && significant,
+		})
+	}
+}
+
+func relativeChange(baseline, current float64) float64 {
+	if baseline == 0 {
+		if current == 0 {
+			return 0
+		}
+		return math.Copysign(1, current)
+	}
+
+	return (current - baseline) / math.Abs(baseline)
+}
+
+func wireSizes(op OperationResult) []float64 {
+	sizes := make([]float64, min(len(op.RequestBytes), len(op.ResponseBytes)))
+	for i := range sizes {
+		sizes[i] = float64(op.RequestBytes[i] + op.ResponseBytes[i])
+	}
+	return sizes
+}
+
+// findProtocolResult finds the result of a protocol, and transport when the name has one
+func findProtocolResult(report *BenchmarkReport, name string) (ProtocolResult, bool) {
+	for _, result := range report.Results {
+		if result.Name() == name {
+			return result, true
+		}
+	}
+	return ProtocolResult{}, false
+}
+
+func findOperationResult(result ProtocolResult, operation string) (OperationResult, bool) {
+	for _, op := range result.Operations {
+		if op.Operation == operation {
+			return op, true
+		}
+	}
+	return OperationResult{}, false
+}
+
+func (c *BenchmarkComparison) WriteText(w io.Writer) error {
+	fmt.Fprintf(w, "Comparing scenario %s against baseline %s\n\n", c.Scenario, c.Baseline)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "protocol\toperation\tmetric\tbaseline\tcurrent\tchange\tp-value\t\t")
+
+	for _, f := range c.Findings {
+		if f.Missing {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t-\t\t\tMISSING\t\n",
+				f.Protocol,
+				f.Operation,
+				f.Metric,
+				formatMetricValue(f.Metric, f.Baseline),
+			)
+			continue
+		}
+
+		verdict := ""
+		if f.Regression {
+			verdict = "REGRESSION"
+		}
+
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%+.1f%%\t%.4f\t%s\t\n",
+			f.Protocol,
+			f.Operation,
+			f.Metric,
+			formatMetricValue(f.Metric, f.Baseline),
+			formatMetricValue(f.Metric, f.Current),
+			f.Change*100,
+			f.PValue,
+			verdict,
+		)
+	}
+
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(w, "\n%d regression(s) found\n", len(c.Regressions()))
+
+	return nil
+}
+
+func formatMetricValue(metric string, value float64) string {
+	switch metric {
+	case "latency_p50", "latency_p90", "latency_p99":
+		return time.Duration(value).Round(time.Microsecond).String()
+	case "throughput":
+		return fmt.Sprintf("%.1f/s", value)
+	case "requests":
+		return fmt.Sprintf("%.0f", value)
+	default:
+		return fmt.Sprintf("%.1f", value)
+	}
+}
+
+// BaselineStore keeps named benchmark reports as JSON files inside Dir
+type BaselineStore struct {
+	Dir string
+}
+
+func NewBaselineStore(dir string) *BaselineStore {
+	return &BaselineStore{Dir: dir}
+}
+
+func (s *BaselineStore) path(name string) (string, error) {
+	if !baselineNameRegex.MatchString(name) {
+		return "", fmt.Errorf("invalid baseline name %q, use only letters, digits, dots, dashes and underscores", name)
+	}
+
+	return filepath.Join(s.Dir, name+".json"), nil
+}
+
+func (s *BaselineStore) Save(name string, report *BenchmarkReport) (string, error) {
+	path, err := s.path(name)
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(s.Dir, 0o755); err != nil {
+		return "", err
+	}
+
+	return path, WriteReportFile(path, report)
+}
+
+func (s *BaselineStore) Load(name string) (*BenchmarkReport, error) {
+	path, err := s.path(name)
+	if err != nil {
+		return nil, err
+	}
+
+	report, err := ReadReportFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load baseline %s: %w", name, err)
+	}
+
+	return report, nil
+}
+
+func WriteReportFile(path string, report *BenchmarkReport) error {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, 0o644)
+}
+
+func ReadReportFile(path string) (*BenchmarkReport, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var report BenchmarkReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, err
+	}
+
+	return &report, nil
+}
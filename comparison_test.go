@@ -0,0 +1,192 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"math/rand/v2"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMannWhitneyU(t *testing.T) {
+	u, p := MannWhitneyU([]float64{1, 2, 3, 4, 5}, []float64{6, 7, 8, 9, 10})
+	assert.Equal(t, 0.0, u)
+	assert.InDelta(t, 0.0122, p, 0.001, "p-value should match the normal approximation")
+
+	_, p = MannWhitneyU([]float64{1, 2, 3, 4, 5}, []float64{1, 2, 3, 4, 5})
+	assert.InDelta(t, 1.0, p, 0.001, "identical samples should not be significant")
+
+	_, p = MannWhitneyU([]float64{7, 7, 7}, []float64{7, 7, 7})
+	assert.Equal(t, 1.0, p, "all tied samples should not be significant")
+}
+
+func TestBootstrapDiffCI(t *testing.T) {
+	rng := rand.New(rand.NewPCG(1, 2))
+
+	a := make([]float64, 500)
+	b := make([]float64, 500)
+	for i := range a {
+		a[i] = 100 + rng.NormFloat64()
+		b[i] = 110 + rng.NormFloat64()
+	}
+
+	low, high := BootstrapDiffCI(a, b, mean, 500, 0.95, rng)
+	assert.Greater(t, low, 9.0)
+	assert.Less(t, high, 11.0)
+}
+
+func newComparisonReport(rng *rand.Rand, latency time.Duration, requestBytes int) *BenchmarkReport {
+	op := OperationResult{Operation: OperationEcho}
+	for range 300 {
+		op.Latencies = append(op.Latencies, latency+time.Duration(rng.IntN(int(latency/10))))
+		op.RequestBytes = append(op.RequestBytes, requestBytes)
+		op.ResponseBytes = append(op.ResponseBytes, 200)
+	}
+	for range 10 {
+		op.ThroughputSeries = append(op.ThroughputSeries, 1000+rng.Float64()*10)
+	}
+
+	return &BenchmarkReport{
+		Scenario: Scenario{Name: "test"},
+		Results: []ProtocolResult{
+			{
+				Protocol:         ProtocolJSON,
+				Operations:       []OperationResult{op},
+				ThroughputSeries: op.ThroughputSeries,
+				AllocsSeries:     []float64{50, 51, 50, 49, 50},
+			},
+		},
+	}
+}
+
+func TestCompareReports(t *testing.T) {
+	rng := rand.New(rand.NewPCG(1, 2))
+	baseline := newComparisonReport(rng, time.Millisecond, 100)
+
+	t.Run("Same distribution has no regressions", func(t *testing.T) {
+		current := newComparisonReport(rng, time.Millisecond, 100)
+
+		comparison := CompareReports("base", baseline, current, DefaultComparisonOptions)
+
+		assert.NotEmpty(t, comparison.Findings)
+		assert.Empty(t, comparison.Regressions())
+	})
+
+	t.Run("Slower and bigger run is flagged", func(t *testing.T) {
+		current := newComparisonReport(rng, 2*time.Millisecond, 150)
+
+		comparison := CompareReports("base", baseline, current, DefaultComparisonOptions)
+
+		flagged := map[string]bool{}
+		for _, finding := range comparison.Regressions() {
+			flagged[finding.Metric] = true
+		}
+
+		assert.True(t, flagged["latency_p50"])
+		assert.True(t, flagged["latency_p99"])
+		assert.True(t, flagged["wire_bytes"])
+		assert.False(t, flagged["throughput"])
+	})
+
+	t.Run("Faster run is not a regression", func(t *testing.T) {
+		current := newComparisonReport(rng, time.Millisecond/2, 100)
+
+		comparison := CompareReports("base", baseline, current, DefaultComparisonOptions)
+
+		assert.Empty(t, comparison.Regressions())
+	})
+
+	t.Run("Transports are compared separately", func(t *testing.T) {
+		withTransports := func(tcp, unix *BenchmarkReport) *BenchmarkReport {
+			tcp.Results[0].Transport = TransportTCP
+			unix.Results[0].Transport = TransportUnix
+			tcp.Results = append(tcp.Results, unix.Results[0])
+			return tcp
+		}
+		baseline := withTransports(newComparisonReport(rng, time.Millisecond, 100), newComparisonReport(rng, time.Millisecond, 100))
+		current := withTransports(newComparisonReport(rng, time.Millisecond, 100), newComparisonReport(rng, 2*time.Millisecond, 100))
+
+		comparison := CompareReports("base", baseline, current, DefaultComparisonOptions)
+
+		require.NotEmpty(t, comparison.Regressions())
+		for _, finding := range comparison.Regressions() {
+			assert.Equal(t, "json/unix", finding.Protocol, "only the slower transport should be flagged")
+		}
+	})
+
+	t.Run("Failing run is flagged", func(t *testing.T) {
+		current := newComparisonReport(rng, time.Millisecond/2, 100)
+		op := &current.Results[0].Operations[0]
+		for i := range op.ThroughputSeries {
+			op.ThroughputSeries[i] = 0
+		}
+
+		comparison := CompareReports("base", baseline, current, DefaultComparisonOptions)
+
+		flagged := map[string]bool{}
+		for _, finding := range comparison.Regressions() {
+			flagged[finding.Operation+" "+finding.Metric] = true
+		}
+		assert.True(t, flagged[OperationEcho+" throughput"], "failed requests should not count as throughput")
+	})
+
+	t.Run("Missing operations are reported", func(t *testing.T) {
+		baseline := newComparisonReport(rng, time.Millisecond, 100)
+		baseline.Results[0].Operations = append(baseline.Results[0].Operations, OperationResult{Operation: OperationSum, Requests: 120})
+		baseline.Results = append(baseline.Results, ProtocolResult{Protocol: ProtocolString, Requests: 300})
+		current := newComparisonReport(rng, time.Millisecond, 100)
+
+		comparison := CompareReports("base", baseline, current, DefaultComparisonOptions)
+
+		var buf bytes.Buffer
+		require.NoError(t, comparison.WriteText(&buf))
+		assert.Equal(t, []ComparisonFinding{
+			{Protocol: ProtocolJSON, Operation: OperationSum, Metric: "requests", Baseline: 120, Change: -1, Regression: true, Missing: true},
+			{Protocol: ProtocolString, Operation: totalOperationName, Metric: "requests", Baseline: 300, Change: -1, Regression: true, Missing: true},
+		}, comparison.Regressions())
+		assert.Contains(t, buf.String(), "MISSING")
+	})
+}
+
+func TestSampleCollectorThroughput(t *testing.T) {
+	// Arrange
+	start := time.Now()
+	collector := newSampleCollector(start, time.Second)
+	for i := range 4 {
+		sample := BenchmarkSample{Operation: OperationEcho, At: start.Add(time.Duration(i) * 100 * time.Millisecond), Latency: time.Millisecond}
+		if i%2 == 1 {
+			sample.Err = errors.New("timeout")
+		}
+		collector.add(sample)
+	}
+
+	// Act
+	result := collector.result(ProtocolJSON, 2*time.Second)
+
+	// Assert
+	assert.Equal(t, 4, result.Requests)
+	assert.Equal(t, 2, result.Errors)
+	assert.Equal(t, 1.0, result.Throughput, "only the successful requests should count")
+	assert.Equal(t, []float64{2, 0}, result.Operations[0].ThroughputSeries)
+}
+
+func TestBaselineStore(t *testing.T) {
+	store := NewBaselineStore(t.TempDir())
+	report := newComparisonReport(rand.New(rand.NewPCG(1, 2)), time.Millisecond, 100)
+
+	_, err := store.Save("v1.0", report)
+	require.NoError(t, err, "Save should not return an error")
+
+	loaded, err := store.Load("v1.0")
+	require.NoError(t, err, "Load should not return an error")
+	assert.Equal(t, report.Results[0].Operations[0].Latencies, loaded.Results[0].Operations[0].Latencies)
+
+	_, err = store.Save("../escape", report)
+	assert.Error(t, err, "baseline names must not contain paths")
+
+	_, err = store.Load("missing")
+	assert.Error(t, err)
+}
@@ -0,0 +1,417 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/klauspost/compress/gzip"
+	"github.com/klauspost/compress/snappy"
+	"github.com/klauspost/compress/zstd"
+)
+
+const (
+	CompressionNone   = "none"
+	CompressionGzip   = "gzip"
+	CompressionZstd   = "zstd"
+	CompressionSnappy = "snappy"
+)
+
+// Compressions lists the codecs a protocol can be compressed with, in display order. The index of a
+// codec is the header flag of the messages it compresses.
+var Compressions = []string{CompressionNone, CompressionGzip, CompressionZstd, CompressionSnappy}
+
+// compressionHeaderSize is the flag byte followed by the 4 byte big endian length of the body
+const compressionHeaderSize = 5
+
+// maxDecompressedSize bounds what a single message may inflate to
+const maxDecompressedSize = 16 << 20
+
+var (
+	gzipWriters = sync.Pool{New: func() any { return gzip.NewWriter(nil) }}
+	zstdEncoder = newZstdEncoder()
+	zstdDecoder = newZstdDecoder()
+)
+
+func newZstdEncoder() *zstd.Encoder {
+	encoder, err := zstd.NewWriter(nil)
+	if err != nil {
+		panic(err)
+	}
+
+	return encoder
+}
+
+func newZstdDecoder() *zstd.Decoder {
+	decoder, err := zstd.NewReader(nil, zstd.WithDecoderMaxMemory(maxDecompressedSize))
+	if err != nil {
+		panic(err)
+	}
+
+	return decoder
+}
+
+// CompressionStats adds up the messages a CompressedSerde compressed and decompressed. Bytes are
+// counted in both directions, compressed bytes include the header.
+type CompressionStats struct {
+	Compressed        int           `json:"compressed"`
+	Decompressed      int           `json:"decompressed"`
+	UncompressedBytes int           `json:"uncompressed_bytes"`
+	CompressedBytes   int           `json:"compressed_bytes"`
+	CompressTime      time.Duration `json:"compress_time"`
+	DecompressTime    time.Duration `json:"decompress_time"`
+}
+
+func (s CompressionStats) Add(other CompressionStats) CompressionStats {
+	return CompressionStats{
+		Compressed:        s.Compressed + other.Compressed,
+		Decompressed:      s.Decompressed + other.Decompressed,
+		UncompressedBytes: s.UncompressedBytes + other.UncompressedBytes,
+		CompressedBytes:   s.CompressedBytes + other.CompressedBytes,
+		CompressTime:      s.CompressTime + other.CompressTime,
+		DecompressTime:    s.DecompressTime + other.DecompressTime,
+	}
+}
+
+func (s CompressionStats) Messages() int {
+	return s.Compressed + s.Decompressed
+}
+
+// Ratio is the size of the compressed messages relative to the uncompressed ones
+func (s CompressionStats) Ratio() float64 {
+	if s.UncompressedBytes == 0 {
+		return 0
+	}
+
+	return float64(s.CompressedBytes) / float64(s.UncompressedBytes)
+}
+
+// CompressedSerde compresses the messages of Inner with Codec. Every message starts with a flag
+// byte naming the codec its body was compressed with, followed by the length of the body.
+// Receivers decompress whatever codec the flag names, so a server answers clients of every codec
+// and a reply may be compressed differently than its request.
+type CompressedSerde struct {
+	Inner Serde
+	Codec string
+
+	mu    sync.Mutex
+	stats CompressionStats
+}
+
+func NewCompressedSerde(inner Serde, codec string) (*CompressedSerde, error) {
+	if !slices.Contains(Compressions, codec) {
+		return nil, fmt.Errorf("unknown compression %s", codec)
+	}
+
+	return &CompressedSerde{
+		Inner: inner,
+		Codec: codec,
+	}, nil
+}
+
+// ParseCompressions reads the compression of every protocol from a comma separated list of
+// protocol=codec pairs, a lone codec applies to all the protocols
+func ParseCompressions(value string, protocols []string) (map[string]string, error) {
+	return parseProtocolOptions(value, protocols, "compression", Compressions)
+}
+
+// parseProtocolOptions reads a comma separated list of protocol=option pairs, a lone option
+// applies to all the protocols
+func parseProtocolOptions(value string, protocols []string, kind string, options []string) (map[string]string, error) {
+	chosen := map[string]string{}
+
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		protocol, option, found := strings.Cut(pair, "=")
+		if !found {
+			option = protocol
+		}
+
+		if !slices.Contains(options, option) {
+			return nil, fmt.Errorf("unknown %s %s", kind, option)
+		}
+
+		if !found {
+			for _, protocol := range protocols {
+				chosen[protocol] = option
+			}
+			continue
+		}
+
+		if !slices.Contains(Protocols, protocol) {
+			return nil, fmt.Errorf("unknown protocol %s", protocol)
+		}
+
+		chosen[protocol] = option
+	}
+
+	return chosen, nil
+}
+
+// Unwrap returns the serde the messages are compressed for
+func (c *CompressedSerde) Unwrap() Serde {
+	return c.Inner
+}
+
+// Stats returns what was compressed and decompressed since the last ResetStats
+func (c *CompressedSerde) Stats() CompressionStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.stats
+}
+
+func (c *CompressedSerde) ResetStats() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.stats = CompressionStats{}
+}
+
+func (c *CompressedSerde) record(stats CompressionStats) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.stats = c.stats.Add(stats)
+}
+
+// compress puts data behind the header, compressed with the codec of the serde
+func (c *CompressedSerde) compress(data []byte) ([]byte, error) {
+	flag := slices.Index(Compressions, c.Codec)
+	if flag < 0 {
+		return nil, fmt.Errorf("unknown compression %s", c.Codec)
+	}
+
+	start := time.Now()
+	body, err := compressBody(c.Codec, data)
+	if err != nil {
+		return nil, err
+	}
+	elapsed := time.Since(start)
+
+	message := make([]byte, compressionHeaderSize, compressionHeaderSize+len(body))
+	message[0] = byte(flag)
+	binary.BigEndian.PutUint32(message[1:], uint32(len(body)))
+	message = append(message, body...)
+
+	c.record(CompressionStats{
+		Compressed:        1,
+		UncompressedBytes: len(data),
+		CompressedBytes:   len(message),
+		CompressTime:      elapsed,
+	})
+
+	return message, nil
+}
+
+// ReplyLength implements ReplyFramer.
+func (c *CompressedSerde) ReplyLength(data []byte) (int, bool) {
+	if len(data) < compressionHeaderSize {
+		return 0, false
+	}
+
+	return compressionHeaderSize + int(binary.BigEndian.Uint32(data[1:compressionHeaderSize])), true
+}
+
+// decompress returns the message behind the header, decompressed with the codec its flag names
+func (c *CompressedSerde) decompress(data []byte) ([]byte, error) {
+	if len(data) < compressionHeaderSize {
+		return nil, fmt.Errorf("data too small, expected at least %d bytes for the compression header, got %d", compressionHeaderSize, len(data))
+	}
+
+	flag := int(data[0])
+	if flag >= len(Compressions) {
+		return nil, fmt.Errorf("unknown compression flag %d", flag)
+	}
+
+	length := int(binary.BigEndian.Uint32(data[1:compressionHeaderSize]))
+	if length > maxDecompressedSize {
+		return nil, fmt.Errorf("compressed body of %d bytes exceeds the limit of %d bytes", length, maxDecompressedSize)
+	}
+
+	body := data[compressionHeaderSize:]
+	if len(body) < length {
+		return nil, fmt.Errorf("compressed body of %d bytes is shorter than the %d bytes of its header", len(body), length)
+	}
+	body = body[:length]
+
+	start := time.Now()
+	message, err := decompressBody(Compressions[flag], body)
+	if err != nil {
+		return nil, err
+	}
+	elapsed := time.Since(start)
+
+	c.record(CompressionStats{
+		Decompressed:      1,
+		UncompressedBytes: len(message),
+		CompressedBytes:   compressionHeaderSize + length,
+		DecompressTime:    elapsed,
+	})
+
+	return message, nil
+}
+
+func compressBody(codec string, data []byte) ([]byte, error) {
+	switch codec {
+	case CompressionNone:
+		return data, nil
+	case CompressionGzip:
+		w := gzipWriters.Get().(*gzip.Writer)
+		defer gzipWriters.Put(w)
+
+		var buf bytes.Buffer
+		w.Reset(&buf)
+
+		if _, err := w.Write(data); err != nil {
+			return nil, err
+		}
+		if err := w.Close(); err != nil {
+			return nil, err
+		}
+
+		return buf.Bytes(), nil
+	case CompressionZstd:
+		return zstdEncoder.EncodeAll(data, nil), nil
+	case CompressionSnappy:
+		return snappy.Encode(nil, data), nil
+	default:
+		return nil, fmt.Errorf("unknown compression %s", codec)
+	}
+}
+
+func decompressBody(codec string, body []byte) ([]byte, error) {
+	switch codec {
+	case CompressionNone:
+		return body, nil
+	case CompressionGzip:
+		r, err := gzip.NewReader(bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		defer r.Close()
+
+		data, err := io.ReadAll(io.LimitReader(r, maxDecompressedSize+1))
+		if err != nil {
+			return nil, err
+		}
+		if len(data) > maxDecompressedSize {
+			return nil, fmt.Errorf("decompressed message exceeds the limit of %d bytes", maxDecompressedSize)
+		}
+
+		return data, nil
+	case CompressionZstd:
+		return zstdDecoder.DecodeAll(body, nil)
+	case CompressionSnappy:
+		length, err := snappy.DecodedLen(body)
+		if err != nil {
+			return nil, err
+		}
+		if length > maxDecompressedSize {
+			return nil, fmt.Errorf("decompressed message exceeds the limit of %d bytes", maxDecompressedSize)
+		}
+
+		return snappy.Decode(nil, body)
+	default:
+		return nil, fmt.Errorf("unknown compression %s", codec)
+	}
+}
+
+// Marshal implements Serde.
+func (c *CompressedSerde) Marshal(v any) ([]byte, error) {
+	data, err := c.Inner.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.compress(data)
+}
+
+// Unmarshal implements Serde.
+func (c *CompressedSerde) Unmarshal(data []byte, v any) error {
+	data, err := c.decompress(data)
+	if err != nil {
+		return err
+	}
+
+	return c.Inner.Unmarshal(data, v)
+}
+
+// UnmarshalRequest implements ServerSerde.
+func (c *CompressedSerde) UnmarshalRequest(data []byte, req *PresentationLayerRequest) error {
+	inner, ok := c.Inner.(ServerSerde)
+	if !ok {
+		return fmt.Errorf("%T does not implement ServerSerde", c.Inner)
+	}
+
+	data, err := c.decompress(data)
+	if err != nil {
+		return err
+	}
+
+	return inner.UnmarshalRequest(data, req)
+}
+
+// MarshalResponse implements ServerSerde.
+func (c *CompressedSerde) MarshalResponse(v any) ([]byte, error) {
+	inner, ok := c.Inner.(ServerSerde)
+	if !ok {
+		return nil, fmt.Errorf("%T does not implement ServerSerde", c.Inner)
+	}
+
+	data, err := inner.MarshalResponse(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.compress(data)
+}
+
+// CompressionOperationSummary is the compression of the messages of one operation
+type CompressionOperationSummary struct {
+	Protocol  string           `json:"protocol"`
+	Codec     string           `json:"codec"`
+	Operation string           `json:"operation"`
+	Stats     CompressionStats `json:"stats"`
+}
+
+// WriteCompressionText writes a table of the average message sizes and codec time of every summary
+func WriteCompressionText(w io.Writer, summaries []CompressionOperationSummary) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "protocol\tcodec\toperation\tmessages\tuncompressed\tcompressed\tratio\tcompress\tdecompress\t")
+
+	for _, s := range summaries {
+		messages := max(s.Stats.Messages(), 1)
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%d\t%d\t%.2f\t%s\t%s\t\n",
+			s.Protocol,
+			s.Codec,
+			s.Operation,
+			s.Stats.Messages(),
+			s.Stats.UncompressedBytes/messages,
+			s.Stats.CompressedBytes/messages,
+			s.Stats.Ratio(),
+			averageDuration(s.Stats.CompressTime, s.Stats.Compressed),
+			averageDuration(s.Stats.DecompressTime, s.Stats.Decompressed),
+		)
+	}
+
+	return tw.Flush()
+}
+
+func averageDuration(total time.Duration, n int) time.Duration {
+	if n == 0 {
+		return 0
+	}
+
+	return (total / time.Duration(n)).Round(100 * time.Nanosecond)
+}
@@ -0,0 +1,230 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newCompressedSerde(t *testing.T, protocol, compression string) *CompressedSerde {
+	t.Helper()
+
+	inner, err := NewSerde(protocol)
+	require.NoError(t, err)
+
+	serde, err := NewCompressedSerde(inner, compression)
+	require.NoError(t, err)
+
+	return serde
+}
+
+func TestCompressedSerdeRoundTrip(t *testing.T) {
+	for _, protocol := range Protocols {
+		for _, compression := range Compressions {
+			t.Run(protocol+"/"+compression, func(t *testing.T) {
+				client := newCompressedSerde(t, protocol, compression)
+				server := newCompressedSerde(t, protocol, compression)
+				r := newConformanceRand(t)
+
+				for _, tc := range conformanceRequests {
+					// Arrange
+					expected := tc.generate(r)
+
+					// Act
+					data, err := client.Marshal(expected)
+					require.NoError(t, err)
+
+					var actual PresentationLayerRequest
+					err = server.UnmarshalRequest(data, &actual)
+
+					// Assert
+					require.NoError(t, err, "UnmarshalRequest should not return an error for %q", data)
+					require.Equal(t, expected, actual, "%s request should survive the round trip", tc.name)
+				}
+
+				for _, tc := range conformanceResponses {
+					// Arrange
+					body := tc.generate(r)
+					expected := PresentationLayerResponse[OperationResponse]{Body: body, StatusCode: http.StatusOK}
+
+					// Act
+					data, err := server.MarshalResponse(expected)
+					require.NoError(t, err)
+
+					actual := PresentationLayerResponse[OperationResponse]{Body: tc.newBody()}
+					err = client.Unmarshal(data, &actual)
+
+					// Assert
+					require.NoError(t, err, "Unmarshal should not return an error for %q", data)
+					expectedJSON, err := json.Marshal(body)
+					require.NoError(t, err)
+					actualJSON, err := json.Marshal(actual.Body)
+					require.NoError(t, err)
+					require.JSONEq(t, string(expectedJSON), string(actualJSON), "%s response should survive the round trip", tc.name)
+				}
+
+				stats := client.Stats()
+				assert.Equal(t, len(conformanceRequests), stats.Compressed)
+				assert.Equal(t, len(conformanceResponses), stats.Decompressed)
+				assert.Positive(t, stats.UncompressedBytes)
+				assert.Positive(t, stats.CompressedBytes)
+			})
+		}
+	}
+}
+
+func TestCompressedSerdeDecompressesEveryCodec(t *testing.T) {
+	server := newCompressedSerde(t, ProtocolJSON, CompressionZstd)
+	expected := PresentationLayerRequest{Token: "abc", Body: EchoRequest{Message: "ola mundo"}}
+
+	for _, compression := range Compressions {
+		t.Run(compression, func(t *testing.T) {
+			// Arrange
+			client := newCompressedSerde(t, ProtocolJSON, compression)
+			data, err := client.Marshal(expected)
+			require.NoError(t, err)
+
+			// Act
+			var actual PresentationLayerRequest
+			err = server.UnmarshalRequest(data, &actual)
+
+			// Assert
+			require.NoError(t, err, "the header flag should name the codec of the message")
+			assert.Equal(t, expected, actual)
+		})
+	}
+}
+
+func TestCompressedSerdeRejectsTruncatedBody(t *testing.T) {
+	for _, compression := range []string{CompressionGzip, CompressionZstd} {
+		t.Run(compression, func(t *testing.T) {
+			// Arrange
+			server := newCompressedSerde(t, ProtocolJSON, compression)
+			client := newCompressedSerde(t, ProtocolJSON, compression)
+			expected := &EchoResponse{EchoMessage: strings.Repeat("ECO ", 100)}
+
+			data, err := server.MarshalResponse(PresentationLayerResponse[OperationResponse]{Body: expected, StatusCode: http.StatusOK})
+			require.NoError(t, err)
+
+			// Act
+			length, ok := client.ReplyLength(data[:compressionHeaderSize])
+			actual := PresentationLayerResponse[OperationResponse]{Body: &EchoResponse{}}
+			err = client.Unmarshal(data[:len(data)-1], &actual)
+
+			// Assert
+			require.True(t, ok)
+			assert.Equal(t, len(data), length)
+			assert.ErrorContains(t, err, "shorter than")
+		})
+	}
+}
+
+func TestCompressedSerdeShrinksLargeMessages(t *testing.T) {
+	req := PresentationLayerRequest{Token: "abc", Body: EchoRequest{Message: strings.Repeat("ola mundo ", 200)}}
+
+	plain, err := jsonserde.Marshal(req)
+	require.NoError(t, err)
+
+	for _, compression := range []string{CompressionGzip, CompressionZstd, CompressionSnappy} {
+		// Act
+		data, err := newCompressedSerde(t, ProtocolJSON, compression).Marshal(req)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Less(t, len(data), len(plain)/4, "%s should shrink a repetitive message", compression)
+	}
+}
+
+func TestCompressedSerdeRejectsMalformedMessages(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{name: "empty", data: nil},
+		{name: "short header", data: []byte{1, 0, 0}},
+		{name: "unknown flag", data: []byte{9, 0, 0, 0, 1, 'x'}},
+		{name: "length over the limit", data: []byte{1, 0xff, 0xff, 0xff, 0xff}},
+		{name: "corrupt gzip", data: []byte{1, 0, 0, 0, 3, 'x', 'y', 'z'}},
+		{name: "corrupt zstd", data: []byte{2, 0, 0, 0, 3, 'x', 'y', 'z'}},
+		{name: "corrupt snappy", data: []byte{3, 0, 0, 0, 2, 0xff, 0xff}},
+	}
+
+	serde := newCompressedSerde(t, ProtocolJSON, CompressionGzip)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			resp := PresentationLayerResponse[OperationResponse]{Body: &EchoResponse{}}
+			err := serde.Unmarshal(tt.data, &resp)
+
+			// Assert
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestParseCompressions(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		expected map[string]string
+		wantErr  bool
+	}{
+		{name: "one codec for all", value: "zstd", expected: map[string]string{ProtocolJSON: CompressionZstd, ProtocolProtobuf: CompressionZstd}},
+		{name: "pairs", value: "json=gzip, protobuf=snappy", expected: map[string]string{ProtocolJSON: CompressionGzip, ProtocolProtobuf: CompressionSnappy}},
+		{name: "pair overrides codec", value: "gzip,protobuf=none", expected: map[string]string{ProtocolJSON: CompressionGzip, ProtocolProtobuf: CompressionNone}},
+		{name: "unknown codec", value: "json=brotli", wantErr: true},
+		{name: "unknown protocol", value: "xml=gzip", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			actual, err := ParseCompressions(tt.value, []string{ProtocolJSON, ProtocolProtobuf})
+
+			// Assert
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, actual)
+		})
+	}
+}
+
+func TestWriteCompressionText(t *testing.T) {
+	// Arrange
+	summaries := []CompressionOperationSummary{
+		{
+			Protocol:  ProtocolJSON,
+			Codec:     CompressionZstd,
+			Operation: OperationHistory,
+			Stats: CompressionStats{
+				Compressed:        2,
+				Decompressed:      2,
+				UncompressedBytes: 4000,
+				CompressedBytes:   1000,
+				CompressTime:      20 * time.Microsecond,
+				DecompressTime:    10 * time.Microsecond,
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+
+	// Act
+	err := WriteCompressionText(&buf, summaries)
+
+	// Assert
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+	assert.Equal(t, []string{"json", "zstd", "historico", "4", "1000", "250", "0.25", "10µs", "5µs"}, strings.Fields(lines[1]))
+}
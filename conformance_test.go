@@ -0,0 +1,450 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"math/rand/v2"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var conformanceSeed = flag.Uint64("conformance.seed", 0, "seed of the round-trip conformance suite, random when zero")
+
+const conformanceIterations = 50
+
+// conformanceRand generates values every protocol can carry: strings avoid the
+// separators and python literals of the string protocol, times are truncated to
+// the precision of the wire layouts
+type conformanceRand struct {
+	*rand.Rand
+}
+
+func newConformanceRand(t *testing.T) *conformanceRand {
+	t.Helper()
+
+	seed := *conformanceSeed
+	if seed == 0 {
+		seed = uint64(time.Now().UnixNano())
+	}
+	t.Logf("replay with -conformance.seed=%d", seed)
+
+	return &conformanceRand{rand.New(rand.NewPCG(seed, seed))}
+}
+
+func (r *conformanceRand) word(alphabet string) string {
+	var sb strings.Builder
+	for range 1 + r.IntN(12) {
+		sb.WriteByte(alphabet[r.IntN(len(alphabet))])
+	}
+	return sb.String()
+}
+
+func (r *conformanceRand) text() string {
+	words := make([]string, 1+r.IntN(4))
+	for i := range words {
+		words[i] = r.word("abcdefghijklmnopqrstuvwxyz0123456789")
+	}
+	return strings.Join(words, " ")
+}
+
+func (r *conformanceRand) letters() string {
+	return r.word("abcdefghijklmnopqrstuvwxyz")
+}
+
+func (r *conformanceRand) number() float64 {
+	return float64(r.IntN(200_000)-100_000) / 100
+}
+
+func (r *conformanceRand) moment() time.Time {
+	return time.Unix(1_600_000_000+r.Int64N(200_000_000), 0).UTC()
+}
+
+func (r *conformanceRand) nonISO8601Time() NonISO8601Time {
+	return NonISO8601Time{r.moment().Add(time.Duration(r.IntN(1_000_000)) * time.Microsecond)}
+}
+
+func (r *conformanceRand) unixTimestamp() UnixTimestamp {
+	return UnixTimestamp{r.moment()}
+}
+
+func (r *conformanceRand) scalar() any {
+	switch r.IntN(3) {
+	case 0:
+		return r.letters()
+	case 1:
+		return r.IntN(2) == 0
+	default:
+		return r.IntN(10_000)
+	}
+}
+
+func (r *conformanceRand) dict() map[string]any {
+	if r.IntN(3) == 0 {
+		return nil
+	}
+
+	dict := map[string]any{}
+	for range 1 + r.IntN(3) {
+		dict[r.letters()] = r.scalar()
+	}
+	return dict
+}
+
+type conformanceRequest struct {
+	name     string
+	generate func(r *conformanceRand) PresentationLayerRequest
+}
+
+// conformanceRequests covers every command and operation a client sends
+var conformanceRequests = []conformanceRequest{
+	{
+		name: "auth",
+		generate: func(r *conformanceRand) PresentationLayerRequest {
+			// Only the string protocol carries the client timestamp, so it is left zero
+			return PresentationLayerRequest{Body: AuthRequest{StudentID: r.word("0123456789")}}
+		},
+	},
+	{
+		name: OperationEcho,
+		generate: func(r *conformanceRand) PresentationLayerRequest {
+			return PresentationLayerRequest{Token: r.letters(), Body: EchoRequest{Message: r.text()}}
+		},
+	},
+	{
+		name: OperationSum,
+		generate: func(r *conformanceRand) PresentationLayerRequest {
+			numbers := make([]int, 1+r.IntN(50))
+			for i := range numbers {
+				numbers[i] = r.IntN(20_000) - 10_000
+			}
+			return PresentationLayerRequest{Token: r.letters(), Body: SumRequest{Numbers: numbers}}
+		},
+	},
+	{
+		name: OperationTimestamp,
+		generate: func(r *conformanceRand) PresentationLayerRequest {
+			return PresentationLayerRequest{Token: r.letters(), Body: TimestampRequest{}}
+		},
+	},
+	{
+		name: OperationStatus,
+		generate: func(r *conformanceRand) PresentationLayerRequest {
+			return PresentationLayerRequest{Token: r.letters(), Body: StatusRequest{Detailed: r.IntN(2) == 0}}
+		},
+	},
+	{
+		name: OperationHistory,
+		generate: func(r *conformanceRand) PresentationLayerRequest {
+			return PresentationLayerRequest{Token: r.letters(), Body: HistoryRequest{Limit: 1 + r.IntN(100)}}
+		},
+	},
+	{
+		name: "logout",
+		generate: func(r *conformanceRand) PresentationLayerRequest {
+			return PresentationLayerRequest{Token: r.letters(), Body: LogoutRequest{}}
+		},
+	},
+}
+
+type conformanceResponse struct {
+	name     string
+	generate func(r *conformanceRand) OperationResponse
+	newBody  func() OperationResponse
+}
+
+// conformanceResponses covers every response a server sends
+var conformanceResponses = []conformanceResponse{
+	{
+		name: "auth",
+		generate: func(r *conformanceRand) OperationResponse {
+			return AuthResponse{
+				Token:      r.letters(),
+				Name:       r.text(),
+				Enrollment: r.word("0123456789"),
+				Timestamp:  r.nonISO8601Time(),
+			}
+		},
+		newBody: func() OperationResponse { return &AuthResponse{} },
+	},
+	{
+		name: OperationEcho,
+		generate: func(r *conformanceRand) OperationResponse {
+			message := r.text()
+			return EchoResponse{
+				OriginalMessage: message,
+				EchoMessage:     "ECO: " + message,
+				ServerTimestamp: r.nonISO8601Time(),
+				MessageSize:     len(message),
+				HashMD5:         r.word("0123456789abcdef"),
+				Timestamp:       r.nonISO8601Time(),
+			}
+		},
+		newBody: func() OperationResponse { return &EchoResponse{} },
+	},
+	{
+		name: OperationSum,
+		generate: func(r *conformanceRand) OperationResponse {
+			numbers := make([]float64, 1+r.IntN(50))
+			for i := range numbers {
+				numbers[i] = r.number()
+			}
+			return SumResponse{
+				OriginalNumbers:      numbers,
+				Sum:                  r.number(),
+				Mean:                 r.number(),
+				Maximum:              r.number(),
+				Minimum:              r.number(),
+				Amount:               float64(len(numbers)),
+				Timestamp:            r.nonISO8601Time(),
+				CalculationTimestamp: r.nonISO8601Time(),
+			}
+		},
+		newBody: func() OperationResponse { return &SumResponse{} },
+	},
+	{
+		name: OperationTimestamp,
+		generate: func(r *conformanceRand) OperationResponse {
+			now := r.nonISO8601Time()
+			return TimestampResponse{
+				FormatedTimestamp: now.Format("02/01/2006 15:04:05"),
+				ISOTimestamp:      now,
+				UnixTimestamp:     r.unixTimestamp(),
+				Year:              now.Year(),
+				Month:             int(now.Month()),
+				Day:               now.Day(),
+				Hour:              now.Hour(),
+				Minute:            now.Minute(),
+				Second:            now.Second(),
+				Microsecond:       now.Nanosecond() / 1000,
+				Timestamp:         r.nonISO8601Time(),
+			}
+		},
+		newBody: func() OperationResponse { return &TimestampResponse{} },
+	},
+	{
+		name: OperationStatus,
+		generate: func(r *conformanceRand) OperationResponse {
+			resp := StatusResponse{
+				Status:              r.letters(),
+				OperationsProcessed: r.IntN(100_000),
+				TimeActive:          r.unixTimestamp(),
+				Version:             r.word("0123456789."),
+				Timestamp:           r.nonISO8601Time(),
+				Metrics: StatusResponseMetrics{
+					SimulatedCPU:     r.number(),
+					SimulatedMemory:  r.number(),
+					LatencySimulated: r.number(),
+				},
+			}
+
+			if r.IntN(2) == 0 {
+				return resp
+			}
+
+			resp.ActiveSessions = 1 + r.IntN(100)
+			resp.DatabaseStatistics = &StatusDatabaseStatistics{
+				TotalSessions:   r.IntN(1000),
+				TotalOperations: r.IntN(1000),
+				OperationsPerType: StatusDatabaseOperationType{
+					Authentication: r.IntN(100),
+					Echo:           r.IntN(100),
+					History:        r.IntN(100),
+					Sum:            r.IntN(100),
+					Status:         r.IntN(100),
+					Timestamp:      r.IntN(100),
+				},
+				UniqueStudents: r.IntN(100),
+			}
+
+			sessions := map[string]StatusResponseSessionDetails{}
+			for range 1 + r.IntN(5) {
+				sessions[r.letters()] = StatusResponseSessionDetails{
+					TimestampLogin: r.unixTimestamp(),
+					IPClient:       r.word("0123456789."),
+					Name:           r.text(),
+					Enrollment:     r.word("0123456789"),
+				}
+			}
+			resp.SessionDetails = &sessions
+
+			return resp
+		},
+		newBody: func() OperationResponse { return &StatusResponse{} },
+	},
+	{
+		name: OperationHistory,
+		generate: func(r *conformanceRand) OperationResponse {
+			history := make([]HistoryOperationHistoryResponse, 1+r.IntN(10))
+			for i := range history {
+				history[i] = HistoryOperationHistoryResponse{
+					Operation: Operations[r.IntN(len(Operations))],
+					Params:    r.dict(),
+					Result:    r.dict(),
+					Timestamp: r.nonISO8601Time(),
+					Success:   r.IntN(2) == 0,
+				}
+			}
+
+			var mostUsed [][]any
+			for range r.IntN(4) {
+				mostUsed = append(mostUsed, []any{r.letters(), r.IntN(100)})
+			}
+
+			return HistoryResponse{
+				StudentID:        r.word("0123456789"),
+				RequestedLimit:   1 + r.IntN(100),
+				TotalFound:       len(history),
+				History:          history,
+				ConsultTimestamp: r.nonISO8601Time(),
+				Stats: HistoryResponseStats{
+					TotalOperations:   r.IntN(1000),
+					SuccessOperations: r.IntN(1000),
+					ErroOperations:    r.IntN(1000),
+					SuccessRate:       r.number(),
+				},
+				MostUsedOperations: mostUsed,
+				Timestamp:          r.nonISO8601Time(),
+			}
+		},
+		newBody: func() OperationResponse { return &HistoryResponse{} },
+	},
+	{
+		name: "logout",
+		generate: func(r *conformanceRand) OperationResponse {
+			return LogoutResponse{Message: r.text(), Timestamp: r.nonISO8601Time()}
+		},
+		newBody: func() OperationResponse { return &LogoutResponse{} },
+	},
+}
+
+// conformanceCodecs returns the codecs protocol is implemented by
+func conformanceCodecs(protocol string) []string {
+	codecs := []string{}
+	for _, codec := range Codecs {
+		if _, err := NewCodecSerde(protocol, codec); !errors.Is(err, ErrNoGeneratedCodec) {
+			codecs = append(codecs, codec)
+		}
+	}
+	return codecs
+}
+
+func newConformanceSerde(t *testing.T, protocol, codec string) (Serde, ServerSerde) {
+	t.Helper()
+
+	serde, err := NewCodecSerde(protocol, codec)
+	require.NoError(t, err)
+
+	serverSerde, ok := serde.(ServerSerde)
+	require.True(t, ok, "%s serde should implement ServerSerde", protocol)
+
+	return serde, serverSerde
+}
+
+func TestRequestRoundTripConformance(t *testing.T) {
+	for _, protocol := range Protocols {
+		for _, codec := range conformanceCodecs(protocol) {
+			for _, tc := range conformanceRequests {
+				t.Run(protocol+"/"+codec+"/"+tc.name, func(t *testing.T) {
+					serde, serverSerde := newConformanceSerde(t, protocol, codec)
+					r := newConformanceRand(t)
+
+					for range conformanceIterations {
+						// Arrange
+						expected := tc.generate(r)
+
+						// Act
+						data, err := serde.Marshal(expected)
+						require.NoError(t, err, "Marshal should not return an error")
+
+						var actual PresentationLayerRequest
+						err = serverSerde.UnmarshalRequest(data, &actual)
+
+						// Assert
+						require.NoError(t, err, "UnmarshalRequest should not return an error for %q", data)
+						require.Equal(t, expected, actual, "request should survive the round trip through %q", data)
+					}
+				})
+			}
+		}
+	}
+}
+
+func TestResponseRoundTripConformance(t *testing.T) {
+	for _, protocol := range Protocols {
+		for _, codec := range conformanceCodecs(protocol) {
+			for _, tc := range conformanceResponses {
+				t.Run(protocol+"/"+codec+"/"+tc.name, func(t *testing.T) {
+					serde, serverSerde := newConformanceSerde(t, protocol, codec)
+					r := newConformanceRand(t)
+
+					for range conformanceIterations {
+						// Arrange
+						body := tc.generate(r)
+						expected := PresentationLayerResponse[OperationResponse]{Body: body, StatusCode: http.StatusOK}
+
+						// Act
+						data, err := serverSerde.MarshalResponse(expected)
+						require.NoError(t, err, "MarshalResponse should not return an error")
+
+						actual := PresentationLayerResponse[OperationResponse]{Body: tc.newBody()}
+						err = serde.Unmarshal(data, &actual)
+
+						// Assert
+						require.NoError(t, err, "Unmarshal should not return an error for %q", data)
+						require.Nil(t, actual.Err, "successful responses should not carry an error")
+						require.Equal(t, http.StatusOK, actual.StatusCode)
+
+						// Dynamic fields decode numbers as float64, so bodies are compared as JSON
+						expectedJSON, err := json.Marshal(body)
+						require.NoError(t, err)
+						actualJSON, err := json.Marshal(actual.Body)
+						require.NoError(t, err)
+						require.JSONEq(t, string(expectedJSON), string(actualJSON), "response should survive the round trip through %q", data)
+					}
+				})
+			}
+		}
+	}
+}
+
+func TestErrorResponseRoundTripConformance(t *testing.T) {
+	for _, protocol := range Protocols {
+		for _, codec := range conformanceCodecs(protocol) {
+			t.Run(protocol+"/"+codec, func(t *testing.T) {
+				serde, serverSerde := newConformanceSerde(t, protocol, codec)
+				r := newConformanceRand(t)
+
+				for range conformanceIterations {
+					// Arrange
+					tc := conformanceResponses[r.IntN(len(conformanceResponses))]
+					expected := PresentationLayerResponse[OperationResponse]{
+						Err: &PresentationLayerErrorResponse{
+							Code:    http.StatusText(http.StatusInternalServerError),
+							Message: r.text(),
+							Details: map[string]any{},
+						},
+						StatusCode: http.StatusInternalServerError,
+					}
+
+					// Act
+					data, err := serverSerde.MarshalResponse(expected)
+					require.NoError(t, err, "MarshalResponse should not return an error")
+
+					actual := PresentationLayerResponse[OperationResponse]{Body: tc.newBody()}
+					err = serde.Unmarshal(data, &actual)
+
+					// Assert
+					require.NoError(t, err, "Unmarshal should not return an error for %q", data)
+					assert.Equal(t, expected.StatusCode, actual.StatusCode)
+					assert.Equal(t, expected.Err, actual.Err, "error should survive the round trip through %q", data)
+				}
+			})
+		}
+	}
+}
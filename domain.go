@@ -3,6 +3,9 @@ package main
 import (
 	"encoding/json"
 	"time"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/vmihailenco/msgpack/v5"
 )
 
 var _ error = (*PresentationLayerErrorResponse)(nil)
@@ -38,18 +41,28 @@ type OperationResponse interface {
 	OperationResponseName() string
 }
 
+// nonISO8601Layout is the timestamp layout of the reference server, microseconds without a zone
+const nonISO8601Layout = "2006-01-02T15:04:05.000000"
+
 type NonISO8601Time struct {
 	time.Time
 }
 
 var (
-	_ json.Marshaler   = (*NonISO8601Time)(nil)
-	_ json.Unmarshaler = (*NonISO8601Time)(nil)
+	_ json.Marshaler        = (*NonISO8601Time)(nil)
+	_ json.Unmarshaler      = (*NonISO8601Time)(nil)
+	_ msgpack.CustomEncoder = (*NonISO8601Time)(nil)
+	_ msgpack.CustomDecoder = (*NonISO8601Time)(nil)
+	_ cbor.Marshaler        = (*NonISO8601Time)(nil)
+	_ cbor.Unmarshaler      = (*NonISO8601Time)(nil)
 )
 
-// MarshalJSON implements the json.Marshaler interface for ISO8601Time.
+// MarshalJSON implements the json.Marshaler interface for ISO8601Time. It writes the layout of the
+// reference server, the only one UnmarshalJSON reads, so the server halves and the TUI show timestamps
+// as they travel and the output can be read back. RFC 3339 dropped the microseconds and added a zone
+// the server never sent.
 func (t NonISO8601Time) MarshalJSON() ([]byte, error) {
-	s := t.Format(time.RFC3339)
+	s := t.Format(nonISO8601Layout)
 	return json.Marshal(s)
 }
 
@@ -63,8 +76,49 @@ func (t *NonISO8601Time) UnmarshalJSON(data []byte) error {
 	return t.Parse(s)
 }
 
+// EncodeMsgpack implements the msgpack.CustomEncoder interface for NonISO8601Time, as the JSON string.
+func (t NonISO8601Time) EncodeMsgpack(enc *msgpack.Encoder) error {
+	return enc.EncodeString(t.Format(nonISO8601Layout))
+}
+
+// DecodeMsgpack implements the msgpack.CustomDecoder interface for NonISO8601Time.
+func (t *NonISO8601Time) DecodeMsgpack(dec *msgpack.Decoder) error {
+	s, err := dec.DecodeString()
+	if err != nil {
+		return err
+	}
+
+	return t.Parse(s)
+}
+
+// MarshalCBOR implements the cbor.Marshaler interface for NonISO8601Time, as a standard date/time string.
+func (t NonISO8601Time) MarshalCBOR() ([]byte, error) {
+	// Timestamps take their deterministic form under both encodings of CBORSerde
+	return cborDeterministicEncMode.Marshal(cbor.Tag{Number: cborTagDateTime, Content: t.Format(time.RFC3339Nano)})
+}
+
+// UnmarshalCBOR implements the cbor.Unmarshaler interface for NonISO8601Time, untagged strings use the JSON layout.
+func (t *NonISO8601Time) UnmarshalCBOR(data []byte) error {
+	parsedTime, err := unmarshalCBORTime(data, func(data []byte) (time.Time, error) {
+		var s string
+		if err := cborDecMode.Unmarshal(data, &s); err != nil {
+			return time.Time{}, err
+		}
+
+		var untagged NonISO8601Time
+		err := untagged.Parse(s)
+		return untagged.Time, err
+	})
+	if err != nil {
+		return err
+	}
+
+	*t = NonISO8601Time{parsedTime}
+	return nil
+}
+
 func (t *NonISO8601Time) Parse(s string) error {
-	parsedTime, err := time.Parse("2006-01-02T15:04:05.000000", s)
+	parsedTime, err := time.Parse(nonISO8601Layout, s)
 	if err != nil {
 		// Try without seconds, because some responses are dumb now
 		parsedTime, err = time.Parse("2006-01-02T15:04", s)
@@ -81,8 +135,12 @@ type UnixTimestamp struct {
 }
 
 var (
-	_ json.Marshaler   = (*UnixTimestamp)(nil)
-	_ json.Unmarshaler = (*UnixTimestamp)(nil)
+	_ json.Marshaler        = (*UnixTimestamp)(nil)
+	_ json.Unmarshaler      = (*UnixTimestamp)(nil)
+	_ msgpack.CustomEncoder = (*UnixTimestamp)(nil)
+	_ msgpack.CustomDecoder = (*UnixTimestamp)(nil)
+	_ cbor.Marshaler        = (*UnixTimestamp)(nil)
+	_ cbor.Unmarshaler      = (*UnixTimestamp)(nil)
 )
 
 // MarshalJSON implements the json.Marshaler interface for UnixTimestamp.
@@ -101,11 +159,54 @@ func (t *UnixTimestamp) UnmarshalJSON(data []byte) error {
 		return err
 	}
 
-	// Convert unix timestamp to time.Time
+	*t = newUnixTimestamp(floatValue)
+	return nil
+}
+
+// EncodeMsgpack implements the msgpack.CustomEncoder interface for UnixTimestamp, as the JSON float.
+func (t UnixTimestamp) EncodeMsgpack(enc *msgpack.Encoder) error {
+	return enc.EncodeFloat64(float64(t.Unix()) + float64(t.Nanosecond())/1e9)
+}
+
+// DecodeMsgpack implements the msgpack.CustomDecoder interface for UnixTimestamp.
+func (t *UnixTimestamp) DecodeMsgpack(dec *msgpack.Decoder) error {
+	floatValue, err := dec.DecodeFloat64()
+	if err != nil {
+		return err
+	}
+
+	*t = newUnixTimestamp(floatValue)
+	return nil
+}
+
+// MarshalCBOR implements the cbor.Marshaler interface for UnixTimestamp, as an epoch based date/time.
+func (t UnixTimestamp) MarshalCBOR() ([]byte, error) {
+	return cborDeterministicEncMode.Marshal(cbor.Tag{Number: cborTagEpoch, Content: float64(t.Unix()) + float64(t.Nanosecond())/1e9})
+}
+
+// UnmarshalCBOR implements the cbor.Unmarshaler interface for UnixTimestamp, untagged numbers are epoch seconds.
+func (t *UnixTimestamp) UnmarshalCBOR(data []byte) error {
+	parsedTime, err := unmarshalCBORTime(data, func(data []byte) (time.Time, error) {
+		var floatValue float64
+		if err := cborDecMode.Unmarshal(data, &floatValue); err != nil {
+			return time.Time{}, err
+		}
+
+		return newUnixTimestamp(floatValue).Time, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	*t = UnixTimestamp{parsedTime.UTC()}
+	return nil
+}
+
+// newUnixTimestamp converts a unix timestamp in fractional seconds
+func newUnixTimestamp(floatValue float64) UnixTimestamp {
 	seconds := int64(floatValue)
 	nanos := int64((floatValue - float64(seconds)) * 1e9)
-	*t = UnixTimestamp{time.Unix(seconds, nanos).UTC()}
-	return nil
+	return UnixTimestamp{time.Unix(seconds, nanos).UTC()}
 }
 
 var (
@@ -153,7 +254,7 @@ func (a AuthResponse) OperationResponseName() string {
 }
 
 type EchoRequest struct {
-	Message string `json:"mensagem"`
+	Message string `json:"mensagem" validate:"required"`
 }
 
 // CommandOrOperationName implements OperationRequest.
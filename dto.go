@@ -1,7 +1,7 @@
 package main
 
 type HandlerRequest[T any] struct {
-	Protocol string `query:"protocolo" validate:"required, oneof=json proto string"`
+	Protocol string `query:"protocolo" validate:"required, oneof=json proto string msgpack cbor"`
 	Payload  T
 }
 
@@ -0,0 +1,369 @@
+package main
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"slices"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+const (
+	CipherNone             = "none"
+	CipherAESGCM           = "aes-gcm"
+	CipherChaCha20Poly1305 = "chacha20-poly1305"
+)
+
+// Ciphers lists the AEADs a protocol can be sealed with, in display order. The index of a cipher is
+// the header flag of the messages it seals, the flag of none is never accepted.
+var Ciphers = []string{CipherNone, CipherAESGCM, CipherChaCha20Poly1305}
+
+// encryptionKeySize is the size of the pre-shared key, AES-256 and ChaCha20 both take 32 bytes
+const encryptionKeySize = 32
+
+// encryptionNonceSize is the standard nonce size of both AEADs
+const encryptionNonceSize = 12
+
+// encryptionHeaderSize is the flag byte, the 8 byte session, the 8 byte counter, the nonce and the
+// 4 byte big endian length of the sealed body
+const encryptionHeaderSize = 1 + 8 + 8 + encryptionNonceSize + 4
+
+// maxSealedSize bounds the sealed body of a single message
+const maxSealedSize = 16 << 20
+
+// The direction is authenticated with the header, so a request can not be replayed as a response
+const (
+	encryptionDirectionRequest  byte = 1
+	encryptionDirectionResponse byte = 2
+)
+
+// EncryptionStats adds up the messages an EncryptedSerde sealed and opened. Bytes are counted in
+// both directions, sealed bytes include the header and the tag.
+type EncryptionStats struct {
+	Sealed         int           `json:"sealed"`
+	Opened         int           `json:"opened"`
+	PlaintextBytes int           `json:"plaintext_bytes"`
+	SealedBytes    int           `json:"sealed_bytes"`
+	SealTime       time.Duration `json:"seal_time"`
+	OpenTime       time.Duration `json:"open_time"`
+}
+
+func (s EncryptionStats) Add(other EncryptionStats) EncryptionStats {
+	return EncryptionStats{
+		Sealed:         s.Sealed + other.Sealed,
+		Opened:         s.Opened + other.Opened,
+		PlaintextBytes: s.PlaintextBytes + other.PlaintextBytes,
+		SealedBytes:    s.SealedBytes + other.SealedBytes,
+		SealTime:       s.SealTime + other.SealTime,
+		OpenTime:       s.OpenTime + other.OpenTime,
+	}
+}
+
+func (s EncryptionStats) Messages() int {
+	return s.Sealed + s.Opened
+}
+
+// EncryptedSerde seals the messages of Inner with Cipher and a pre-shared key. Every message starts
+// with a flag byte naming its cipher, the session of the sender, a counter, the nonce and the length
+// of the sealed body. The header and the direction of the message are authenticated along with the
+// body, tampered messages fail to open and messages whose counter is not above the last one received
+// from their session are rejected as replays. Receivers open both ciphers, so a server keeps a single
+// EncryptedSerde for all of its clients.
+type EncryptedSerde struct {
+	Inner  Serde
+	Cipher string
+
+	aeads   []cipher.AEAD
+	session uint64
+
+	mu       sync.Mutex
+	counter  uint64
+	received map[uint64]uint64
+	stats    EncryptionStats
+}
+
+func NewEncryptedSerde(inner Serde, cipherName string, key []byte) (*EncryptedSerde, error) {
+	if cipherName == CipherNone || !slices.Contains(Ciphers, cipherName) {
+		return nil, fmt.Errorf("unknown cipher %s", cipherName)
+	}
+
+	if len(key) != encryptionKeySize {
+		return nil, fmt.Errorf("the pre-shared key must have %d bytes, got %d", encryptionKeySize, len(key))
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	chacha, err := chacha20poly1305.New(key)
+	if err != nil {
+		return nil, err
+	}
+
+	var session [8]byte
+	if _, err := rand.Read(session[:]); err != nil {
+		return nil, err
+	}
+
+	return &EncryptedSerde{
+		Inner:    inner,
+		Cipher:   cipherName,
+		aeads:    []cipher.AEAD{nil, gcm, chacha},
+		session:  binary.BigEndian.Uint64(session[:]),
+		received: map[uint64]uint64{},
+	}, nil
+}
+
+// ParseEncryptions reads the cipher of every protocol from a comma separated list of
+// protocol=cipher pairs, a lone cipher applies to all the protocols
+func ParseEncryptions(value string, protocols []string) (map[string]string, error) {
+	return parseProtocolOptions(value, protocols, "cipher", Ciphers)
+}
+
+// PreSharedKey decodes the hex encoded key messages are sealed with
+func (s *AppSettings) PreSharedKey() ([]byte, error) {
+	if s.EncryptionKey == "" {
+		return nil, fmt.Errorf("encryption needs a pre-shared key, set app.encryption-key")
+	}
+
+	key, err := hex.DecodeString(s.EncryptionKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pre-shared key: %w", err)
+	}
+
+	return key, nil
+}
+
+// Unwrap returns the serde the messages are sealed for
+func (e *EncryptedSerde) Unwrap() Serde {
+	return e.Inner
+}
+
+// Stats returns what was sealed and opened since the last ResetStats
+func (e *EncryptedSerde) Stats() EncryptionStats {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.stats
+}
+
+func (e *EncryptedSerde) ResetStats() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.stats = EncryptionStats{}
+}
+
+// next returns the counter of the next message sent
+func (e *EncryptedSerde) next() uint64 {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.counter++
+	return e.counter
+}
+
+func (e *EncryptedSerde) record(stats EncryptionStats) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.stats = e.stats.Add(stats)
+}
+
+// additionalData authenticates the header, but the nonce, along with the direction of the message
+func additionalData(header []byte, direction byte) []byte {
+	data := make([]byte, 0, encryptionHeaderSize-encryptionNonceSize+1)
+	data = append(data, header[:17]...)
+	data = append(data, header[17+encryptionNonceSize:encryptionHeaderSize]...)
+
+	return append(data, direction)
+}
+
+// seal puts plaintext behind the header, sealed with the cipher of the serde
+func (e *EncryptedSerde) seal(plaintext []byte, direction byte) ([]byte, error) {
+	flag := slices.Index(Ciphers, e.Cipher)
+	if flag <= 0 {
+		return nil, fmt.Errorf("unknown cipher %s", e.Cipher)
+	}
+	aead := e.aeads[flag]
+
+	start := time.Now()
+	message := make([]byte, encryptionHeaderSize, encryptionHeaderSize+len(plaintext)+aead.Overhead())
+	message[0] = byte(flag)
+	binary.BigEndian.PutUint64(message[1:9], e.session)
+	binary.BigEndian.PutUint64(message[9:17], e.next())
+
+	nonce := message[17 : 17+encryptionNonceSize]
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	binary.BigEndian.PutUint32(message[17+encryptionNonceSize:], uint32(len(plaintext)+aead.Overhead()))
+
+	message = aead.Seal(message, nonce, plaintext, additionalData(message, direction))
+	elapsed := time.Since(start)
+
+	e.record(EncryptionStats{
+		Sealed:         1,
+		PlaintextBytes: len(plaintext),
+		SealedBytes:    len(message),
+		SealTime:       elapsed,
+	})
+
+	return message, nil
+}
+
+// ReplyLength implements ReplyFramer.
+func (e *EncryptedSerde) ReplyLength(data []byte) (int, bool) {
+	if len(data) < encryptionHeaderSize {
+		return 0, false
+	}
+
+	return encryptionHeaderSize + int(binary.BigEndian.Uint32(data[17+encryptionNonceSize:encryptionHeaderSize])), true
+}
+
+// open returns the plaintext behind the header once it is authenticated and known to be fresh
+func (e *EncryptedSerde) open(data []byte, direction byte) ([]byte, error) {
+	if len(data) < encryptionHeaderSize {
+		return nil, fmt.Errorf("data too small, expected at least %d bytes for the encryption header, got %d", encryptionHeaderSize, len(data))
+	}
+
+	flag := int(data[0])
+	if flag == 0 {
+		return nil, fmt.Errorf("unencrypted message rejected")
+	}
+	if flag >= len(Ciphers) {
+		return nil, fmt.Errorf("unknown cipher flag %d", flag)
+	}
+	aead := e.aeads[flag]
+
+	session := binary.BigEndian.Uint64(data[1:9])
+	counter := binary.BigEndian.Uint64(data[9:17])
+	nonce := data[17 : 17+encryptionNonceSize]
+
+	length := int(binary.BigEndian.Uint32(data[17+encryptionNonceSize : encryptionHeaderSize]))
+	if length > maxSealedSize {
+		return nil, fmt.Errorf("sealed body of %d bytes exceeds the limit of %d bytes", length, maxSealedSize)
+	}
+	body := data[encryptionHeaderSize:]
+	if len(body) < length {
+		return nil, fmt.Errorf("sealed body of %d bytes is shorter than the %d bytes of its header", len(body), length)
+	}
+	body = body[:length]
+
+	start := time.Now()
+	plaintext, err := aead.Open(nil, nonce, body, additionalData(data, direction))
+	if err != nil {
+		return nil, fmt.Errorf("message authentication failed: %w", err)
+	}
+	elapsed := time.Since(start)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if counter <= e.received[session] {
+		return nil, fmt.Errorf("replayed message, counter %d of session %016x was already received", counter, session)
+	}
+	e.received[session] = counter
+
+	e.stats = e.stats.Add(EncryptionStats{
+		Opened:         1,
+		PlaintextBytes: len(plaintext),
+		SealedBytes:    encryptionHeaderSize + length,
+		OpenTime:       elapsed,
+	})
+
+	return plaintext, nil
+}
+
+// Marshal implements Serde.
+func (e *EncryptedSerde) Marshal(v any) ([]byte, error) {
+	data, err := e.Inner.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return e.seal(data, encryptionDirectionRequest)
+}
+
+// Unmarshal implements Serde.
+func (e *EncryptedSerde) Unmarshal(data []byte, v any) error {
+	data, err := e.open(data, encryptionDirectionResponse)
+	if err != nil {
+		return err
+	}
+
+	return e.Inner.Unmarshal(data, v)
+}
+
+// UnmarshalRequest implements ServerSerde.
+func (e *EncryptedSerde) UnmarshalRequest(data []byte, req *PresentationLayerRequest) error {
+	inner, ok := e.Inner.(ServerSerde)
+	if !ok {
+		return fmt.Errorf("%T does not implement ServerSerde", e.Inner)
+	}
+
+	data, err := e.open(data, encryptionDirectionRequest)
+	if err != nil {
+		return err
+	}
+
+	return inner.UnmarshalRequest(data, req)
+}
+
+// MarshalResponse implements ServerSerde.
+func (e *EncryptedSerde) MarshalResponse(v any) ([]byte, error) {
+	inner, ok := e.Inner.(ServerSerde)
+	if !ok {
+		return nil, fmt.Errorf("%T does not implement ServerSerde", e.Inner)
+	}
+
+	data, err := inner.MarshalResponse(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return e.seal(data, encryptionDirectionResponse)
+}
+
+// EncryptionOperationSummary is the encryption of the messages of one operation
+type EncryptionOperationSummary struct {
+	Protocol  string          `json:"protocol"`
+	Cipher    string          `json:"cipher"`
+	Operation string          `json:"operation"`
+	Stats     EncryptionStats `json:"stats"`
+}
+
+// WriteEncryptionText writes a table of the average message sizes and cipher time of every summary
+func WriteEncryptionText(w io.Writer, summaries []EncryptionOperationSummary) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "protocol\tcipher\toperation\tmessages\tplaintext\tsealed\toverhead\tseal\topen\t")
+
+	for _, s := range summaries {
+		messages := max(s.Stats.Messages(), 1)
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%d\t%d\t%d\t%s\t%s\t\n",
+			s.Protocol,
+			s.Cipher,
+			s.Operation,
+			s.Stats.Messages(),
+			s.Stats.PlaintextBytes/messages,
+			s.Stats.SealedBytes/messages,
+			(s.Stats.SealedBytes-s.Stats.PlaintextBytes)/messages,
+			averageDuration(s.Stats.SealTime, s.Stats.Sealed),
+			averageDuration(s.Stats.OpenTime, s.Stats.Opened),
+		)
+	}
+
+	return tw.Flush()
+}
@@ -0,0 +1,361 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testPreSharedKey = bytes.Repeat([]byte{0x42}, encryptionKeySize)
+
+func newEncryptedSerde(t *testing.T, protocol, cipherName string) *EncryptedSerde {
+	t.Helper()
+
+	inner, err := NewSerde(protocol)
+	require.NoError(t, err)
+
+	serde, err := NewEncryptedSerde(inner, cipherName, testPreSharedKey)
+	require.NoError(t, err)
+
+	return serde
+}
+
+func TestEncryptedSerdeRoundTrip(t *testing.T) {
+	for _, protocol := range Protocols {
+		for _, cipherName := range Ciphers[1:] {
+			t.Run(protocol+"/"+cipherName, func(t *testing.T) {
+				client := newEncryptedSerde(t, protocol, cipherName)
+				server := newEncryptedSerde(t, protocol, cipherName)
+				r := newConformanceRand(t)
+
+				for _, tc := range conformanceRequests {
+					// Arrange
+					expected := tc.generate(r)
+
+					// Act
+					data, err := client.Marshal(expected)
+					require.NoError(t, err)
+
+					var actual PresentationLayerRequest
+					err = server.UnmarshalRequest(data, &actual)
+
+					// Assert
+					require.NoError(t, err, "UnmarshalRequest should not return an error for %q", data)
+					require.Equal(t, expected, actual, "%s request should survive the round trip", tc.name)
+				}
+
+				for _, tc := range conformanceResponses {
+					// Arrange
+					body := tc.generate(r)
+					expected := PresentationLayerResponse[OperationResponse]{Body: body, StatusCode: http.StatusOK}
+
+					// Act
+					data, err := server.MarshalResponse(expected)
+					require.NoError(t, err)
+
+					actual := PresentationLayerResponse[OperationResponse]{Body: tc.newBody()}
+					err = client.Unmarshal(data, &actual)
+
+					// Assert
+					require.NoError(t, err, "Unmarshal should not return an error for %q", data)
+					expectedJSON, err := json.Marshal(body)
+					require.NoError(t, err)
+					actualJSON, err := json.Marshal(actual.Body)
+					require.NoError(t, err)
+					require.JSONEq(t, string(expectedJSON), string(actualJSON), "%s response should survive the round trip", tc.name)
+				}
+
+				stats := client.Stats()
+				assert.Equal(t, len(conformanceRequests), stats.Sealed)
+				assert.Equal(t, len(conformanceResponses), stats.Opened)
+				assert.Greater(t, stats.SealedBytes, stats.PlaintextBytes)
+			})
+		}
+	}
+}
+
+func TestEncryptedSerdeHidesCredentials(t *testing.T) {
+	for _, cipherName := range Ciphers[1:] {
+		// Arrange
+		serde := newEncryptedSerde(t, ProtocolString, cipherName)
+		req := PresentationLayerRequest{Body: &AuthRequest{StudentID: "538349", Timestamp: time.Now()}}
+
+		// Act
+		data, err := serde.Marshal(req)
+
+		// Assert
+		require.NoError(t, err)
+		assert.NotContains(t, string(data), "538349", "%s should not leak the student id", cipherName)
+	}
+}
+
+func TestEncryptedSerdeOpensEveryCipher(t *testing.T) {
+	server := newEncryptedSerde(t, ProtocolJSON, CipherAESGCM)
+	expected := PresentationLayerRequest{Token: "abc", Body: EchoRequest{Message: "ola mundo"}}
+
+	for _, cipherName := range Ciphers[1:] {
+		t.Run(cipherName, func(t *testing.T) {
+			// Arrange
+			client := newEncryptedSerde(t, ProtocolJSON, cipherName)
+			data, err := client.Marshal(expected)
+			require.NoError(t, err)
+
+			// Act
+			var actual PresentationLayerRequest
+			err = server.UnmarshalRequest(data, &actual)
+
+			// Assert
+			require.NoError(t, err, "the header flag should name the cipher of the message")
+			assert.Equal(t, expected, actual)
+		})
+	}
+}
+
+func TestEncryptedSerdeRejectsTamperedMessages(t *testing.T) {
+	server := newEncryptedSerde(t, ProtocolJSON, CipherChaCha20Poly1305)
+
+	sealed, err := server.MarshalResponse(PresentationLayerResponse[OperationResponse]{Body: &EchoResponse{EchoMessage: "ECO"}, StatusCode: http.StatusOK})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		offset int
+	}{
+		{name: "flag", offset: 0},
+		{name: "session", offset: 3},
+		{name: "counter", offset: 12},
+		{name: "nonce", offset: 20},
+		{name: "length", offset: encryptionHeaderSize - 1},
+		{name: "ciphertext", offset: encryptionHeaderSize + 2},
+		{name: "tag", offset: len(sealed) - 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			client := newEncryptedSerde(t, ProtocolJSON, CipherChaCha20Poly1305)
+			data := bytes.Clone(sealed)
+			data[tt.offset] ^= 0x01
+
+			// Act
+			resp := PresentationLayerResponse[OperationResponse]{Body: &EchoResponse{}}
+			err := client.Unmarshal(data, &resp)
+
+			// Assert
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestEncryptedSerdeRejectsReplays(t *testing.T) {
+	// Arrange
+	client := newEncryptedSerde(t, ProtocolJSON, CipherAESGCM)
+	server := newEncryptedSerde(t, ProtocolJSON, CipherAESGCM)
+	resp := PresentationLayerResponse[OperationResponse]{Body: &EchoResponse{EchoMessage: "ECO"}, StatusCode: http.StatusOK}
+
+	first, err := server.MarshalResponse(resp)
+	require.NoError(t, err)
+	second, err := server.MarshalResponse(resp)
+	require.NoError(t, err)
+
+	// Act
+	secondErr := client.Unmarshal(second, &PresentationLayerResponse[OperationResponse]{Body: &EchoResponse{}})
+	replayErr := client.Unmarshal(second, &PresentationLayerResponse[OperationResponse]{Body: &EchoResponse{}})
+	staleErr := client.Unmarshal(first, &PresentationLayerResponse[OperationResponse]{Body: &EchoResponse{}})
+
+	// Assert
+	require.NoError(t, secondErr)
+	assert.ErrorContains(t, replayErr, "replayed message")
+	assert.ErrorContains(t, staleErr, "replayed message", "counters below the last one received are stale")
+}
+
+func TestEncryptedSerdeTracksEverySession(t *testing.T) {
+	// Arrange
+	server := newEncryptedSerde(t, ProtocolJSON, CipherAESGCM)
+	clients := []*EncryptedSerde{
+		newEncryptedSerde(t, ProtocolJSON, CipherAESGCM),
+		newEncryptedSerde(t, ProtocolJSON, CipherChaCha20Poly1305),
+	}
+
+	for range 3 {
+		for _, client := range clients {
+			data, err := client.Marshal(PresentationLayerRequest{Token: "abc", Body: EchoRequest{Message: "ola"}})
+			require.NoError(t, err)
+
+			// Act
+			var req PresentationLayerRequest
+			err = server.UnmarshalRequest(data, &req)
+
+			// Assert
+			require.NoError(t, err, "clients count their messages independently")
+		}
+	}
+}
+
+func TestEncryptedSerdeRejectsReflectedRequests(t *testing.T) {
+	// Arrange
+	client := newEncryptedSerde(t, ProtocolJSON, CipherAESGCM)
+	other := newEncryptedSerde(t, ProtocolJSON, CipherAESGCM)
+
+	data, err := other.Marshal(PresentationLayerRequest{Token: "abc", Body: EchoRequest{Message: "ola"}})
+	require.NoError(t, err)
+
+	// Act
+	err = client.Unmarshal(data, &PresentationLayerResponse[OperationResponse]{Body: &EchoResponse{}})
+
+	// Assert
+	assert.ErrorContains(t, err, "message authentication failed", "a request must not open as a response")
+}
+
+func TestEncryptedSerdeRejectsOtherKeys(t *testing.T) {
+	// Arrange
+	client := newEncryptedSerde(t, ProtocolJSON, CipherAESGCM)
+	server, err := NewEncryptedSerde(jsonserde, CipherAESGCM, bytes.Repeat([]byte{0x24}, encryptionKeySize))
+	require.NoError(t, err)
+
+	data, err := client.Marshal(PresentationLayerRequest{Token: "abc", Body: EchoRequest{Message: "ola"}})
+	require.NoError(t, err)
+
+	// Act
+	var req PresentationLayerRequest
+	err = server.UnmarshalRequest(data, &req)
+
+	// Assert
+	assert.ErrorContains(t, err, "message authentication failed")
+}
+
+func TestEncryptedSerdeRejectsTruncatedBody(t *testing.T) {
+	// Arrange
+	server := newEncryptedSerde(t, ProtocolJSON, CipherAESGCM)
+	client := newEncryptedSerde(t, ProtocolJSON, CipherAESGCM)
+
+	data, err := server.MarshalResponse(PresentationLayerResponse[OperationResponse]{Body: &EchoResponse{EchoMessage: "ECO"}, StatusCode: http.StatusOK})
+	require.NoError(t, err)
+
+	// Act
+	length, ok := client.ReplyLength(data[:encryptionHeaderSize])
+	actual := PresentationLayerResponse[OperationResponse]{Body: &EchoResponse{}}
+	err = client.Unmarshal(data[:len(data)-1], &actual)
+
+	// Assert
+	require.True(t, ok)
+	assert.Equal(t, len(data), length)
+	assert.ErrorContains(t, err, "shorter than")
+}
+
+func TestEncryptedSerdeRejectsMalformedMessages(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{name: "empty", data: nil},
+		{name: "short header", data: []byte{1, 0, 0}},
+		{name: "unencrypted", data: append([]byte{0}, make([]byte, encryptionHeaderSize)...)},
+		{name: "unknown flag", data: append([]byte{9}, make([]byte, encryptionHeaderSize)...)},
+		{name: "length over the limit", data: append(append([]byte{1}, make([]byte, encryptionHeaderSize-5)...), 0xff, 0xff, 0xff, 0xff)},
+		{name: "garbage body", data: append(append([]byte{2}, make([]byte, encryptionHeaderSize-2)...), 3, 'x', 'y', 'z')},
+	}
+
+	serde := newEncryptedSerde(t, ProtocolJSON, CipherAESGCM)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			resp := PresentationLayerResponse[OperationResponse]{Body: &EchoResponse{}}
+			err := serde.Unmarshal(tt.data, &resp)
+
+			// Assert
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestNewEncryptedSerdeValidation(t *testing.T) {
+	tests := []struct {
+		name   string
+		cipher string
+		key    []byte
+	}{
+		{name: "none is not a cipher", cipher: CipherNone, key: testPreSharedKey},
+		{name: "unknown cipher", cipher: "rot13", key: testPreSharedKey},
+		{name: "short key", cipher: CipherAESGCM, key: testPreSharedKey[:16]},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			_, err := NewEncryptedSerde(jsonserde, tt.cipher, tt.key)
+
+			// Assert
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestPreSharedKey(t *testing.T) {
+	// Arrange
+	settings := &AppSettings{EncryptionKey: strings.Repeat("42", encryptionKeySize)}
+
+	// Act
+	key, err := settings.PreSharedKey()
+	_, missingErr := (&AppSettings{}).PreSharedKey()
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, testPreSharedKey, key)
+	assert.ErrorContains(t, missingErr, "app.encryption-key")
+}
+
+func TestSerdeLayer(t *testing.T) {
+	// Arrange
+	compressed, err := NewCompressedSerde(jsonserde, CompressionZstd)
+	require.NoError(t, err)
+	encrypted, err := NewEncryptedSerde(compressed, CipherAESGCM, testPreSharedKey)
+	require.NoError(t, err)
+
+	// Act
+	foundCompressed, compressedOK := serdeLayer[*CompressedSerde](encrypted)
+	foundEncrypted, encryptedOK := serdeLayer[*EncryptedSerde](compressed)
+
+	// Assert
+	assert.True(t, compressedOK)
+	assert.Same(t, compressed, foundCompressed)
+	assert.False(t, encryptedOK)
+	assert.Nil(t, foundEncrypted)
+}
+
+func TestWriteEncryptionText(t *testing.T) {
+	// Arrange
+	summaries := []EncryptionOperationSummary{
+		{
+			Protocol:  ProtocolProtobuf,
+			Cipher:    CipherChaCha20Poly1305,
+			Operation: OperationEcho,
+			Stats: EncryptionStats{
+				Sealed:         2,
+				Opened:         2,
+				PlaintextBytes: 400,
+				SealedBytes:    596,
+				SealTime:       4 * time.Microsecond,
+				OpenTime:       6 * time.Microsecond,
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+
+	// Act
+	err := WriteEncryptionText(&buf, summaries)
+
+	// Assert
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+	assert.Equal(t, []string{"protobuf", "chacha20-poly1305", "echo", "4", "100", "149", "49", "2µs", "3µs"}, strings.Fields(lines[1]))
+}
@@ -0,0 +1,203 @@
+package main
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fuzzResponseBodies builds every response type a server reply can be bound to
+var fuzzResponseBodies = []func() OperationResponse{
+	func() OperationResponse { return &AuthResponse{} },
+	func() OperationResponse { return &EchoResponse{} },
+	func() OperationResponse { return &SumResponse{} },
+	func() OperationResponse { return &TimestampResponse{} },
+	func() OperationResponse { return &StatusResponse{} },
+	func() OperationResponse { return &HistoryResponse{} },
+	func() OperationResponse { return &LogoutResponse{} },
+}
+
+// fuzzSeeds returns the flow fixtures and the sweep responses of protocol
+func fuzzSeeds(f testing.TB, protocol string) [][]byte {
+	seeds := [][]byte{}
+
+	for _, fixture := range appLayerFlowFixtures(f) {
+		if fixture.protocol != protocol {
+			continue
+		}
+		seeds = append(seeds, fixture.authReply, fixture.echoReply, fixture.logoutReply, fixture.errorReply)
+	}
+
+	for _, c := range SweepResponseCases() {
+		if c.Size > 10 {
+			continue
+		}
+
+		data, err := encodeSweepResponse(protocol, c)
+		if err != nil {
+			f.Fatalf("failed to encode seed %s: %v", c.Name(), err)
+		}
+		seeds = append(seeds, data)
+	}
+
+	return seeds
+}
+
+func fuzzUnmarshal(f *testing.F, protocol string, seeds ...[]byte) {
+	serde, err := NewSerde(protocol)
+	if err != nil {
+		f.Fatal(err)
+	}
+
+	for _, seed := range append(fuzzSeeds(f, protocol), seeds...) {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		for _, newBody := range fuzzResponseBodies {
+			resp := PresentationLayerResponse[OperationResponse]{Body: newBody()}
+
+			// Any input may be rejected, but it must never panic
+			_ = serde.Unmarshal(data, &resp)
+		}
+	})
+}
+
+func FuzzStringUnmarshal(f *testing.F) {
+	fuzzUnmarshal(f, ProtocolString,
+		[]byte("OK|numeros_originais=1.0,2.0,3.0|quantidade=3|soma=6.0|media=2.0|maximo=3.0|minimo=1.0|timestamp_calculo=2025-11-01T16:04:55.257055|timestamp=2025-11-01T16:04:55.256385|FIM"),
+		[]byte("OK|timestamp_unix=1761859371.6872423|timestamp_iso=2025-10-30T21:22:51.687237|timestamp_formatado=30/10/2025 21:22:51|ano=2025|mes=10|dia=30|hora=21|minuto=22|segundo=51|microsegundo=687237|timestamp=2025-10-30T21:22:51.686268|FIM"),
+		[]byte("OK|status=ATIVO|timestamp_consulta=2025-10-30T21:43:40.585539|operacoes_processadas=33|sessoes_ativas=1|tempo_ativo=1761860620.5855508|versao=1.0.0|metricas={'cpu_simulado': 34.94, 'memoria_simulada': 63.05, 'latencia_simulada': 2.66}|timestamp=2025-10-30T21:43:40.584881|FIM"),
+		[]byte("OK|aluno_id=538349|limite_solicitado=1|total_encontrado=1|historico=[]|timestamp_consulta=2025-10-31T01:14:19.616416|estatisticas={'total_operacoes': 1, 'operacoes_sucesso': 1, 'operacoes_erro': 0, 'taxa_sucesso': 100.0}|operacoes_mais_usadas=('status', 1)|timestamp=2025-10-31T01:14:19.615292|FIM"),
+		[]byte("OK|token=abc123|nome=Test User"),
+		[]byte("OK|key=value=extra=data|FIM"),
+		[]byte("INVALIDO|msg=bad|FIM"),
+		[]byte("OK|aluno_id=1|limite_solicitado=1|total_encontrado=1|historico=[{'operacao': 'x', 'parametros': {'a': 'null ['}, 'timestamp': '2025-10-31T16:48:31.156806', 'sucesso': True}]|timestamp_consulta=2025-10-31T01:14:19.616416|estatisticas={'total_operacoes': 1, 'operacoes_sucesso': 1, 'operacoes_erro': 0, 'taxa_sucesso': 100.0}|timestamp=2025-10-31T01:14:19.615292|FIM"),
+	)
+}
+
+func FuzzJSONUnmarshal(f *testing.F) {
+	fuzzUnmarshal(f, ProtocolJSON,
+		[]byte(`{"sucesso": true, "token": "tokenauth", "timestamp": "2025-11-01T13:18:04.381480"}`),
+		[]byte(`{"sucesso": true, "resultado": null, "timestamp": "2025-11-01T13:18"}`),
+		[]byte(`{"sucesso": true, "resultado": [], "timestamp": "2025-11-01T13:18"}`),
+		[]byte(`null`),
+	)
+}
+
+func FuzzProtobufUnmarshal(f *testing.F) {
+	fuzzUnmarshal(f, ProtocolProtobuf,
+		[]byte{0, 0, 0, 0},
+		[]byte{0, 0, 0, 2, 10, 0},
+		[]byte{0xff, 0xff, 0xff, 0xff},
+	)
+}
+
+func FuzzMsgpackUnmarshal(f *testing.F) {
+	fuzzUnmarshal(f, ProtocolMsgpack,
+		[]byte{0, 0, 0, 0},
+		[]byte{0, 0, 0, 1, 0x80},
+		[]byte{0, 0, 0, 3, 0x81, 0xa1, 0xc1},
+		[]byte{0xff, 0xff, 0xff, 0xff},
+	)
+}
+
+func FuzzCBORUnmarshal(f *testing.F) {
+	fuzzUnmarshal(f, ProtocolCBOR,
+		[]byte{0, 0, 0, 0},
+		[]byte{0, 0, 0, 1, 0xa0},
+		[]byte{0, 0, 0, 3, 0xa1, 0x61, 0x1c},
+		[]byte{0, 0, 0, 4, 0xbf, 0x61, 0x61, 0xff},
+		[]byte{0xff, 0xff, 0xff, 0xff},
+	)
+}
+
+func FuzzCompressedUnmarshal(f *testing.F) {
+	serde, err := NewCompressedSerde(jsonserde, CompressionNone)
+	if err != nil {
+		f.Fatal(err)
+	}
+
+	// Seed every codec with the JSON replies, the flag of the message picks the decompressor
+	for i, codec := range Compressions {
+		for _, seed := range fuzzSeeds(f, ProtocolJSON) {
+			body, err := compressBody(codec, seed)
+			if err != nil {
+				f.Fatal(err)
+			}
+			f.Add(append(binary.BigEndian.AppendUint32([]byte{byte(i)}, uint32(len(body))), body...))
+		}
+	}
+	f.Add([]byte{1, 0xff, 0xff, 0xff, 0xff})
+	f.Add([]byte{3, 0, 0, 0, 2, 0xff, 0xff})
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		for _, newBody := range fuzzResponseBodies {
+			resp := PresentationLayerResponse[OperationResponse]{Body: newBody()}
+
+			// Any input may be rejected, but it must never panic
+			_ = serde.Unmarshal(data, &resp)
+		}
+	})
+}
+
+func FuzzEncryptedUnmarshal(f *testing.F) {
+	key := make([]byte, encryptionKeySize)
+	serde, err := NewEncryptedSerde(jsonserde, CipherAESGCM, key)
+	if err != nil {
+		f.Fatal(err)
+	}
+
+	// Seed both ciphers with the JSON replies sealed by a server sharing the key
+	for _, cipherName := range Ciphers[1:] {
+		server, err := NewEncryptedSerde(jsonserde, cipherName, key)
+		if err != nil {
+			f.Fatal(err)
+		}
+
+		for _, seed := range fuzzSeeds(f, ProtocolJSON) {
+			sealed, err := server.seal(seed, encryptionDirectionResponse)
+			if err != nil {
+				f.Fatal(err)
+			}
+			f.Add(sealed)
+		}
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		for _, newBody := range fuzzResponseBodies {
+			resp := PresentationLayerResponse[OperationResponse]{Body: newBody()}
+
+			// Any input may be rejected, but it must never panic
+			_ = serde.Unmarshal(data, &resp)
+		}
+	})
+}
+
+func TestUnmarshalInvalidTargets(t *testing.T) {
+	targets := []struct {
+		name   string
+		target any
+	}{
+		{name: "non pointer", target: PresentationLayerResponse[OperationResponse]{Body: &EchoResponse{}}},
+		{name: "nil pointer", target: (*PresentationLayerResponse[OperationResponse])(nil)},
+		{name: "pointer to non struct", target: new(int)},
+		{name: "struct without response fields", target: &struct{ Name string }{}},
+		{name: "nil body interface", target: &PresentationLayerResponse[OperationResponse]{}},
+	}
+
+	for _, fixture := range appLayerFlowFixtures(t) {
+		serde, err := NewSerde(fixture.protocol)
+		assert.NoError(t, err)
+
+		for _, tt := range targets {
+			t.Run(fixture.protocol+"/"+tt.name, func(t *testing.T) {
+				assert.NotPanics(t, func() {
+					err := serde.Unmarshal(fixture.echoReply, tt.target)
+					assert.Error(t, err, "Unmarshal should reject the target")
+				})
+			})
+		}
+	}
+}
@@ -0,0 +1,546 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//go:generate go run ./cmd/serdegen -input domain.go -output codec_generated.go
+
+var (
+	_ Serde          = (*GeneratedStringSerde)(nil)
+	_ Serde          = (*GeneratedJSONSerde)(nil)
+	_ ServerSerde    = (*GeneratedStringSerde)(nil)
+	_ ServerSerde    = (*GeneratedJSONSerde)(nil)
+	_ WireAccountant = (*GeneratedStringSerde)(nil)
+	_ WireAccountant = (*GeneratedJSONSerde)(nil)
+)
+
+// GeneratedStringSerde is StringSerde with the codecs generated from domain.go, it writes
+// and reads the same bytes. Values without a generated codec fall back to StringSerde.
+type GeneratedStringSerde struct {
+	StringSerde
+}
+
+// GeneratedJSONSerde is JSONSerde with the codecs generated from domain.go, it writes
+// and reads the same bytes. Values without a generated codec fall back to JSONSerde.
+type GeneratedJSONSerde struct {
+	JSONSerde
+}
+
+// responseTarget is a presentation layer response seen through its fields, so the
+// generated codecs can bind it without reflection
+type responseTarget struct {
+	body       OperationResponse
+	statusCode *int
+	err        **PresentationLayerErrorResponse
+}
+
+func (t responseTarget) fail(statusCode int, message string) {
+	*t.err = &PresentationLayerErrorResponse{
+		Code:    http.StatusText(statusCode),
+		Message: message,
+		Details: make(map[string]any),
+	}
+}
+
+// Marshal implements Serde.
+func (s GeneratedStringSerde) Marshal(v any) ([]byte, error) {
+	r, ok := v.(PresentationLayerRequest)
+	if !ok || r.Body == nil {
+		return s.StringSerde.Marshal(v)
+	}
+
+	body := r.Body
+	buf := make([]byte, 0, 64)
+
+	if body.IsOperation() {
+		buf = append(buf, "OP"...)
+	} else {
+		buf = append(buf, body.CommandOrOperationName()...)
+	}
+
+	if r.Token != "" {
+		buf = append(buf, "|token="...)
+		buf = append(buf, r.Token...)
+	}
+
+	if body.IsOperation() {
+		buf = append(buf, "|operacao="...)
+		buf = append(buf, body.CommandOrOperationName()...)
+	}
+
+	buf, ok = appendStringRequestBody(buf, body)
+	if !ok {
+		return s.StringSerde.Marshal(v)
+	}
+
+	return append(buf, "|FIM\n"...), nil
+}
+
+// Unmarshal implements Serde.
+func (s GeneratedStringSerde) Unmarshal(data []byte, v any) error {
+	target, ok := generatedResponseTarget(v)
+	if !ok {
+		return s.StringSerde.Unmarshal(data, v)
+	}
+
+	dataArgs := strings.Split(string(data), "|")
+
+	if len(dataArgs) < 3 {
+		return fmt.Errorf("invalid response from server, expected at least 3 parameters, found %d, data %s", len(dataArgs), string(data))
+	}
+
+	if strings.TrimSuffix(dataArgs[len(dataArgs)-1], "\n") != "FIM" {
+		target.fail(http.StatusInternalServerError, "Malformed response from server: missing FIM token")
+		return nil
+	}
+
+	var statusCode int
+
+	switch status := dataArgs[0]; status {
+	case "OK":
+		statusCode = http.StatusOK
+	case "INVALIDO":
+		statusCode = http.StatusUnprocessableEntity
+	case "ERROR":
+		statusCode = http.StatusInternalServerError
+	default:
+		return fmt.Errorf("unexpected status code %s", status)
+	}
+
+	*target.statusCode = statusCode
+
+	properties := make(map[string]string, len(dataArgs)-2)
+	for _, arg := range dataArgs[1 : len(dataArgs)-1] {
+		property, value, ok := strings.Cut(arg, "=")
+		if !ok || strings.Contains(value, "=") {
+			return fmt.Errorf("expected 2 args after spliting argument, found %d", strings.Count(arg, "=")+1)
+		}
+		properties[property] = value
+	}
+
+	if statusCode != http.StatusOK {
+		target.fail(statusCode, properties["msg"])
+		return nil
+	}
+
+	*target.err = nil
+
+	return bindStringResponseBody(target.body, properties)
+}
+
+// Marshal implements Serde.
+func (j GeneratedJSONSerde) Marshal(v any) ([]byte, error) {
+	r, ok := v.(PresentationLayerRequest)
+	if !ok || r.Body == nil {
+		return j.JSONSerde.Marshal(v)
+	}
+
+	buf := make([]byte, 0, 128)
+
+	switch name := r.Body.CommandOrOperationName(); name {
+	case logoutCommandName:
+		buf = append(buf, `{"tipo":"logout"`...)
+		buf = appendJSONOptionalString(buf, `,"token":`, r.Token)
+	case authCommandName:
+		auth, ok := r.Body.(AuthRequest)
+		if !ok {
+			return j.JSONSerde.Marshal(v)
+		}
+
+		buf = append(buf, `{"tipo":"autenticar"`...)
+		buf = appendJSONOptionalString(buf, `,"aluno_id":`, auth.StudentID)
+	default:
+		buf = append(buf, `{"tipo":"operacao"`...)
+		buf = appendJSONOptionalString(buf, `,"operacao":`, name)
+		buf = appendJSONOptionalString(buf, `,"token":`, r.Token)
+		buf = append(buf, `,"parametros":`...)
+
+		var err error
+		buf, ok, err = appendJSONRequestBody(buf, r.Body)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return j.JSONSerde.Marshal(v)
+		}
+	}
+
+	return append(buf, '}'), nil
+}
+
+// appendJSONOptionalString appends an omitempty string field
+func appendJSONOptionalString(buf []byte, key string, value string) []byte {
+	if value == "" {
+		return buf
+	}
+
+	buf = append(buf, key...)
+
+	return appendJSONString(buf, value)
+}
+
+// Unmarshal implements Serde.
+func (j GeneratedJSONSerde) Unmarshal(data []byte, v any) error {
+	target, ok := generatedResponseTarget(v)
+	if !ok {
+		return j.JSONSerde.Unmarshal(data, v)
+	}
+
+	if err := validJSON(data); err != nil {
+		return err
+	}
+
+	var wrapper jsonResponseWrapper
+	if err := decodeJSONResponseWrapper(newJSONDecoder(data), target.body, &wrapper); err != nil {
+		return err
+	}
+
+	if !wrapper.Success {
+		*target.statusCode = http.StatusInternalServerError
+		target.fail(http.StatusInternalServerError, wrapper.Message)
+		return nil
+	}
+
+	*target.statusCode = http.StatusOK
+	*target.err = nil
+
+	setJSONResponseTimestamp(target.body, wrapper.Timestamp)
+
+	switch body := target.body.(type) {
+	case *AuthResponse:
+		body.Token = wrapper.Token
+		// Some servers leave dados_aluno out of the reply
+		if wrapper.StudentData != nil {
+			body.Name = wrapper.StudentData.Name
+			body.Enrollment = wrapper.StudentData.Enrollment
+		}
+	case *LogoutResponse:
+		body.Message = wrapper.Message
+	}
+
+	return nil
+}
+
+// decodeJSONResponseWrapper decodes the reply envelope, resultado is bound straight to body
+func decodeJSONResponseWrapper(d *jsonDecoder, body OperationResponse, w *jsonResponseWrapper) error {
+	if d.null() {
+		return nil
+	}
+
+	if err := d.openObject("main.jsonResponseWrapper"); err != nil {
+		return err
+	}
+
+	// A null resultado drops the body from the wrapper, later values are decoded into nothing
+	resultDropped := false
+
+	for first := true; ; first = false {
+		key, ok, err := d.nextKey(first)
+		if err != nil || !ok {
+			return err
+		}
+
+		switch {
+		case jsonKeyIs(key, "mensagem"):
+			err = decodeJSONString(d, &w.Message)
+		case jsonKeyIs(key, "token"):
+			err = decodeJSONString(d, &w.Token)
+		case jsonKeyIs(key, "sucesso"):
+			err = decodeJSONBool(d, &w.Success)
+		case jsonKeyIs(key, "resultado"):
+			switch {
+			case resultDropped:
+				err = d.skipValue()
+			case d.null():
+				resultDropped = true
+			default:
+				err = decodeJSONResponseBody(d, body)
+			}
+		case jsonKeyIs(key, "dados_aluno"):
+			err = decodeJSONStudentData(d, &w.StudentData)
+		case jsonKeyIs(key, "timestamp"):
+			err = decodeJSONNonISO8601Time(d, &w.Timestamp)
+		default:
+			err = d.skipValue()
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func decodeJSONStudentData(d *jsonDecoder, v **studenData) error {
+	if d.null() {
+		*v = nil
+		return nil
+	}
+
+	if err := d.openObject("main.studenData"); err != nil {
+		return err
+	}
+
+	if *v == nil {
+		*v = &studenData{}
+	}
+
+	for first := true; ; first = false {
+		key, ok, err := d.nextKey(first)
+		if err != nil || !ok {
+			return err
+		}
+
+		switch {
+		case jsonKeyIs(key, "nome"):
+			err = decodeJSONString(d, &(*v).Name)
+		case jsonKeyIs(key, "matricula"):
+			err = decodeJSONString(d, &(*v).Enrollment)
+		default:
+			err = d.skipValue()
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+var (
+	// pythonLiteralReplacer is convertPythonDictTOJSONDict in a single pass
+	pythonLiteralReplacer = strings.NewReplacer("'", `"`, "False", "false", "True", "true", "(", "[", ")", "]")
+
+	stringIntegerPattern = regexp.MustCompile(`^[-+]?\d+$`)
+	stringFloatPattern   = regexp.MustCompile(`^[-+]?\d*\.\d+$`)
+)
+
+// stringPropertyNotFound is the error of a missing required property
+func stringPropertyNotFound(name string) error {
+	return fmt.Errorf("property %s not found", name)
+}
+
+// stringSliceItems splits a list value into the strings its elements are parsed from,
+// like the slice case of setFieldValueFromString
+func stringSliceItems(valueStr string) ([]string, error) {
+	if !strings.HasPrefix(valueStr, "[") {
+		valueStr = "[" + valueStr + "]"
+	}
+
+	d := newJSONDecoder([]byte(pythonLiteralReplacer.Replace(valueStr)))
+	if err := d.openArray("[]interface {}"); err != nil {
+		return nil, fmt.Errorf("error unmarshaling bindSlice from string: %w", err)
+	}
+
+	items := []string{}
+	for first := true; ; first = false {
+		more, err := d.nextElement(first)
+		if err != nil {
+			return nil, fmt.Errorf("error unmarshaling bindSlice from string: %w", err)
+		}
+		if !more {
+			return items, nil
+		}
+
+		item, err := d.anyValue(true)
+		if err != nil {
+			return nil, fmt.Errorf("error unmarshaling bindSlice from string: %w", err)
+		}
+
+		switch item.(type) {
+		case map[string]any, []any:
+			buf, err := appendJSONAny(nil, item)
+			if err != nil {
+				return nil, fmt.Errorf("error marshaling map field to string: %w", err)
+			}
+			items = append(items, string(buf))
+		default:
+			items = append(items, formatGoValue(item))
+		}
+	}
+}
+
+// stringDictProperties is generateMapStrinStringFromValueStr without reflection
+func stringDictProperties(stringValue string) (map[string]string, error) {
+	d := newJSONDecoder([]byte(pythonLiteralReplacer.Replace(stringValue)))
+
+	mapProperties := make(map[string]string)
+	if d.null() {
+		return mapProperties, nil
+	}
+
+	if err := d.openObject("map[string]interface {}"); err != nil {
+		return nil, fmt.Errorf("error unmarshaling struct field from string: %w", err)
+	}
+
+	for first := true; ; first = false {
+		key, ok, err := d.nextKey(first)
+		if err != nil {
+			return nil, fmt.Errorf("error unmarshaling struct field from string: %w", err)
+		}
+		if !ok {
+			return mapProperties, nil
+		}
+
+		value, err := d.anyValue(true)
+		if err != nil {
+			return nil, fmt.Errorf("error unmarshaling struct field from string: %w", err)
+		}
+
+		if _, ok := value.(map[string]any); ok {
+			buf, err := appendJSONAny(nil, value)
+			if err != nil {
+				return nil, fmt.Errorf("error marshaling map field to string: %w", err)
+			}
+			mapProperties[key] = string(buf)
+			continue
+		}
+
+		mapProperties[key] = formatGoValue(value)
+	}
+}
+
+// formatGoValue prints a decoded JSON value like fmt's %v does
+func formatGoValue(v any) string {
+	switch value := v.(type) {
+	case nil:
+		return "<nil>"
+	case string:
+		return value
+	case bool:
+		return strconv.FormatBool(value)
+	case json.Number:
+		return value.String()
+	case float64:
+		return strconv.FormatFloat(value, 'g', -1, 64)
+	case []any:
+		items := make([]string, 0, len(value))
+		for _, item := range value {
+			items = append(items, formatGoValue(item))
+		}
+		return "[" + strings.Join(items, " ") + "]"
+	case map[string]any:
+		items := make([]string, 0, len(value))
+		for _, key := range slices.Sorted(maps.Keys(value)) {
+			items = append(items, key+":"+formatGoValue(value[key]))
+		}
+		return "map[" + strings.Join(items, " ") + "]"
+	default:
+		return fmt.Sprintf("%v", value)
+	}
+}
+
+func parseStringString(valueStr string, v *string) error {
+	*v = valueStr
+	return nil
+}
+
+func parseStringInt(valueStr string, v *int) error {
+	n, err := strconv.Atoi(valueStr)
+	if err != nil {
+		return err
+	}
+	*v = n
+	return nil
+}
+
+func parseStringFloat64(valueStr string, v *float64) error {
+	f, err := strconv.ParseFloat(valueStr, 64)
+	if err != nil {
+		return err
+	}
+	*v = f
+	return nil
+}
+
+func parseStringBool(valueStr string, v *bool) error {
+	b, err := strconv.ParseBool(valueStr)
+	if err != nil {
+		return err
+	}
+	*v = b
+	return nil
+}
+
+func parseStringTime(valueStr string, v *time.Time) error {
+	t, err := time.Parse(time.RFC3339, valueStr)
+	if err != nil {
+		return err
+	}
+	*v = t
+	return nil
+}
+
+func parseStringNonISO8601Time(valueStr string, v *NonISO8601Time) error {
+	return v.Parse(valueStr)
+}
+
+func parseStringUnixTimestamp(valueStr string, v *UnixTimestamp) error {
+	f, err := strconv.ParseFloat(valueStr, 64)
+	if err != nil {
+		return fmt.Errorf("error parsing unix timestamp: %w", err)
+	}
+	*v = newUnixTimestamp(f)
+	return nil
+}
+
+// parseStringAny mirrors the interface case of setFieldValueFromString
+func parseStringAny(valueStr string, v *any) error {
+	jsonStr := pythonLiteralReplacer.Replace(valueStr)
+
+	if stringIntegerPattern.MatchString(jsonStr) {
+		integer, err := strconv.Atoi(jsonStr)
+		if err != nil {
+			return fmt.Errorf("error converting string to integer: %w", err)
+		}
+		*v = integer
+		return nil
+	}
+
+	if stringFloatPattern.MatchString(jsonStr) {
+		floatValue, err := strconv.ParseFloat(jsonStr, 64)
+		if err != nil {
+			return fmt.Errorf("error converting string to float: %w", err)
+		}
+		*v = floatValue
+		return nil
+	}
+
+	if jsonStr == "true" || jsonStr == "false" {
+		*v = jsonStr == "true"
+		return nil
+	}
+
+	if !strings.Contains(jsonStr, "{") && !strings.Contains(jsonStr, "[") {
+		*v = valueStr
+		return nil
+	}
+
+	value, err := newJSONDecoder([]byte(jsonStr)).anyValue(false)
+	if err != nil {
+		return fmt.Errorf("error unmarshaling bindAny from string: %w", err)
+	}
+	*v = value
+
+	return nil
+}
+
+// appendStringInts joins numbers with commas, like the []int case of getStrFieldRepresentation
+func appendStringInts(buf []byte, numbers []int) []byte {
+	for i, number := range numbers {
+		if i > 0 {
+			buf = append(buf, ',')
+		}
+		buf = strconv.AppendInt(buf, int64(number), 10)
+	}
+	return buf
+}
+
+func appendStringUnixTimestamp(buf []byte, t UnixTimestamp) []byte {
+	return strconv.AppendFloat(buf, float64(t.Unix())+float64(t.Nanosecond())/1e9, 'f', -1, 64)
+}
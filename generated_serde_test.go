@@ -0,0 +1,234 @@
+package main
+
+import (
+	"encoding/json"
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// generatedProtocols lists the protocols serdegen generates codecs for
+var generatedProtocols = []string{ProtocolJSON, ProtocolString}
+
+func newCodecSerdes(t testing.TB, protocol string) (reflective Serde, generated Serde) {
+	t.Helper()
+
+	reflective, err := NewCodecSerde(protocol, CodecReflect)
+	require.NoError(t, err)
+
+	generated, err = NewCodecSerde(protocol, CodecGenerated)
+	require.NoError(t, err)
+
+	return reflective, generated
+}
+
+func TestNewCodecSerde(t *testing.T) {
+	tests := []struct {
+		name     string
+		protocol string
+		codec    string
+		expected Serde
+		err      error
+	}{
+		{name: "default codec", protocol: ProtocolJSON, codec: "", expected: &JSONSerde{}},
+		{name: "reflect", protocol: ProtocolString, codec: CodecReflect, expected: &StringSerde{}},
+		{name: "generated json", protocol: ProtocolJSON, codec: CodecGenerated, expected: &GeneratedJSONSerde{}},
+		{name: "generated string", protocol: ProtocolString, codec: CodecGenerated, expected: &GeneratedStringSerde{}},
+		{name: "generated protobuf", protocol: ProtocolProtobuf, codec: CodecGenerated, err: ErrNoGeneratedCodec},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			serde, err := NewCodecSerde(tt.protocol, tt.codec)
+
+			// Assert
+			if tt.err != nil {
+				assert.ErrorIs(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, serde)
+		})
+	}
+
+	_, err := NewCodecSerde(ProtocolJSON, "unknown")
+	assert.Error(t, err, "unknown codecs should be rejected")
+}
+
+func TestGeneratedCodecsCoverDomainTypes(t *testing.T) {
+	r := newConformanceRand(t)
+
+	for _, tc := range conformanceRequests {
+		body := tc.generate(r).Body
+
+		_, ok := appendStringRequestBody(nil, body)
+		assert.True(t, ok, "%T should have a generated string encoder", body)
+
+		_, ok, err := appendJSONRequestBody(nil, body)
+		assert.NoError(t, err)
+		assert.True(t, ok, "%T should have a generated JSON encoder", body)
+	}
+
+	for _, tc := range conformanceResponses {
+		_, ok := generatedResponseTarget(&PresentationLayerResponse[OperationResponse]{Body: tc.newBody()})
+		assert.True(t, ok, "%T should have generated decoders", tc.newBody())
+	}
+}
+
+func TestGeneratedMarshalMatchesReflect(t *testing.T) {
+	for _, protocol := range generatedProtocols {
+		for _, tc := range conformanceRequests {
+			t.Run(protocol+"/"+tc.name, func(t *testing.T) {
+				reflective, generated := newCodecSerdes(t, protocol)
+				r := newConformanceRand(t)
+
+				for range conformanceIterations {
+					// Arrange
+					req := tc.generate(r)
+
+					// Act
+					expected, expectedErr := reflective.Marshal(req)
+					actual, actualErr := generated.Marshal(req)
+
+					// Assert
+					require.Equal(t, expectedErr, actualErr)
+					require.Equal(t, string(expected), string(actual), "generated encoder should write the reflective bytes")
+				}
+			})
+		}
+	}
+}
+
+func TestGeneratedUnmarshalMatchesReflect(t *testing.T) {
+	for _, protocol := range generatedProtocols {
+		reflective, generated := newCodecSerdes(t, protocol)
+
+		for _, data := range fuzzSeeds(t, protocol) {
+			for _, newBody := range fuzzResponseBodies {
+				// Arrange
+				expected := PresentationLayerResponse[OperationResponse]{Body: newBody()}
+				actual := PresentationLayerResponse[OperationResponse]{Body: newBody()}
+
+				// Act
+				expectedErr := reflective.Unmarshal(data, &expected)
+				actualErr := generated.Unmarshal(data, &actual)
+
+				// Assert
+				require.Equal(t, expectedErr == nil, actualErr == nil, "both codecs should accept %q as %T: %v, %v", data, expected.Body, expectedErr, actualErr)
+				if expectedErr == nil {
+					require.Equal(t, expected, actual, "both codecs should decode %q as %T alike", data, expected.Body)
+				}
+			}
+		}
+	}
+}
+
+func TestGeneratedUnmarshalFallsBackToReflect(t *testing.T) {
+	for _, fixture := range appLayerFlowFixtures(t) {
+		if !slices.Contains(generatedProtocols, fixture.protocol) {
+			continue
+		}
+
+		t.Run(fixture.protocol, func(t *testing.T) {
+			// Arrange
+			_, generated := newCodecSerdes(t, fixture.protocol)
+			var target struct {
+				Body       *EchoResponse
+				StatusCode int
+				Err        *PresentationLayerErrorResponse
+			}
+			target.Body = &EchoResponse{}
+
+			// Act
+			err := generated.Unmarshal(fixture.echoReply, &target)
+
+			// Assert
+			require.NoError(t, err, "targets without generated codecs should use the reflective path")
+			assert.NotEmpty(t, target.Body.EchoMessage)
+		})
+	}
+}
+
+func TestAppendJSONStringMatchesEncodingJSON(t *testing.T) {
+	tests := []string{
+		"",
+		"plain",
+		"quote \" and backslash \\",
+		"control \x00\x01\x1f\t\n\r\b\f",
+		"<html> & 'quotes'",
+		"separators \u2028 \u2029",
+		"acentuação 日本語 🎉",
+		"invalid \xff\xfe utf8 \xc3",
+		"\x7f delete",
+	}
+
+	for _, tt := range tests {
+		t.Run(tt, func(t *testing.T) {
+			// Arrange
+			expected, err := json.Marshal(tt)
+			require.NoError(t, err)
+
+			// Act
+			actual := appendJSONString(nil, tt)
+
+			// Assert
+			assert.Equal(t, string(expected), string(actual))
+		})
+	}
+}
+
+func TestAppendJSONFloat64MatchesEncodingJSON(t *testing.T) {
+	tests := []float64{0, 1, -1, 0.1, 2.5, 100, 1e20, 1e21, 1.5e21, 1e-6, 1e-7, 1.234e-9, math.MaxFloat64, math.SmallestNonzeroFloat64, -0.000001234}
+
+	for _, tt := range tests {
+		// Arrange
+		expected, err := json.Marshal(tt)
+		require.NoError(t, err)
+
+		// Act
+		actual, err := appendJSONFloat64(nil, tt)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, string(expected), string(actual))
+	}
+
+	_, err := appendJSONFloat64(nil, math.NaN())
+	assert.Error(t, err, "NaN has no JSON representation")
+}
+
+// fuzzGeneratedParity checks the generated codec accepts and decodes the inputs the reflective one does
+func fuzzGeneratedParity(f *testing.F, protocol string) {
+	reflective, generated := newCodecSerdes(f, protocol)
+
+	for _, seed := range fuzzSeeds(f, protocol) {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		for _, newBody := range fuzzResponseBodies {
+			expected := PresentationLayerResponse[OperationResponse]{Body: newBody()}
+			actual := PresentationLayerResponse[OperationResponse]{Body: newBody()}
+
+			expectedErr := reflective.Unmarshal(data, &expected)
+			actualErr := generated.Unmarshal(data, &actual)
+
+			require.Equal(t, expectedErr == nil, actualErr == nil, "both codecs should accept %q as %T: %v, %v", data, expected.Body, expectedErr, actualErr)
+			if expectedErr == nil {
+				require.Equal(t, expected, actual, "both codecs should decode %q as %T alike", data, expected.Body)
+			}
+		}
+	})
+}
+
+func FuzzGeneratedStringParity(f *testing.F) {
+	fuzzGeneratedParity(f, ProtocolString)
+}
+
+func FuzzGeneratedJSONParity(f *testing.F) {
+	fuzzGeneratedParity(f, ProtocolJSON)
+}
@@ -0,0 +1,793 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// jsonMaxDepth matches the nesting limit of encoding/json
+const jsonMaxDepth = 10000
+
+// jsonDecoder is the reflection-free JSON reader of the generated codecs. It follows
+// encoding/json semantics for the values the domain types hold: null leaves values
+// untouched, unknown keys are skipped and keys match case-insensitively.
+type jsonDecoder struct {
+	data  []byte
+	pos   int
+	depth int
+}
+
+func newJSONDecoder(data []byte) *jsonDecoder {
+	return &jsonDecoder{data: data}
+}
+
+func (d *jsonDecoder) syntaxError(msg string) error {
+	return fmt.Errorf("invalid JSON at offset %d: %s", d.pos, msg)
+}
+
+func (d *jsonDecoder) typeError(value, goType string) error {
+	return fmt.Errorf("json: cannot unmarshal %s into Go value of type %s", value, goType)
+}
+
+func (d *jsonDecoder) skipSpace() {
+	for d.pos < len(d.data) {
+		switch d.data[d.pos] {
+		case ' ', '\t', '\n', '\r':
+			d.pos++
+		default:
+			return
+		}
+	}
+}
+
+// peek returns the next non space byte, 0 at the end of the input
+func (d *jsonDecoder) peek() byte {
+	d.skipSpace()
+	if d.pos >= len(d.data) {
+		return 0
+	}
+	return d.data[d.pos]
+}
+
+// end fails when anything but space follows the top level value
+func (d *jsonDecoder) end() error {
+	if d.peek() != 0 {
+		return d.syntaxError("invalid character after top-level value")
+	}
+	return nil
+}
+
+func (d *jsonDecoder) literal(word string) bool {
+	if d.peek() == word[0] && bytes.HasPrefix(d.data[d.pos:], []byte(word)) {
+		d.pos += len(word)
+		return true
+	}
+	return false
+}
+
+// null consumes a null literal
+func (d *jsonDecoder) null() bool {
+	return d.literal("null")
+}
+
+// kind names the next value the way encoding/json type errors do
+func (d *jsonDecoder) kind() string {
+	switch d.peek() {
+	case '{':
+		return "object"
+	case '[':
+		return "array"
+	case '"':
+		return "string"
+	case 't', 'f':
+		return "bool"
+	default:
+		return "number"
+	}
+}
+
+// openObject consumes the opening brace of an object
+func (d *jsonDecoder) openObject(goType string) error {
+	if d.peek() != '{' {
+		kind := d.kind()
+		if err := d.skipValue(); err != nil {
+			return err
+		}
+		return d.typeError(kind, goType)
+	}
+	d.pos++
+	return nil
+}
+
+// openArray consumes the opening bracket of an array
+func (d *jsonDecoder) openArray(goType string) error {
+	if d.peek() != '[' {
+		kind := d.kind()
+		if err := d.skipValue(); err != nil {
+			return err
+		}
+		return d.typeError(kind, goType)
+	}
+	d.pos++
+	return nil
+}
+
+// nextKey returns the next key of the object, ok is false once the object is closed
+func (d *jsonDecoder) nextKey(first bool) (string, bool, error) {
+	c := d.peek()
+	if c == '}' {
+		d.pos++
+		return "", false, nil
+	}
+
+	if !first {
+		if c != ',' {
+			return "", false, d.syntaxError("expected ',' or '}' after object value")
+		}
+		d.pos++
+	}
+
+	if d.peek() != '"' {
+		return "", false, d.syntaxError("expected string as object key")
+	}
+
+	key, err := d.str()
+	if err != nil {
+		return "", false, err
+	}
+
+	if d.peek() != ':' {
+		return "", false, d.syntaxError("expected ':' after object key")
+	}
+	d.pos++
+
+	return key, true, nil
+}
+
+// nextElement reports whether another array element follows, consuming the separator
+func (d *jsonDecoder) nextElement(first bool) (bool, error) {
+	c := d.peek()
+	if c == ']' {
+		d.pos++
+		return false, nil
+	}
+
+	if !first {
+		if c != ',' {
+			return false, d.syntaxError("expected ',' or ']' after array element")
+		}
+		d.pos++
+	}
+
+	return true, nil
+}
+
+// str reads a string, escapes are decoded and invalid UTF-8 is replaced like encoding/json does
+func (d *jsonDecoder) str() (string, error) {
+	if d.peek() != '"' {
+		return "", d.syntaxError("expected string")
+	}
+	d.pos++
+
+	start := d.pos
+	for d.pos < len(d.data) {
+		c := d.data[d.pos]
+		switch {
+		case c == '"':
+			s := string(d.data[start:d.pos])
+			d.pos++
+			return s, nil
+		case c == '\\' || c >= utf8.RuneSelf:
+			return d.unquote(start)
+		case c < ' ':
+			return "", d.syntaxError("invalid character in string literal")
+		}
+		d.pos++
+	}
+
+	return "", d.syntaxError("unexpected end of JSON input")
+}
+
+func (d *jsonDecoder) unquote(start int) (string, error) {
+	var sb strings.Builder
+	sb.Write(d.data[start:d.pos])
+
+	for d.pos < len(d.data) {
+		c := d.data[d.pos]
+		switch {
+		case c == '"':
+			d.pos++
+			return sb.String(), nil
+		case c < ' ':
+			return "", d.syntaxError("invalid character in string literal")
+		case c >= utf8.RuneSelf:
+			r, size := utf8.DecodeRune(d.data[d.pos:])
+			sb.WriteRune(r)
+			d.pos += size
+		case c != '\\':
+			sb.WriteByte(c)
+			d.pos++
+		default:
+			d.pos++
+			if d.pos >= len(d.data) {
+				return "", d.syntaxError("unexpected end of JSON input")
+			}
+
+			escape := d.data[d.pos]
+			d.pos++
+			switch escape {
+			case '"', '\\', '/':
+				sb.WriteByte(escape)
+			case 'b':
+				sb.WriteByte('\b')
+			case 'f':
+				sb.WriteByte('\f')
+			case 'n':
+				sb.WriteByte('\n')
+			case 'r':
+				sb.WriteByte('\r')
+			case 't':
+				sb.WriteByte('\t')
+			case 'u':
+				r, ok := d.hex4()
+				if !ok {
+					return "", d.syntaxError("invalid unicode escape")
+				}
+
+				if utf16.IsSurrogate(r) {
+					// An invalid pair writes a replacement and leaves the next escape to be read on its own
+					pos := d.pos
+					if low, ok := d.lowSurrogate(); ok {
+						if pair := utf16.DecodeRune(r, low); pair != utf8.RuneError {
+							sb.WriteRune(pair)
+							continue
+						}
+					}
+					d.pos = pos
+					r = utf8.RuneError
+				}
+				sb.WriteRune(r)
+			default:
+				return "", d.syntaxError("invalid escape in string literal")
+			}
+		}
+	}
+
+	return "", d.syntaxError("unexpected end of JSON input")
+}
+
+func (d *jsonDecoder) hex4() (rune, bool) {
+	if d.pos+4 > len(d.data) {
+		return 0, false
+	}
+
+	n, err := strconv.ParseUint(string(d.data[d.pos:d.pos+4]), 16, 32)
+	if err != nil {
+		return 0, false
+	}
+	d.pos += 4
+
+	return rune(n), true
+}
+
+// lowSurrogate consumes a \uXXXX escape following a high surrogate
+func (d *jsonDecoder) lowSurrogate() (rune, bool) {
+	if !bytes.HasPrefix(d.data[d.pos:], []byte(`\u`)) {
+		return 0, false
+	}
+
+	d.pos += 2
+
+	return d.hex4()
+}
+
+// number reads a number literal
+func (d *jsonDecoder) number() (string, error) {
+	d.skipSpace()
+	start := d.pos
+
+	if d.pos < len(d.data) && d.data[d.pos] == '-' {
+		d.pos++
+	}
+
+	switch {
+	case d.pos < len(d.data) && d.data[d.pos] == '0':
+		d.pos++
+	case d.pos < len(d.data) && d.data[d.pos] >= '1' && d.data[d.pos] <= '9':
+		d.digits()
+	default:
+		return "", d.syntaxError("invalid number literal")
+	}
+
+	if d.pos < len(d.data) && d.data[d.pos] == '.' {
+		d.pos++
+		if d.digits() == 0 {
+			return "", d.syntaxError("invalid number literal")
+		}
+	}
+
+	if d.pos < len(d.data) && (d.data[d.pos] == 'e' || d.data[d.pos] == 'E') {
+		d.pos++
+		if d.pos < len(d.data) && (d.data[d.pos] == '+' || d.data[d.pos] == '-') {
+			d.pos++
+		}
+		if d.digits() == 0 {
+			return "", d.syntaxError("invalid number literal")
+		}
+	}
+
+	return string(d.data[start:d.pos]), nil
+}
+
+func (d *jsonDecoder) digits() int {
+	start := d.pos
+	for d.pos < len(d.data) && d.data[d.pos] >= '0' && d.data[d.pos] <= '9' {
+		d.pos++
+	}
+	return d.pos - start
+}
+
+func (d *jsonDecoder) enter() error {
+	d.depth++
+	if d.depth > jsonMaxDepth {
+		return d.syntaxError("exceeded max depth")
+	}
+	return nil
+}
+
+// skipValue consumes and validates the next value
+func (d *jsonDecoder) skipValue() error {
+	switch d.peek() {
+	case '{':
+		if err := d.enter(); err != nil {
+			return err
+		}
+		d.pos++
+		for first := true; ; first = false {
+			_, ok, err := d.nextKey(first)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				break
+			}
+			if err := d.skipValue(); err != nil {
+				return err
+			}
+		}
+		d.depth--
+		return nil
+	case '[':
+		if err := d.enter(); err != nil {
+			return err
+		}
+		d.pos++
+		for first := true; ; first = false {
+			more, err := d.nextElement(first)
+			if err != nil {
+				return err
+			}
+			if !more {
+				break
+			}
+			if err := d.skipValue(); err != nil {
+				return err
+			}
+		}
+		d.depth--
+		return nil
+	case '"':
+		_, err := d.str()
+		return err
+	case 't':
+		return d.word("true")
+	case 'f':
+		return d.word("false")
+	case 'n':
+		return d.word("null")
+	default:
+		_, err := d.number()
+		return err
+	}
+}
+
+func (d *jsonDecoder) word(word string) error {
+	if !d.literal(word) {
+		return d.syntaxError("invalid literal")
+	}
+	return nil
+}
+
+// anyValue reads the next value as encoding/json decodes into an interface,
+// numbers are kept as json.Number when useNumber is set
+func (d *jsonDecoder) anyValue(useNumber bool) (any, error) {
+	switch d.peek() {
+	case '{':
+		if err := d.enter(); err != nil {
+			return nil, err
+		}
+		d.pos++
+		object := map[string]any{}
+		for first := true; ; first = false {
+			key, ok, err := d.nextKey(first)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				break
+			}
+			if object[key], err = d.anyValue(useNumber); err != nil {
+				return nil, err
+			}
+		}
+		d.depth--
+		return object, nil
+	case '[':
+		if err := d.enter(); err != nil {
+			return nil, err
+		}
+		d.pos++
+		array := []any{}
+		for first := true; ; first = false {
+			more, err := d.nextElement(first)
+			if err != nil {
+				return nil, err
+			}
+			if !more {
+				break
+			}
+			item, err := d.anyValue(useNumber)
+			if err != nil {
+				return nil, err
+			}
+			array = append(array, item)
+		}
+		d.depth--
+		return array, nil
+	case '"':
+		return d.str()
+	case 't':
+		return true, d.word("true")
+	case 'f':
+		return false, d.word("false")
+	case 'n':
+		return nil, d.word("null")
+	default:
+		number, err := d.number()
+		if err != nil {
+			return nil, err
+		}
+		if useNumber {
+			return json.Number(number), nil
+		}
+		f, err := strconv.ParseFloat(number, 64)
+		if err != nil {
+			return nil, d.typeError("number "+number, "float64")
+		}
+		return f, nil
+	}
+}
+
+// validJSON checks data holds exactly one value, encoding/json validates before decoding anything
+func validJSON(data []byte) error {
+	d := newJSONDecoder(data)
+	if err := d.skipValue(); err != nil {
+		return err
+	}
+	return d.end()
+}
+
+// jsonKeyIs matches object keys against field names like encoding/json does
+func jsonKeyIs(key, name string) bool {
+	return key == name || strings.EqualFold(key, name)
+}
+
+func decodeJSONString(d *jsonDecoder, v *string) error {
+	if d.null() {
+		return nil
+	}
+	if d.peek() != '"' {
+		kind := d.kind()
+		if err := d.skipValue(); err != nil {
+			return err
+		}
+		return d.typeError(kind, "string")
+	}
+
+	s, err := d.str()
+	if err != nil {
+		return err
+	}
+	*v = s
+
+	return nil
+}
+
+func decodeJSONInt(d *jsonDecoder, v *int) error {
+	if d.null() {
+		return nil
+	}
+	if kind := d.kind(); kind != "number" {
+		if err := d.skipValue(); err != nil {
+			return err
+		}
+		return d.typeError(kind, "int")
+	}
+
+	number, err := d.number()
+	if err != nil {
+		return err
+	}
+
+	n, err := strconv.ParseInt(number, 10, strconv.IntSize)
+	if err != nil {
+		return d.typeError("number "+number, "int")
+	}
+	*v = int(n)
+
+	return nil
+}
+
+func decodeJSONFloat64(d *jsonDecoder, v *float64) error {
+	if d.null() {
+		return nil
+	}
+	if kind := d.kind(); kind != "number" {
+		if err := d.skipValue(); err != nil {
+			return err
+		}
+		return d.typeError(kind, "float64")
+	}
+
+	number, err := d.number()
+	if err != nil {
+		return err
+	}
+
+	f, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		return d.typeError("number "+number, "float64")
+	}
+	*v = f
+
+	return nil
+}
+
+func decodeJSONBool(d *jsonDecoder, v *bool) error {
+	switch {
+	case d.null():
+		return nil
+	case d.literal("true"):
+		*v = true
+		return nil
+	case d.literal("false"):
+		*v = false
+		return nil
+	}
+
+	kind := d.kind()
+	if err := d.skipValue(); err != nil {
+		return err
+	}
+	return d.typeError(kind, "bool")
+}
+
+func decodeJSONAny(d *jsonDecoder, v *any) error {
+	value, err := d.anyValue(false)
+	if err != nil {
+		return err
+	}
+	*v = value
+
+	return nil
+}
+
+func decodeJSONTime(d *jsonDecoder, v *time.Time) error {
+	start := d.pos
+	if err := d.skipValue(); err != nil {
+		return err
+	}
+
+	return v.UnmarshalJSON(bytes.TrimSpace(d.data[start:d.pos]))
+}
+
+// decodeJSONNonISO8601Time mirrors NonISO8601Time.UnmarshalJSON, null parses as an empty string and fails
+func decodeJSONNonISO8601Time(d *jsonDecoder, v *NonISO8601Time) error {
+	var s string
+	if err := decodeJSONString(d, &s); err != nil {
+		return err
+	}
+
+	return v.Parse(s)
+}
+
+// decodeJSONUnixTimestamp mirrors UnixTimestamp.UnmarshalJSON, null becomes the epoch
+func decodeJSONUnixTimestamp(d *jsonDecoder, v *UnixTimestamp) error {
+	var f float64
+	if err := decodeJSONFloat64(d, &f); err != nil {
+		return err
+	}
+	*v = newUnixTimestamp(f)
+
+	return nil
+}
+
+const jsonHex = "0123456789abcdef"
+
+// jsonSafe marks the ASCII bytes appendJSONString copies without escaping
+var jsonSafe = func() (safe [utf8.RuneSelf]bool) {
+	for c := ' '; c < utf8.RuneSelf; c++ {
+		safe[c] = c != '"' && c != '\\' && c != '<' && c != '>' && c != '&'
+	}
+	return safe
+}()
+
+// appendJSONString appends s quoted the way encoding/json does, HTML characters included
+func appendJSONString(buf []byte, s string) []byte {
+	buf = append(buf, '"')
+
+	start := 0
+	for i := 0; i < len(s); {
+		if c := s[i]; c < utf8.RuneSelf {
+			if jsonSafe[c] {
+				i++
+				continue
+			}
+
+			buf = append(buf, s[start:i]...)
+			switch c {
+			case '\\', '"':
+				buf = append(buf, '\\', c)
+			case '\b':
+				buf = append(buf, '\\', 'b')
+			case '\f':
+				buf = append(buf, '\\', 'f')
+			case '\n':
+				buf = append(buf, '\\', 'n')
+			case '\r':
+				buf = append(buf, '\\', 'r')
+			case '\t':
+				buf = append(buf, '\\', 't')
+			default:
+				buf = append(buf, '\\', 'u', '0', '0', jsonHex[c>>4], jsonHex[c&0xF])
+			}
+			i++
+			start = i
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			buf = append(buf, s[start:i]...)
+			buf = utf8.AppendRune(buf, utf8.RuneError)
+			i += size
+			start = i
+			continue
+		}
+
+		if r == '\u2028' || r == '\u2029' {
+			buf = append(buf, s[start:i]...)
+			buf = append(buf, '\\', 'u', '2', '0', '2', jsonHex[r&0xF])
+			i += size
+			start = i
+			continue
+		}
+
+		i += size
+	}
+
+	buf = append(buf, s[start:]...)
+
+	return append(buf, '"')
+}
+
+// appendJSONFloat64 formats f like encoding/json, exponents only for very large or small values
+func appendJSONFloat64(buf []byte, f float64) ([]byte, error) {
+	if math.IsInf(f, 0) || math.IsNaN(f) {
+		return nil, fmt.Errorf("json: unsupported value: %s", strconv.FormatFloat(f, 'g', -1, 64))
+	}
+
+	abs := math.Abs(f)
+	format := byte('f')
+	if abs != 0 && (abs < 1e-6 || abs >= 1e21) {
+		format = 'e'
+	}
+
+	buf = strconv.AppendFloat(buf, f, format, -1, 64)
+	if format == 'e' {
+		// Clean up e-09 to e-9
+		n := len(buf)
+		if n >= 4 && buf[n-4] == 'e' && buf[n-3] == '-' && buf[n-2] == '0' {
+			buf[n-2] = buf[n-1]
+			buf = buf[:n-1]
+		}
+	}
+
+	return buf, nil
+}
+
+func appendJSONTime(buf []byte, t time.Time) ([]byte, error) {
+	data, err := t.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	return append(buf, data...), nil
+}
+
+func appendJSONNonISO8601Time(buf []byte, t NonISO8601Time) ([]byte, error) {
+	return appendJSONString(buf, t.Format(nonISO8601Layout)), nil
+}
+
+func appendJSONUnixTimestamp(buf []byte, t UnixTimestamp) ([]byte, error) {
+	return appendJSONFloat64(buf, float64(t.Unix())+float64(t.Nanosecond())/1e9)
+}
+
+// appendJSONAny encodes the values anyValue produces, anything else goes through encoding/json
+func appendJSONAny(buf []byte, v any) ([]byte, error) {
+	switch value := v.(type) {
+	case nil:
+		return append(buf, "null"...), nil
+	case string:
+		return appendJSONString(buf, value), nil
+	case bool:
+		return strconv.AppendBool(buf, value), nil
+	case int:
+		return strconv.AppendInt(buf, int64(value), 10), nil
+	case float64:
+		return appendJSONFloat64(buf, value)
+	case json.Number:
+		if value == "" {
+			return append(buf, '0'), nil
+		}
+		return append(buf, value...), nil
+	case []any:
+		if value == nil {
+			return append(buf, "null"...), nil
+		}
+
+		var err error
+		buf = append(buf, '[')
+		for i, item := range value {
+			if i > 0 {
+				buf = append(buf, ',')
+			}
+			if buf, err = appendJSONAny(buf, item); err != nil {
+				return nil, err
+			}
+		}
+		return append(buf, ']'), nil
+	case map[string]any:
+		if value == nil {
+			return append(buf, "null"...), nil
+		}
+
+		var err error
+		buf = append(buf, '{')
+		for i, key := range slices.Sorted(maps.Keys(value)) {
+			if i > 0 {
+				buf = append(buf, ',')
+			}
+			buf = appendJSONString(buf, key)
+			buf = append(buf, ':')
+			if buf, err = appendJSONAny(buf, value[key]); err != nil {
+				return nil, err
+			}
+		}
+		return append(buf, '}'), nil
+	default:
+		data, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		return append(buf, data...), nil
+	}
+}
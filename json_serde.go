@@ -1,8 +1,11 @@
 package main
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"reflect"
 )
@@ -20,7 +23,17 @@ type jsonRequestWrapper struct {
 }
 
 type studenData struct {
-	Name string `json:"nome"`
+	Name       string `json:"nome"`
+	Enrollment string `json:"matricula,omitempty"`
+}
+
+// jsonRequestEnvelope is jsonRequestWrapper as the server reads it, parameters are bound once the operation is known
+type jsonRequestEnvelope struct {
+	Kind      string          `json:"tipo"`
+	Operation string          `json:"operacao"`
+	Token     string          `json:"token"`
+	Params    json.RawMessage `json:"parametros"`
+	StudentID string          `json:"aluno_id"`
 }
 
 type jsonResponseWrapper struct {
@@ -34,20 +47,30 @@ type jsonResponseWrapper struct {
 
 // Marshal implements Serde.
 func (j JSONSerde) Marshal(v any) ([]byte, error) {
+	request, err := newJSONRequestWrapper(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(request)
+}
+
+// newJSONRequestWrapper builds the envelope of a request, shared by the JSON and MessagePack serdes
+func newJSONRequestWrapper(v any) (jsonRequestWrapper, error) {
 	typ := reflect.TypeOf(v)
 	value := reflect.ValueOf(v)
 
 	if typ.Kind() != reflect.Struct && typ.Kind() != reflect.Pointer {
-		return nil, fmt.Errorf("pointers or structs are supported, found %s", typ.Kind().String())
+		return jsonRequestWrapper{}, fmt.Errorf("pointers or structs are supported, found %s", typ.Kind().String())
 	}
 
 	if typ.Kind() == reflect.Pointer && value.IsNil() {
-		return nil, fmt.Errorf("nil pointer provided")
+		return jsonRequestWrapper{}, fmt.Errorf("nil pointer provided")
 	}
 
 	r, ok := v.(PresentationLayerRequest)
 	if !ok {
-		return nil, fmt.Errorf("only presentation layer requests are supported, found %s", typ.Kind().String())
+		return jsonRequestWrapper{}, fmt.Errorf("only presentation layer requests are supported, found %s", typ.Kind().String())
 	}
 
 	request := jsonRequestWrapper{}
@@ -66,50 +89,37 @@ func (j JSONSerde) Marshal(v any) ([]byte, error) {
 		request.Token = r.Token
 	}
 
-	return json.Marshal(request)
+	return request, nil
 }
 
 // Unmarshal implements Serde.
 func (j JSONSerde) Unmarshal(data []byte, v any) error {
-	typ := reflect.TypeOf(v)
-	value := reflect.ValueOf(v)
-
-	if typ.Kind() != reflect.Pointer {
-		return fmt.Errorf("pointers are supported, found %s", typ.Kind().String())
-	}
-
-	if value.IsNil() {
-		return fmt.Errorf("nil pointer provided")
-	}
-
-	value = value.Elem()
-
-	bodyField := value.FieldByName("Body")
-
-	if bodyField.Kind() != reflect.Pointer && bodyField.Kind() != reflect.Interface {
-		return fmt.Errorf("body field is not a pointer or interface: %v", bodyField.Kind())
-	}
-
-	if bodyField.Kind() == reflect.Interface {
-		bodyField = bodyField.Elem()
+	value, err := responseValue(v)
+	if err != nil {
+		return err
 	}
 
-	if bodyField.IsNil() {
-		bodyField.Set(reflect.New(bodyField.Type().Elem()))
+	bodyElem, err := responseBody(value)
+	if err != nil {
+		return err
 	}
 
-	// Get the actual body type to unmarshal the result into
-	bodyElem := bodyField.Elem()
-
 	responseWrapper := jsonResponseWrapper{
 		Result: bodyElem.Addr().Interface(),
 	}
 
-	err := json.Unmarshal(data, &responseWrapper)
+	err = json.Unmarshal(data, &responseWrapper)
 	if err != nil {
 		return err
 	}
 
+	bindJSONResponseWrapper(value, bodyElem, responseWrapper)
+
+	return nil
+}
+
+// bindJSONResponseWrapper sets the status, error and envelope fields of a decoded response
+func bindJSONResponseWrapper(value reflect.Value, bodyElem reflect.Value, responseWrapper jsonResponseWrapper) {
 	if !responseWrapper.Success {
 		err := PresentationLayerErrorResponse{
 			Code:    http.StatusText(http.StatusInternalServerError),
@@ -121,6 +131,8 @@ func (j JSONSerde) Unmarshal(data []byte, v any) error {
 
 		errField := value.FieldByName("Err")
 		errField.Set(reflect.ValueOf(&err))
+
+		return
 	}
 
 	statusField := value.FieldByName("StatusCode")
@@ -129,7 +141,7 @@ func (j JSONSerde) Unmarshal(data []byte, v any) error {
 	value.FieldByName("Err").Set(reflect.Zero(value.FieldByName("Err").Type()))
 
 	timestampField := bodyElem.FieldByName("Timestamp")
-	if timestampField.IsValid() && timestampField.CanSet() {
+	if timestampField.IsValid() && timestampField.CanSet() && timestampField.Type() == reflect.TypeOf(responseWrapper.Timestamp) {
 		timestampField.Set(reflect.ValueOf(responseWrapper.Timestamp))
 	}
 
@@ -137,10 +149,119 @@ func (j JSONSerde) Unmarshal(data []byte, v any) error {
 	switch bodyFieldType.Name() {
 	case "AuthResponse":
 		bodyElem.FieldByName("Token").SetString(responseWrapper.Token)
-		bodyElem.FieldByName("Name").Set(reflect.ValueOf(responseWrapper.StudentData.Name))
+		// Some servers leave dados_aluno out of the reply
+		if responseWrapper.StudentData != nil {
+			bodyElem.FieldByName("Name").SetString(responseWrapper.StudentData.Name)
+			bodyElem.FieldByName("Enrollment").SetString(responseWrapper.StudentData.Enrollment)
+		}
 	case "LogoutResponse":
 		bodyElem.FieldByName("Message").SetString(responseWrapper.Message)
 	}
+}
+
+// AccountRequest implements WireAccountant.
+func (j JSONSerde) AccountRequest(data []byte) (WireBreakdown, error) {
+	return accountJSONDocument(data, wireControlFields)
+}
+
+// ReplyLength implements ReplyFramer, replies are a single JSON document.
+func (j JSONSerde) ReplyLength(data []byte) (int, bool) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+
+	var document json.RawMessage
+	if err := dec.Decode(&document); err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return 0, false
+		}
+		// Malformed replies are left to Unmarshal to report
+		return len(data), true
+	}
+
+	return int(dec.InputOffset()), true
+}
+
+// AccountResponse implements WireAccountant.
+func (j JSONSerde) AccountResponse(data []byte) (WireBreakdown, error) {
+	return accountJSONDocument(data, wireControlFields)
+}
+
+// UnmarshalRequest implements ServerSerde.
+func (j JSONSerde) UnmarshalRequest(data []byte, req *PresentationLayerRequest) error {
+	if req == nil {
+		return fmt.Errorf("nil pointer provided")
+	}
+
+	var envelope jsonRequestEnvelope
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return err
+	}
+
+	body, err := newEnvelopeRequestBody(envelope.Kind, envelope.Operation, envelope.StudentID, func(body reflect.Value) error {
+		if len(envelope.Params) == 0 {
+			return nil
+		}
+		return json.Unmarshal(envelope.Params, body.Addr().Interface())
+	})
+	if err != nil {
+		return err
+	}
+
+	req.Token = envelope.Token
+	req.Body = body
 
 	return nil
 }
+
+// newEnvelopeRequestBody returns the request of a JSON or MessagePack envelope, bind decodes the operation parameters
+func newEnvelopeRequestBody(kind, operation, studentID string, bind func(body reflect.Value) error) (OperationRequest, error) {
+	switch kind {
+	case "autenticar":
+		return AuthRequest{StudentID: studentID}, nil
+	case "logout":
+		return LogoutRequest{}, nil
+	case "operacao":
+		return decodeRequestBody(operation, bind)
+	default:
+		return nil, fmt.Errorf("unexpected request kind %s", kind)
+	}
+}
+
+// MarshalResponse implements ServerSerde.
+func (j JSONSerde) MarshalResponse(v any) ([]byte, error) {
+	wrapper, err := newJSONResponseWrapper(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(wrapper)
+}
+
+// newJSONResponseWrapper builds the envelope of a response, shared by the JSON and MessagePack serdes
+func newJSONResponseWrapper(v any) (jsonResponseWrapper, error) {
+	resp, err := newServerResponse(v)
+	if err != nil {
+		return jsonResponseWrapper{}, err
+	}
+
+	wrapper := jsonResponseWrapper{
+		Success:   !resp.failed(),
+		Timestamp: resp.timestamp(),
+	}
+
+	if resp.failed() {
+		wrapper.Message = resp.message()
+		return wrapper, nil
+	}
+
+	switch body := resp.body.Interface().(type) {
+	case AuthResponse:
+		wrapper.Token = body.Token
+		wrapper.StudentData = &studenData{Name: body.Name, Enrollment: body.Enrollment}
+	case LogoutResponse:
+		wrapper.Message = body.Message
+	default:
+		wrapper.Result = resp.body.Interface()
+	}
+
+	return wrapper, nil
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"encoding/json"
 	"net/http"
 	"testing"
 	"time"
@@ -488,3 +489,19 @@ func TestJSONDeserialization(t *testing.T) {
 		})
 	}
 }
+
+func TestNonISO8601TimeJSON(t *testing.T) {
+	// Arrange
+	timestamp := NonISO8601Time{time.Date(2025, 11, 1, 13, 18, 4, 381480000, time.UTC)}
+
+	// Act
+	data, marshalErr := json.Marshal(timestamp)
+	var decoded NonISO8601Time
+	unmarshalErr := json.Unmarshal(data, &decoded)
+
+	// Assert
+	require.NoError(t, marshalErr)
+	require.NoError(t, unmarshalErr)
+	assert.Equal(t, `"2025-11-01T13:18:04.381480"`, string(data), "the layout should be the one of the reference server")
+	assert.Equal(t, timestamp, decoded)
+}
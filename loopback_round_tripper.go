@@ -0,0 +1,291 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+)
+
+// TransportLoopback runs the protocol servers in process, requests never leave the benchmark
+const TransportLoopback = "loopback"
+
+// loopbackScheme prefixes the addresses of loopback handlers, loopback://json is the handler named json
+const loopbackScheme = "loopback://"
+
+// LoopbackAddress returns the address of the loopback handler named name
+func LoopbackAddress(name string) string {
+	return loopbackScheme + name
+}
+
+// LoopbackHandler answers the raw bytes of a request with the raw bytes of its reply
+type LoopbackHandler func(ctx context.Context, req []byte) ([]byte, error)
+
+var _ RoundTripper = (*LoopbackRoundTripper)(nil)
+
+// LoopbackRoundTripper hands requests to the handler registered for their address, without sockets.
+// Replies are returned as the handler wrote them.
+type LoopbackRoundTripper struct {
+	mu       sync.RWMutex
+	handlers map[string]LoopbackHandler
+}
+
+func NewLoopbackRoundTripper() *LoopbackRoundTripper {
+	return &LoopbackRoundTripper{handlers: map[string]LoopbackHandler{}}
+}
+
+// Handle registers the handler of address, replacing the previous one
+func (t *LoopbackRoundTripper) Handle(address string, handler LoopbackHandler) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.handlers[address] = handler
+}
+
+// RequestReply implements RoundTripper.
+func (t *LoopbackRoundTripper) RequestReply(ctx context.Context, address string, req []byte) ([]byte, error) {
+	ctx, span := tracer.Start(ctx, "LoopbackRoundTripper.RequestReply")
+	defer span.End()
+
+	t.mu.RLock()
+	handler, ok := t.handlers[address]
+	t.mu.RUnlock()
+
+	if !ok {
+		slog.Error("No loopback handler", slog.String("address", address))
+		return nil, fmt.Errorf("no loopback handler registered for %s", address)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	// The handler owns its request like a server owns what it read from a socket
+	return handler(ctx, bytes.Clone(req))
+}
+
+const loopbackHistoryLimit = 100
+
+// LoopbackServer answers every command and operation the way the protocol servers do, for
+// LoopbackRoundTripper. Sessions, counters and history are kept in memory.
+type LoopbackServer struct {
+	Serde ServerSerde
+
+	mu         sync.Mutex
+	started    time.Time
+	sessions   map[string]AuthResponse
+	history    map[string][]HistoryOperationHistoryResponse
+	operations StatusDatabaseOperationType
+	processed  int
+	issued     int
+}
+
+func NewLoopbackServer(serde ServerSerde) *LoopbackServer {
+	return &LoopbackServer{
+		Serde:    serde,
+		started:  time.Now(),
+		sessions: map[string]AuthResponse{},
+		history:  map[string][]HistoryOperationHistoryResponse{},
+	}
+}
+
+// Handle is the LoopbackHandler of the server, requests that cannot be decoded are answered with a
+// bad request like the servers do
+func (s *LoopbackServer) Handle(ctx context.Context, data []byte) ([]byte, error) {
+	var req PresentationLayerRequest
+	if err := s.Serde.UnmarshalRequest(data, &req); err != nil {
+		slog.DebugContext(ctx, "Invalid loopback request", slog.String("error", err.Error()))
+		return s.Serde.MarshalResponse(loopbackError(http.StatusBadRequest, "INVALID_REQUEST", err.Error()))
+	}
+
+	return s.Serde.MarshalResponse(s.answer(req))
+}
+
+func loopbackError(statusCode int, code string, message string) PresentationLayerResponse[OperationResponse] {
+	return PresentationLayerResponse[OperationResponse]{
+		Err:        &PresentationLayerErrorResponse{Code: code, Message: message},
+		StatusCode: statusCode,
+	}
+}
+
+func (s *LoopbackServer) answer(req PresentationLayerRequest) PresentationLayerResponse[OperationResponse] {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := NonISO8601Time{time.Now().UTC()}
+
+	if auth, ok := req.Body.(AuthRequest); ok {
+		s.issued++
+		resp := AuthResponse{
+			Token:      fmt.Sprintf("loopback%s%d", auth.StudentID, s.issued),
+			Name:       "ALUNO " + auth.StudentID,
+			Enrollment: auth.StudentID,
+			Timestamp:  now,
+		}
+		s.sessions[resp.Token] = resp
+		s.operations.Authentication++
+
+		return PresentationLayerResponse[OperationResponse]{Body: resp, StatusCode: http.StatusOK}
+	}
+
+	session, ok := s.sessions[req.Token]
+	if !ok {
+		return loopbackError(http.StatusUnauthorized, "INVALID_TOKEN", "invalid or expired token")
+	}
+
+	var body OperationResponse
+	switch r := req.Body.(type) {
+	case LogoutRequest:
+		delete(s.sessions, req.Token)
+		return PresentationLayerResponse[OperationResponse]{
+			Body:       LogoutResponse{Message: "Logout realizado com sucesso", Timestamp: now},
+			StatusCode: http.StatusOK,
+		}
+	case EchoRequest:
+		hash := md5.Sum([]byte(r.Message))
+		body = EchoResponse{
+			OriginalMessage: r.Message,
+			EchoMessage:     "ECO: " + r.Message,
+			ServerTimestamp: now,
+			MessageSize:     len(r.Message),
+			HashMD5:         hex.EncodeToString(hash[:]),
+			Timestamp:       now,
+		}
+		s.operations.Echo++
+	case SumRequest:
+		body = loopbackSum(r.Numbers, now)
+		s.operations.Sum++
+	case TimestampRequest:
+		body = TimestampResponse{
+			FormatedTimestamp: now.Format("2006-01-02 15:04:05"),
+			ISOTimestamp:      now,
+			UnixTimestamp:     UnixTimestamp{now.Time},
+			Year:              now.Year(),
+			Month:             int(now.Month()),
+			Day:               now.Day(),
+			Hour:              now.Hour(),
+			Minute:            now.Minute(),
+			Second:            now.Second(),
+			Microsecond:       now.Nanosecond() / int(time.Microsecond),
+			Timestamp:         now,
+		}
+		s.operations.Timestamp++
+	case HistoryRequest:
+		body = s.historyResponse(session.Enrollment, r.Limit, now)
+		s.operations.History++
+	case StatusRequest:
+		body = s.statusResponse(r.Detailed, now)
+		s.operations.Status++
+	default:
+		return loopbackError(http.StatusBadRequest, "UNKNOWN_OPERATION", "unknown operation "+req.Body.CommandOrOperationName())
+	}
+
+	s.processed++
+	s.record(session.Enrollment, req.Body.CommandOrOperationName(), now)
+
+	return PresentationLayerResponse[OperationResponse]{Body: body, StatusCode: http.StatusOK}
+}
+
+func loopbackSum(numbers []int, now NonISO8601Time) SumResponse {
+	resp := SumResponse{
+		OriginalNumbers:      make([]float64, len(numbers)),
+		Amount:               float64(len(numbers)),
+		Timestamp:            now,
+		CalculationTimestamp: now,
+	}
+
+	for i, n := range numbers {
+		resp.OriginalNumbers[i] = float64(n)
+		resp.Sum += float64(n)
+	}
+
+	if len(numbers) > 0 {
+		resp.Mean = resp.Sum / resp.Amount
+		resp.Maximum = float64(slices.Max(numbers))
+		resp.Minimum = float64(slices.Min(numbers))
+	}
+
+	return resp
+}
+
+// record keeps the last operations of every student for the history operation
+func (s *LoopbackServer) record(studentID string, operation string, now NonISO8601Time) {
+	history := append(s.history[studentID], HistoryOperationHistoryResponse{
+		Operation: operation,
+		Timestamp: now,
+		Success:   true,
+	})
+	if len(history) > loopbackHistoryLimit {
+		history = history[len(history)-loopbackHistoryLimit:]
+	}
+	s.history[studentID] = history
+}
+
+func (s *LoopbackServer) historyResponse(studentID string, limit int, now NonISO8601Time) HistoryResponse {
+	history := s.history[studentID]
+	if len(history) > limit {
+		history = history[len(history)-limit:]
+	}
+
+	resp := HistoryResponse{
+		StudentID:      studentID,
+		RequestedLimit: limit,
+		TotalFound:     len(history),
+		// Servers send an empty list rather than nothing when there is no history yet
+		History:          append([]HistoryOperationHistoryResponse{}, history...),
+		ConsultTimestamp: now,
+		Stats: HistoryResponseStats{
+			TotalOperations:   len(history),
+			SuccessOperations: len(history),
+			SuccessRate:       100,
+		},
+		Timestamp: now,
+	}
+	// The latest operation comes first
+	slices.Reverse(resp.History)
+
+	return resp
+}
+
+func (s *LoopbackServer) statusResponse(detailed bool, now NonISO8601Time) StatusResponse {
+	resp := StatusResponse{
+		Status:              "ATIVO",
+		OperationsProcessed: s.processed,
+		TimeActive:          UnixTimestamp{s.started.UTC()},
+		Version:             "loopback",
+		ActiveSessions:      len(s.sessions),
+		Timestamp:           now,
+	}
+
+	if !detailed {
+		return resp
+	}
+
+	students := map[string]bool{}
+	details := make(map[string]StatusResponseSessionDetails, len(s.sessions))
+	for _, session := range s.sessions {
+		students[session.Enrollment] = true
+		details[session.Enrollment] = StatusResponseSessionDetails{
+			TimestampLogin: UnixTimestamp{session.Timestamp.Time},
+			IPClient:       TransportLoopback,
+			Name:           session.Name,
+			Enrollment:     session.Enrollment,
+		}
+	}
+
+	resp.DatabaseStatistics = &StatusDatabaseStatistics{
+		TotalSessions:     len(s.sessions),
+		TotalOperations:   s.processed,
+		OperationsPerType: s.operations,
+		UniqueStudents:    len(students),
+	}
+	resp.SessionDetails = &details
+
+	return resp
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newLoopbackClient(t *testing.T, protocol string) (*AppLayerClient[OperationRequest, OperationResponse], string) {
+	t.Helper()
+
+	serde, err := NewSerde(protocol)
+	require.NoError(t, err)
+	serverSerde, err := NewSerde(protocol)
+	require.NoError(t, err)
+
+	address := LoopbackAddress(protocol)
+	roundTripper := NewLoopbackRoundTripper()
+	roundTripper.Handle(address, NewLoopbackServer(serverSerde.(ServerSerde)).Handle)
+
+	return NewAppLayerClient[OperationRequest, OperationResponse](serde, roundTripper, &AppSettings{}), address
+}
+
+func TestLoopbackServerFlow(t *testing.T) {
+	for _, protocol := range Protocols {
+		t.Run(protocol, func(t *testing.T) {
+			// Arrange
+			client, address := newLoopbackClient(t, protocol)
+			ctx := context.Background()
+
+			// Act
+			authResp, err := client.Auth(ctx, address, &AuthRequest{StudentID: "538349", Timestamp: time.Now()})
+			require.NoError(t, err, "Auth should not return an error")
+
+			emptyHistoryResp := &HistoryResponse{}
+			emptyHistoryErr := client.Do(ctx, address, HistoryRequest{Limit: 5}, emptyHistoryResp, authResp.Token)
+
+			echoResp := &EchoResponse{}
+			echoErr := client.Do(ctx, address, EchoRequest{Message: "ola mundo"}, echoResp, authResp.Token)
+			sumResp := &SumResponse{}
+			sumErr := client.Do(ctx, address, SumRequest{Numbers: []int{4, 1, 7}}, sumResp, authResp.Token)
+			timestampResp := &TimestampResponse{}
+			timestampErr := client.Do(ctx, address, TimestampRequest{}, timestampResp, authResp.Token)
+			historyResp := &HistoryResponse{}
+			historyErr := client.Do(ctx, address, HistoryRequest{Limit: 2}, historyResp, authResp.Token)
+			statusResp := &StatusResponse{}
+			statusErr := client.Do(ctx, address, StatusRequest{Detailed: true}, statusResp, authResp.Token)
+			_, logoutErr := client.Logout(ctx, address, &LogoutRequest{}, authResp.Token)
+			afterLogoutErr := client.Do(ctx, address, EchoRequest{Message: "ola mundo"}, &EchoResponse{}, authResp.Token)
+
+			// Assert
+			assert.NotEmpty(t, authResp.Token)
+			require.NoError(t, emptyHistoryErr, "an empty history should decode")
+			assert.Empty(t, emptyHistoryResp.History)
+			require.NoError(t, echoErr)
+			assert.Equal(t, "ECO: ola mundo", echoResp.EchoMessage)
+			assert.Equal(t, 9, echoResp.MessageSize)
+			require.NoError(t, sumErr)
+			assert.Equal(t, 12.0, sumResp.Sum)
+			assert.Equal(t, 7.0, sumResp.Maximum)
+			assert.Equal(t, 1.0, sumResp.Minimum)
+			require.NoError(t, timestampErr)
+			assert.Positive(t, timestampResp.Year)
+			require.NoError(t, historyErr)
+			assert.Equal(t, 2, historyResp.TotalFound)
+			require.Len(t, historyResp.History, 2)
+			assert.Equal(t, OperationTimestamp, historyResp.History[0].Operation, "the history starts with the latest operation")
+			require.NoError(t, statusErr)
+			assert.Equal(t, 5, statusResp.OperationsProcessed)
+			require.NoError(t, logoutErr)
+			var appErr *PresentationLayerErrorResponse
+			require.ErrorAs(t, afterLogoutErr, &appErr, "tokens are invalid after logout")
+		})
+	}
+}
+
+func TestLoopbackServerRejectsInvalidRequests(t *testing.T) {
+	// Arrange
+	serde := &JSONSerde{}
+	server := NewLoopbackServer(serde)
+
+	// Act
+	data, err := server.Handle(context.Background(), []byte(`not json`))
+	require.NoError(t, err)
+
+	resp := PresentationLayerResponse[OperationResponse]{Body: &EchoResponse{}}
+	unmarshalErr := serde.Unmarshal(data, &resp)
+
+	// Assert
+	require.NoError(t, unmarshalErr)
+	assert.GreaterOrEqual(t, resp.StatusCode, http.StatusBadRequest)
+	require.NotNil(t, resp.Err)
+	assert.NotEmpty(t, resp.Err.Message)
+}
+
+func TestLoopbackRoundTripperUnknownAddress(t *testing.T) {
+	// Arrange
+	roundTripper := NewLoopbackRoundTripper()
+
+	// Act
+	_, err := roundTripper.RequestReply(context.Background(), LoopbackAddress("json"), []byte(`{}`))
+
+	// Assert
+	assert.ErrorContains(t, err, "loopback://json")
+}
+
+func TestLoopbackRoundTripperCopiesRequests(t *testing.T) {
+	// Arrange
+	roundTripper := NewLoopbackRoundTripper()
+	roundTripper.Handle(LoopbackAddress("echo"), func(ctx context.Context, req []byte) ([]byte, error) {
+		clear(req)
+		return []byte("reply"), nil
+	})
+	req := []byte("request")
+
+	// Act
+	resp, err := roundTripper.RequestReply(context.Background(), LoopbackAddress("echo"), req)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, []byte("reply"), resp)
+	assert.Equal(t, []byte("request"), req, "handlers should not see the client buffer")
+}
+
+func TestBenchmarkRunnerLoopback(t *testing.T) {
+	// Arrange
+	scenario := &Scenario{
+		Name:        "loopback",
+		Protocols:   Protocols,
+		Transports:  []string{TransportLoopback},
+		StudentID:   "538349",
+		Duration:    100 * time.Millisecond,
+		Concurrency: 2,
+		Encryption:  map[string]string{ProtocolJSON: CipherAESGCM},
+		Compression: map[string]string{ProtocolCBOR: CompressionGzip},
+		Operations: []ScenarioOperation{
+			{Name: OperationEcho, Weight: 1},
+			{Name: OperationSum, Weight: 1},
+			{Name: OperationTimestamp, Weight: 1},
+			{Name: OperationHistory, Weight: 1},
+			{Name: OperationStatus, Weight: 1, DetailedRatio: 0.5},
+		},
+	}
+	settings := &AppSettings{EncryptionKey: "000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f"}
+	runner := NewBenchmarkRunner(scenario, settings, DefaultTCPRoundTripper)
+
+	// Act
+	report, err := runner.Run(context.Background())
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, report.Results, len(Protocols))
+	for _, result := range report.Results {
+		assert.Equal(t, result.Protocol+"/loopback", result.Name())
+		assert.Positive(t, result.Requests, "%s should run requests", result.Name())
+		assert.Zero(t, result.Errors, "%s should not fail", result.Name())
+	}
+}
@@ -6,7 +6,15 @@ import (
 )
 
 func main() {
-	if err := RunTUI(); err != nil {
+	if len(os.Args) > 1 {
+		if err := RunCLI(os.Args[1:]); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if err := RunTUI(TUIOptions{}); err != nil {
 		fmt.Printf("Error running TUI: %v\n", err)
 		os.Exit(1)
 	}
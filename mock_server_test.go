@@ -0,0 +1,273 @@
+package main
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/binary"
+	"encoding/json"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fxamacker/cbor/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vmihailenco/msgpack/v5"
+	"google.golang.org/protobuf/proto"
+)
+
+// mockExchange scripts one connection of the mock server
+type mockExchange struct {
+	// Expect, when set, must match the whole request
+	Expect []byte
+	// ExpectContains are substrings the request must contain
+	ExpectContains []string
+	Reply          []byte
+	// Delay is waited before replying
+	Delay time.Duration
+	// Disconnect closes the connection without replying
+	Disconnect bool
+}
+
+// mockServer is an in-process TCP server on an ephemeral localhost port that serves its script
+// in order, one exchange per connection like TCPRoundTripper expects
+type mockServer struct {
+	t        *testing.T
+	listener net.Listener
+	complete func([]byte) bool
+
+	mu        sync.Mutex
+	exchanges []mockExchange
+	requests  [][]byte
+
+	wg sync.WaitGroup
+}
+
+func newMockServer(t *testing.T, protocol string, exchanges ...mockExchange) *mockServer {
+	t.Helper()
+
+	return newMockServerAt(t, "127.0.0.1:0", protocol, exchanges...)
+}
+
+// newMockServerAt is newMockServer listening on a host:port or unix:// address
+func newMockServerAt(t *testing.T, address string, protocol string, exchanges ...mockExchange) *mockServer {
+	t.Helper()
+
+	listener, err := Listen(address)
+	require.NoError(t, err, "mock server should listen on %s", address)
+
+	return startMockServer(t, listener, protocol, exchanges)
+}
+
+// newTLSMockServer is newMockServer behind a TLS listener built from settings
+func newTLSMockServer(t *testing.T, protocol string, settings TLSSettings, exchanges ...mockExchange) *mockServer {
+	t.Helper()
+
+	listener, err := NewTLSListener("127.0.0.1:0", settings)
+	require.NoError(t, err, "mock server should listen on an ephemeral port")
+
+	return startMockServer(t, listener, protocol, exchanges)
+}
+
+func startMockServer(t *testing.T, listener net.Listener, protocol string, exchanges []mockExchange) *mockServer {
+	s := &mockServer{
+		t:         t,
+		listener:  listener,
+		complete:  mockRequestComplete(protocol),
+		exchanges: exchanges,
+	}
+
+	s.wg.Add(1)
+	go s.serve()
+
+	t.Cleanup(func() {
+		listener.Close()
+		s.wg.Wait()
+	})
+
+	return s
+}
+
+// mockRequestComplete tells when a request of the protocol was fully read
+func mockRequestComplete(protocol string) func([]byte) bool {
+	switch protocol {
+	case ProtocolString:
+		return func(data []byte) bool { return bytes.HasSuffix(data, []byte("\n")) }
+	case ProtocolProtobuf, ProtocolMsgpack, ProtocolCBOR:
+		return func(data []byte) bool {
+			return len(data) >= 4 && len(data)-4 >= int(binary.BigEndian.Uint32(data[:4]))
+		}
+	default:
+		return json.Valid
+	}
+}
+
+func (s *mockServer) Address() string {
+	return listenerAddress(s.listener)
+}
+
+func (s *mockServer) serve() {
+	defer s.wg.Done()
+
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			defer conn.Close()
+			s.handle(conn)
+		}()
+	}
+}
+
+func (s *mockServer) handle(conn net.Conn) {
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	// Rejected handshakes never reach the script, tests of the TLS settings expect them
+	if tlsConn, ok := conn.(*tls.Conn); ok {
+		if err := tlsConn.Handshake(); err != nil {
+			return
+		}
+	}
+
+	request, err := s.readRequest(conn)
+	if err != nil {
+		s.t.Errorf("mock server failed to read request: %v", err)
+		return
+	}
+
+	s.mu.Lock()
+	index := len(s.requests)
+	s.requests = append(s.requests, request)
+	var exchange mockExchange
+	scripted := index < len(s.exchanges)
+	if scripted {
+		exchange = s.exchanges[index]
+	}
+	s.mu.Unlock()
+
+	if !scripted {
+		s.t.Errorf("mock server received unexpected request %d: %q", index, request)
+		return
+	}
+
+	if exchange.Expect != nil && !bytes.Equal(exchange.Expect, request) {
+		s.t.Errorf("mock server request %d mismatch\nexpected: %q\nactual:   %q", index, exchange.Expect, request)
+	}
+
+	for _, part := range exchange.ExpectContains {
+		if !bytes.Contains(request, []byte(part)) {
+			s.t.Errorf("mock server request %d should contain %q, got %q", index, part, request)
+		}
+	}
+
+	if exchange.Delay > 0 {
+		time.Sleep(exchange.Delay)
+	}
+
+	if exchange.Disconnect {
+		return
+	}
+
+	// The client may have given up waiting, so write errors are expected
+	conn.Write(exchange.Reply)
+}
+
+func (s *mockServer) readRequest(conn net.Conn) ([]byte, error) {
+	request := []byte{}
+	buf := make([]byte, 4096)
+
+	for !s.complete(request) {
+		n, err := conn.Read(buf)
+		request = append(request, buf[:n]...)
+		if err != nil {
+			return request, err
+		}
+	}
+
+	return request, nil
+}
+
+// Requests returns a copy of every request received so far
+func (s *mockServer) Requests() [][]byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	requests := make([][]byte, len(s.requests))
+	copy(requests, s.requests)
+
+	return requests
+}
+
+// AssertExhausted asserts every scripted exchange was served
+func (s *mockServer) AssertExhausted(t *testing.T) {
+	t.Helper()
+
+	assert.Eventually(t, func() bool {
+		return len(s.Requests()) == len(s.exchanges)
+	}, time.Second, 5*time.Millisecond, "mock server should serve every scripted exchange")
+}
+
+// AssertRequestContains asserts the i-th request contains every part
+func (s *mockServer) AssertRequestContains(t *testing.T, i int, parts ...string) {
+	t.Helper()
+
+	requests := s.Requests()
+	require.Greater(t, len(requests), i, "mock server should have received request %d", i)
+
+	for _, part := range parts {
+		assert.True(t, strings.Contains(string(requests[i]), part), "request %d should contain %q, got %q", i, part, requests[i])
+	}
+}
+
+// protobufReply frames a protobuf response the way the server does
+func protobufReply(t testing.TB, msg proto.Message) []byte {
+	t.Helper()
+
+	body, err := proto.Marshal(msg)
+	require.NoError(t, err)
+
+	data := binary.BigEndian.AppendUint32(nil, uint32(len(body)))
+
+	return append(data, body...)
+}
+
+// msgpackReply frames a MessagePack map the way the server does, keys and values alternate in pairs
+// and keep their order
+func msgpackReply(t testing.TB, pairs ...any) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	enc := msgpack.NewEncoder(&buf)
+	require.NoError(t, enc.EncodeMapLen(len(pairs)/2))
+	for _, v := range pairs {
+		require.NoError(t, enc.Encode(v))
+	}
+
+	data := binary.BigEndian.AppendUint32(nil, uint32(buf.Len()))
+
+	return append(data, buf.Bytes()...)
+}
+
+// cborReply frames a CBOR map the way the server does, keys and values alternate in pairs and keep
+// their order
+func cborReply(t testing.TB, pairs ...any) []byte {
+	t.Helper()
+	require.Less(t, len(pairs)/2, 24, "the map length should fit in the initial byte")
+
+	// Map of len(pairs)/2 entries
+	body := []byte{0xa0 | byte(len(pairs)/2)}
+	for _, v := range pairs {
+		item, err := cbor.Marshal(v)
+		require.NoError(t, err)
+		body = append(body, item...)
+	}
+
+	return frameMessage(body)
+}
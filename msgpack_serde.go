@@ -0,0 +1,149 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"reflect"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// MsgpackSerde sends the envelope of the JSON protocol encoded as MessagePack, behind the length
+// prefix of the protobuf protocol. Domain types are encoded through their json tags.
+type MsgpackSerde struct{}
+
+// msgpackRequestEnvelope is jsonRequestWrapper as the server reads it, parameters are bound once the operation is known
+type msgpackRequestEnvelope struct {
+	Kind      string             `msgpack:"tipo"`
+	Operation string             `msgpack:"operacao"`
+	Token     string             `msgpack:"token"`
+	Params    msgpack.RawMessage `msgpack:"parametros"`
+	StudentID string             `msgpack:"aluno_id"`
+}
+
+// marshalMsgpack encodes v behind its length prefix
+func marshalMsgpack(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	// Room for the length prefix, written once the size is known
+	buf.Write(make([]byte, 4))
+
+	enc := msgpack.NewEncoder(&buf)
+	enc.SetCustomStructTag("json")
+	enc.UseCompactInts(true)
+
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+
+	data := buf.Bytes()
+	binary.BigEndian.PutUint32(data, uint32(len(data)-4))
+
+	return data, nil
+}
+
+// unmarshalMsgpack decodes the message behind the length prefix into v
+func unmarshalMsgpack(data []byte, v any) error {
+	data, err := unframeMessage(data)
+	if err != nil {
+		return err
+	}
+
+	return decodeMsgpack(data, v)
+}
+
+func decodeMsgpack(data []byte, v any) error {
+	dec := msgpack.NewDecoder(bytes.NewReader(data))
+	dec.SetCustomStructTag("json")
+	// Dynamic fields get int64 and float64 numbers, like the JSON protocol
+	dec.UseLooseInterfaceDecoding(true)
+
+	return dec.Decode(v)
+}
+
+// Marshal implements Serde.
+func (m MsgpackSerde) Marshal(v any) ([]byte, error) {
+	request, err := newJSONRequestWrapper(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return marshalMsgpack(request)
+}
+
+// Unmarshal implements Serde.
+func (m MsgpackSerde) Unmarshal(data []byte, v any) error {
+	value, err := responseValue(v)
+	if err != nil {
+		return err
+	}
+
+	bodyElem, err := responseBody(value)
+	if err != nil {
+		return err
+	}
+
+	responseWrapper := jsonResponseWrapper{
+		Result: bodyElem.Addr().Interface(),
+	}
+
+	if err := unmarshalMsgpack(data, &responseWrapper); err != nil {
+		return err
+	}
+
+	bindJSONResponseWrapper(value, bodyElem, responseWrapper)
+
+	return nil
+}
+
+// AccountRequest implements WireAccountant.
+func (m MsgpackSerde) AccountRequest(data []byte) (WireBreakdown, error) {
+	return accountMsgpackMessage(data, wireControlFields)
+}
+
+// ReplyLength implements ReplyFramer.
+func (m MsgpackSerde) ReplyLength(data []byte) (int, bool) {
+	return framedMessageLength(data)
+}
+
+// AccountResponse implements WireAccountant.
+func (m MsgpackSerde) AccountResponse(data []byte) (WireBreakdown, error) {
+	return accountMsgpackMessage(data, wireControlFields)
+}
+
+// UnmarshalRequest implements ServerSerde.
+func (m MsgpackSerde) UnmarshalRequest(data []byte, req *PresentationLayerRequest) error {
+	if req == nil {
+		return fmt.Errorf("nil pointer provided")
+	}
+
+	var envelope msgpackRequestEnvelope
+	if err := unmarshalMsgpack(data, &envelope); err != nil {
+		return err
+	}
+
+	body, err := newEnvelopeRequestBody(envelope.Kind, envelope.Operation, envelope.StudentID, func(body reflect.Value) error {
+		if len(envelope.Params) == 0 {
+			return nil
+		}
+		return decodeMsgpack(envelope.Params, body.Addr().Interface())
+	})
+	if err != nil {
+		return err
+	}
+
+	req.Token = envelope.Token
+	req.Body = body
+
+	return nil
+}
+
+// MarshalResponse implements ServerSerde.
+func (m MsgpackSerde) MarshalResponse(v any) ([]byte, error) {
+	wrapper, err := newJSONResponseWrapper(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return marshalMsgpack(wrapper)
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/go-playground/validator/v10"
+)
+
+const (
+	OperationEcho      = "echo"
+	OperationSum       = "soma"
+	OperationTimestamp = "timestamp"
+	OperationHistory   = "historico"
+	OperationStatus    = "status"
+)
+
+// Operations lists the operation names as they travel on the wire
+var Operations = []string{OperationEcho, OperationSum, OperationTimestamp, OperationHistory, OperationStatus}
+
+// NewOperationResponse returns an empty response to bind the reply of the given operation
+func NewOperationResponse(operation string) (OperationResponse, error) {
+	switch operation {
+	case OperationEcho:
+		return &EchoResponse{}, nil
+	case OperationSum:
+		return &SumResponse{}, nil
+	case OperationTimestamp:
+		return &TimestampResponse{}, nil
+	case OperationHistory:
+		return &HistoryResponse{}, nil
+	case OperationStatus:
+		return &StatusResponse{}, nil
+	default:
+		return nil, fmt.Errorf("unknown operation %s", operation)
+	}
+}
+
+// NewOperationRequest returns an empty request to bind the parameters of the given operation
+func NewOperationRequest(operation string) (OperationRequest, error) {
+	switch operation {
+	case OperationEcho:
+		return &EchoRequest{}, nil
+	case OperationSum:
+		return &SumRequest{}, nil
+	case OperationTimestamp:
+		return &TimestampRequest{}, nil
+	case OperationHistory:
+		return &HistoryRequest{}, nil
+	case OperationStatus:
+		return &StatusRequest{}, nil
+	default:
+		return nil, fmt.Errorf("unknown operation %s", operation)
+	}
+}
+
+// ParseOperationRequest binds the parameters of the given operation from a JSON object keyed by
+// their wire names, like {"mensagem":"ola"}, and validates them
+func ParseOperationRequest(operation string, params []byte) (OperationRequest, error) {
+	req, err := NewOperationRequest(operation)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(params))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(req); err != nil {
+		return nil, fmt.Errorf("invalid parameters of %s: %w", operation, err)
+	}
+
+	// The codecs take requests by value
+	value := reflect.ValueOf(req).Elem().Interface().(OperationRequest)
+	if err := validator.New(validator.WithRequiredStructEnabled()).Struct(value); err != nil {
+		return nil, fmt.Errorf("invalid parameters of %s: %w", operation, err)
+	}
+
+	return value, nil
+}
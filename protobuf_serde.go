@@ -31,50 +31,67 @@ func (p ProtobufSerde) Marshal(v any) ([]byte, error) {
 		return nil, err
 	}
 
+	return frameProtobuf(msg)
+}
+
+// frameProtobuf marshals msg behind its 4 byte big endian length
+func frameProtobuf(msg proto.Message) ([]byte, error) {
 	msgBytes, err := proto.Marshal(msg)
 	if err != nil {
 		return nil, err
 	}
 
-	data := make([]byte, 4)
-	// Calculate header size
-	binary.BigEndian.PutUint32(data, uint32(len(msgBytes)))
-
-	data = append(data, msgBytes...)
-
-	return data, nil
+	return frameMessage(msgBytes), nil
 }
 
-// Unmarshal implements Serde.
-func (p ProtobufSerde) Unmarshal(data []byte, v any) error {
-	typ := reflect.TypeOf(v)
-	value := reflect.ValueOf(v)
+// frameMessage puts payload behind its 4 byte big endian length, the framing of the binary protocols
+func frameMessage(payload []byte) []byte {
+	data := make([]byte, 4, 4+len(payload))
+	// Calculate header size
+	binary.BigEndian.PutUint32(data, uint32(len(payload)))
 
-	if typ.Kind() != reflect.Pointer {
-		return fmt.Errorf("pointers are supported, found %s", typ.Kind().String())
-	}
+	return append(data, payload...)
+}
 
-	if value.IsNil() {
-		return fmt.Errorf("nil pointer provided")
+// framedMessageLength is the length of the length prefixed message data starts with, once the prefix is read
+func framedMessageLength(data []byte) (int, bool) {
+	if len(data) < 4 {
+		return 0, false
 	}
 
-	value = value.Elem()
+	return 4 + int(binary.BigEndian.Uint32(data[:4])), true
+}
 
+// unframeMessage returns the message behind the 4 byte big endian length
+func unframeMessage(data []byte) ([]byte, error) {
 	if len(data) < 4 {
-		return fmt.Errorf("data too small, expected at least 4 bytes for header, got %d", len(data))
+		return nil, fmt.Errorf("data too small, expected at least 4 bytes for header, got %d", len(data))
 	}
 
 	headerSize := binary.BigEndian.Uint32(data[:4])
 
 	// Check for overflow and ensure we have enough data
 	if headerSize > uint32(len(data)-4) {
-		return fmt.Errorf("data size is smaller than header size, probably corrupted data. Expected at least %d bytes, got %d bytes", headerSize+4, len(data)-4)
+		return nil, fmt.Errorf("data size is smaller than header size, probably corrupted data. Expected at least %d bytes, got %d bytes", headerSize+4, len(data)-4)
 	}
 
-	data = data[4 : 4+headerSize]
+	return data[4 : 4+headerSize], nil
+}
+
+// Unmarshal implements Serde.
+func (p ProtobufSerde) Unmarshal(data []byte, v any) error {
+	value, err := responseValue(v)
+	if err != nil {
+		return err
+	}
+
+	data, err = unframeMessage(data)
+	if err != nil {
+		return err
+	}
 
 	msg := &protogenerated.Resposta{}
-	err := proto.Unmarshal(data, msg)
+	err = proto.Unmarshal(data, msg)
 	if err != nil {
 		slog.Error("Error unmarshaling proto", slog.String("error", err.Error()))
 		return err
@@ -178,24 +195,151 @@ func fromProtoToDomain(msg *protogenerated.Resposta, value reflect.Value) error
 		return fmt.Errorf("expected to have a pointer to RespostaOK")
 	}
 
+	value.FieldByName("StatusCode").SetInt(int64(http.StatusOK))
 	value.FieldByName("Err").Set(reflect.Zero(value.FieldByName("Err").Type()))
-	bodyField := value.FieldByName("Body")
 
-	if bodyField.Kind() != reflect.Pointer && bodyField.Kind() != reflect.Interface {
-		return fmt.Errorf("body field is not a pointer or interface: %v", bodyField.Kind())
+	bodyField, err := responseBody(value)
+	if err != nil {
+		return err
 	}
 
-	if bodyField.Kind() == reflect.Interface {
-		bodyField = bodyField.Elem()
+	// Replies without data decode to a nil map
+	if okMsg.Dados == nil {
+		okMsg.Dados = make(map[string]string)
 	}
+	okMsg.Dados["timestamp"] = okMsg.Timestamp
+
+	return bindStructFields(bodyField, okMsg.Dados)
+}
+
+// AccountRequest implements WireAccountant.
+func (p ProtobufSerde) AccountRequest(data []byte) (WireBreakdown, error) {
+	return accountProtobufMessage(data, &protogenerated.Requisicao{})
+}
+
+// ReplyLength implements ReplyFramer.
+func (p ProtobufSerde) ReplyLength(data []byte) (int, bool) {
+	return framedMessageLength(data)
+}
+
+// AccountResponse implements WireAccountant.
+func (p ProtobufSerde) AccountResponse(data []byte) (WireBreakdown, error) {
+	return accountProtobufMessage(data, &protogenerated.Resposta{})
+}
 
-	if bodyField.IsNil() {
-		bodyField.Set(reflect.New(bodyField.Type().Elem()))
+func accountProtobufMessage(data []byte, msg proto.Message) (WireBreakdown, error) {
+	body, err := unframeMessage(data)
+	if err != nil {
+		return WireBreakdown{}, err
 	}
 
-	bodyField = bodyField.Elem()
+	if err := proto.Unmarshal(body, msg); err != nil {
+		return WireBreakdown{}, err
+	}
 
-	okMsg.Dados["timestamp"] = okMsg.Timestamp
+	breakdown := accountProtoPayload(msg.ProtoReflect())
+	breakdown.Envelope = len(body) - breakdown.Payload
+	// The length prefix and anything trailing the message
+	breakdown.Framing = len(data) - len(body)
 
-	return bindStructFields(bodyField, okMsg.Dados)
+	return breakdown, nil
+}
+
+// UnmarshalRequest implements ServerSerde.
+func (p ProtobufSerde) UnmarshalRequest(data []byte, req *PresentationLayerRequest) error {
+	if req == nil {
+		return fmt.Errorf("nil pointer provided")
+	}
+
+	data, err := unframeMessage(data)
+	if err != nil {
+		return err
+	}
+
+	msg := &protogenerated.Requisicao{}
+	if err := proto.Unmarshal(data, msg); err != nil {
+		return err
+	}
+
+	switch tipo := msg.Tipo.(type) {
+	case *protogenerated.Requisicao_Auth:
+		req.Token = ""
+		req.Body = AuthRequest{StudentID: tipo.Auth.GetAlunoId()}
+	case *protogenerated.Requisicao_Logout:
+		req.Token = tipo.Logout.GetToken()
+		req.Body = LogoutRequest{}
+	case *protogenerated.Requisicao_Operacao:
+		params := tipo.Operacao.GetParametros()
+		if params == nil {
+			params = make(map[string]string)
+		}
+
+		body, err := decodeRequestBody(tipo.Operacao.GetOperacao(), func(body reflect.Value) error {
+			return bindStructFields(body, params)
+		})
+		if err != nil {
+			return err
+		}
+
+		req.Token = tipo.Operacao.GetToken()
+		req.Body = body
+	default:
+		return fmt.Errorf("unsupported request type %T", msg.Tipo)
+	}
+
+	return nil
+}
+
+// MarshalResponse implements ServerSerde.
+func (p ProtobufSerde) MarshalResponse(v any) ([]byte, error) {
+	resp, err := newServerResponse(v)
+	if err != nil {
+		return nil, err
+	}
+
+	timestamp := resp.timestamp().Format(nonISO8601Layout)
+
+	if resp.failed() {
+		details := make(map[string]string)
+		if resp.err != nil {
+			for key, value := range resp.err.Details {
+				details[key] = fmt.Sprintf("%v", value)
+			}
+		}
+
+		return frameProtobuf(&protogenerated.Resposta{
+			Tipo: &protogenerated.Resposta_Erro{
+				Erro: &protogenerated.RespostaErro{
+					Comando:   resp.command(),
+					Mensagem:  resp.message(),
+					Timestamp: timestamp,
+					Detalhes:  details,
+				},
+			},
+		})
+	}
+
+	properties, err := responseProperties(resp.body)
+	if err != nil {
+		return nil, err
+	}
+
+	dados := make(map[string]string, len(properties))
+	for _, property := range properties {
+		// The timestamp travels in its own field
+		if property.Key == "timestamp" {
+			continue
+		}
+		dados[property.Key] = property.Value
+	}
+
+	return frameProtobuf(&protogenerated.Resposta{
+		Tipo: &protogenerated.Resposta_Ok{
+			Ok: &protogenerated.RespostaOk{
+				Comando:   resp.command(),
+				Dados:     dados,
+				Timestamp: timestamp,
+			},
+		},
+	})
 }
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+)
+
+const (
+	ProtocolJSON     = "json"
+	ProtocolString   = "string"
+	ProtocolProtobuf = "protobuf"
+	ProtocolMsgpack  = "msgpack"
+	ProtocolCBOR     = "cbor"
+)
+
+// Protocols lists every presentation protocol supported by the client, in display order
+var Protocols = []string{ProtocolJSON, ProtocolString, ProtocolProtobuf, ProtocolMsgpack, ProtocolCBOR}
+
+func NewSerde(protocol string) (Serde, error) {
+	switch protocol {
+	case ProtocolJSON:
+		return &JSONSerde{}, nil
+	case ProtocolString:
+		return &StringSerde{}, nil
+	case ProtocolProtobuf:
+		return &ProtobufSerde{}, nil
+	case ProtocolMsgpack:
+		return &MsgpackSerde{}, nil
+	case ProtocolCBOR:
+		return &CBORSerde{}, nil
+	default:
+		return nil, fmt.Errorf("unknown protocol %s", protocol)
+	}
+}
+
+const (
+	CodecReflect   = "reflect"
+	CodecGenerated = "generated"
+)
+
+// Codecs lists the serde implementations a protocol can be benchmarked with
+var Codecs = []string{CodecReflect, CodecGenerated}
+
+// ErrNoGeneratedCodec is returned for protocols serdegen does not generate codecs for
+var ErrNoGeneratedCodec = errors.New("no generated codec for protocol")
+
+// NewCodecSerde returns the serde of the protocol implemented by codec, an empty codec means reflect
+func NewCodecSerde(protocol, codec string) (Serde, error) {
+	switch codec {
+	case "", CodecReflect:
+		return NewSerde(protocol)
+	case CodecGenerated:
+	default:
+		return nil, fmt.Errorf("unknown codec %s", codec)
+	}
+
+	switch protocol {
+	case ProtocolJSON:
+		return &GeneratedJSONSerde{}, nil
+	case ProtocolString:
+		return &GeneratedStringSerde{}, nil
+	case ProtocolProtobuf, ProtocolMsgpack, ProtocolCBOR:
+		return nil, fmt.Errorf("%w %s", ErrNoGeneratedCodec, protocol)
+	default:
+		return nil, fmt.Errorf("unknown protocol %s", protocol)
+	}
+}
+
+// ServerAddress returns the configured server address for the given protocol
+func (a *AppSettings) ServerAddress(protocol string) (string, error) {
+	switch protocol {
+	case ProtocolJSON:
+		return a.JSONProtocolServerAddress, nil
+	case ProtocolString:
+		return a.StringProtocolServerAddress, nil
+	case ProtocolProtobuf:
+		return a.ProtobufProtocolServerAddress, nil
+	case ProtocolMsgpack:
+		return a.MsgpackProtocolServerAddress, nil
+	case ProtocolCBOR:
+		return a.CBORProtocolServerAddress, nil
+	default:
+		return "", fmt.Errorf("unknown protocol %s", protocol)
+	}
+}
+
+// TransportAddress returns the address of the server of the protocol over transport, the configured
+// one when transport is empty. Protocols configured with a host:port are reached over Unix domain
+// sockets named after them in UnixSocketDir, and every protocol over loopback at the handler named
+// after it.
+func (a *AppSettings) TransportAddress(protocol string, transport string) (string, error) {
+	address, err := a.ServerAddress(protocol)
+	if err != nil {
+		return "", err
+	}
+
+	network, _ := splitAddress(address)
+
+	switch {
+	case transport == "" || transport == network:
+		return address, nil
+	case transport == TransportLoopback:
+		return LoopbackAddress(protocol), nil
+	case transport == TransportUnix:
+		return UnixAddress(filepath.Join(a.UnixSocketDir, protocol+".sock")), nil
+	case transport == TransportTCP:
+		return "", fmt.Errorf("the %s server is only configured on %s", protocol, address)
+	default:
+		return "", fmt.Errorf("unknown transport %s", transport)
+	}
+}
@@ -1,21 +1,124 @@
 package main
 
 import (
-	"bytes"
 	"context"
+	"errors"
+	"fmt"
+	"io"
 	"log/slog"
 	"net"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/go-playground/validator/v10"
 	"go.opentelemetry.io/otel"
 )
 
 var tracer = otel.Tracer("triprotocol-benchmark/round_tripper")
 
+const (
+	TransportTCP  = "tcp"
+	TransportUnix = "unix"
+)
+
+// Transports lists the networks a server can be reached on, in display order
+var Transports = []string{TransportTCP, TransportUnix, TransportLoopback}
+
+// unixScheme prefixes the addresses of Unix domain sockets, unix:///run/server.sock is /run/server.sock
+const unixScheme = "unix://"
+
+// splitAddress returns the network and the dial address of a host:port or unix:// address
+func splitAddress(address string) (string, string) {
+	if path, ok := strings.CutPrefix(address, unixScheme); ok {
+		return TransportUnix, path
+	}
+
+	return TransportTCP, address
+}
+
+// UnixAddress returns the address of the Unix domain socket at path
+func UnixAddress(path string) string {
+	return unixScheme + path
+}
+
+// hostValidator checks the host of host:port addresses
+var hostValidator = validator.New()
+
+// ValidAddress tells whether address is a host:port or the unix:// address of an absolute path
+func ValidAddress(address string) bool {
+	network, addr := splitAddress(address)
+	if network == TransportUnix {
+		return filepath.IsAbs(addr) && filepath.Clean(addr) == addr
+	}
+
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil || hostValidator.Var(host, "required,hostname_rfc1123|ip") != nil {
+		return false
+	}
+
+	n, err := strconv.ParseUint(port, 10, 16)
+	return err == nil && n > 0
+}
+
+// Listen listens on a host:port or unix:// address, for servers of any protocol
+func Listen(address string) (net.Listener, error) {
+	return net.Listen(splitAddress(address))
+}
+
+// listenerAddress is the address clients dial to reach listener
+func listenerAddress(listener net.Listener) string {
+	addr := listener.Addr()
+	if addr.Network() == TransportUnix {
+		return UnixAddress(addr.String())
+	}
+
+	return addr.String()
+}
+
+// dial connects to a host:port or unix:// address
+func dial(address string, timeout time.Duration) (net.Conn, error) {
+	network, addr := splitAddress(address)
+
+	return net.DialTimeout(network, addr, timeout)
+}
+
 type RoundTripper interface {
 	RequestReply(ctx context.Context, address string, req []byte) ([]byte, error)
 }
 
+// ReplyFramer is implemented by serdes that know where their replies end, so the transport stops
+// reading once a reply is complete instead of waiting for the server to close the connection.
+// Requests are framed like replies, so servers find their end the same way.
+type ReplyFramer interface {
+	// ReplyLength returns the length of the reply data starts with, ok is false while data holds
+	// too little of it to tell
+	ReplyLength(data []byte) (length int, ok bool)
+}
+
+var (
+	_ ReplyFramer = (*StringSerde)(nil)
+	_ ReplyFramer = (*JSONSerde)(nil)
+	_ ReplyFramer = (*ProtobufSerde)(nil)
+	_ ReplyFramer = (*MsgpackSerde)(nil)
+	_ ReplyFramer = (*CBORSerde)(nil)
+	_ ReplyFramer = (*CompressedSerde)(nil)
+	_ ReplyFramer = (*EncryptedSerde)(nil)
+)
+
+type replyFramerKey struct{}
+
+// withReplyFramer tells the transports serving ctx where the replies of serde end, when serde knows
+func withReplyFramer(ctx context.Context, serde Serde) context.Context {
+	framer, ok := serde.(ReplyFramer)
+	if !ok {
+		return ctx
+	}
+
+	return context.WithValue(ctx, replyFramerKey{}, framer)
+}
+
 var _ RoundTripper = (*TCPRoundTripper)(nil)
 
 var DefaultTCPRoundTripper = &TCPRoundTripper{
@@ -44,33 +147,64 @@ func (t *TCPRoundTripper) RequestReply(ctx context.Context, address string, req
 	defer span.End()
 
 	slog.DebugContext(ctx, "Connecting to TCP server", slog.String("address", address))
-	conn, err := net.DialTimeout("tcp", address, t.DialTimeout)
+	conn, err := dial(address, t.DialTimeout)
 	if err != nil {
 		slog.Error("Error connecting to TCP server", slog.String("address", address), slog.String("error", err.Error()))
 		return nil, err
 	}
 	defer conn.Close()
 
+	return exchange(ctx, conn, address, req, t.WriteTimeout, t.ReadTimeout)
+}
+
+// exchange writes the request on conn and reads the reply until the ReplyFramer of ctx finds its end,
+// or until the server closes the connection when there is none
+func exchange(ctx context.Context, conn net.Conn, address string, req []byte, writeTimeout time.Duration, readTimeout time.Duration) ([]byte, error) {
 	slog.DebugContext(ctx, "Sending request to TCP server", slog.String("address", address))
-	conn.SetDeadline(time.Now().Add(t.WriteTimeout))
-	_, err = conn.Write(req)
+	conn.SetDeadline(time.Now().Add(writeTimeout))
+	_, err := conn.Write(req)
 	if err != nil {
 		slog.Error("Error writing to TCP server", slog.String("address", address), slog.String("error", err.Error()))
 		return nil, err
 	}
 
-	buf := make([]byte, 64*1024)
+	framer, _ := ctx.Value(replyFramerKey{}).(ReplyFramer)
 
-	conn.SetDeadline(time.Now().Add(t.ReadTimeout))
-	_, err = conn.Read(buf)
+	conn.SetDeadline(time.Now().Add(readTimeout))
+	reply, err := readFramed(conn, framer)
 	if err != nil {
 		slog.Error("Error reading from TCP server", slog.String("address", address), slog.String("error", err.Error()))
 		return nil, err
 	}
 
-	trimmedData := bytes.TrimRight(buf, "\x00")
-
 	slog.DebugContext(ctx, "Received response from TCP server", slog.String("address", address))
 
-	return trimmedData, nil
+	return reply, nil
+}
+
+// readFramed reads a message from r until framer finds its end, or until EOF when framer is nil
+func readFramed(r io.Reader, framer ReplyFramer) ([]byte, error) {
+	data := []byte{}
+	buf := make([]byte, 64*1024)
+
+	for {
+		n, err := r.Read(buf)
+		data = append(data, buf[:n]...)
+
+		if framer != nil {
+			if length, ok := framer.ReplyLength(data); ok && len(data) >= length {
+				return data[:length], nil
+			}
+		}
+
+		if errors.Is(err, io.EOF) && len(data) > 0 {
+			if framer == nil {
+				return data, nil
+			}
+			err = fmt.Errorf("connection closed after %d bytes of an incomplete message: %w", len(data), io.ErrUnexpectedEOF)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
 }
@@ -0,0 +1,274 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newSocketPath returns a socket path in a short lived directory, test names make t.TempDir too
+// long for the 108 bytes of a socket address
+func newSocketPath(t *testing.T, name string) string {
+	t.Helper()
+
+	dir, err := os.MkdirTemp("", "trp")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	return filepath.Join(dir, name)
+}
+
+func TestValidAddress(t *testing.T) {
+	tests := []struct {
+		address  string
+		expected bool
+	}{
+		{address: "localhost:8080", expected: true},
+		{address: "3.88.99.255:8081", expected: true},
+		{address: "[::1]:8082", expected: true},
+		{address: "unix:///tmp/json.sock", expected: true},
+		{address: "unix:///run/triprotocol/server.sock", expected: true},
+		{address: "json-server.internal:8080", expected: true},
+		{address: "localhost", expected: false},
+		{address: "bad host:80", expected: false},
+		{address: "bad_host!:80", expected: false},
+		{address: ":8080", expected: false},
+		{address: "localhost:0", expected: false},
+		{address: "localhost:65536", expected: false},
+		{address: "localhost:http", expected: false},
+		{address: "unix://", expected: false},
+		{address: "unix://relative.sock", expected: false},
+		{address: "unix:///tmp/../json.sock", expected: false},
+		{address: "tcp://localhost:8080", expected: false},
+		{address: "loopback://json", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.address, func(t *testing.T) {
+			// Act
+			actual := ValidAddress(tt.address)
+
+			// Assert
+			assert.Equal(t, tt.expected, actual)
+		})
+	}
+}
+
+func TestTransportAddress(t *testing.T) {
+	settings := &AppSettings{
+		JSONProtocolServerAddress:   "localhost:8081",
+		StringProtocolServerAddress: "unix:///run/string.sock",
+		UnixSocketDir:               "/tmp/triprotocol",
+	}
+
+	tests := []struct {
+		name      string
+		protocol  string
+		transport string
+		expected  string
+		wantErr   bool
+	}{
+		{name: "configured address", protocol: ProtocolJSON, transport: "", expected: "localhost:8081"},
+		{name: "tcp address over tcp", protocol: ProtocolJSON, transport: TransportTCP, expected: "localhost:8081"},
+		{name: "tcp address over unix", protocol: ProtocolJSON, transport: TransportUnix, expected: "unix:///tmp/triprotocol/json.sock"},
+		{name: "unix address over unix", protocol: ProtocolString, transport: TransportUnix, expected: "unix:///run/string.sock"},
+		{name: "tcp address over loopback", protocol: ProtocolJSON, transport: TransportLoopback, expected: "loopback://json"},
+		{name: "unix address over loopback", protocol: ProtocolString, transport: TransportLoopback, expected: "loopback://string"},
+		{name: "unix address over tcp", protocol: ProtocolString, transport: TransportTCP, wantErr: true},
+		{name: "unknown transport", protocol: ProtocolJSON, transport: "udp", wantErr: true},
+		{name: "unknown protocol", protocol: "xml", transport: TransportUnix, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			actual, err := settings.TransportAddress(tt.protocol, tt.transport)
+
+			// Assert
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, actual)
+		})
+	}
+}
+
+// serveChunks replies to a single request with chunks, keeping the connection open until the test
+// ends unless closeAfter is set
+func serveChunks(t *testing.T, closeAfter bool, chunks ...[]byte) string {
+	t.Helper()
+
+	listener, err := Listen("127.0.0.1:0")
+	require.NoError(t, err)
+	done := make(chan struct{})
+	t.Cleanup(func() {
+		close(done)
+		listener.Close()
+	})
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		conn.Read(make([]byte, 1024))
+		for _, chunk := range chunks {
+			conn.Write(chunk)
+			time.Sleep(10 * time.Millisecond)
+		}
+		if !closeAfter {
+			<-done
+		}
+	}()
+
+	return listenerAddress(listener)
+}
+
+func TestTCPRoundTripperReadsWholeReplies(t *testing.T) {
+	large := bytes.Repeat([]byte("ab"), 50*1024)
+	framed := binary.BigEndian.AppendUint32(nil, uint32(len(large)))
+	framed = append(framed, large...)
+	zeros := []byte{0, 0, 0, 3, 7, 0, 0}
+
+	tests := []struct {
+		name     string
+		serde    Serde
+		close    bool
+		chunks   [][]byte
+		expected []byte
+		wantErr  error
+	}{
+		{name: "trailing zero bytes", serde: ProtobufSerde{}, chunks: [][]byte{zeros}, expected: zeros},
+		{name: "frame split over reads", serde: ProtobufSerde{}, chunks: [][]byte{framed[:2], framed[2 : 64*1024+10], framed[64*1024+10:]}, expected: framed},
+		{name: "string reply", serde: StringSerde{}, chunks: [][]byte{[]byte("OK|ok=tr"), []byte("ue|FIM\n")}, expected: []byte("OK|ok=true|FIM\n")},
+		{name: "json reply", serde: JSONSerde{}, chunks: [][]byte{[]byte(`{"sucesso": `), []byte(`{"a": "}"}}`)}, expected: []byte(`{"sucesso": {"a": "}"}}`)},
+		{name: "without a framer until closed", close: true, chunks: [][]byte{large[:70*1024], large[70*1024:]}, expected: large},
+		{name: "closed inside a frame", serde: ProtobufSerde{}, close: true, chunks: [][]byte{framed[:100]}, wantErr: io.ErrUnexpectedEOF},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			address := serveChunks(t, tt.close, tt.chunks...)
+			ctx := context.Background()
+			if tt.serde != nil {
+				ctx = withReplyFramer(ctx, tt.serde)
+			}
+
+			// Act
+			actual, err := NewTCPRoundTripper(time.Second, time.Second, time.Second).RequestReply(ctx, address, []byte("req"))
+
+			// Assert
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, actual)
+		})
+	}
+}
+
+func TestTCPRoundTripperUnixSocket(t *testing.T) {
+	for _, fixture := range appLayerFlowFixtures(t) {
+		t.Run(fixture.protocol, func(t *testing.T) {
+			// Arrange
+			server := newMockServerAt(t, UnixAddress(newSocketPath(t, fixture.protocol+".sock")), fixture.protocol,
+				mockExchange{Expect: fixture.authRequest, Reply: fixture.authReply},
+				mockExchange{Expect: fixture.echoRequest, Reply: fixture.echoReply},
+			)
+			client := newMockClient(t, fixture.protocol, time.Second)
+			ctx := context.Background()
+
+			// Act
+			authResp, authErr := client.Auth(ctx, server.Address(), &AuthRequest{
+				StudentID: "538349",
+				Timestamp: time.Date(2025, 10, 10, 14, 30, 0, 0, time.UTC),
+			})
+			require.NoError(t, authErr, "Auth should not return an error")
+
+			echoResp := &EchoResponse{}
+			echoErr := client.Do(ctx, server.Address(), EchoRequest{Message: "ola mundo"}, echoResp, authResp.Token)
+
+			// Assert
+			assert.Regexp(t, `^unix:///`, server.Address())
+			assert.Equal(t, "tokenauth", authResp.Token)
+			require.NoError(t, echoErr, "Do should not return an error")
+			assert.Equal(t, "ECO: ola mundo", echoResp.EchoMessage)
+
+			server.AssertExhausted(t)
+		})
+	}
+}
+
+func TestTCPRoundTripperMissingUnixSocket(t *testing.T) {
+	// Arrange
+	roundTripper := NewTCPRoundTripper(time.Second, time.Second, time.Second)
+
+	// Act
+	_, err := roundTripper.RequestReply(context.Background(), UnixAddress(newSocketPath(t, "missing.sock")), []byte("{}"))
+
+	// Assert
+	assert.Error(t, err)
+}
+
+func TestTLSRoundTripperUnixSocket(t *testing.T) {
+	// Arrange
+	files := newTestCertificates(t)
+	reply := []byte(`{"sucesso": true}`)
+
+	listener, err := NewTLSListener(UnixAddress(newSocketPath(t, "tls.sock")), files.ServerSettings())
+	require.NoError(t, err)
+	server := startMockServer(t, listener, ProtocolJSON, []mockExchange{{Reply: reply}})
+
+	// Act
+	resp, timing, err := newTestTLSRoundTripper(t, files.ClientSettings()).RequestReplyTimed(context.Background(), server.Address(), []byte(`{}`))
+
+	// Assert
+	require.NoError(t, err, "unix sockets are verified as localhost")
+	assert.Equal(t, reply, resp)
+	assert.Positive(t, timing.Handshake)
+}
+
+func TestLoadConfigServerAddresses(t *testing.T) {
+	tests := []struct {
+		name    string
+		address string
+		wantErr bool
+	}{
+		{name: "host and port", address: "localhost:9000"},
+		{name: "unix socket", address: "unix:///tmp/json.sock"},
+		{name: "relative unix socket", address: "unix://json.sock", wantErr: true},
+		{name: "missing port", address: "localhost", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			t.Setenv("TUI_APP_JSONPROTOCOLSERVERADDRESS", tt.address)
+
+			// Act
+			settings, err := LoadConfig[Settings]("TUI", BaseSettings)
+
+			// Assert
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.address, settings.App.JSONProtocolServerAddress)
+		})
+	}
+}
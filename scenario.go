@@ -0,0 +1,147 @@
+package main
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/spf13/viper"
+)
+
+const scenarioAlphabet = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789 "
+
+// IntRange is an inclusive range used by the scenario parameter generators.
+// A zero range means the operation default is used.
+type IntRange struct {
+	Min int `mapstructure:"min" json:"min" validate:"gte=0"`
+	Max int `mapstructure:"max" json:"max" validate:"gtefield=Min"`
+}
+
+func (r IntRange) pick(rng *rand.Rand, defaultMin, defaultMax int) int {
+	minValue, maxValue := r.Min, r.Max
+	if minValue == 0 && maxValue == 0 {
+		minValue, maxValue = defaultMin, defaultMax
+	}
+
+	return minValue + rng.IntN(maxValue-minValue+1)
+}
+
+type ScenarioOperation struct {
+	Name          string   `mapstructure:"name" json:"name" validate:"required,oneof=echo soma timestamp historico status"`
+	Weight        int      `mapstructure:"weight" json:"weight" validate:"gte=1"`
+	MessageLength IntRange `mapstructure:"message-length" json:"message_length"`
+	ListSize      IntRange `mapstructure:"list-size" json:"list_size"`
+	NumberValue   IntRange `mapstructure:"number-value" json:"number_value"`
+	Limit         IntRange `mapstructure:"limit" json:"limit"`
+	DetailedRatio float64  `mapstructure:"detailed-ratio" json:"detailed_ratio" validate:"gte=0,lte=1"`
+}
+
+// NewRequest generates a random request for the operation using its parameter generators
+func (o ScenarioOperation) NewRequest(rng *rand.Rand) OperationRequest {
+	switch o.Name {
+	case OperationEcho:
+		length := o.MessageLength.pick(rng, 1, 64)
+
+		var sb strings.Builder
+		sb.Grow(length)
+		for range length {
+			sb.WriteByte(scenarioAlphabet[rng.IntN(len(scenarioAlphabet))])
+		}
+
+		return EchoRequest{Message: sb.String()}
+	case OperationSum:
+		size := min(max(o.ListSize.pick(rng, 1, 1000), 1), 1000)
+
+		numbers := make([]int, size)
+		for i := range numbers {
+			numbers[i] = o.NumberValue.pick(rng, 0, 1000)
+		}
+
+		return SumRequest{Numbers: numbers}
+	case OperationHistory:
+		return HistoryRequest{Limit: min(max(o.Limit.pick(rng, 1, 100), 1), 100)}
+	case OperationStatus:
+		return StatusRequest{Detailed: rng.Float64() < o.DetailedRatio}
+	default:
+		return TimestampRequest{}
+	}
+}
+
+type Scenario struct {
+	Name        string              `mapstructure:"name" json:"name" validate:"required"`
+	Description string              `mapstructure:"description" json:"description"`
+	Protocols   []string            `mapstructure:"protocols" json:"protocols" validate:"min=1,dive,oneof=json string protobuf msgpack cbor"`
+	Operations  []ScenarioOperation `mapstructure:"operations" json:"operations" validate:"min=1,dive"`
+	StudentID   string              `mapstructure:"student-id" json:"student_id" validate:"required"`
+	Duration    time.Duration       `mapstructure:"duration" json:"duration" validate:"gt=0s"`
+	Warmup      time.Duration       `mapstructure:"warmup" json:"warmup" validate:"gte=0s"`
+	ThinkTime   time.Duration       `mapstructure:"think-time" json:"think_time" validate:"gte=0s"`
+	Concurrency int                 `mapstructure:"concurrency" json:"concurrency" validate:"gte=1"`
+	Seed        uint64              `mapstructure:"seed" json:"seed"`
+	Codec       string              `mapstructure:"codec" json:"codec" validate:"omitempty,oneof=reflect generated"`
+	Transports  []string            `mapstructure:"transports" json:"transports,omitempty" validate:"unique,dive,oneof=tcp unix loopback"`
+
+	// Compression maps protocols to the codec their messages are compressed with, see CompressedSerde
+	Compression map[string]string `mapstructure:"compression" json:"compression,omitempty" validate:"dive,keys,oneof=json string protobuf msgpack cbor,endkeys,oneof=none gzip zstd snappy"`
+
+	// Encryption maps protocols to the cipher their messages are sealed with, see EncryptedSerde
+	Encryption map[string]string `mapstructure:"encryption" json:"encryption,omitempty" validate:"dive,keys,oneof=json string protobuf msgpack cbor,endkeys,oneof=none aes-gcm chacha20-poly1305"`
+
+	// CBORDeterministic sends CBOR in the core deterministic encoding, see CBORSerde
+	CBORDeterministic bool `mapstructure:"cbor-deterministic" json:"cbor_deterministic,omitempty"`
+}
+
+// PickOperation chooses one of the scenario operations according to their weights
+func (s *Scenario) PickOperation(rng *rand.Rand) ScenarioOperation {
+	total := 0
+	for _, op := range s.Operations {
+		total += op.Weight
+	}
+
+	n := rng.IntN(total)
+	for _, op := range s.Operations {
+		if n < op.Weight {
+			return op
+		}
+		n -= op.Weight
+	}
+
+	return s.Operations[len(s.Operations)-1]
+}
+
+func LoadScenario(path string) (*Scenario, error) {
+	v := viper.New()
+	v.SetConfigFile(path)
+	v.SetDefault("concurrency", 1)
+	v.SetDefault("student-id", defaultEnrollmentID)
+
+	if err := v.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("failed to read scenario %s: %w", path, err)
+	}
+
+	var scenario Scenario
+	if err := v.Unmarshal(&scenario); err != nil {
+		return nil, err
+	}
+
+	for i := range scenario.Operations {
+		if scenario.Operations[i].Weight == 0 {
+			scenario.Operations[i].Weight = 1
+		}
+	}
+
+	if err := scenario.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &scenario, nil
+}
+
+// Validate checks the scenario like LoadScenario does, for scenarios built in code
+func (s *Scenario) Validate() error {
+	validate := validator.New(validator.WithRequiredStructEnabled())
+
+	return validate.Struct(s)
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeScenario(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "scenario.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+
+	return path
+}
+
+func TestLoadScenario(t *testing.T) {
+	scenario, err := LoadScenario("scenarios/mixed.yaml")
+
+	require.NoError(t, err, "LoadScenario should not return an error")
+	assert.Equal(t, "mixed", scenario.Name)
+	assert.Equal(t, []string{"json", "string", "protobuf"}, scenario.Protocols)
+	assert.Equal(t, 30*time.Second, scenario.Duration)
+	assert.Equal(t, 5*time.Second, scenario.Warmup)
+	assert.Equal(t, 50*time.Millisecond, scenario.ThinkTime)
+	assert.Equal(t, 4, scenario.Concurrency)
+	assert.Len(t, scenario.Operations, 5)
+	assert.Equal(t, IntRange{Min: 1, Max: 1000}, scenario.Operations[1].ListSize)
+}
+
+func TestLoadScenarioTransports(t *testing.T) {
+	scenario, err := LoadScenario("scenarios/transports.yaml")
+
+	require.NoError(t, err, "LoadScenario should not return an error")
+	assert.Equal(t, []string{TransportTCP, TransportUnix}, scenario.Transports)
+}
+
+func TestLoadScenarioLoopback(t *testing.T) {
+	scenario, err := LoadScenario("scenarios/loopback.yaml")
+
+	require.NoError(t, err, "LoadScenario should not return an error")
+	assert.Equal(t, []string{TransportLoopback}, scenario.Transports)
+	assert.Equal(t, Protocols, scenario.Protocols)
+}
+
+func TestLoadScenarioValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{
+			name:    "Missing name",
+			content: "protocols: [json]\nduration: 1s\noperations:\n  - name: echo\n",
+		},
+		{
+			name:    "Unknown protocol",
+			content: "name: x\nprotocols: [xml]\nduration: 1s\noperations:\n  - name: echo\n",
+		},
+		{
+			name:    "Unknown operation",
+			content: "name: x\nprotocols: [json]\nduration: 1s\noperations:\n  - name: divide\n",
+		},
+		{
+			name:    "Missing duration",
+			content: "name: x\nprotocols: [json]\noperations:\n  - name: echo\n",
+		},
+		{
+			name:    "No operations",
+			content: "name: x\nprotocols: [json]\nduration: 1s\n",
+		},
+		{
+			name:    "Inverted range",
+			content: "name: x\nprotocols: [json]\nduration: 1s\noperations:\n  - name: soma\n    list-size: {min: 10, max: 1}\n",
+		},
+		{
+			name:    "Detailed ratio out of bounds",
+			content: "name: x\nprotocols: [json]\nduration: 1s\noperations:\n  - name: status\n    detailed-ratio: 2\n",
+		},
+		{
+			name:    "Unknown codec",
+			content: "name: x\nprotocols: [json]\nduration: 1s\ncodec: jit\noperations:\n  - name: echo\n",
+		},
+		{
+			name:    "Unknown transport",
+			content: "name: x\nprotocols: [json]\nduration: 1s\ntransports: [udp]\noperations:\n  - name: echo\n",
+		},
+		{
+			name:    "Repeated transport",
+			content: "name: x\nprotocols: [json]\nduration: 1s\ntransports: [unix, unix]\noperations:\n  - name: echo\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadScenario(writeScenario(t, tt.content))
+
+			assert.Error(t, err, "LoadScenario should reject invalid scenarios")
+		})
+	}
+}
+
+func TestScenarioOperationNewRequest(t *testing.T) {
+	rng := rand.New(rand.NewPCG(1, 2))
+
+	echo := ScenarioOperation{Name: OperationEcho, MessageLength: IntRange{Min: 10, Max: 20}}
+	sum := ScenarioOperation{Name: OperationSum, ListSize: IntRange{Min: 1, Max: 5000}}
+	history := ScenarioOperation{Name: OperationHistory}
+
+	for range 100 {
+		echoReq := echo.NewRequest(rng).(EchoRequest)
+		assert.GreaterOrEqual(t, len(echoReq.Message), 10)
+		assert.LessOrEqual(t, len(echoReq.Message), 20)
+
+		sumReq := sum.NewRequest(rng).(SumRequest)
+		assert.GreaterOrEqual(t, len(sumReq.Numbers), 1)
+		assert.LessOrEqual(t, len(sumReq.Numbers), 1000, "soma lists are capped to the server limit")
+
+		historyReq := history.NewRequest(rng).(HistoryRequest)
+		assert.GreaterOrEqual(t, historyReq.Limit, 1)
+		assert.LessOrEqual(t, historyReq.Limit, 100)
+	}
+}
+
+func TestScenarioPickOperation(t *testing.T) {
+	scenario := Scenario{
+		Operations: []ScenarioOperation{
+			{Name: OperationEcho, Weight: 3},
+			{Name: OperationTimestamp, Weight: 1},
+		},
+	}
+	rng := rand.New(rand.NewPCG(1, 2))
+
+	counts := map[string]int{}
+	for range 4000 {
+		counts[scenario.PickOperation(rng).Name]++
+	}
+
+	assert.InDelta(t, 3000, counts[OperationEcho], 200)
+	assert.InDelta(t, 1000, counts[OperationTimestamp], 200)
+}
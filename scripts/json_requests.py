@@ -1,11 +1,27 @@
 #!/usr/bin/env python3
 
+import argparse
 import socket
 import json
 
-host = "3.88.99.255"
-port = 8081
-aluno_id = "538349"
+parser = argparse.ArgumentParser(description="Runs operations against a JSON protocol server")
+parser.add_argument("host", nargs="?", default="3.88.99.255")
+parser.add_argument("port", nargs="?", type=int, default=8081)
+parser.add_argument("aluno_id", nargs="?", default="538349")
+parser.add_argument(
+    "operacao", nargs="?", help="operation to run, the sample operations when missing"
+)
+parser.add_argument(
+    "parametros",
+    nargs="?",
+    default="{}",
+    help='parameters of the operation as a JSON object, like {"numeros": [1, 2, 3]}',
+)
+args = parser.parse_args()
+
+host = args.host
+port = args.port
+aluno_id = args.aluno_id
 
 
 def tcp_request(message: str) -> str:
@@ -37,11 +53,14 @@ auth_request = json.dumps({"tipo": "autenticar", "aluno_id": aluno_id})
 auth_response = json.loads(tcp_request(auth_request))
 token = auth_response["token"]
 
-do_operation("echo", token, mensagem="ola mundo")
-do_operation("soma", token, numeros=[1, 2, 3])
-do_operation("timestamp", token)
-do_operation("status", token, detalhado=True)
-do_operation("historico", token, limite=2)
+if args.operacao:
+    do_operation(args.operacao, token, **json.loads(args.parametros))
+else:
+    do_operation("echo", token, mensagem="ola mundo")
+    do_operation("soma", token, numeros=[1, 2, 3])
+    do_operation("timestamp", token)
+    do_operation("status", token, detalhado=True)
+    do_operation("historico", token, limite=2)
 
 logout_request = json.dumps({"tipo": "logout", "token": token})
 tcp_request(logout_request)
@@ -1,14 +1,31 @@
 #!/usr/bin/env python3
 
+import argparse
+import json
 import socket
 import triprotocol_pb2
 import struct
 from datetime import datetime
 
 
-host = "3.88.99.255"
-port = 8082
-aluno_id = "538349"
+parser = argparse.ArgumentParser(description="Runs operations against a Protobuf protocol server")
+parser.add_argument("host", nargs="?", default="3.88.99.255")
+parser.add_argument("port", nargs="?", type=int, default=8082)
+parser.add_argument("aluno_id", nargs="?", default="538349")
+parser.add_argument(
+    "operacao", nargs="?", help="operation to run, the sample operations when missing"
+)
+parser.add_argument(
+    "parametros",
+    nargs="?",
+    default="{}",
+    help='parameters of the operation as a JSON object, like {"numeros": "1,2,3"}',
+)
+args = parser.parse_args()
+
+host = args.host
+port = args.port
+aluno_id = args.aluno_id
 
 
 def tcp_request(message: bytes) -> bytes:
@@ -45,61 +62,72 @@ token = auth_response.ok.dados["token"]
 
 print(f"Received token: {token}\n")
 
-do_request(
-    triprotocol_pb2.Requisicao(
-        operacao=triprotocol_pb2.ComandoOperacao(
-            operacao="echo",
-            parametros={"mensagem": "Hello, World!"},
-            token=token,
+if args.operacao:
+    do_request(
+        triprotocol_pb2.Requisicao(
+            operacao=triprotocol_pb2.ComandoOperacao(
+                operacao=args.operacao,
+                parametros=json.loads(args.parametros),
+                token=token,
+            )
+        )
+    )
+else:
+    do_request(
+        triprotocol_pb2.Requisicao(
+            operacao=triprotocol_pb2.ComandoOperacao(
+                operacao="echo",
+                parametros={"mensagem": "Hello, World!"},
+                token=token,
+            )
         )
     )
-)
 
-do_request(
-    triprotocol_pb2.Requisicao(
-        operacao=triprotocol_pb2.ComandoOperacao(
-            operacao="soma",
-            parametros={
-                "numeros": "1,2,3",
-            },
-            token=token,
+    do_request(
+        triprotocol_pb2.Requisicao(
+            operacao=triprotocol_pb2.ComandoOperacao(
+                operacao="soma",
+                parametros={
+                    "numeros": "1,2,3",
+                },
+                token=token,
+            )
         )
     )
-)
 
-do_request(
-    triprotocol_pb2.Requisicao(
-        operacao=triprotocol_pb2.ComandoOperacao(
-            operacao="timestamp",
-            parametros={},
-            token=token,
+    do_request(
+        triprotocol_pb2.Requisicao(
+            operacao=triprotocol_pb2.ComandoOperacao(
+                operacao="timestamp",
+                parametros={},
+                token=token,
+            )
         )
     )
-)
 
-do_request(
-    triprotocol_pb2.Requisicao(
-        operacao=triprotocol_pb2.ComandoOperacao(
-            operacao="status",
-            parametros={
-                "detalhado": "true",
-            },
-            token=token,
+    do_request(
+        triprotocol_pb2.Requisicao(
+            operacao=triprotocol_pb2.ComandoOperacao(
+                operacao="status",
+                parametros={
+                    "detalhado": "true",
+                },
+                token=token,
+            )
         )
     )
-)
 
-do_request(
-    triprotocol_pb2.Requisicao(
-        operacao=triprotocol_pb2.ComandoOperacao(
-            operacao="historico",
-            parametros={
-                "limite": "1",
-            },
-            token=token,
+    do_request(
+        triprotocol_pb2.Requisicao(
+            operacao=triprotocol_pb2.ComandoOperacao(
+                operacao="historico",
+                parametros={
+                    "limite": "1",
+                },
+                token=token,
+            )
         )
     )
-)
 
 do_request(
     triprotocol_pb2.Requisicao(
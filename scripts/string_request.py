@@ -1,11 +1,28 @@
 #!/usr/bin/env python3
 
+import argparse
+import json
 import socket
 import re
 
-host = "3.88.99.255"
-port = 8080
-aluno_id = 538349
+parser = argparse.ArgumentParser(description="Runs operations against a String protocol server")
+parser.add_argument("host", nargs="?", default="3.88.99.255")
+parser.add_argument("port", nargs="?", type=int, default=8080)
+parser.add_argument("aluno_id", nargs="?", default="538349")
+parser.add_argument(
+    "operacao", nargs="?", help="operation to run, the sample operations when missing"
+)
+parser.add_argument(
+    "parametros",
+    nargs="?",
+    default="{}",
+    help='parameters of the operation as a JSON object, like {"nums": "1,2,3"}',
+)
+args = parser.parse_args()
+
+host = args.host
+port = args.port
+aluno_id = args.aluno_id
 
 
 def tcp_request(message: str) -> str:
@@ -43,11 +60,15 @@ if not match:
 token = match.group(1)
 
 
-do_operation("echo", token, mensagem="ola mundo")
-do_operation("soma", token, nums="1,2,3")
-do_operation("timestamp", token)
-do_operation("status", token, detalhado=True)
-do_operation("historico", token, limite=2)
-
 logout_request = f"LOGOUT|token={token}|FIM"
-# tcp_request(logout_request)
+
+if args.operacao:
+    do_operation(args.operacao, token, **json.loads(args.parametros))
+    tcp_request(logout_request)
+else:
+    do_operation("echo", token, mensagem="ola mundo")
+    do_operation("soma", token, nums="1,2,3")
+    do_operation("timestamp", token)
+    do_operation("status", token, detalhado=True)
+    do_operation("historico", token, limite=2)
+    # tcp_request(logout_request)
@@ -16,8 +16,30 @@ var (
 	_ Serde = (*StringSerde)(nil)
 	_ Serde = (*JSONSerde)(nil)
 	_ Serde = (*ProtobufSerde)(nil)
+	_ Serde = (*MsgpackSerde)(nil)
+	_ Serde = (*CBORSerde)(nil)
+	_ Serde = (*CompressedSerde)(nil)
+	_ Serde = (*EncryptedSerde)(nil)
 )
 
+// serdeLayer finds the first T in the chain of decorators wrapping serde, like errors.As does
+func serdeLayer[T Serde](serde Serde) (T, bool) {
+	for serde != nil {
+		if layer, ok := serde.(T); ok {
+			return layer, true
+		}
+
+		wrapper, ok := serde.(interface{ Unwrap() Serde })
+		if !ok {
+			break
+		}
+		serde = wrapper.Unwrap()
+	}
+
+	var zero T
+	return zero, false
+}
+
 type (
 	SerdeMarshall  = func(v any) ([]byte, error)
 	SerdeUnmarshal = func(data []byte, v any) error
@@ -57,3 +79,63 @@ func copyStruct(src, dst any) error {
 	}
 	return nil
 }
+
+// responseValue returns the struct v points to, making sure it has the fields every serde binds
+func responseValue(v any) (reflect.Value, error) {
+	value := reflect.ValueOf(v)
+
+	if value.Kind() != reflect.Pointer {
+		return reflect.Value{}, fmt.Errorf("pointers are supported, found %s", value.Kind().String())
+	}
+
+	if value.IsNil() {
+		return reflect.Value{}, fmt.Errorf("nil pointer provided")
+	}
+
+	value = value.Elem()
+	if value.Kind() != reflect.Struct {
+		return reflect.Value{}, fmt.Errorf("expected a pointer to a presentation layer response, found %s", value.Type().String())
+	}
+
+	for _, name := range []string{"Body", "Err", "StatusCode"} {
+		if !value.FieldByName(name).IsValid() {
+			return reflect.Value{}, fmt.Errorf("expected a presentation layer response, %s has no %s field", value.Type().String(), name)
+		}
+	}
+
+	return value, nil
+}
+
+// responseBody returns the settable struct behind the Body field of a response, allocating it when nil
+func responseBody(value reflect.Value) (reflect.Value, error) {
+	bodyField := value.FieldByName("Body")
+
+	if bodyField.Kind() != reflect.Pointer && bodyField.Kind() != reflect.Interface {
+		return reflect.Value{}, fmt.Errorf("body field is not a pointer or interface: %v", bodyField.Kind())
+	}
+
+	if bodyField.Kind() == reflect.Interface {
+		if bodyField.IsNil() {
+			return reflect.Value{}, fmt.Errorf("body interface is nil, set it to the response to bind")
+		}
+		bodyField = bodyField.Elem()
+	}
+
+	if bodyField.Kind() != reflect.Pointer {
+		return reflect.Value{}, fmt.Errorf("body must hold a pointer, found %v", bodyField.Kind())
+	}
+
+	if bodyField.IsNil() {
+		if !bodyField.CanSet() {
+			return reflect.Value{}, fmt.Errorf("body holds a nil pointer that cannot be set")
+		}
+		bodyField.Set(reflect.New(bodyField.Type().Elem()))
+	}
+
+	bodyField = bodyField.Elem()
+	if bodyField.Kind() != reflect.Struct {
+		return reflect.Value{}, fmt.Errorf("body must point to a struct, found %v", bodyField.Kind())
+	}
+
+	return bodyField, nil
+}
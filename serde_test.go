@@ -2,6 +2,7 @@ package main
 
 import (
 	"encoding/binary"
+	"errors"
 	"testing"
 	"time"
 )
@@ -196,3 +197,73 @@ func BenchmarkUnmarshallProtocols(b *testing.B) {
 		})
 	}
 }
+
+func BenchmarkSweepMarshallProtocols(b *testing.B) {
+	for _, c := range SweepRequestCases() {
+		for _, protocol := range Protocols {
+			for _, codec := range Codecs {
+				serde, err := NewCodecSerde(protocol, codec)
+				if errors.Is(err, ErrNoGeneratedCodec) {
+					continue
+				}
+				if err != nil {
+					b.Fatalf("Error creating serde: %v", err)
+				}
+
+				b.Run(c.Name()+"/"+protocol+"-"+codec, func(b *testing.B) {
+					b.ReportAllocs()
+
+					var buf []byte
+					for b.Loop() {
+						// Act
+						buf, err = serde.Marshal(c.Request)
+
+						// Assert
+						if err != nil {
+							b.Fatalf("Error marshalling: %v", err)
+						}
+					}
+
+					b.ReportMetric(float64(len(buf)), "wire-B/op")
+				})
+			}
+		}
+	}
+}
+
+func BenchmarkSweepUnmarshallProtocols(b *testing.B) {
+	for _, c := range SweepResponseCases() {
+		for _, protocol := range Protocols {
+			for _, codec := range Codecs {
+				serde, err := NewCodecSerde(protocol, codec)
+				if errors.Is(err, ErrNoGeneratedCodec) {
+					continue
+				}
+				if err != nil {
+					b.Fatalf("Error creating serde: %v", err)
+				}
+
+				// Arrange
+				data, err := encodeSweepResponse(protocol, c)
+				if err != nil {
+					b.Fatalf("Error encoding response: %v", err)
+				}
+
+				b.Run(c.Name()+"/"+protocol+"-"+codec, func(b *testing.B) {
+					b.ReportAllocs()
+					b.ReportMetric(float64(len(data)), "wire-B/op")
+
+					for b.Loop() {
+						// Act
+						err := decodeSweepResponse(serde, data, c)
+
+						// Assert
+						if err != nil {
+							b.Fatalf("Error unmarshalling: %v", err)
+						}
+					}
+				})
+			}
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"sync"
+	"time"
+)
+
+// ProtocolServer serves a LoopbackServer on a listener, one request and reply per connection like
+// TCPRoundTripper expects
+type ProtocolServer struct {
+	Listener net.Listener
+	Server   *LoopbackServer
+	Framer   ReplyFramer
+	Timeout  time.Duration
+}
+
+// NewProtocolServer answers the requests of serde on listener, serde must frame its messages
+func NewProtocolServer(listener net.Listener, serde Serde, timeout time.Duration) (*ProtocolServer, error) {
+	serverSerde, ok := serde.(ServerSerde)
+	if !ok {
+		return nil, fmt.Errorf("the %T serde cannot serve requests", serde)
+	}
+
+	framer, ok := serde.(ReplyFramer)
+	if !ok {
+		return nil, fmt.Errorf("the %T serde does not frame its requests", serde)
+	}
+
+	return &ProtocolServer{
+		Listener: listener,
+		Server:   NewLoopbackServer(serverSerde),
+		Framer:   framer,
+		Timeout:  timeout,
+	}, nil
+}
+
+// Address is the address clients dial to reach the server
+func (s *ProtocolServer) Address() string {
+	return listenerAddress(s.Listener)
+}
+
+// Serve accepts connections until ctx is done, then waits for the open ones
+func (s *ProtocolServer) Serve(ctx context.Context) error {
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	stop := context.AfterFunc(ctx, func() { s.Listener.Close() })
+	defer stop()
+
+	for {
+		conn, err := s.Listener.Accept()
+		if err != nil {
+			if ctx.Err() != nil || errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer conn.Close()
+			s.handle(ctx, conn)
+		}()
+	}
+}
+
+func (s *ProtocolServer) handle(ctx context.Context, conn net.Conn) {
+	conn.SetDeadline(time.Now().Add(s.Timeout))
+
+	req, err := readFramed(conn, s.Framer)
+	if err != nil {
+		slog.WarnContext(ctx, "Error reading request", slog.String("remote", conn.RemoteAddr().String()), slog.String("error", err.Error()))
+		return
+	}
+
+	reply, err := s.Server.Handle(ctx, req)
+	if err != nil {
+		slog.ErrorContext(ctx, "Error answering request", slog.String("error", err.Error()))
+		return
+	}
+
+	if _, err := conn.Write(reply); err != nil {
+		slog.WarnContext(ctx, "Error writing reply", slog.String("remote", conn.RemoteAddr().String()), slog.String("error", err.Error()))
+	}
+}
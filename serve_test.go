@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startProtocolServer serves the protocol on listener until the test ends
+func startProtocolServer(t *testing.T, listener net.Listener, serde Serde) *ProtocolServer {
+	t.Helper()
+
+	server, err := NewProtocolServer(listener, serde, time.Second)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- server.Serve(ctx) }()
+	t.Cleanup(func() {
+		cancel()
+		assert.NoError(t, <-done, "Serve should stop cleanly once its context is done")
+	})
+
+	return server
+}
+
+func TestProtocolServerFlow(t *testing.T) {
+	files := newTestCertificates(t)
+
+	tests := []struct {
+		name        string
+		listen      func(t *testing.T, protocol string) (net.Listener, error)
+		compression string
+		tls         bool
+	}{
+		{name: "tcp", listen: func(t *testing.T, protocol string) (net.Listener, error) { return Listen("127.0.0.1:0") }},
+		{name: "unix", listen: func(t *testing.T, protocol string) (net.Listener, error) {
+			return Listen(UnixAddress(newSocketPath(t, protocol+".sock")))
+		}},
+		{name: "compressed", compression: CompressionZstd, listen: func(t *testing.T, protocol string) (net.Listener, error) { return Listen("127.0.0.1:0") }},
+		{name: "tls", tls: true, listen: func(t *testing.T, protocol string) (net.Listener, error) {
+			return NewTLSListener("127.0.0.1:0", files.ServerSettings())
+		}},
+	}
+
+	for _, tt := range tests {
+		for _, protocol := range Protocols {
+			t.Run(tt.name+"/"+protocol, func(t *testing.T) {
+				// Arrange
+				compression := CompressionNone
+				if tt.compression != "" {
+					compression = tt.compression
+				}
+				serverSerde, _, err := newClientSerde(protocol, compression)
+				require.NoError(t, err)
+				serde, _, err := newClientSerde(protocol, compression)
+				require.NoError(t, err)
+
+				listener, err := tt.listen(t, protocol)
+				require.NoError(t, err)
+				server := startProtocolServer(t, listener, serverSerde)
+
+				var roundTripper RoundTripper = NewTCPRoundTripper(time.Second, time.Second, time.Second)
+				if tt.tls {
+					roundTripper = newTestTLSRoundTripper(t, files.ClientSettings())
+				}
+				client := NewAppLayerClient[OperationRequest, OperationResponse](serde, roundTripper, &AppSettings{})
+				ctx := context.Background()
+
+				// Act
+				authResp, err := client.Auth(ctx, server.Address(), &AuthRequest{StudentID: "538349", Timestamp: time.Now()})
+				require.NoError(t, err, "Auth should not return an error")
+
+				sumResp := &SumResponse{}
+				sumErr := client.Do(ctx, server.Address(), SumRequest{Numbers: []int{4, 1, 7}}, sumResp, authResp.Token)
+				historyResp := &HistoryResponse{}
+				historyErr := client.Do(ctx, server.Address(), HistoryRequest{Limit: 5}, historyResp, authResp.Token)
+
+				// Assert
+				require.NoError(t, sumErr)
+				assert.Equal(t, 12.0, sumResp.Sum)
+				require.NoError(t, historyErr)
+				require.Len(t, historyResp.History, 1)
+				assert.Equal(t, OperationSum, historyResp.History[0].Operation)
+			})
+		}
+	}
+}
+
+func TestProtocolServerRejectsIncompleteRequests(t *testing.T) {
+	// Arrange
+	listener, err := Listen("127.0.0.1:0")
+	require.NoError(t, err)
+	server := startProtocolServer(t, listener, &ProtobufSerde{})
+
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	// Act
+	_, err = conn.Write([]byte{0, 0, 0, 9, 1})
+	require.NoError(t, err)
+	require.NoError(t, conn.(*net.TCPConn).CloseWrite())
+	reply, readErr := readFramed(conn, nil)
+
+	// Assert
+	assert.NotEmpty(t, server.Address())
+	assert.Error(t, readErr, "the server should close the connection without replying")
+	assert.Empty(t, reply)
+}
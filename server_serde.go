@@ -0,0 +1,269 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ServerSerde is the server half of a Serde, it decodes requests and encodes responses
+type ServerSerde interface {
+	UnmarshalRequest(data []byte, req *PresentationLayerRequest) error
+	MarshalResponse(v any) ([]byte, error)
+}
+
+var (
+	_ ServerSerde = (*StringSerde)(nil)
+	_ ServerSerde = (*JSONSerde)(nil)
+	_ ServerSerde = (*ProtobufSerde)(nil)
+	_ ServerSerde = (*MsgpackSerde)(nil)
+	_ ServerSerde = (*CBORSerde)(nil)
+	_ ServerSerde = (*CompressedSerde)(nil)
+	_ ServerSerde = (*EncryptedSerde)(nil)
+)
+
+const (
+	authCommandName   = "AUTH"
+	logoutCommandName = "LOGOUT"
+)
+
+// newRequestBody returns an empty request to bind a command or operation
+func newRequestBody(name string) (OperationRequest, error) {
+	switch name {
+	case authCommandName:
+		return &AuthRequest{}, nil
+	case logoutCommandName:
+		return &LogoutRequest{}, nil
+	default:
+		return NewOperationRequest(name)
+	}
+}
+
+// decodeRequestBody binds the request named name with bind and returns it by value, like clients send it
+func decodeRequestBody(name string, bind func(body reflect.Value) error) (OperationRequest, error) {
+	target, err := newRequestBody(name)
+	if err != nil {
+		return nil, err
+	}
+
+	body := reflect.ValueOf(target).Elem()
+	if err := bind(body); err != nil {
+		return nil, err
+	}
+
+	return body.Interface().(OperationRequest), nil
+}
+
+// serverResponse is a PresentationLayerResponse of any body type, as the server encoders see it
+type serverResponse struct {
+	body       reflect.Value
+	err        *PresentationLayerErrorResponse
+	statusCode int
+}
+
+func newServerResponse(v any) (serverResponse, error) {
+	value := reflect.ValueOf(v)
+	if value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			return serverResponse{}, fmt.Errorf("nil pointer provided")
+		}
+		value = value.Elem()
+	}
+
+	if value.Kind() != reflect.Struct {
+		return serverResponse{}, fmt.Errorf("only presentation layer responses are supported, found %s", value.Kind().String())
+	}
+
+	bodyField := value.FieldByName("Body")
+	errField := value.FieldByName("Err")
+	statusField := value.FieldByName("StatusCode")
+	if !bodyField.IsValid() || !errField.IsValid() || !statusField.IsValid() {
+		return serverResponse{}, fmt.Errorf("only presentation layer responses are supported, found %s", value.Type().String())
+	}
+
+	resp := serverResponse{statusCode: int(statusField.Int())}
+	resp.err, _ = errField.Interface().(*PresentationLayerErrorResponse)
+
+	for bodyField.Kind() == reflect.Interface || bodyField.Kind() == reflect.Pointer {
+		if bodyField.IsNil() {
+			break
+		}
+		bodyField = bodyField.Elem()
+	}
+
+	if bodyField.Kind() == reflect.Struct {
+		resp.body = bodyField
+	}
+
+	if !resp.failed() && !resp.body.IsValid() {
+		return serverResponse{}, fmt.Errorf("successful responses need a body")
+	}
+
+	return resp, nil
+}
+
+func (r serverResponse) failed() bool {
+	return r.err != nil || r.statusCode >= http.StatusBadRequest
+}
+
+func (r serverResponse) message() string {
+	if r.err == nil {
+		return http.StatusText(r.statusCode)
+	}
+	return r.err.Message
+}
+
+// timestamp returns the body timestamp, failures without a body are stamped with the current time
+func (r serverResponse) timestamp() NonISO8601Time {
+	if r.body.IsValid() {
+		if ts, ok := r.body.FieldByName("Timestamp").Interface().(NonISO8601Time); ok {
+			return ts
+		}
+	}
+
+	return NonISO8601Time{time.Now().UTC()}
+}
+
+// command returns the command a response answers, as the protobuf server names it
+func (r serverResponse) command() string {
+	if !r.body.IsValid() {
+		return "OPERACAO"
+	}
+
+	switch r.body.Interface().(type) {
+	case AuthResponse:
+		return authCommandName
+	case LogoutResponse:
+		return logoutCommandName
+	default:
+		return "OPERACAO"
+	}
+}
+
+type responseProperty struct {
+	Key   string
+	Value string
+}
+
+// responseProperties flattens a body into the key=value properties sent by the String and Protobuf
+// servers, in field order. Empty omitempty fields are left out.
+func responseProperties(body reflect.Value) ([]responseProperty, error) {
+	typ := body.Type()
+	properties := make([]responseProperty, 0, body.NumField())
+
+	for i := range body.NumField() {
+		field := body.Field(i)
+		fieldType := typ.Field(i)
+		if !fieldType.IsExported() {
+			continue
+		}
+
+		tagValues := strings.Split(getFieldTagValue(fieldType), ",")
+		omitEmpty := slices.Contains(tagValues, "omitempty") || strings.Contains(fieldType.Tag.Get("json"), "omitempty")
+		if omitEmpty && field.IsZero() {
+			continue
+		}
+
+		value, err := pythonFieldRepresentation(field)
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", fieldType.Name, err)
+		}
+
+		properties = append(properties, responseProperty{Key: tagValues[0], Value: value})
+	}
+
+	return properties, nil
+}
+
+// pythonFieldRepresentation renders a top level field the way the reference server prints it
+func pythonFieldRepresentation(field reflect.Value) (string, error) {
+	switch value := field.Interface().(type) {
+	case NonISO8601Time:
+		return value.Format(nonISO8601Layout), nil
+	case UnixTimestamp:
+		floatTimestamp := float64(value.Unix()) + float64(value.Nanosecond())/1e9
+		return strconv.FormatFloat(floatTimestamp, 'f', -1, 64), nil
+	case time.Time:
+		return value.Format(time.RFC3339), nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		return field.String(), nil
+	case reflect.Bool:
+		return formatPythonValue(field.Bool(), false), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(field.Int(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return formatPythonValue(field.Float(), false), nil
+	case reflect.Pointer, reflect.Interface:
+		if field.IsNil() {
+			return formatPythonValue(nil, false), nil
+		}
+		return pythonFieldRepresentation(field.Elem())
+	}
+
+	// Nested values go through JSON so they keep their json tags and time layouts
+	data, err := json.Marshal(field.Interface())
+	if err != nil {
+		return "", err
+	}
+
+	var generic any
+	d := json.NewDecoder(strings.NewReader(string(data)))
+	d.UseNumber()
+	if err := d.Decode(&generic); err != nil {
+		return "", err
+	}
+
+	return formatPythonValue(generic, true), nil
+}
+
+// formatPythonValue renders a value the way the reference python server prints it,
+// top level strings are written without quotes
+func formatPythonValue(v any, quoteStrings bool) string {
+	switch value := v.(type) {
+	case nil:
+		return "None"
+	case bool:
+		if value {
+			return "True"
+		}
+		return "False"
+	case string:
+		if quoteStrings {
+			return "'" + value + "'"
+		}
+		return value
+	case int:
+		return strconv.Itoa(value)
+	case json.Number:
+		return value.String()
+	case float64:
+		str := strconv.FormatFloat(value, 'f', -1, 64)
+		if !strings.ContainsAny(str, ".eE") {
+			str += ".0"
+		}
+		return str
+	case []any:
+		items := make([]string, 0, len(value))
+		for _, item := range value {
+			items = append(items, formatPythonValue(item, true))
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	case map[string]any:
+		items := make([]string, 0, len(value))
+		for _, key := range slices.Sorted(maps.Keys(value)) {
+			items = append(items, "'"+key+"': "+formatPythonValue(value[key], true))
+		}
+		return "{" + strings.Join(items, ", ") + "}"
+	default:
+		return fmt.Sprintf("%v", value)
+	}
+}
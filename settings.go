@@ -62,14 +62,47 @@ type OpenTelemetrySettings struct {
 	Interval int                         `mapstructure:"interval"`
 }
 
+// TLSSettings configure both ends of a TLS connection. Clients verify the server with the CA bundle
+// and present the certificate for mutual TLS, listeners present the certificate and require client
+// certificates signed by the CA bundle when it is set. SessionCache is the number of sessions a
+// client keeps for resumption, zero disables resumption on both ends.
+type TLSSettings struct {
+	Enabled      bool   `mapstructure:"enabled"`
+	CAFile       string `mapstructure:"ca-file" validate:"omitempty,file"`
+	CertFile     string `mapstructure:"cert-file" validate:"required_with=KeyFile,omitempty,file"`
+	KeyFile      string `mapstructure:"key-file" validate:"required_with=CertFile,omitempty,file"`
+	ServerName   string `mapstructure:"server-name"`
+	MinVersion   string `mapstructure:"min-version" validate:"omitempty,oneof=1.2 1.3"`
+	SessionCache int    `mapstructure:"session-cache" validate:"gte=0"`
+}
+
 type AppSettings struct {
-	Name                          string `mapstructure:"name"`
-	Version                       string `mapstructure:"version"`
-	Env                           string `mapstructure:"env"`
-	TCPTimeoutInSeconds           int    `mapstructure:"tcp-timeout-in-seconds" validate:"required"`
-	StringProtocolServerAddress   string `mapstructure:"string-protocol-server-address" validate:"required,hostname_port"`
-	JSONProtocolServerAddress     string `mapstructure:"json-protocol-server-address" validate:"required,hostname_port"`
-	ProtobufProtocolServerAddress string `mapstructure:"protobuf-protocol-server-address" validate:"required,hostname_port"`
+	Name                          string           `mapstructure:"name"`
+	Version                       string           `mapstructure:"version"`
+	Env                           string           `mapstructure:"env"`
+	TCPTimeoutInSeconds           int              `mapstructure:"tcp-timeout-in-seconds" validate:"required"`
+	StringProtocolServerAddress   string           `mapstructure:"string-protocol-server-address" validate:"required,server_address"`
+	JSONProtocolServerAddress     string           `mapstructure:"json-protocol-server-address" validate:"required,server_address"`
+	ProtobufProtocolServerAddress string           `mapstructure:"protobuf-protocol-server-address" validate:"required,server_address"`
+	MsgpackProtocolServerAddress  string           `mapstructure:"msgpack-protocol-server-address" validate:"required,server_address"`
+	CBORProtocolServerAddress     string           `mapstructure:"cbor-protocol-server-address" validate:"required,server_address"`
+	EncryptionKey                 string           `mapstructure:"encryption-key" validate:"omitempty,len=64,hexadecimal"`
+	TLS                           TLSSettings      `mapstructure:"tls"`
+	UnixSocketDir                 string           `mapstructure:"unix-socket-dir" validate:"required"`
+	ProfilesFile                  string           `mapstructure:"profiles-file"`
+	Theme                         string           `mapstructure:"theme"`
+	Themes                        map[string]Theme `mapstructure:"themes"`
+	Monitor                       MonitorSettings  `mapstructure:"monitor"`
+}
+
+// MonitorSettings are the defaults of the status monitor of the TUI, a threshold of 0 is off
+type MonitorSettings struct {
+	Protocol          string  `mapstructure:"protocol" validate:"omitempty,oneof=json string protobuf msgpack cbor"`
+	IntervalInSeconds int     `mapstructure:"interval-in-seconds" validate:"gte=0"`
+	CPUThreshold      float64 `mapstructure:"cpu-threshold" validate:"gte=0"`
+	MemoryThreshold   float64 `mapstructure:"memory-threshold" validate:"gte=0"`
+	LatencyThreshold  float64 `mapstructure:"latency-threshold" validate:"gte=0"`
+	SessionsThreshold int     `mapstructure:"sessions-threshold" validate:"gte=0"`
 }
 
 type Settings struct {
@@ -96,9 +129,27 @@ func LoadConfig[T any](prefix string, baseConfig []byte) (*T, error) {
 		return nil, err
 	}
 
-	validate := validator.New(validator.WithRequiredStructEnabled())
+	validate, err := newSettingsValidator()
+	if err != nil {
+		return nil, err
+	}
 	if err := validate.Struct(cfg); err != nil {
 		return nil, err
 	}
 	return cfg, nil
 }
+
+// newSettingsValidator returns a validator knowing the rules of the settings, like server_address
+func newSettingsValidator() (*validator.Validate, error) {
+	validate := validator.New(validator.WithRequiredStructEnabled())
+	if err := validate.RegisterValidation("server_address", validateServerAddress); err != nil {
+		return nil, err
+	}
+
+	return validate, nil
+}
+
+// validateServerAddress accepts host:port addresses and unix:// addresses of absolute paths
+func validateServerAddress(fl validator.FieldLevel) bool {
+	return ValidAddress(fl.Field().String())
+}
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"math"
+	"math/rand/v2"
+	"slices"
+	"time"
+)
+
+type LatencySummary struct {
+	Min  time.Duration `json:"min"`
+	Mean time.Duration `json:"mean"`
+	P50  time.Duration `json:"p50"`
+	P90  time.Duration `json:"p90"`
+	P95  time.Duration `json:"p95"`
+	P99  time.Duration `json:"p99"`
+	Max  time.Duration `json:"max"`
+}
+
+func SummarizeLatencies(latencies []time.Duration) LatencySummary {
+	if len(latencies) == 0 {
+		return LatencySummary{}
+	}
+
+	sorted := slices.Clone(latencies)
+	slices.Sort(sorted)
+
+	var total time.Duration
+	for _, latency := range sorted {
+		total += latency
+	}
+
+	return LatencySummary{
+		Min:  sorted[0],
+		Mean: total / time.Duration(len(sorted)),
+		P50:  percentile(sorted, 50),
+		P90:  percentile(sorted, 90),
+		P95:  percentile(sorted, 95),
+		P99:  percentile(sorted, 99),
+		Max:  sorted[len(sorted)-1],
+	}
+}
+
+// percentile uses the nearest-rank method on an already sorted slice
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+
+	return sorted[nearestRank(p, len(sorted))]
+}
+
+func nearestRank(p float64, n int) int {
+	rank := int(math.Ceil(p / 100 * float64(n)))
+	return min(max(rank, 1), n) - 1
+}
+
+func mean(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+
+	total := 0.0
+	for _, value := range values {
+		total += value
+	}
+
+	return total / float64(len(values))
+}
+
+func durationsToFloats(durations []time.Duration) []float64 {
+	values := make([]float64, len(durations))
+	for i, d := range durations {
+		values[i] = float64(d)
+	}
+	return values
+}
+
+// quantile returns a statistic computing the p-th percentile, it sorts its input in place
+func quantile(p float64) func([]float64) float64 {
+	return func(values []float64) float64 {
+		if len(values) == 0 {
+			return 0
+		}
+
+		slices.Sort(values)
+
+		return values[nearestRank(p, len(values))]
+	}
+}
+
+// MannWhitneyU returns the U statistic of a against b and the two-sided p-value,
+// using the normal approximation with tie and continuity corrections
+func MannWhitneyU(a, b []float64) (float64, float64) {
+	n1, n2 := len(a), len(b)
+	if n1 == 0 || n2 == 0 {
+		return 0, 1
+	}
+
+	type rankedValue struct {
+		value float64
+		fromA bool
+	}
+
+	values := make([]rankedValue, 0, n1+n2)
+	for _, v := range a {
+		values = append(values, rankedValue{value: v, fromA: true})
+	}
+	for _, v := range b {
+		values = append(values, rankedValue{value: v})
+	}
+
+	slices.SortFunc(values, func(x, y rankedValue) int {
+		switch {
+		case x.value < y.value:
+			return -1
+		case x.value > y.value:
+			return 1
+		default:
+			return 0
+		}
+	})
+
+	n := float64(n1 + n2)
+	rankSumA := 0.0
+	tieCorrection := 0.0
+
+	for i := 0; i < len(values); {
+		j := i
+		for j < len(values) && values[j].value == values[i].value {
+			j++
+		}
+
+		// Tied values share the average of their ranks
+		averageRank := float64(i+j+1) / 2
+		for k := i; k < j; k++ {
+			if values[k].fromA {
+				rankSumA += averageRank
+			}
+		}
+
+		ties := float64(j - i)
+		tieCorrection += ties*ties*ties - ties
+		i = j
+	}
+
+	u := rankSumA - float64(n1)*float64(n1+1)/2
+	mu := float64(n1) * float64(n2) / 2
+	variance := float64(n1) * float64(n2) / 12 * ((n + 1) - tieCorrection/(n*(n-1)))
+
+	if variance <= 0 {
+		return u, 1
+	}
+
+	z := (math.Abs(u-mu) - 0.5) / math.Sqrt(variance)
+	if z < 0 {
+		z = 0
+	}
+
+	return u, math.Erfc(z / math.Sqrt2)
+}
+
+// maxBootstrapSamples bounds the resampled size so large runs stay cheap to compare
+const maxBootstrapSamples = 2000
+
+// BootstrapDiffCI estimates the confidence interval of stat(b) - stat(a) by resampling both samples
+func BootstrapDiffCI(a, b []float64, stat func([]float64) float64, iterations int, confidence float64, rng *rand.Rand) (float64, float64) {
+	if len(a) == 0 || len(b) == 0 || iterations <= 0 {
+		return math.Inf(-1), math.Inf(1)
+	}
+
+	a = bootstrapSubsample(a, rng)
+	b = bootstrapSubsample(b, rng)
+
+	bufA := make([]float64, len(a))
+	bufB := make([]float64, len(b))
+	diffs := make([]float64, iterations)
+
+	for i := range diffs {
+		for j := range bufA {
+			bufA[j] = a[rng.IntN(len(a))]
+		}
+		for j := range bufB {
+			bufB[j] = b[rng.IntN(len(b))]
+		}
+
+		diffs[i] = stat(bufB) - stat(bufA)
+	}
+
+	slices.Sort(diffs)
+
+	tail := (1 - confidence) / 2
+	low := diffs[min(int(tail*float64(iterations)), iterations-1)]
+	high := diffs[min(int((1-tail)*float64(iterations)), iterations-1)]
+
+	return low, high
+}
+
+func bootstrapSubsample(values []float64, rng *rand.Rand) []float64 {
+	if len(values) <= maxBootstrapSamples {
+		return values
+	}
+
+	subsample := make([]float64, maxBootstrapSamples)
+	for i := range subsample {
+		subsample[i] = values[rng.IntN(len(values))]
+	}
+
+	return subsample
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -120,19 +121,11 @@ func getStrFieldRepresentation(field reflect.Value) string {
 
 // Unmarshal implements Serde.
 func (s StringSerde) Unmarshal(data []byte, v any) error {
-	typ := reflect.TypeOf(v)
-	value := reflect.ValueOf(v)
-
-	if typ.Kind() != reflect.Pointer {
-		return fmt.Errorf("pointers are supported, found %s", typ.Kind().String())
-	}
-
-	if value.IsNil() {
-		return fmt.Errorf("nil pointer provided")
+	value, err := responseValue(v)
+	if err != nil {
+		return err
 	}
 
-	value = value.Elem()
-
 	dataArgs := strings.Split(string(data), "|")
 
 	if len(dataArgs) < 3 {
@@ -201,21 +194,11 @@ func (s StringSerde) Unmarshal(data []byte, v any) error {
 
 	value.FieldByName("Err").Set(reflect.Zero(value.FieldByName("Err").Type()))
 
-	bodyField := value.FieldByName("Body")
-
-	if bodyField.Kind() != reflect.Pointer && bodyField.Kind() != reflect.Interface {
-		return fmt.Errorf("body field is not a pointer or interface: %v", bodyField.Kind())
-	}
-
-	if bodyField.Kind() == reflect.Interface {
-		bodyField = bodyField.Elem()
-	}
-
-	if bodyField.IsNil() {
-		bodyField.Set(reflect.New(bodyField.Type().Elem()))
+	bodyField, err := responseBody(value)
+	if err != nil {
+		return err
 	}
 
-	bodyField = bodyField.Elem()
 	return bindStructFields(bodyField, properties)
 }
 
@@ -308,7 +291,9 @@ func setFieldValueFromString(field reflect.Value, valueStr string) error {
 		bindSlice := []any{}
 		jsonStr := convertPythonDictTOJSONDict(valueStr)
 
+		// Numbers keep their digits, otherwise large integers print in exponent notation
 		d := json.NewDecoder(strings.NewReader(jsonStr))
+		d.UseNumber()
 		err := d.Decode(&bindSlice)
 		if err != nil {
 			return fmt.Errorf("error unmarshaling bindSlice from string: %w", err)
@@ -391,17 +376,22 @@ func setFieldValueFromString(field reflect.Value, valueStr string) error {
 			return nil
 		}
 
+		if field.Type() == reflect.TypeOf(time.Time{}) {
+			parsedTime, err := time.Parse(time.RFC3339, valueStr)
+			if err != nil {
+				return err
+			}
+			field.Set(reflect.ValueOf(parsedTime))
+			return nil
+		}
+
 		if field.Type() == reflect.TypeOf(UnixTimestamp{}) {
 			// Parse the unix timestamp as a float
 			floatValue, err := strconv.ParseFloat(valueStr, 64)
 			if err != nil {
 				return fmt.Errorf("error parsing unix timestamp: %w", err)
 			}
-			// Convert to seconds and nanoseconds
-			seconds := int64(floatValue)
-			nanos := int64((floatValue - float64(seconds)) * 1e9)
-			fieldValue := time.Unix(seconds, nanos).UTC()
-			field.Set(reflect.ValueOf(UnixTimestamp{fieldValue}))
+			field.Set(reflect.ValueOf(newUnixTimestamp(floatValue)))
 			return nil
 		}
 
@@ -464,6 +454,12 @@ func setFieldValueFromString(field reflect.Value, valueStr string) error {
 			return fmt.Errorf("error unmarshaling bindAny from string: %w", err)
 		}
 
+		// A JSON null has no value to set
+		if bindAny == nil {
+			field.Set(reflect.Zero(field.Type()))
+			return nil
+		}
+
 		field.Set(reflect.ValueOf(bindAny))
 
 	default:
@@ -513,3 +509,107 @@ func convertPythonDictTOJSONDict(dictStr string) string {
 
 	return str
 }
+
+// AccountRequest implements WireAccountant.
+func (s StringSerde) AccountRequest(data []byte) (WireBreakdown, error) {
+	return accountStringMessage(data)
+}
+
+// ReplyLength implements ReplyFramer, replies end with the FIM token and a line break.
+func (s StringSerde) ReplyLength(data []byte) (int, bool) {
+	end := bytes.Index(data, []byte("|FIM"))
+	if end < 0 {
+		return 0, false
+	}
+
+	newline := bytes.IndexByte(data[end:], '\n')
+	if newline < 0 {
+		return 0, false
+	}
+
+	return end + newline + 1, true
+}
+
+// AccountResponse implements WireAccountant.
+func (s StringSerde) AccountResponse(data []byte) (WireBreakdown, error) {
+	return accountStringMessage(data)
+}
+
+// UnmarshalRequest implements ServerSerde.
+func (s StringSerde) UnmarshalRequest(data []byte, req *PresentationLayerRequest) error {
+	if req == nil {
+		return fmt.Errorf("nil pointer provided")
+	}
+
+	message := strings.TrimRight(string(data), "\r\n")
+	message, ok := strings.CutSuffix(message, "|FIM")
+	if !ok {
+		return fmt.Errorf("malformed request: missing FIM token")
+	}
+
+	args := strings.Split(message, "|")
+	command := args[0]
+
+	properties := make(map[string]string)
+	for _, arg := range args[1:] {
+		property, value, ok := strings.Cut(arg, "=")
+		if !ok {
+			return fmt.Errorf("expected key=value argument, found %s", arg)
+		}
+		properties[property] = value
+	}
+
+	name := command
+	switch command {
+	case authCommandName, logoutCommandName:
+	case "OP":
+		name = properties["operacao"]
+	default:
+		return fmt.Errorf("unexpected command %s", command)
+	}
+
+	body, err := decodeRequestBody(name, func(body reflect.Value) error {
+		return bindStructFields(body, properties)
+	})
+	if err != nil {
+		return err
+	}
+
+	req.Token = properties["token"]
+	req.Body = body
+
+	return nil
+}
+
+// MarshalResponse implements ServerSerde.
+func (s StringSerde) MarshalResponse(v any) ([]byte, error) {
+	resp, err := newServerResponse(v)
+	if err != nil {
+		return nil, err
+	}
+
+	args := []string{}
+
+	if resp.failed() {
+		status := "ERROR"
+		if resp.statusCode == http.StatusUnprocessableEntity {
+			status = "INVALIDO"
+		}
+
+		args = append(args, status, "msg="+resp.message())
+	} else {
+		properties, err := responseProperties(resp.body)
+		if err != nil {
+			return nil, err
+		}
+
+		args = append(args, "OK")
+		for _, property := range properties {
+			args = append(args, property.Key+"="+property.Value)
+		}
+	}
+
+	args = append(args, "FIM")
+
+	return []byte(strings.Join(args, "|") + "\n"), nil
+}
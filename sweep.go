@@ -0,0 +1,495 @@
+package main
+
+import (
+	"crypto/md5"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	sweepTimestamp = "2025-11-06T13:30:34.382931"
+	sweepAlphabet  = "abcdefghijklmnopqrstuvwxyz0123456789"
+)
+
+var (
+	sweepEchoSizes     = []int{1, 16, 256, 1024, 4096, 16384, 61440}
+	sweepSumSizes      = []int{1, 10, 50, 100, 250, 500, 1000}
+	sweepHistorySizes  = []int{1, 5, 10, 25, 50, 100}
+	sweepSessionsSizes = []int{1, 10, 50, 100, 250}
+)
+
+// SweepRequestCase is a request whose payload grows with Size
+type SweepRequestCase struct {
+	Operation string
+	Size      int
+	Request   PresentationLayerRequest
+}
+
+// SweepResponseCase is a server result whose payload grows with Size
+type SweepResponseCase struct {
+	Operation string
+	Size      int
+	Result    map[string]any
+	NewBody   func() OperationResponse
+}
+
+// Name returns the case name used by the sweep benchmarks and reports
+func (c SweepRequestCase) Name() string {
+	return fmt.Sprintf("%s-%d", c.Operation, c.Size)
+}
+
+// Name returns the case name used by the sweep benchmarks and reports
+func (c SweepResponseCase) Name() string {
+	return fmt.Sprintf("%s-%d", c.Operation, c.Size)
+}
+
+type SweepPoint struct {
+	Protocol     string  `json:"protocol"`
+	Codec        string  `json:"codec"`
+	Message      string  `json:"message"`
+	Direction    string  `json:"direction"`
+	Operation    string  `json:"operation"`
+	Size         int     `json:"size"`
+	EncodedBytes int     `json:"encoded_bytes"`
+	NsPerOp      float64 `json:"ns_per_op"`
+	AllocsPerOp  uint64  `json:"allocs_per_op"`
+	BytesPerOp   uint64  `json:"bytes_per_op"`
+
+	Wire WireBreakdown `json:"wire"`
+}
+
+func sweepMessage(size int) string {
+	var sb strings.Builder
+	sb.Grow(size)
+	for i := range size {
+		sb.WriteByte(sweepAlphabet[i%len(sweepAlphabet)])
+	}
+	return sb.String()
+}
+
+func SweepRequestCases() []SweepRequestCase {
+	cases := []SweepRequestCase{}
+
+	for _, size := range sweepEchoSizes {
+		cases = append(cases, SweepRequestCase{
+			Operation: OperationEcho,
+			Size:      size,
+			Request:   PresentationLayerRequest{Token: "tokenecho", Body: EchoRequest{Message: sweepMessage(size)}},
+		})
+	}
+
+	for _, size := range sweepSumSizes {
+		numbers := make([]int, size)
+		for i := range numbers {
+			numbers[i] = i * 7
+		}
+
+		cases = append(cases, SweepRequestCase{
+			Operation: OperationSum,
+			Size:      size,
+			Request:   PresentationLayerRequest{Token: "tokensum", Body: SumRequest{Numbers: numbers}},
+		})
+	}
+
+	for _, size := range sweepHistorySizes {
+		cases = append(cases, SweepRequestCase{
+			Operation: OperationHistory,
+			Size:      size,
+			Request:   PresentationLayerRequest{Token: "tokenhistory", Body: HistoryRequest{Limit: size}},
+		})
+	}
+
+	return cases
+}
+
+func SweepResponseCases() []SweepResponseCase {
+	cases := []SweepResponseCase{}
+
+	for _, size := range sweepEchoSizes {
+		message := sweepMessage(size)
+		hash := md5.Sum([]byte(message))
+
+		cases = append(cases, SweepResponseCase{
+			Operation: OperationEcho,
+			Size:      size,
+			Result: map[string]any{
+				"mensagem_original":  message,
+				"mensagem_eco":       "ECO: " + message,
+				"timestamp_servidor": sweepTimestamp,
+				"tamanho_mensagem":   size,
+				"hash_md5":           hex.EncodeToString(hash[:]),
+			},
+			NewBody: func() OperationResponse { return &EchoResponse{} },
+		})
+	}
+
+	for _, size := range sweepSumSizes {
+		numbers := make([]any, size)
+		sum := 0.0
+		for i := range numbers {
+			numbers[i] = float64(i * 7)
+			sum += float64(i * 7)
+		}
+
+		cases = append(cases, SweepResponseCase{
+			Operation: OperationSum,
+			Size:      size,
+			Result: map[string]any{
+				"numeros_originais": numbers,
+				"quantidade":        size,
+				"soma":              sum,
+				"media":             sum / float64(size),
+				"maximo":            float64((size - 1) * 7),
+				"minimo":            0.0,
+				"timestamp_calculo": sweepTimestamp,
+			},
+			NewBody: func() OperationResponse { return &SumResponse{} },
+		})
+	}
+
+	for _, size := range sweepHistorySizes {
+		history := make([]any, size)
+		for i := range history {
+			history[i] = map[string]any{
+				"operacao":   OperationEcho,
+				"parametros": map[string]any{"mensagem": sweepMessage(32)},
+				"resultado": map[string]any{
+					"mensagem_original": sweepMessage(32),
+					"mensagem_eco":      "ECO: " + sweepMessage(32),
+					"tamanho_mensagem":  32,
+				},
+				"timestamp": sweepTimestamp,
+				"sucesso":   true,
+			}
+		}
+
+		cases = append(cases, SweepResponseCase{
+			Operation: OperationHistory,
+			Size:      size,
+			Result: map[string]any{
+				"aluno_id":           defaultEnrollmentID,
+				"limite_solicitado":  size,
+				"total_encontrado":   size,
+				"historico":          history,
+				"timestamp_consulta": sweepTimestamp,
+				"estatisticas": map[string]any{
+					"total_operacoes":   size,
+					"operacoes_sucesso": size,
+					"operacoes_erro":    0,
+					"taxa_sucesso":      100.0,
+				},
+				"operacoes_mais_usadas": []any{[]any{OperationEcho, size}},
+			},
+			NewBody: func() OperationResponse { return &HistoryResponse{} },
+		})
+	}
+
+	for _, size := range sweepSessionsSizes {
+		sessions := make(map[string]any, size)
+		for i := range size {
+			id := strconv.Itoa(500000 + i)
+			sessions[id] = map[string]any{
+				"timestamp_login": 1761869051 + i,
+				"ip_cliente":      fmt.Sprintf("10.0.%d.%d", i/256, i%256),
+				"nome":            "ALUNO " + id,
+				"matricula":       id,
+			}
+		}
+
+		cases = append(cases, SweepResponseCase{
+			Operation: OperationStatus,
+			Size:      size,
+			Result: map[string]any{
+				"status":                "ATIVO",
+				"operacoes_processadas": size * 10,
+				"sessoes_ativas":        size,
+				"tempo_ativo":           1761869051.4706569,
+				"versao":                "1.0.0",
+				"estatisticas_banco": map[string]any{
+					"total_sessoes":   size,
+					"total_operacoes": size * 10,
+					"operacoes_por_tipo": map[string]any{
+						"autenticacao": size,
+						"echo":         size,
+						"historico":    size,
+						"soma":         size,
+						"status":       size,
+						"timestamp":    size,
+					},
+					"alunos_unicos": size,
+				},
+				"sessoes_detalhes": sessions,
+				"metricas": map[string]any{
+					"cpu_simulado":      73.87,
+					"memoria_simulada":  68.77,
+					"latencia_simulada": 8.66,
+				},
+			},
+			NewBody: func() OperationResponse { return &StatusResponse{} },
+		})
+	}
+
+	return cases
+}
+
+// Response binds the case result to its body, like a client decoding a successful reply
+func (c SweepResponseCase) Response() (PresentationLayerResponse[OperationResponse], error) {
+	resp := PresentationLayerResponse[OperationResponse]{Body: c.NewBody()}
+
+	data, err := json.Marshal(map[string]any{
+		"sucesso":   true,
+		"resultado": c.Result,
+		"timestamp": sweepTimestamp,
+	})
+	if err != nil {
+		return resp, err
+	}
+
+	return resp, jsonserde.Unmarshal(data, &resp)
+}
+
+// encodeSweepResponse renders the case the way the protocol server sends it
+func encodeSweepResponse(protocol string, c SweepResponseCase) ([]byte, error) {
+	serde, err := NewSerde(protocol)
+	if err != nil {
+		return nil, err
+	}
+
+	serverSerde, ok := serde.(ServerSerde)
+	if !ok {
+		return nil, fmt.Errorf("protocol %s cannot encode responses", protocol)
+	}
+
+	resp, err := c.Response()
+	if err != nil {
+		return nil, err
+	}
+
+	return serverSerde.MarshalResponse(resp)
+}
+
+type sweepMeasurement struct {
+	NsPerOp     float64
+	AllocsPerOp uint64
+	BytesPerOp  uint64
+}
+
+// measureSweep runs fn until it takes at least minDuration, like testing.B does
+func measureSweep(minDuration time.Duration, fn func() error) (sweepMeasurement, error) {
+	if err := fn(); err != nil {
+		return sweepMeasurement{}, err
+	}
+
+	n := 1
+	for {
+		var before, after runtime.MemStats
+
+		runtime.GC()
+		runtime.ReadMemStats(&before)
+		start := time.Now()
+		for range n {
+			if err := fn(); err != nil {
+				return sweepMeasurement{}, err
+			}
+		}
+		elapsed := time.Since(start)
+		runtime.ReadMemStats(&after)
+
+		if elapsed >= minDuration || n >= 1<<24 {
+			return sweepMeasurement{
+				NsPerOp:     float64(elapsed.Nanoseconds()) / float64(n),
+				AllocsPerOp: (after.Mallocs - before.Mallocs) / uint64(n),
+				BytesPerOp:  (after.TotalAlloc - before.TotalAlloc) / uint64(n),
+			}, nil
+		}
+
+		n *= 2
+	}
+}
+
+// RunSweep measures the client and server halves of every sweep case on each protocol and codec:
+// requests are encoded and decoded, responses encoded and decoded. Protocols without a generated
+// codec are only swept with the reflective one
+func RunSweep(protocols, codecs []string, minDuration time.Duration) ([]SweepPoint, error) {
+	points := []SweepPoint{}
+
+	for _, protocol := range protocols {
+		for _, codec := range codecs {
+			serde, err := NewCodecSerde(protocol, codec)
+			if errors.Is(err, ErrNoGeneratedCodec) {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+
+			codecPoints, err := sweepSerde(serde, protocol, minDuration)
+			if err != nil {
+				return nil, fmt.Errorf("%s codec: %w", codec, err)
+			}
+
+			for i := range codecPoints {
+				codecPoints[i].Codec = codec
+			}
+			points = append(points, codecPoints...)
+		}
+	}
+
+	return points, nil
+}
+
+func sweepSerde(serde Serde, protocol string, minDuration time.Duration) ([]SweepPoint, error) {
+	points := []SweepPoint{}
+
+	serverSerde, ok := serde.(ServerSerde)
+	if !ok {
+		return nil, fmt.Errorf("protocol %s cannot decode requests and encode responses", protocol)
+	}
+
+	for _, c := range SweepRequestCases() {
+		var encoded []byte
+		encode, err := measureSweep(minDuration, func() (err error) {
+			encoded, err = serde.Marshal(c.Request)
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("%s encode %s: %w", protocol, c.Name(), err)
+		}
+
+		decode, err := measureSweep(minDuration, func() error {
+			var req PresentationLayerRequest
+			return serverSerde.UnmarshalRequest(encoded, &req)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("%s decode %s: %w", protocol, c.Name(), err)
+		}
+
+		wire, err := accountSweepMessage(serde, encoded, true)
+		if err != nil {
+			return nil, fmt.Errorf("%s account %s: %w", protocol, c.Name(), err)
+		}
+
+		points = append(points,
+			newSweepPoint(protocol, "request", "encode", c.Operation, c.Size, encoded, encode, wire),
+			newSweepPoint(protocol, "request", "decode", c.Operation, c.Size, encoded, decode, wire),
+		)
+	}
+
+	for _, c := range SweepResponseCases() {
+		resp, err := c.Response()
+		if err != nil {
+			return nil, fmt.Errorf("%s build %s: %w", protocol, c.Name(), err)
+		}
+
+		var data []byte
+		encode, err := measureSweep(minDuration, func() (err error) {
+			data, err = serverSerde.MarshalResponse(resp)
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("%s encode %s: %w", protocol, c.Name(), err)
+		}
+
+		decode, err := measureSweep(minDuration, func() error {
+			return decodeSweepResponse(serde, data, c)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("%s decode %s: %w", protocol, c.Name(), err)
+		}
+
+		wire, err := accountSweepMessage(serde, data, false)
+		if err != nil {
+			return nil, fmt.Errorf("%s account %s: %w", protocol, c.Name(), err)
+		}
+
+		points = append(points,
+			newSweepPoint(protocol, "response", "encode", c.Operation, c.Size, data, encode, wire),
+			newSweepPoint(protocol, "response", "decode", c.Operation, c.Size, data, decode, wire),
+		)
+	}
+	return points, nil
+}
+
+func newSweepPoint(protocol, message, direction, operation string, size int, encoded []byte, m sweepMeasurement, wire WireBreakdown) SweepPoint {
+	return SweepPoint{
+		Protocol:     protocol,
+		Message:      message,
+		Direction:    direction,
+		Operation:    operation,
+		Size:         size,
+		EncodedBytes: len(encoded),
+		NsPerOp:      m.NsPerOp,
+		AllocsPerOp:  m.AllocsPerOp,
+		BytesPerOp:   m.BytesPerOp,
+		Wire:         wire,
+	}
+}
+
+func decodeSweepResponse(serde Serde, data []byte, c SweepResponseCase) error {
+	resp := PresentationLayerResponse[OperationResponse]{Body: c.NewBody()}
+
+	if err := serde.Unmarshal(data, &resp); err != nil {
+		return err
+	}
+
+	if resp.Err != nil {
+		return resp.Err
+	}
+
+	return nil
+}
+
+func accountSweepMessage(serde Serde, data []byte, request bool) (WireBreakdown, error) {
+	accountant, ok := serde.(WireAccountant)
+	if !ok {
+		return WireBreakdown{Payload: len(data)}, nil
+	}
+
+	if request {
+		return accountant.AccountRequest(data)
+	}
+
+	return accountant.AccountResponse(data)
+}
+
+func WriteSweepCSV(w io.Writer, points []SweepPoint) error {
+	cw := csv.NewWriter(w)
+
+	err := cw.Write([]string{"protocol", "codec", "message", "direction", "operation", "size", "encoded_bytes", "framing_bytes", "envelope_bytes", "payload_bytes", "ns_per_op", "allocs_per_op", "bytes_per_op"})
+	if err != nil {
+		return err
+	}
+
+	for _, p := range points {
+		err := cw.Write([]string{
+			p.Protocol,
+			p.Codec,
+			p.Message,
+			p.Direction,
+			p.Operation,
+			strconv.Itoa(p.Size),
+			strconv.Itoa(p.EncodedBytes),
+			strconv.Itoa(p.Wire.Framing),
+			strconv.Itoa(p.Wire.Envelope),
+			strconv.Itoa(p.Wire.Payload),
+			strconv.FormatFloat(p.NsPerOp, 'f', 1, 64),
+			strconv.FormatUint(p.AllocsPerOp, 10),
+			strconv.FormatUint(p.BytesPerOp, 10),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+
+	return cw.Error()
+}
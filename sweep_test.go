@@ -0,0 +1,55 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSweepResponseCasesDecode(t *testing.T) {
+	for _, c := range SweepResponseCases() {
+		for _, protocol := range Protocols {
+			t.Run(c.Name()+"/"+protocol, func(t *testing.T) {
+				serde, err := NewSerde(protocol)
+				require.NoError(t, err)
+
+				data, err := encodeSweepResponse(protocol, c)
+				require.NoError(t, err, "encodeSweepResponse should not return an error")
+
+				err = decodeSweepResponse(serde, data, c)
+				assert.NoError(t, err, "every protocol should decode the generated response")
+			})
+		}
+	}
+}
+
+func TestRunSweepCSV(t *testing.T) {
+	points, err := RunSweep([]string{ProtocolJSON}, []string{CodecReflect}, time.Microsecond)
+	require.NoError(t, err, "RunSweep should not return an error")
+	assert.Len(t, points, 2*(len(SweepRequestCases())+len(SweepResponseCases())), "every case should be measured encoding and decoding")
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteSweepCSV(&buf, points))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	assert.Len(t, lines, len(points)+1, "CSV should have a header plus one line per point")
+	assert.True(t, strings.HasPrefix(lines[1], "json,reflect,request,encode,echo,1,"))
+	assert.True(t, strings.HasPrefix(lines[2], "json,reflect,request,decode,echo,1,"))
+}
+
+func TestRunSweepSkipsMissingGeneratedCodecs(t *testing.T) {
+	// Act
+	points, err := RunSweep([]string{ProtocolString, ProtocolProtobuf}, Codecs, time.Microsecond)
+
+	// Assert
+	require.NoError(t, err, "protocols without a generated codec should be skipped")
+	perCodec := 2 * (len(SweepRequestCases()) + len(SweepResponseCases()))
+	assert.Len(t, points, 3*perCodec, "string should be swept with both codecs and protobuf only with reflect")
+	assert.Equal(t, CodecGenerated, points[perCodec].Codec)
+	assert.Equal(t, ProtocolProtobuf, points[2*perCodec].Protocol)
+	assert.Equal(t, CodecReflect, points[2*perCodec].Codec)
+}
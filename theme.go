@@ -1,19 +1,52 @@
 package main
 
-import "github.com/charmbracelet/lipgloss"
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
 
-// Theme defines the color scheme for the TUI
+	"github.com/charmbracelet/bubbles/progress"
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Theme defines the color scheme for the TUI, colors are #rrggbb or ANSI colors from 0 to 255
 type Theme struct {
-	Primary      lipgloss.Color
-	Secondary    lipgloss.Color
-	Text         lipgloss.Color
-	Muted        lipgloss.Color
-	Border       lipgloss.Color
-	Error        lipgloss.Color
-	Background   lipgloss.Color
-	Focused      lipgloss.Color
-	ButtonActive lipgloss.Color
-	ButtonBg     lipgloss.Color
+	Primary      lipgloss.Color `mapstructure:"primary"`
+	Secondary    lipgloss.Color `mapstructure:"secondary"`
+	Text         lipgloss.Color `mapstructure:"text"`
+	Muted        lipgloss.Color `mapstructure:"muted"`
+	Border       lipgloss.Color `mapstructure:"border"`
+	Error        lipgloss.Color `mapstructure:"error"`
+	Background   lipgloss.Color `mapstructure:"background"`
+	Focused      lipgloss.Color `mapstructure:"focused"`
+	ButtonActive lipgloss.Color `mapstructure:"button-active"`
+	ButtonBg     lipgloss.Color `mapstructure:"button-bg"`
+}
+
+// colors lists the colors of the theme by their config key
+func (t *Theme) colors() []struct {
+	key   string
+	color *lipgloss.Color
+} {
+	return []struct {
+		key   string
+		color *lipgloss.Color
+	}{
+		{"primary", &t.Primary},
+		{"secondary", &t.Secondary},
+		{"text", &t.Text},
+		{"muted", &t.Muted},
+		{"border", &t.Border},
+		{"error", &t.Error},
+		{"background", &t.Background},
+		{"focused", &t.Focused},
+		{"button-active", &t.ButtonActive},
+		{"button-bg", &t.ButtonBg},
+	}
 }
 
 // DefaultTheme returns the default color scheme
@@ -80,21 +113,100 @@ func GruvboxTheme() Theme {
 	}
 }
 
-// ApplyTheme applies a theme to the global color variables
+// DefaultThemeName is the theme used when none is picked
+const DefaultThemeName = "default"
+
+// Themes are the built-in themes by name
+var Themes = map[string]func() Theme{
+	DefaultThemeName: DefaultTheme,
+	"cyberpunk":      CyberpunkTheme,
+	"dracula":        DraculaTheme,
+	"gruvbox":        GruvboxTheme,
+}
+
+// ThemeNames lists the built-in themes in the order they are cycled through
+var ThemeNames = []string{DefaultThemeName, "cyberpunk", "dracula", "gruvbox"}
+
+var hexColorRegex = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// validColor tells whether color is a #rgb or #rrggbb color or an ANSI color from 0 to 255
+func validColor(color lipgloss.Color) bool {
+	if hexColorRegex.MatchString(string(color)) {
+		return true
+	}
+
+	n, err := strconv.ParseUint(string(color), 10, 8)
+	return err == nil && n <= 255
+}
+
+// themeCatalog holds the built-in themes and the custom ones of the settings
+type themeCatalog struct {
+	names  []string
+	themes map[string]Theme
+}
+
+// builtinThemes returns the catalog of the built-in themes only
+func builtinThemes() themeCatalog {
+	catalog := themeCatalog{names: slices.Clone(ThemeNames), themes: map[string]Theme{}}
+	for _, name := range ThemeNames {
+		catalog.themes[name] = Themes[name]()
+	}
+
+	return catalog
+}
+
+// newThemeCatalog adds the custom themes to the built-in ones, after them and sorted by name. The
+// colors a custom theme leaves out are the ones of the default theme.
+func newThemeCatalog(custom map[string]Theme) (themeCatalog, error) {
+	catalog := builtinThemes()
+	for _, name := range slices.Sorted(maps.Keys(custom)) {
+		if _, ok := Themes[name]; ok {
+			return themeCatalog{}, fmt.Errorf("theme %s is built in, name the custom theme differently", name)
+		}
+
+		theme, fallback := custom[name], DefaultTheme()
+		fallbackColors := fallback.colors()
+		for i, color := range theme.colors() {
+			if *color.color == "" {
+				*color.color = *fallbackColors[i].color
+				continue
+			}
+			if !validColor(*color.color) {
+				return themeCatalog{}, fmt.Errorf("theme %s: %s %q is not a #rrggbb color or an ANSI color from 0 to 255", name, color.key, *color.color)
+			}
+		}
+
+		catalog.names = append(catalog.names, name)
+		catalog.themes[name] = theme
+	}
+
+	return catalog, nil
+}
+
+// theme returns the theme named name
+func (c themeCatalog) theme(name string) (Theme, error) {
+	theme, ok := c.themes[name]
+	if !ok {
+		return Theme{}, fmt.Errorf("unknown theme %s, pick one of %s", name, strings.Join(c.names, ", "))
+	}
+
+	return theme, nil
+}
+
+// next returns the name of the theme after name, the first one after the last
+func (c themeCatalog) next(name string) string {
+	return c.names[(slices.Index(c.names, name)+1)%len(c.names)]
+}
+
+// activeTheme is the theme of the styles
+var activeTheme Theme
+
+// ApplyTheme rebuilds the styles of the TUI with the colors of theme
 func ApplyTheme(theme Theme) {
-	colorPrimary = theme.Primary
-	colorSecondary = theme.Secondary
-	colorText = theme.Text
-	colorMuted = theme.Muted
-	colorBorder = theme.Border
-	colorError = theme.Error
-	colorBackground = theme.Background
-	colorFocused = theme.Focused
-	colorButtonActive = theme.ButtonActive
-	colorButtonBg = theme.ButtonBg
+	activeTheme = theme
 
 	// Recreate styles with new colors
-	initStyles()
+	initStyles(theme)
 }
 
 func init() {
@@ -102,64 +214,114 @@ func init() {
 	ApplyTheme(DefaultTheme())
 }
 
-// initStyles initializes all styles with current colors
-func initStyles() {
+// initStyles initializes all styles with the colors of theme
+func initStyles(theme Theme) {
 	titleStyle = lipgloss.NewStyle().
 		Bold(true).
-		Foreground(colorPrimary)
+		Foreground(theme.Primary)
 
 	fieldStyle = lipgloss.NewStyle()
 
 	focusedFieldStyle = lipgloss.NewStyle().
 		Bold(true).
-		Foreground(colorFocused)
+		Foreground(theme.Focused)
 
 	inputStyle = lipgloss.NewStyle().
-		Foreground(colorText)
+		Foreground(theme.Text)
 
 	hintStyle = lipgloss.NewStyle().
-		Foreground(colorMuted).
+		Foreground(theme.Muted).
 		Italic(true)
 
+	placeholderStyle = lipgloss.NewStyle().
+		Foreground(theme.Muted)
+
 	buttonBlurredStyle = lipgloss.NewStyle().
 		Padding(0, 2).
 		MarginRight(1).
-		Foreground(colorText).
-		Background(colorButtonBg)
+		Foreground(theme.Text).
+		Background(theme.ButtonBg)
 
 	buttonFocusedStyle = lipgloss.NewStyle().
 		Padding(0, 2).
 		MarginRight(1).
 		Bold(true).
-		Foreground(colorButtonBg).
-		Background(colorButtonActive)
+		Foreground(theme.ButtonBg).
+		Background(theme.ButtonActive)
 
 	panelBorderStyle = lipgloss.NewStyle().
 		BorderStyle(lipgloss.RoundedBorder()).
-		BorderForeground(colorBackground)
+		BorderForeground(theme.Border)
 
 	errorPopupStyle = lipgloss.NewStyle().
 		Border(lipgloss.RoundedBorder()).
-		BorderForeground(colorError).
-		Background(colorBackground).
-		Foreground(colorText).
+		BorderForeground(theme.Error).
+		Background(theme.Background).
+		Foreground(theme.Text).
 		Padding(1, 2)
 
 	errorTitleStyle = lipgloss.NewStyle().
 		Bold(true).
-		Foreground(colorError).
+		Foreground(theme.Error).
 		Align(lipgloss.Center)
 
 	errorMsgStyle = lipgloss.NewStyle().
-		Foreground(colorText)
+		Foreground(theme.Text)
 
 	errorHintStyle = lipgloss.NewStyle().
-		Foreground(colorMuted).
+		Foreground(theme.Muted).
 		Italic(true).
 		Align(lipgloss.Center)
 
 	contentStyle = lipgloss.NewStyle()
 
 	separatorStyle = lipgloss.NewStyle().
-		Foreground(colorMuted)
+		Foreground(theme.Muted)
+}
+
+// styleInput styles input with the active theme
+func styleInput(input *textinput.Model) {
+	input.PromptStyle = inputStyle
+	input.TextStyle = inputStyle
+	input.PlaceholderStyle = placeholderStyle
+}
+
+// newProgress returns the progress bar of the active theme
+func newProgress() progress.Model {
+	return progress.New(progress.WithSolidFill(string(activeTheme.Primary)))
+}
+
+// setTheme switches the TUI to the theme named name, restyling what was styled already
+func (m *model) setTheme(name string) error {
+	theme, err := m.themes.theme(name)
+	if err != nil {
+		return err
+	}
+
+	ApplyTheme(theme)
+	m.themeName = name
+
+	inputs := []*textinput.Model{&m.enrollment, &m.dashboard.duration, &m.dashboard.concurrency, &m.history.filter}
+	for i := range m.forms {
+		for j := range m.forms[i].fields {
+			inputs = append(inputs, &m.forms[i].fields[j].input)
+		}
+	}
+	for i := range m.profiles.fields {
+		inputs = append(inputs, &m.profiles.fields[i])
+	}
+	for i := range m.monitor.fields {
+		inputs = append(inputs, &m.monitor.fields[i])
+	}
+	for _, input := range inputs {
+		styleInput(input)
+	}
+
+	m.progress = newProgress()
+	if m.comparison != nil {
+		m.compareViewport.SetContent(renderComparison(m.comparison.columns, m.compareViewport.Width))
+	}
+	m.setViewportContent()
+
+	return nil
 }
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewThemeCatalog(t *testing.T) {
+	tests := []struct {
+		name     string
+		custom   map[string]Theme
+		expected []string
+		wantErr  string
+	}{
+		{
+			name:     "built in only",
+			expected: ThemeNames,
+		},
+		{
+			name:     "custom after built in",
+			custom:   map[string]Theme{"ocean": {Primary: "#5fafff"}, "mono": {Primary: "250"}},
+			expected: append(append([]string{}, ThemeNames...), "mono", "ocean"),
+		},
+		{
+			name:    "built in name",
+			custom:  map[string]Theme{"dracula": {Primary: "#5fafff"}},
+			wantErr: "theme dracula is built in",
+		},
+		{
+			name:    "invalid color",
+			custom:  map[string]Theme{"ocean": {Focused: "blue"}},
+			wantErr: `theme ocean: focused "blue" is not a #rrggbb color`,
+		},
+		{
+			name:    "ansi color out of range",
+			custom:  map[string]Theme{"ocean": {Border: "256"}},
+			wantErr: `border "256"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			catalog, err := newThemeCatalog(tt.custom)
+
+			// Assert
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, catalog.names)
+		})
+	}
+}
+
+func TestThemeCatalogTheme(t *testing.T) {
+	// Arrange
+	catalog, err := newThemeCatalog(map[string]Theme{"ocean": {Primary: "#5fafff", Focused: "39"}})
+	require.NoError(t, err)
+
+	// Act
+	ocean, oceanErr := catalog.theme("ocean")
+	_, unknownErr := catalog.theme("solarized")
+
+	// Assert
+	require.NoError(t, oceanErr)
+	assert.Equal(t, lipgloss.Color("#5fafff"), ocean.Primary)
+	assert.Equal(t, lipgloss.Color("39"), ocean.Focused)
+	assert.Equal(t, DefaultTheme().Error, ocean.Error, "left out colors should be the default ones")
+	assert.ErrorContains(t, unknownErr, "unknown theme solarized, pick one of default, cyberpunk, dracula, gruvbox, ocean")
+	assert.Equal(t, "cyberpunk", catalog.next(DefaultThemeName))
+	assert.Equal(t, DefaultThemeName, catalog.next("ocean"), "the last theme should wrap around")
+}
+
+func TestLoadConfigThemes(t *testing.T) {
+	// Arrange
+	config := bytes.Replace(BaseSettings, []byte("  themes: {}\n"), []byte("  themes:\n    ocean:\n      primary: \"#5fafff\"\n      button-active: 39\n"), 1)
+	t.Setenv("TUI_APP_THEME", "ocean")
+
+	// Act
+	settings, err := LoadConfig[Settings]("TUI", config)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "ocean", settings.App.Theme)
+	assert.Equal(t, map[string]Theme{"ocean": {Primary: "#5fafff", ButtonActive: "39"}}, settings.App.Themes)
+}
+
+func TestModelSetTheme(t *testing.T) {
+	// Arrange
+	t.Cleanup(func() { ApplyTheme(DefaultTheme()) })
+	m := newTestModel(t, &Settings{}, DefaultTCPRoundTripper)
+
+	// Act
+	err := m.setTheme("gruvbox")
+	unknownErr := m.setTheme("solarized")
+
+	// Assert
+	require.NoError(t, err)
+	assert.ErrorContains(t, unknownErr, "unknown theme solarized")
+	assert.Equal(t, "gruvbox", m.themeName, "an unknown theme should keep the current one")
+	assert.Equal(t, GruvboxTheme(), activeTheme)
+	assert.Equal(t, GruvboxTheme().Primary, titleStyle.GetForeground())
+	assert.Equal(t, GruvboxTheme().Text, m.enrollment.TextStyle.GetForeground(), "existing inputs should be restyled")
+	assert.Equal(t, GruvboxTheme().Text, m.forms[0].fields[0].input.TextStyle.GetForeground())
+	assert.Equal(t, GruvboxTheme().Text, m.profiles.fields[0].TextStyle.GetForeground())
+}
@@ -0,0 +1,247 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"os"
+	"text/tabwriter"
+	"time"
+)
+
+// TransportTiming is the connection setup of one exchange
+type TransportTiming struct {
+	Handshake time.Duration
+	Resumed   bool
+}
+
+// TimedRoundTripper is a RoundTripper that also reports the connection setup of every exchange, so
+// it can be told apart from the time spent on the request itself
+type TimedRoundTripper interface {
+	RoundTripper
+	RequestReplyTimed(ctx context.Context, address string, req []byte) ([]byte, TransportTiming, error)
+}
+
+var (
+	_ RoundTripper      = (*TLSRoundTripper)(nil)
+	_ TimedRoundTripper = (*TLSRoundTripper)(nil)
+)
+
+// TLSRoundTripper is TCPRoundTripper over TLS, a new connection is handshaken for every exchange.
+// Sessions are resumed when Config has a ClientSessionCache.
+type TLSRoundTripper struct {
+	DialTimeout  time.Duration
+	WriteTimeout time.Duration
+	ReadTimeout  time.Duration
+	Config       *tls.Config
+}
+
+func NewTLSRoundTripper(dialTimeout time.Duration, writeTimeout time.Duration, readTimeout time.Duration, config *tls.Config) *TLSRoundTripper {
+	return &TLSRoundTripper{
+		DialTimeout:  dialTimeout,
+		WriteTimeout: writeTimeout,
+		ReadTimeout:  readTimeout,
+		Config:       config,
+	}
+}
+
+// RequestReply implements RoundTripper.
+func (t *TLSRoundTripper) RequestReply(ctx context.Context, address string, req []byte) ([]byte, error) {
+	resp, _, err := t.RequestReplyTimed(ctx, address, req)
+	return resp, err
+}
+
+// RequestReplyTimed implements TimedRoundTripper.
+func (t *TLSRoundTripper) RequestReplyTimed(ctx context.Context, address string, req []byte) ([]byte, TransportTiming, error) {
+	_, span := tracer.Start(ctx, "TLSRoundTripper.RequestReply")
+	defer span.End()
+
+	slog.DebugContext(ctx, "Connecting to TLS server", slog.String("address", address))
+	rawConn, err := dial(address, t.DialTimeout)
+	if err != nil {
+		slog.Error("Error connecting to TLS server", slog.String("address", address), slog.String("error", err.Error()))
+		return nil, TransportTiming{}, err
+	}
+	defer rawConn.Close()
+
+	config := t.Config
+	if config.ServerName == "" {
+		// Unix sockets have no host to verify, the certificates of the certs command cover localhost
+		host := "localhost"
+		if network, addr := splitAddress(address); network == TransportTCP {
+			host, _, err = net.SplitHostPort(addr)
+			if err != nil {
+				return nil, TransportTiming{}, err
+			}
+		}
+
+		config = config.Clone()
+		config.ServerName = host
+	}
+
+	conn := tls.Client(rawConn, config)
+	defer conn.Close()
+
+	handshakeCtx, cancel := context.WithTimeout(ctx, t.DialTimeout)
+	defer cancel()
+
+	start := time.Now()
+	if err := conn.HandshakeContext(handshakeCtx); err != nil {
+		slog.Error("Error in the TLS handshake", slog.String("address", address), slog.String("error", err.Error()))
+		return nil, TransportTiming{}, err
+	}
+	timing := TransportTiming{
+		Handshake: time.Since(start),
+		Resumed:   conn.ConnectionState().DidResume,
+	}
+
+	resp, err := exchange(ctx, conn, address, req, t.WriteTimeout, t.ReadTimeout)
+
+	return resp, timing, err
+}
+
+// tlsVersion converts the min-version setting, TLS 1.2 is the default
+func tlsVersion(version string) (uint16, error) {
+	switch version {
+	case "", "1.2":
+		return tls.VersionTLS12, nil
+	case "1.3":
+		return tls.VersionTLS13, nil
+	default:
+		return 0, fmt.Errorf("unsupported TLS version %s", version)
+	}
+}
+
+// loadCertPool reads the PEM certificates of a CA bundle
+func loadCertPool(path string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("no certificates found in %s", path)
+	}
+
+	return pool, nil
+}
+
+// ClientConfig builds the TLS configuration of a client, the system roots are used without a CA bundle
+func (s TLSSettings) ClientConfig() (*tls.Config, error) {
+	version, err := tlsVersion(s.MinVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	config := &tls.Config{
+		MinVersion: version,
+		ServerName: s.ServerName,
+	}
+
+	if s.CAFile != "" {
+		config.RootCAs, err = loadCertPool(s.CAFile)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if s.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(s.CertFile, s.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	if s.SessionCache > 0 {
+		config.ClientSessionCache = tls.NewLRUClientSessionCache(s.SessionCache)
+	}
+
+	return config, nil
+}
+
+// ServerConfig builds the TLS configuration of a listener, client certificates are required when
+// there is a CA bundle
+func (s TLSSettings) ServerConfig() (*tls.Config, error) {
+	if s.CertFile == "" {
+		return nil, fmt.Errorf("a TLS listener needs a certificate, set tls.cert-file and tls.key-file")
+	}
+
+	version, err := tlsVersion(s.MinVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	cert, err := tls.LoadX509KeyPair(s.CertFile, s.KeyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	config := &tls.Config{
+		MinVersion:             version,
+		Certificates:           []tls.Certificate{cert},
+		SessionTicketsDisabled: s.SessionCache == 0,
+	}
+
+	if s.CAFile != "" {
+		config.ClientCAs, err = loadCertPool(s.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	return config, nil
+}
+
+// NewTLSListener listens on a host:port or unix:// address and serves TLS with the settings, for
+// servers of any protocol
+func NewTLSListener(address string, settings TLSSettings) (net.Listener, error) {
+	config, err := settings.ServerConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	listener, err := Listen(address)
+	if err != nil {
+		return nil, err
+	}
+
+	return tls.NewListener(listener, config), nil
+}
+
+// HandshakeOperationSummary splits the latency of the TLS exchanges of one operation
+type HandshakeOperationSummary struct {
+	Protocol   string         `json:"protocol"`
+	Operation  string         `json:"operation"`
+	Handshakes int            `json:"handshakes"`
+	Resumed    int            `json:"resumed"`
+	Handshake  LatencySummary `json:"handshake"`
+	Request    LatencySummary `json:"request"`
+}
+
+// WriteHandshakeText writes a table of the handshake and request time of every summary
+func WriteHandshakeText(w io.Writer, summaries []HandshakeOperationSummary) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "protocol\toperation\thandshakes\tresumed\thandshake p50\thandshake p99\trequest p50\trequest p99\t")
+
+	for _, s := range summaries {
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%d\t%s\t%s\t%s\t%s\t\n",
+			s.Protocol,
+			s.Operation,
+			s.Handshakes,
+			s.Resumed,
+			s.Handshake.P50.Round(time.Microsecond),
+			s.Handshake.P99.Round(time.Microsecond),
+			s.Request.P50.Round(time.Microsecond),
+			s.Request.P99.Round(time.Microsecond),
+		)
+	}
+
+	return tw.Flush()
+}
@@ -0,0 +1,301 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestCertificates(t *testing.T) CertificateFiles {
+	t.Helper()
+
+	files, err := GenerateCertificates(t.TempDir(), []string{"localhost", "127.0.0.1"}, time.Hour)
+	require.NoError(t, err, "certificates should be generated")
+
+	return files
+}
+
+func newTestTLSRoundTripper(t *testing.T, settings TLSSettings) *TLSRoundTripper {
+	t.Helper()
+
+	config, err := settings.ClientConfig()
+	require.NoError(t, err)
+
+	return NewTLSRoundTripper(time.Second, time.Second, time.Second, config)
+}
+
+func TestTLSRoundTripperFlow(t *testing.T) {
+	files := newTestCertificates(t)
+
+	for _, fixture := range appLayerFlowFixtures(t) {
+		t.Run(fixture.protocol, func(t *testing.T) {
+			// Arrange
+			server := newTLSMockServer(t, fixture.protocol, files.ServerSettings(),
+				mockExchange{Expect: fixture.authRequest, Reply: fixture.authReply},
+				mockExchange{Expect: fixture.echoRequest, Reply: fixture.echoReply},
+			)
+			serde, err := NewSerde(fixture.protocol)
+			require.NoError(t, err)
+			client := NewAppLayerClient[OperationRequest, OperationResponse](serde, newTestTLSRoundTripper(t, files.ClientSettings()), &AppSettings{})
+			ctx := context.Background()
+
+			// Act
+			authResp, authErr := client.Auth(ctx, server.Address(), &AuthRequest{
+				StudentID: "538349",
+				Timestamp: time.Date(2025, 10, 10, 14, 30, 0, 0, time.UTC),
+			})
+			require.NoError(t, authErr, "Auth should not return an error")
+
+			echoResp := &EchoResponse{}
+			echoErr := client.Do(ctx, server.Address(), EchoRequest{Message: "ola mundo"}, echoResp, authResp.Token)
+
+			// Assert
+			assert.Equal(t, "tokenauth", authResp.Token)
+			require.NoError(t, echoErr, "Do should not return an error")
+			assert.Equal(t, "ECO: ola mundo", echoResp.EchoMessage)
+
+			server.AssertExhausted(t)
+		})
+	}
+}
+
+func TestTLSRoundTripperResumesSessions(t *testing.T) {
+	for _, version := range []string{"1.2", "1.3"} {
+		t.Run(version, func(t *testing.T) {
+			// Arrange
+			files := newTestCertificates(t)
+			serverSettings := files.ServerSettings()
+			serverSettings.SessionCache = 1
+			clientSettings := files.ClientSettings()
+			clientSettings.MinVersion = version
+			clientSettings.SessionCache = 8
+
+			reply := []byte(`{"sucesso": true}`)
+			server := newTLSMockServer(t, ProtocolJSON, serverSettings,
+				mockExchange{Reply: reply},
+				mockExchange{Reply: reply},
+			)
+			roundTripper := newTestTLSRoundTripper(t, clientSettings)
+			ctx := context.Background()
+
+			// Act
+			firstResp, first, firstErr := roundTripper.RequestReplyTimed(ctx, server.Address(), []byte(`{}`))
+			secondResp, second, secondErr := roundTripper.RequestReplyTimed(ctx, server.Address(), []byte(`{}`))
+
+			// Assert
+			require.NoError(t, firstErr)
+			require.NoError(t, secondErr)
+			assert.Equal(t, reply, firstResp)
+			assert.Equal(t, reply, secondResp)
+			assert.Positive(t, first.Handshake)
+			assert.Positive(t, second.Handshake)
+			assert.False(t, first.Resumed, "the first connection has no session to resume")
+			assert.True(t, second.Resumed, "the second connection should resume the first session")
+		})
+	}
+}
+
+func TestTLSRoundTripperWithoutSessionCache(t *testing.T) {
+	// Arrange
+	files := newTestCertificates(t)
+	reply := []byte(`{"sucesso": true}`)
+	server := newTLSMockServer(t, ProtocolJSON, files.ServerSettings(),
+		mockExchange{Reply: reply},
+		mockExchange{Reply: reply},
+	)
+	roundTripper := newTestTLSRoundTripper(t, files.ClientSettings())
+	ctx := context.Background()
+
+	// Act
+	_, first, firstErr := roundTripper.RequestReplyTimed(ctx, server.Address(), []byte(`{}`))
+	_, second, secondErr := roundTripper.RequestReplyTimed(ctx, server.Address(), []byte(`{}`))
+
+	// Assert
+	require.NoError(t, firstErr)
+	require.NoError(t, secondErr)
+	assert.False(t, first.Resumed)
+	assert.False(t, second.Resumed, "sessions are only resumed with a session cache")
+}
+
+func TestTLSRoundTripperRejections(t *testing.T) {
+	files := newTestCertificates(t)
+	other := newTestCertificates(t)
+
+	tests := []struct {
+		name   string
+		client TLSSettings
+	}{
+		{
+			name:   "client without certificate",
+			client: TLSSettings{CAFile: files.CA},
+		},
+		{
+			name:   "client certificate of another CA",
+			client: TLSSettings{CAFile: files.CA, CertFile: other.ClientCert, KeyFile: other.ClientKey},
+		},
+		{
+			name:   "server of another CA",
+			client: other.ClientSettings(),
+		},
+		{
+			name:   "server name not in the certificate",
+			client: TLSSettings{CAFile: files.CA, CertFile: files.ClientCert, KeyFile: files.ClientKey, ServerName: "other.example"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			server := newTLSMockServer(t, ProtocolJSON, files.ServerSettings())
+			roundTripper := newTestTLSRoundTripper(t, tt.client)
+
+			// Act
+			_, err := roundTripper.RequestReply(context.Background(), server.Address(), []byte(`{}`))
+
+			// Assert
+			assert.Error(t, err)
+			assert.Empty(t, server.Requests(), "rejected connections should not reach the server")
+		})
+	}
+}
+
+func TestTLSSettingsClientConfig(t *testing.T) {
+	files := newTestCertificates(t)
+
+	tests := []struct {
+		name     string
+		settings TLSSettings
+		assert   func(t *testing.T, config *tls.Config)
+		wantErr  bool
+	}{
+		{
+			name:     "defaults",
+			settings: TLSSettings{},
+			assert: func(t *testing.T, config *tls.Config) {
+				assert.Equal(t, uint16(tls.VersionTLS12), config.MinVersion)
+				assert.Nil(t, config.RootCAs, "the system roots are used without a CA bundle")
+				assert.Empty(t, config.Certificates)
+				assert.Nil(t, config.ClientSessionCache)
+			},
+		},
+		{
+			name:     "mutual TLS",
+			settings: TLSSettings{CAFile: files.CA, CertFile: files.ClientCert, KeyFile: files.ClientKey, ServerName: "localhost", MinVersion: "1.3", SessionCache: 4},
+			assert: func(t *testing.T, config *tls.Config) {
+				assert.Equal(t, uint16(tls.VersionTLS13), config.MinVersion)
+				assert.Equal(t, "localhost", config.ServerName)
+				assert.NotNil(t, config.RootCAs)
+				assert.Len(t, config.Certificates, 1)
+				assert.NotNil(t, config.ClientSessionCache)
+			},
+		},
+		{name: "unsupported version", settings: TLSSettings{MinVersion: "1.0"}, wantErr: true},
+		{name: "missing CA bundle", settings: TLSSettings{CAFile: files.CA + ".missing"}, wantErr: true},
+		{name: "CA bundle without certificates", settings: TLSSettings{CAFile: files.ClientKey}, wantErr: true},
+		{name: "key of another certificate", settings: TLSSettings{CertFile: files.ClientCert, KeyFile: files.ServerKey}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			config, err := tt.settings.ClientConfig()
+
+			// Assert
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			tt.assert(t, config)
+		})
+	}
+}
+
+func TestTLSSettingsServerConfig(t *testing.T) {
+	// Arrange
+	files := newTestCertificates(t)
+	withoutClientAuth := files.ServerSettings()
+	withoutClientAuth.CAFile = ""
+
+	// Act
+	mutual, mutualErr := files.ServerSettings().ServerConfig()
+	plain, plainErr := withoutClientAuth.ServerConfig()
+	_, missingErr := TLSSettings{}.ServerConfig()
+
+	// Assert
+	require.NoError(t, mutualErr)
+	require.NoError(t, plainErr)
+	assert.Equal(t, tls.RequireAndVerifyClientCert, mutual.ClientAuth, "a CA bundle requires client certificates")
+	assert.Equal(t, tls.NoClientCert, plain.ClientAuth)
+	assert.True(t, mutual.SessionTicketsDisabled, "a zero session cache disables resumption")
+	assert.ErrorContains(t, missingErr, "tls.cert-file")
+}
+
+func TestWireRecordingRoundTripperTiming(t *testing.T) {
+	// Arrange
+	files := newTestCertificates(t)
+	server := newTLSMockServer(t, ProtocolJSON, files.ServerSettings(), mockExchange{Reply: []byte(`{"sucesso": true}`)})
+	recording := &wireRecordingRoundTripper{inner: newTestTLSRoundTripper(t, files.ClientSettings())}
+
+	// Act
+	_, err := recording.RequestReply(context.Background(), server.Address(), []byte(`{}`))
+
+	// Assert
+	require.NoError(t, err)
+	assert.True(t, recording.timed, "TLS exchanges should be timed")
+	assert.Positive(t, recording.timing.Handshake)
+
+	recording.reset()
+	assert.False(t, recording.timed)
+}
+
+func TestWriteHandshakeText(t *testing.T) {
+	// Arrange
+	summaries := []HandshakeOperationSummary{
+		{
+			Protocol:   ProtocolJSON,
+			Operation:  OperationEcho,
+			Handshakes: 10,
+			Resumed:    9,
+			Handshake:  LatencySummary{P50: 300 * time.Microsecond, P99: 2 * time.Millisecond},
+			Request:    LatencySummary{P50: 150 * time.Microsecond, P99: 400 * time.Microsecond},
+		},
+	}
+
+	var buf bytes.Buffer
+
+	// Act
+	err := WriteHandshakeText(&buf, summaries)
+
+	// Assert
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+	assert.Equal(t, []string{"json", "echo", "10", "9", "300µs", "2ms", "150µs", "400µs"}, strings.Fields(lines[1]))
+}
+
+func TestGenerateCertificatesVerify(t *testing.T) {
+	// Arrange
+	files := newTestCertificates(t)
+
+	// Act
+	server, err := tls.LoadX509KeyPair(files.ServerCert, files.ServerKey)
+	require.NoError(t, err)
+	pool, err := loadCertPool(files.CA)
+	require.NoError(t, err)
+
+	// Assert
+	require.NotNil(t, server.Leaf)
+	for _, host := range []string{"localhost", "127.0.0.1"} {
+		assert.NoError(t, server.Leaf.VerifyHostname(host), "the server certificate should cover %s", host)
+	}
+	_, verifyErr := server.Leaf.Verify(x509.VerifyOptions{Roots: pool, KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}})
+	assert.NoError(t, verifyErr, "the server certificate should be signed by the CA")
+}
@@ -1,15 +1,18 @@
 package main
 
 import (
+	"cmp"
 	"context"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
-	"strconv"
 	"strings"
 	"time"
 
+	"github.com/atotto/clipboard"
 	"github.com/charmbracelet/bubbles/help"
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/progress"
@@ -25,20 +28,6 @@ const (
 	minHeight = 30
 )
 
-// Color palette
-var (
-	colorPrimary      = lipgloss.Color("205")
-	colorSecondary    = lipgloss.Color("170")
-	colorText         = lipgloss.Color("231")
-	colorMuted        = lipgloss.Color("240")
-	colorBorder       = lipgloss.Color("240")
-	colorError        = lipgloss.Color("196")
-	colorBackground   = lipgloss.Color("235")
-	colorFocused      = lipgloss.Color("170")
-	colorButtonActive = lipgloss.Color("170")
-	colorButtonBg     = lipgloss.Color("0")
-)
-
 type tickMsg time.Time
 
 func doTick() tea.Cmd {
@@ -47,96 +36,57 @@ func doTick() tea.Cmd {
 	})
 }
 
-// Styles
+// Styles, built from the active theme by ApplyTheme
 var (
-	titleStyle = lipgloss.NewStyle().
-			Bold(true).
-			Foreground(colorPrimary)
-
-	fieldStyle = lipgloss.NewStyle()
-
-	focusedFieldStyle = lipgloss.NewStyle().
-				Bold(true).
-				Foreground(colorFocused)
-
-	inputStyle = lipgloss.NewStyle().
-			Foreground(colorText)
-
-	hintStyle = lipgloss.NewStyle().
-			Foreground(colorMuted).
-			Italic(true)
-
-	buttonBlurredStyle = lipgloss.NewStyle().
-				Padding(0, 2).
-				MarginRight(1).
-				Foreground(colorText).
-				Background(colorButtonBg)
-
-	buttonFocusedStyle = lipgloss.NewStyle().
-				Padding(0, 2).
-				MarginRight(1).
-				Bold(true).
-				Foreground(colorButtonBg).
-				Background(colorButtonActive)
-
-	panelBorderStyle = lipgloss.NewStyle().
-				BorderStyle(lipgloss.NormalBorder()).
-				BorderForeground(colorMuted)
-
-	errorPopupStyle = lipgloss.NewStyle().
-			Border(lipgloss.RoundedBorder()).
-			BorderForeground(colorError).
-			Background(colorBackground).
-			Foreground(colorText).
-			Padding(1, 2)
-
-	errorTitleStyle = lipgloss.NewStyle().
-			Bold(true).
-			Foreground(colorError).
-			Align(lipgloss.Center)
-
-	errorMsgStyle = lipgloss.NewStyle().
-			Foreground(colorText)
-
-	errorHintStyle = lipgloss.NewStyle().
-			Foreground(colorMuted).
-			Italic(true).
-			Align(lipgloss.Center)
-
-	contentStyle = lipgloss.NewStyle()
-
-	separatorStyle = lipgloss.NewStyle().
-			Foreground(colorMuted)
+	titleStyle         lipgloss.Style
+	fieldStyle         lipgloss.Style
+	focusedFieldStyle  lipgloss.Style
+	inputStyle         lipgloss.Style
+	hintStyle          lipgloss.Style
+	placeholderStyle   lipgloss.Style
+	buttonBlurredStyle lipgloss.Style
+	buttonFocusedStyle lipgloss.Style
+	panelBorderStyle   lipgloss.Style
+	errorPopupStyle    lipgloss.Style
+	errorTitleStyle    lipgloss.Style
+	errorMsgStyle      lipgloss.Style
+	errorHintStyle     lipgloss.Style
+	contentStyle       lipgloss.Style
+	separatorStyle     lipgloss.Style
 )
 
-type lastOperation struct {
-	success      bool
-	operation    string
-	params       string
-	protocol     string
-	whenHappened time.Time
-}
-
 // Key bindings
 type keyMap struct {
-	Up       key.Binding
-	Down     key.Binding
-	Left     key.Binding
-	Right    key.Binding
-	Enter    key.Binding
-	Quit     key.Binding
-	Tab      key.Binding
-	ShiftTab key.Binding
+	Up         key.Binding
+	Down       key.Binding
+	Left       key.Binding
+	Right      key.Binding
+	Enter      key.Binding
+	Quit       key.Binding
+	Tab        key.Binding
+	ShiftTab   key.Binding
+	Toggle     key.Binding
+	Screen     key.Binding
+	Wire       key.Binding
+	Theme      key.Binding
+	Save       key.Binding
+	Format     key.Binding
+	Copy       key.Binding
+	CopyCLI    key.Binding
+	CopyPython key.Binding
+	Cancel     key.Binding
 }
 
 func (k keyMap) ShortHelp() []key.Binding {
-	return []key.Binding{k.Tab, k.Left, k.Right, k.Up, k.Down, k.Enter, k.Quit}
+	return []key.Binding{k.Screen, k.Wire, k.Theme, k.Tab, k.Left, k.Right, k.Up, k.Down, k.Enter, k.Quit}
 }
 
 func (k keyMap) FullHelp() [][]key.Binding {
 	return [][]key.Binding{
 		{k.Tab, k.ShiftTab, k.Up, k.Down},
-		{k.Left, k.Right, k.Enter, k.Quit},
+		{k.Left, k.Right, k.Enter, k.Cancel, k.Quit},
+		{k.Toggle, k.Screen, k.Wire, k.Theme},
+		{k.Save, k.Format, k.Copy, k.CopyCLI, k.CopyPython},
 	}
 }
 
@@ -173,35 +123,102 @@ var keys = keyMap{
 		key.WithKeys("shift+tab"),
 		key.WithHelp("shift+tab", "prev field"),
 	),
+	Toggle: key.NewBinding(
+		key.WithKeys(" "),
+		key.WithHelp("space", "toggle"),
+	),
+	Cancel: key.NewBinding(
+		key.WithKeys("esc"),
+		key.WithHelp("esc", "cancel comparison"),
+	),
+	Screen: key.NewBinding(
+		key.WithKeys("ctrl+t"),
+		key.WithHelp("ctrl+t", "switch screen"),
+	),
+	Wire: key.NewBinding(
+		key.WithKeys("ctrl+r"),
+		key.WithHelp("ctrl+r", "raw wire"),
+	),
+	Theme: key.NewBinding(
+		key.WithKeys("ctrl+o"),
+		key.WithHelp("ctrl+o", "next theme"),
+	),
+	Save: key.NewBinding(
+		key.WithKeys("ctrl+s"),
+		key.WithHelp("ctrl+s", "save result"),
+	),
+	Format: key.NewBinding(
+		key.WithKeys("ctrl+x"),
+		key.WithHelp("ctrl+x", "export format"),
+	),
+	Copy: key.NewBinding(
+		key.WithKeys("ctrl+y"),
+		key.WithHelp("ctrl+y", "copy result"),
+	),
+	CopyCLI: key.NewBinding(
+		key.WithKeys("ctrl+g"),
+		key.WithHelp("ctrl+g", "copy CLI command"),
+	),
+	CopyPython: key.NewBinding(
+		key.WithKeys("ctrl+p"),
+		key.WithHelp("ctrl+p", "copy Python command"),
+	),
 }
 
+type screen int
+
+const (
+	screenClient screen = iota
+	screenDashboard
+	screenCompare
+	screenHistory
+	screenProfiles
+	screenMonitor
+	screenCount
+)
+
+// screenNames label the screens in the title bar
+var screenNames = []string{"Client", "Benchmark", "Compare", "History", "Servers", "Monitor"}
+
 // Message types for async operations
 type operationResultMsg struct {
-	operation string
-	params    string
-	protocol  string
-	result    string
-	err       error
+	operation   string
+	params      string
+	protocol    string
+	compression string
+	studentID   string
+	result      string
+	latency     time.Duration
+	request     OperationRequest
+	response    OperationResponse
+	wire        *wireCapture
+	session     *clientSession
+	err         error
 }
 
 type focusField int
 
 const (
 	focusProtocol focusField = iota
+	focusCompression
 	focusEnrollment
 	focusOperation
 	focusParams
 	focusSubmit
+	focusCompare
+	focusSession
+	focusFieldCount
 )
 
 type model struct {
 	// Form fields
-	protocolIdx  int
-	protocols    []string
-	enrollment   textinput.Model
-	operationIdx int
-	operations   []string
-	paramsInput  textinput.Model
+	protocolIdx    int
+	protocols      []string
+	compressionIdx []int // per protocol, indexes Compressions
+	enrollment     textinput.Model
+	operationIdx   int
+	operations     []string
+	forms          []operationForm // per operation
 
 	// Components
 	help     help.Model
@@ -209,17 +226,33 @@ type model struct {
 	progress progress.Model
 
 	// State
+	screen       screen
+	dashboard    dashboard
+	history      historyPane
+	session      *clientSession
+	profiles     profilesPane
+	monitor      monitorPane
 	focusIndex   focusField
 	result       string
+	wire         *wireCapture
+	showWire     bool
+	export       *resultExport
+	exportFormat int // indexes exportFormats
+	exportDir    string
+	exportStatus string
+	copyText     func(string) error
 	errorMsg     string
 	showError    bool
 	isValidation bool
 	loading      bool
 
 	// App settings
-	settings *Settings
-	width    int
-	height   int
+	themes       themeCatalog
+	themeName    string
+	settings     *Settings
+	roundTripper RoundTripper
+	width        int
+	height       int
 
 	// viewport
 	ready           bool
@@ -227,25 +260,30 @@ type model struct {
 	leftPanelWidth  int
 	rightPanelWidth int
 
-	renderer       *glamour.TermRenderer
-	lastOperations []lastOperation
+	renderer *glamour.TermRenderer
+
+	// Compare screen
+	comparison      *comparisonResultMsg
+	compareViewport viewport.Model
+	// compareCancel stops the comparison in flight, it is nil when there is none
+	compareCancel context.CancelFunc
 }
 
 const defaultEnrollmentID = "538349"
 
-func initialModel(settings *Settings) model {
+// initialModel returns the client screen of the TUI, with profiles and history loaded by the caller
+func initialModel(settings *Settings, roundTripper RoundTripper, profiles *serverProfiles, history *operationHistory) model {
 	enrollment := textinput.New()
 	enrollment.Placeholder = "Enter enrollment ID"
+	enrollment.Width = 20
 	enrollment.CharLimit = 50
-	enrollment.PromptStyle = inputStyle
-	enrollment.TextStyle = inputStyle
+	styleInput(&enrollment)
 	enrollment.SetValue(defaultEnrollmentID)
 
-	paramsInput := textinput.New()
-	paramsInput.Placeholder = "Enter parameters (JSON or comma-separated)"
-	paramsInput.CharLimit = 200
-	paramsInput.PromptStyle = inputStyle
-	paramsInput.TextStyle = inputStyle
+	forms := make([]operationForm, len(operationRequests))
+	for i, req := range operationRequests {
+		forms[i] = newOperationForm(req)
+	}
 
 	r, err := glamour.NewTermRenderer(
 		glamour.WithAutoStyle(),
@@ -255,29 +293,39 @@ func initialModel(settings *Settings) model {
 		panic(err)
 	}
 
-	prog := progress.New(progress.WithDefaultGradient())
+	prog := newProgress()
 
 	return model{
-		protocolIdx:    0,
-		protocols:      []string{"json", "string", "protobuf"},
-		enrollment:     enrollment,
-		operationIdx:   0,
-		operations:     []string{"echo", "sum", "timestamp", "history", "status"},
-		paramsInput:    paramsInput,
-		help:           help.New(),
-		keys:           keys,
-		progress:       prog,
-		focusIndex:     focusProtocol,
-		settings:       settings,
-		width:          minWidth,
-		height:         minHeight,
-		renderer:       r,
-		lastOperations: make([]lastOperation, 0),
+		protocolIdx:     0,
+		protocols:       Protocols,
+		compressionIdx:  make([]int, len(Protocols)),
+		enrollment:      enrollment,
+		operationIdx:    0,
+		operations:      Operations,
+		forms:           forms,
+		help:            help.New(),
+		keys:            keys,
+		progress:        prog,
+		themes:          builtinThemes(),
+		themeName:       DefaultThemeName,
+		focusIndex:      focusProtocol,
+		dashboard:       newDashboard(settings, roundTripper),
+		history:         newHistoryPane(history),
+		profiles:        newProfilesPane(profiles, settings),
+		monitor:         newMonitorPane(settings, roundTripper),
+		settings:        settings,
+		roundTripper:    roundTripper,
+		exportDir:       ".",
+		copyText:        clipboard.WriteAll,
+		width:           minWidth,
+		height:          minHeight,
+		renderer:        r,
+		compareViewport: viewport.New(minWidth-4, minHeight-10),
 	}
 }
 
 func (m model) Init() tea.Cmd {
-	return tea.Batch(doTick(), textinput.Blink)
+	return tea.Batch(doTick(), textinput.Blink, m.profiles.check(m.profiles.store.active()))
 }
 
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -285,6 +333,10 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	switch msg := msg.(type) {
 	case tickMsg:
+		m.dashboard.tick(time.Time(msg))
+		if m.screen == screenProfiles {
+			return m, tea.Batch(doTick(), m.profiles.refresh(time.Time(msg)))
+		}
 		return m, doTick() // Schedule the next tick
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
@@ -319,15 +371,121 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		switch {
 		case key.Matches(msg, m.keys.Quit):
-			return m, tea.Quit
+			if m.dashboard.running() {
+				m.dashboard.run.cancel()
+			}
+			m.cancelComparison()
+			// Do not leave sessions open on the server
+			var logouts []tea.Cmd
+			if m.session != nil {
+				logouts = append(logouts, m.logout())
+			}
+			if m.monitor.running() {
+				stop, _ := m.monitor.stop()
+				logouts = append(logouts, stop)
+			}
+			return m, tea.Sequence(append(logouts, tea.Quit)...)
+
+		case key.Matches(msg, m.keys.Cancel) && m.compareCancel != nil:
+			m.cancelComparison()
+			return m, nil
+
+		case key.Matches(msg, m.keys.Screen):
+			m.screen = (m.screen + 1) % screenCount
+			return m, nil
 
+		case key.Matches(msg, m.keys.Theme):
+			if err := m.setTheme(m.themes.next(m.themeName)); err != nil {
+				m.showError = true
+				m.isValidation = false
+				m.errorMsg = err.Error()
+			}
+			return m, nil
+		}
+
+		if m.screen == screenDashboard {
+			var err error
+			m.dashboard, cmd, err = m.dashboard.update(msg, m.keys, m.enrollment.Value())
+			if err != nil {
+				m.showError = true
+				m.isValidation = true
+				m.errorMsg = err.Error()
+			}
+			return m, cmd
+		}
+
+		if m.screen == screenClient && key.Matches(msg, m.keys.Save, m.keys.Format, m.keys.Copy, m.keys.CopyCLI, m.keys.CopyPython) {
+			if err := m.exportResult(msg); err != nil {
+				m.showError = true
+				m.isValidation = false
+				m.errorMsg = err.Error()
+			}
+			return m, nil
+		}
+
+		if m.screen == screenClient && key.Matches(msg, m.keys.Wire) {
+			m.showWire = !m.showWire
+			m.setViewportContent()
+			return m, nil
+		}
+
+		if m.screen == screenHistory {
+			var err error
+			m.history, cmd, err = m.history.update(msg, m.keys)
+			if err != nil {
+				m.showError = true
+				m.isValidation = true
+				m.errorMsg = err.Error()
+			}
+			return m, cmd
+		}
+
+		if m.screen == screenProfiles {
+			var err error
+			m.profiles, cmd, err = m.profiles.update(msg, m.keys)
+			if err != nil {
+				m.showError = true
+				m.isValidation = true
+				m.errorMsg = err.Error()
+			}
+			return m, cmd
+		}
+
+		if m.screen == screenMonitor {
+			var err error
+			m.monitor, cmd, err = m.monitor.update(msg, m.keys, m.enrollment.Value())
+			if err != nil {
+				m.showError = true
+				m.isValidation = true
+				m.errorMsg = err.Error()
+			}
+			return m, cmd
+		}
+
+		if m.screen == screenCompare {
+			switch {
+			case key.Matches(msg, m.keys.Down):
+				m.compareViewport.ScrollDown(1)
+			case key.Matches(msg, m.keys.Up):
+				m.compareViewport.ScrollUp(1)
+			}
+			return m, nil
+		}
+
+		switch {
 		case key.Matches(msg, m.keys.Tab):
-			m.focusIndex = (m.focusIndex + 1) % 5
-			m.updateFocus()
+			if m.focusIndex == focusParams && m.form().next() {
+				return m, nil
+			}
+			m.focusIndex = (m.focusIndex + 1) % focusFieldCount
+			m.updateFocus(false)
 
 		case key.Matches(msg, m.keys.ShiftTab):
-			m.focusIndex = (m.focusIndex - 1 + 5) % 5
-			m.updateFocus()
+			if m.focusIndex == focusParams && m.form().prev() {
+				return m, nil
+			}
+			m.focusIndex = (m.focusIndex - 1 + focusFieldCount) % focusFieldCount
+			m.updateFocus(true)
 
 		case key.Matches(msg, m.keys.Left):
 			switch m.focusIndex {
@@ -335,6 +493,10 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				if m.protocolIdx > 0 {
 					m.protocolIdx--
 				}
+			case focusCompression:
+				if m.compressionIdx[m.protocolIdx] > 0 {
+					m.compressionIdx[m.protocolIdx]--
+				}
 			case focusOperation:
 				if m.operationIdx > 0 {
 					m.operationIdx--
@@ -347,6 +509,10 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				if m.protocolIdx < len(m.protocols)-1 {
 					m.protocolIdx++
 				}
+			case focusCompression:
+				if m.compressionIdx[m.protocolIdx] < len(Compressions)-1 {
+					m.compressionIdx[m.protocolIdx]++
+				}
 			case focusOperation:
 				if m.operationIdx < len(m.operations)-1 {
 					m.operationIdx++
@@ -354,7 +520,11 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		case key.Matches(msg, m.keys.Enter):
 			if m.focusIndex == focusSubmit {
-				if err := m.validate(); err != nil {
+				err := m.validate()
+				if err == nil {
+					err = m.checkSession()
+				}
+				if err != nil {
 					m.showError = true
 					m.isValidation = true
 					m.errorMsg = err.Error()
@@ -363,6 +533,34 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.loading = true
 				return m, m.executeOperation()
 			}
+			if m.focusIndex == focusCompare && m.compareCancel == nil {
+				req, err := m.operationRequest()
+				if err == nil {
+					err = m.validate()
+				}
+				if err != nil {
+					m.showError = true
+					m.isValidation = true
+					m.errorMsg = err.Error()
+					return m, nil
+				}
+				m.loading = true
+				return m, m.executeComparison(req)
+			}
+			if m.focusIndex == focusSession && !m.loading {
+				if m.session != nil {
+					m.loading = true
+					return m, m.logout()
+				}
+				if m.enrollment.Value() == "" {
+					m.showError = true
+					m.isValidation = true
+					m.errorMsg = "enrollment ID is required"
+					return m, nil
+				}
+				m.loading = true
+				return m, m.login()
+			}
 		case key.Matches(msg, m.keys.Down):
 			m.viewport.ScrollDown(1)
 
@@ -371,43 +569,167 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 
 	case operationResultMsg:
-		newOp := lastOperation{
-			whenHappened: time.Now(),
-			operation:    msg.operation,
-			protocol:     msg.protocol,
-			params:       msg.params,
+		entry := historyEntry{
+			At:          time.Now(),
+			Protocol:    msg.protocol,
+			Compression: msg.compression,
+			StudentID:   msg.studentID,
+			Operation:   msg.operation,
+			Params:      msg.params,
+			Latency:     msg.latency,
+			Success:     msg.err == nil,
+			Result:      msg.result,
 		}
 		m.loading = false
+		if msg.wire != nil {
+			m.wire = msg.wire
+			entry.RequestBytes = len(msg.wire.request.data)
+			entry.ResponseBytes = len(msg.wire.response.data)
+			entry.RequestHex = hex.EncodeToString(msg.wire.request.data)
+			entry.ResponseHex = hex.EncodeToString(msg.wire.response.data)
+		}
+		if msg.request != nil {
+			m.export = newResultExport(msg, m.settings, m.profiles.settings)
+			m.exportStatus = ""
+		}
+		if msg.session != nil {
+			msg.session.operations++
+			if msg.err != nil {
+				msg.session.failures++
+			}
+		}
 		if msg.err != nil {
-			newOp.success = false
+			entry.Error = msg.err.Error()
 			m.showError = true
 			m.isValidation = false
 			m.errorMsg = msg.err.Error()
 		} else {
-			newOp.success = true
 			m.result = msg.result
-			content := ""
-
-			markdown := "```json\n" + m.result + "\n```"
-			rendered, err := m.renderer.Render(markdown)
-			if err == nil {
-				content = rendered
-			} else {
-				// Fallback to plain text if glamour fails
-				content = m.result
-			}
-			m.viewport.SetContent(content)
 		}
+		m.setViewportContent()
+		m.recordHistory(entry)
+
+		return m, nil
+
+	case loginResultMsg:
+		m.loading = false
+		m.recordHistory(sessionHistoryEntry("login", msg.session, msg.result, msg.latency, msg.err))
+		if msg.err != nil {
+			m.showError = true
+			m.isValidation = false
+			m.errorMsg = msg.err.Error()
+			return m, nil
+		}
+		m.session = msg.session
+		m.result = msg.result
+		m.export = nil
+		m.setViewportContent()
+
+		return m, nil
+
+	case logoutResultMsg:
+		m.loading = false
+		m.recordHistory(sessionHistoryEntry("logout", msg.session, msg.result, msg.latency, msg.err))
+		if m.session == msg.session {
+			m.session = nil
+		}
+		if msg.err != nil {
+			m.showError = true
+			m.isValidation = false
+			m.errorMsg = msg.err.Error()
+			return m, nil
+		}
+		m.result = msg.result
+		m.export = nil
+		m.setViewportContent()
+
+		return m, nil
+
+	case comparisonResultMsg:
+		m.loading = false
+		m.compareCancel = nil
+		if msg.canceled {
+			return m, nil
+		}
+		m.comparison = &msg
+		m.compareViewport.SetContent(renderComparison(msg.columns, m.compareViewport.Width))
+		m.compareViewport.GotoTop()
+		m.screen = screenCompare
+		m.recordHistory(msg.historyEntries()...)
+
+		return m, nil
+
+	case historyRerunMsg:
+		err := m.loadHistoryEntry(msg.entry)
+		if err == nil {
+			err = m.validate()
+		}
+		if err == nil {
+			err = m.checkSession()
+		}
+		if err != nil {
+			m.showError = true
+			m.isValidation = true
+			m.errorMsg = err.Error()
+			return m, nil
+		}
+		m.screen = screenClient
+		m.loading = true
+		return m, m.executeOperation()
 
-		if len(m.lastOperations) >= 5 {
-			m.lastOperations = m.lastOperations[1:]
+	case profileUseMsg:
+		if err := m.useProfile(msg.profile); err != nil {
+			m.showError = true
+			m.isValidation = true
+			m.errorMsg = err.Error()
+			return m, nil
 		}
+		m.profiles.status = "Using " + msg.profile.Name
+		return m, m.profiles.check(msg.profile)
 
-		m.lastOperations = append(m.lastOperations, newOp)
+	case profileHealthMsg:
+		m.profiles.finish(msg)
+		return m, nil
+
+	case monitorPollMsg:
+		return m, m.monitor.next(msg)
+
+	case monitorSampleMsg:
+		return m, m.monitor.finish(msg)
+
+	case monitorStoppedMsg:
+		m.monitor.stopped(msg)
+		return m, nil
 
+	case benchmarkDoneMsg:
+		if err := m.dashboard.finish(msg); err != nil && !errors.Is(err, context.Canceled) {
+			m.showError = true
+			m.isValidation = false
+			m.errorMsg = err.Error()
+		}
 		return m, nil
 	}
 
+	if m.screen == screenDashboard {
+		m.dashboard, cmd = m.dashboard.updateInputs(msg)
+		return m, cmd
+	}
+
+	if m.screen == screenHistory {
+		m.history, cmd = m.history.updateInputs(msg)
+		return m, cmd
+	}
+
+	if m.screen == screenProfiles {
+		m.profiles, cmd = m.profiles.updateInputs(msg)
+		return m, cmd
+	}
+
+	if m.screen == screenMonitor {
+		m.monitor, cmd = m.monitor.updateInputs(msg)
+		return m, cmd
+	}
+
 	m.viewport, cmd = m.viewport.Update(msg)
 
 	// Handle text input updates
@@ -415,34 +737,66 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case focusEnrollment:
 		m.enrollment, cmd = m.enrollment.Update(msg)
 	case focusParams:
-		m.paramsInput, cmd = m.paramsInput.Update(msg)
+		cmd = m.form().update(msg, m.keys)
 	}
 
 	return m, cmd
 }
 
-func (m *model) updateFocus() {
+// form is the parameter form of the selected operation
+func (m model) form() *operationForm {
+	return &m.forms[m.operationIdx]
+}
+
+// updateFocus focuses the input of the focused field, the last field of the form when going back
+func (m *model) updateFocus(back bool) {
 	m.enrollment.Blur()
-	m.paramsInput.Blur()
+	for i := range m.forms {
+		m.forms[i].blur()
+	}
 
 	switch m.focusIndex {
 	case focusEnrollment:
 		m.enrollment.Focus()
 	case focusParams:
-		m.paramsInput.Focus()
+		m.form().focus(back)
+	}
+}
+
+// setViewportContent shows the response of the last operation or, on the wire tab, its bytes
+func (m *model) setViewportContent() {
+	if m.showWire {
+		m.viewport.SetContent(lipgloss.NewStyle().Width(m.viewport.Width).Render(renderWire(m.wire)))
+		return
+	}
+
+	content := ""
+
+	markdown := "```json\n" + m.result + "\n```"
+	rendered, err := m.renderer.Render(markdown)
+	if err == nil {
+		content = rendered
+	} else {
+		// Fallback to plain text if glamour fails
+		content = m.result
 	}
+	m.viewport.SetContent(content)
 }
 
 func (m model) headerView() string {
-	localTitleStyle := titleStyle.Width(m.viewport.Width)
-	title := localTitleStyle.Render("╔═ Response ═╗")
-	return title
+	title := titleStyle.Render("╔═ Response ═╗")
+	hint := hintStyle.Render("  ctrl+r raw wire")
+	if m.showWire {
+		title = titleStyle.Render("╔═ Raw wire ═╗")
+		hint = hintStyle.Render("  ctrl+r response")
+	}
+	return lipgloss.NewStyle().Width(m.viewport.Width).Render(title + hint)
 }
 
 func (m model) footerView() string {
 	info := panelBorderStyle.Render(fmt.Sprintf("%3.f%%", m.viewport.ScrollPercent()*100))
 	line := strings.Repeat("─", max(0, m.viewport.Width-lipgloss.Width(info)))
-	return lipgloss.JoinHorizontal(lipgloss.Center, line, info)
+	return lipgloss.JoinVertical(lipgloss.Left, lipgloss.JoinHorizontal(lipgloss.Center, line, info), m.exportHint())
 }
 
 func (m model) validate() error {
@@ -450,70 +804,67 @@ func (m model) validate() error {
 		return fmt.Errorf("enrollment ID is required")
 	}
 
-	op := m.operations[m.operationIdx]
-	params := m.paramsInput.Value()
-
-	switch op {
-	case "echo":
-		if params == "" {
-			return fmt.Errorf("echo operation requires a message parameter")
-		}
-	case "sum":
-		if params == "" {
-			return fmt.Errorf("sum operation requires numbers (comma-separated)")
-		}
-		// Validate numbers
-		parts := strings.Split(params, ",")
-		for _, p := range parts {
-			p = strings.TrimSpace(p)
-			if _, err := strconv.Atoi(p); err != nil {
-				return fmt.Errorf("invalid number: %s", p)
-			}
-		}
-	case "history":
-		if params == "" {
-			return fmt.Errorf("history operation requires a limit parameter")
-		}
-		limit, err := strconv.Atoi(params)
-		if err != nil {
-			return fmt.Errorf("limit must be a number")
-		}
-		if limit < 1 || limit > 100 {
-			return fmt.Errorf("limit must be between 1 and 100")
-		}
-	case "status":
-		if params != "" && params != "true" && params != "false" {
-			return fmt.Errorf("status parameter must be 'true' or 'false' for detailed mode")
-		}
-	}
+	// Show the errors of every field along with the popup
+	form := m.form()
+	form.touch()
+	_, err := form.build()
 
-	return nil
+	return err
 }
 
 func (m model) executeOperation() tea.Cmd {
+	// The form is read here, the command runs outside the Update loop
+	req, reqErr := m.form().build()
+	params := m.form().params()
+	op := m.operations[m.operationIdx]
+	session := m.session
+
 	return func() tea.Msg {
 		ctx := context.Background()
 
+		msg := operationResultMsg{
+			operation:   op,
+			params:      params,
+			protocol:    m.protocols[m.protocolIdx],
+			compression: Compressions[m.compressionIdx[m.protocolIdx]],
+			studentID:   m.enrollment.Value(),
+			request:     req,
+			session:     session,
+		}
+		if reqErr != nil {
+			msg.err = reqErr
+			return msg
+		}
+
+		// Operations of a session reuse its login
+		if session != nil {
+			msg.protocol, msg.compression, msg.studentID = session.protocol, session.compression, session.studentID
+			msg.response, msg.wire, msg.latency, msg.err = m.runOperation(ctx, session, req)
+			if msg.err != nil {
+				msg.err = fmt.Errorf("operation failed: %w", msg.err)
+				return msg
+			}
+			msg.result = formatResponse(operationTitle(op), msg.response) + compressionSummary(session.compressed, "this session")
+			return msg
+		}
+
 		// Get protocol serde and server address
-		var serde Serde
-		var serverAddress string
+		serde, compressed, err := newClientSerde(msg.protocol, msg.compression)
+		if err != nil {
+			msg.err = err
+			return msg
+		}
 
-		switch m.protocols[m.protocolIdx] {
-		case "json":
-			serde = &JSONSerde{}
-			serverAddress = m.settings.App.JSONProtocolServerAddress
-		case "string":
-			serde = &StringSerde{}
-			serverAddress = m.settings.App.StringProtocolServerAddress
-		case "protobuf":
-			serde = &ProtobufSerde{}
-			serverAddress = m.settings.App.ProtobufProtocolServerAddress
+		serverAddress, err := m.settings.App.ServerAddress(msg.protocol)
+		if err != nil {
+			msg.err = err
+			return msg
 		}
 
 		// 1. Authenticate
 		authClient := NewAppLayerClient[*AuthRequest, *AuthResponse](
 			serde,
-			DefaultTCPRoundTripper,
+			m.roundTripper,
 			&m.settings.App,
 		)
 
@@ -524,130 +875,128 @@ func (m model) executeOperation() tea.Cmd {
 
 		authResp, err := authClient.Auth(ctx, serverAddress, authReq)
 		if err != nil {
-			return operationResultMsg{
-				err:       fmt.Errorf("authentication failed: %w", err),
-				operation: "auth",
-				params:    m.enrollment.Value(),
-				protocol:  m.protocols[m.protocolIdx],
-			}
+			msg.err = fmt.Errorf("authentication failed: %w", err)
+			msg.operation = "auth"
+			msg.params = m.enrollment.Value()
+			return msg
 		}
 
 		token := authResp.Token
-		var result string
 
 		// 2. Perform operation
-		op := m.operations[m.operationIdx]
-		switch op {
-		case "echo":
-			client := NewAppLayerClient[*EchoRequest, *EchoResponse](
-				serde,
-				DefaultTCPRoundTripper,
-				&m.settings.App,
-			)
-			req := &EchoRequest{Message: m.paramsInput.Value()}
-			var resp EchoResponse
-			err = client.Do(ctx, serverAddress, req, &resp, token)
-			if err == nil {
-				result = formatResponse("Echo Response", resp)
-			}
-
-		case "sum":
-			client := NewAppLayerClient[*SumRequest, *SumResponse](
-				serde,
-				DefaultTCPRoundTripper,
-				&m.settings.App,
-			)
-			parts := strings.Split(m.paramsInput.Value(), ",")
-			numbers := make([]int, 0, len(parts))
-			for _, p := range parts {
-				n, _ := strconv.Atoi(strings.TrimSpace(p))
-				numbers = append(numbers, n)
-			}
-			req := &SumRequest{Numbers: numbers}
-			var resp SumResponse
-			err = client.Do(ctx, serverAddress, req, &resp, token)
-			if err == nil {
-				result = formatResponse("Sum Response", resp)
-			}
-
-		case "timestamp":
-			client := NewAppLayerClient[*TimestampRequest, *TimestampResponse](
-				serde,
-				DefaultTCPRoundTripper,
-				&m.settings.App,
-			)
-			req := &TimestampRequest{}
-			var resp TimestampResponse
-			err = client.Do(ctx, serverAddress, req, &resp, token)
-			if err == nil {
-				result = formatResponse("Timestamp Response", resp)
-			}
-
-		case "history":
-			client := NewAppLayerClient[*HistoryRequest, *HistoryResponse](
-				serde,
-				DefaultTCPRoundTripper,
-				&m.settings.App,
-			)
-			limit, _ := strconv.Atoi(m.paramsInput.Value())
-			req := &HistoryRequest{Limit: limit}
-			var resp HistoryResponse
-			err = client.Do(ctx, serverAddress, req, &resp, token)
-			if err == nil {
-				result = formatResponse("History Response", resp)
-			}
-
-		case "status":
-			client := NewAppLayerClient[*StatusRequest, *StatusResponse](
-				serde,
-				DefaultTCPRoundTripper,
-				&m.settings.App,
-			)
-			detailed := m.paramsInput.Value() == "true"
-			req := &StatusRequest{Detailed: detailed}
-			var resp StatusResponse
-			err = client.Do(ctx, serverAddress, req, &resp, token)
-			if err == nil {
-				result = formatResponse("Status Response", resp)
-			}
-		}
-
-		if err != nil {
-			// Still try to logout
-			logoutClient := NewAppLayerClient[*LogoutRequest, *LogoutResponse](
-				serde,
-				DefaultTCPRoundTripper,
-				&m.settings.App,
-			)
-			logoutReq := &LogoutRequest{}
-			logoutClient.Logout(ctx, serverAddress, logoutReq, token)
-			return operationResultMsg{
-				err:       fmt.Errorf("operation failed: %w", err),
-				protocol:  m.protocols[m.protocolIdx],
-				params:    m.paramsInput.Value(),
-				operation: op,
-			}
-		}
+		login := &clientSession{protocol: msg.protocol, serde: serde, compressed: compressed, address: serverAddress, token: token}
+		msg.response, msg.wire, msg.latency, err = m.runOperation(ctx, login, req)
 
-		// 3. Logout
+		// 3. Logout, also after a failed operation
 		logoutClient := NewAppLayerClient[*LogoutRequest, *LogoutResponse](
 			serde,
-			DefaultTCPRoundTripper,
+			m.roundTripper,
 			&m.settings.App,
 		)
 		logoutReq := &LogoutRequest{}
-		_, err = logoutClient.Logout(ctx, serverAddress, logoutReq, token)
+		_, logoutErr := logoutClient.Logout(ctx, serverAddress, logoutReq, token)
+
 		if err != nil {
-			result += "\n\nWarning: Logout failed: " + err.Error()
+			msg.err = fmt.Errorf("operation failed: %w", err)
+			return msg
 		}
 
-		return operationResultMsg{
-			result:    result,
-			protocol:  m.protocols[m.protocolIdx],
-			params:    m.paramsInput.Value(),
-			operation: op,
+		msg.result = formatResponse(operationTitle(op), msg.response)
+		if logoutErr != nil {
+			msg.result += "\n\nWarning: Logout failed: " + logoutErr.Error()
 		}
+		msg.result += compressionSummary(compressed, "")
+
+		return msg
+	}
+}
+
+// newClientSerde returns the serde of protocol, compressed unless compression is none or empty
+func newClientSerde(protocol string, compression string) (Serde, *CompressedSerde, error) {
+	serde, err := NewSerde(protocol)
+	if err != nil {
+		return nil, nil, err
+	}
+	if compression == "" || compression == CompressionNone {
+		return serde, nil, nil
+	}
+
+	compressed, err := NewCompressedSerde(serde, compression)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return compressed, compressed, nil
+}
+
+// runOperation performs req on the login of session, recording its exchange for the wire tab. The
+// latency is the one of the operation only.
+func (m model) runOperation(ctx context.Context, session *clientSession, req OperationRequest) (OperationResponse, *wireCapture, time.Duration, error) {
+	recorder := &wireRecordingRoundTripper{inner: m.roundTripper}
+	client := NewAppLayerClient[OperationRequest, OperationResponse](session.serde, recorder, &m.settings.App)
+
+	resp, err := NewOperationResponse(req.CommandOrOperationName())
+	if err != nil {
+		return nil, nil, 0, err
+	}
+
+	start := time.Now()
+	err = client.Do(ctx, session.address, req, resp, session.token)
+	latency := time.Since(start)
+
+	wire := newWireCapture(session.protocol, session.compressed, recorder)
+	if err != nil {
+		return nil, wire, latency, err
+	}
+
+	return resp, wire, latency, nil
+}
+
+// operationLabels name the operations in the TUI, keyed by their wire names
+var operationLabels = map[string]string{
+	OperationEcho:      "echo",
+	OperationSum:       "sum",
+	OperationTimestamp: "timestamp",
+	OperationHistory:   "history",
+	OperationStatus:    "status",
+}
+
+// operationDisplayName is the name of op shown in the TUI, op itself when it has no label
+func operationDisplayName(op string) string {
+	if label, ok := operationLabels[op]; ok {
+		return label
+	}
+
+	return op
+}
+
+// operationTitle heads the response of op in the right panel
+func operationTitle(op string) string {
+	label := operationDisplayName(op)
+	return strings.ToUpper(label[:1]) + label[1:] + " Response"
+}
+
+// compressionSummary describes what compressed compressed so far, nothing when it is nil
+func compressionSummary(compressed *CompressedSerde, scope string) string {
+	if compressed == nil {
+		return ""
 	}
+
+	if scope != "" {
+		scope = " " + scope
+	}
+	stats := compressed.Stats()
+
+	return fmt.Sprintf("\n\nCompression (%s)%s: %d messages, %d bytes sent as %d, ratio %.2f, %s compressing, %s decompressing",
+		compressed.Codec,
+		scope,
+		stats.Messages(),
+		stats.UncompressedBytes,
+		stats.CompressedBytes,
+		stats.Ratio(),
+		stats.CompressTime,
+		stats.DecompressTime,
+	)
 }
 
 func formatResponse(title string, data interface{}) string {
@@ -671,6 +1020,22 @@ func (m model) View() string {
 
 	leftPanel := m.renderLeftPanel(m.leftPanelWidth)
 	rightPanel := m.renderRightPanel(m.rightPanelWidth)
+	if m.screen == screenDashboard {
+		leftPanel = m.renderDashboardLeftPanel(m.leftPanelWidth)
+		rightPanel = m.renderDashboardRightPanel()
+	}
+	if m.screen == screenHistory {
+		leftPanel = m.renderHistoryLeftPanel(m.leftPanelWidth)
+		rightPanel = m.renderHistoryRightPanel(m.rightPanelWidth)
+	}
+	if m.screen == screenProfiles {
+		leftPanel = m.renderProfilesLeftPanel(m.leftPanelWidth)
+		rightPanel = m.renderProfilesRightPanel(m.rightPanelWidth)
+	}
+	if m.screen == screenMonitor {
+		leftPanel = m.renderMonitorLeftPanel(m.leftPanelWidth)
+		rightPanel = m.renderMonitorRightPanel(m.rightPanelWidth)
+	}
 
 	// Combine panels side by side
 	mainContent := lipgloss.JoinHorizontal(
@@ -678,6 +1043,9 @@ func (m model) View() string {
 		leftPanel,
 		rightPanel,
 	)
+	if m.screen == screenCompare {
+		mainContent = m.renderComparePanel(minWidth)
+	}
 
 	// Add help at the bottom
 	helpView := m.help.View(m.keys)
@@ -685,6 +1053,17 @@ func (m model) View() string {
 
 	title := titleStyle.Render("╔═ Triprotocol Client ═╗")
 
+	var screenTabs []string
+	for i, name := range screenNames {
+		style := buttonBlurredStyle
+		if screen(i) == m.screen {
+			style = buttonFocusedStyle
+		}
+		screenTabs = append(screenTabs, style.Render(name))
+	}
+	screenTabs = append(screenTabs, hintStyle.Render(" "+m.serverStatus()+", theme "+m.themeName))
+	title = lipgloss.JoinVertical(lipgloss.Center, title, lipgloss.JoinHorizontal(lipgloss.Top, screenTabs...))
+
 	fullView := lipgloss.JoinVertical(
 		lipgloss.Center,
 		title,
@@ -717,7 +1096,6 @@ func (m model) renderLeftPanel(width int) string {
 	localTitleStyle := titleStyle.Width(width)
 	localFieldStyle := fieldStyle.Width(width)
 	localFocusedStyle := focusedFieldStyle.Width(width)
-	localHintStyle := hintStyle.Width(width)
 
 	// Title
 	title := localTitleStyle.Render("╔═ Options ═╗")
@@ -745,6 +1123,28 @@ func (m model) renderLeftPanel(width int) string {
 	}
 	protocolValue := localFieldStyle.Render("  " + lipgloss.JoinHorizontal(lipgloss.Top, protocolTabs...))
 
+	// Compression section, picked per protocol
+	compressionLabel := "Compression:"
+	compressionLabelRendered := ""
+	if m.focusIndex == focusCompression {
+		compressionLabelRendered = localFocusedStyle.Render("▸ " + compressionLabel)
+	} else {
+		compressionLabelRendered = localFieldStyle.Render("  " + compressionLabel)
+	}
+
+	var compressionTabs []string
+	for i, compression := range Compressions {
+		var style lipgloss.Style
+		isActive := i == m.compressionIdx[m.protocolIdx]
+		if isActive {
+			style = buttonFocusedStyle
+		} else {
+			style = buttonBlurredStyle
+		}
+		compressionTabs = append(compressionTabs, style.Render(compression))
+	}
+	compressionValue := localFieldStyle.Render("  " + lipgloss.JoinHorizontal(lipgloss.Top, compressionTabs...))
+
 	// Enrollment section
 	enrollmentLabel := "Enrollment ID:"
 	enrollmentLabelRendered := ""
@@ -774,7 +1174,7 @@ func (m model) renderLeftPanel(width int) string {
 		} else {
 			style = buttonBlurredStyle
 		}
-		operationTabs = append(operationTabs, style.Render(op))
+		operationTabs = append(operationTabs, style.Render(operationDisplayName(op)))
 	}
 	operationsList := localFieldStyle.Render("  " + lipgloss.JoinHorizontal(lipgloss.Top, operationTabs...))
 
@@ -786,35 +1186,41 @@ func (m model) renderLeftPanel(width int) string {
 	} else {
 		paramsLabelRendered = localFieldStyle.Render("  " + paramsLabel)
 	}
-	paramsValue := localFieldStyle.Render("  " + m.paramsInput.View())
-	paramsHint := localHintStyle.Render("  " + m.getParamsHint())
+	paramsForm := m.form().view(width, m.focusIndex == focusParams)
 
-	// Submit button
-	buttonRendered := ""
-	if m.focusIndex == focusSubmit {
-		buttonRendered = localFieldStyle.Render("  " + buttonFocusedStyle.Render("SUBMIT"))
-	} else {
-		buttonRendered = localFieldStyle.Render("  " + buttonBlurredStyle.Render("SUBMIT"))
+	// Submit, compare and session buttons
+	submitStyle, compareStyle, sessionStyle := buttonBlurredStyle, buttonBlurredStyle, buttonBlurredStyle
+	switch m.focusIndex {
+	case focusSubmit:
+		submitStyle = buttonFocusedStyle
+	case focusCompare:
+		compareStyle = buttonFocusedStyle
+	case focusSession:
+		sessionStyle = buttonFocusedStyle
+	}
+	sessionButton := "LOGIN"
+	if m.session != nil {
+		sessionButton = "LOGOUT"
 	}
+	buttonRendered := localFieldStyle.Render("  " + lipgloss.JoinHorizontal(lipgloss.Top, submitStyle.Render("SUBMIT"), compareStyle.Render("COMPARE"), sessionStyle.Render(sessionButton)))
 
 	lastOperations := []string{}
 
-	for _, op := range m.lastOperations {
-		operation := inputStyle.Render(op.operation)
+	for _, op := range m.history.store.recent(historyRecent) {
+		operation := inputStyle.Render(op.Operation)
 
 		status := inputStyle.Render("✅")
 
-		if !op.success {
+		if !op.Success {
 			status = errorTitleStyle.Render("❌")
 		}
 
-		protocol := titleStyle.Render(op.protocol)
-		params := inputStyle.Render(op.params)
+		protocol := titleStyle.Render(op.Protocol)
+		params := inputStyle.Render(op.Params)
 
-		happenedAt := inputStyle.Render(time.Since(op.whenHappened).Truncate(time.Second).String())
+		happenedAt := inputStyle.Render(time.Since(op.At).Truncate(time.Second).String())
 		renderedOperation := lipgloss.JoinHorizontal(lipgloss.Top, status, "|", operation, " at ", happenedAt, "|", protocol, "|", params)
-		lastOperations = append([]string{""}, lastOperations...)
-		lastOperations = append([]string{renderedOperation}, lastOperations...)
+		lastOperations = append(lastOperations, renderedOperation, "")
 	}
 
 	operationsPane := ""
@@ -830,6 +1236,9 @@ func (m model) renderLeftPanel(width int) string {
 		protocolLabelRendered,
 		protocolValue,
 		"",
+		compressionLabelRendered,
+		compressionValue,
+		"",
 		enrollmentLabelRendered,
 		enrollmentValue,
 		"",
@@ -837,10 +1246,10 @@ func (m model) renderLeftPanel(width int) string {
 		operationsList,
 		"",
 		paramsLabelRendered,
-		paramsValue,
-		paramsHint,
+		paramsForm,
 		"",
 		buttonRendered,
+		m.renderSession(width),
 		"",
 		operationsPane,
 	)
@@ -856,7 +1265,7 @@ func (m model) renderRightPanel(width int) string {
 			"",
 			progressBar,
 		)
-	} else if m.result == "" {
+	} else if m.result == "" && (!m.showWire || m.wire == nil) {
 		content = lipgloss.JoinVertical(
 			lipgloss.Left,
 			"No results yet.",
@@ -921,12 +1330,7 @@ func (m model) renderErrorPopup(background string) string {
 		errorHintStyle.Render("Press any key to close"),
 	)
 
-	popupWidget := lipgloss.NewStyle().
-		Border(lipgloss.RoundedBorder()).
-		BorderForeground(colorError).
-		Background(colorBackground).
-		Foreground(colorText).
-		Padding(1, 2).
+	popupWidget := errorPopupStyle.
 		Width(popupWidth).
 		Render(popupContent)
 
@@ -937,33 +1341,41 @@ func (m model) renderErrorPopup(background string) string {
 		lipgloss.Center,
 		popupWidget,
 		lipgloss.WithWhitespaceChars(" "),
-		lipgloss.WithWhitespaceForeground(lipgloss.Color("0")),
+		lipgloss.WithWhitespaceForeground(activeTheme.ButtonBg),
 	)
 }
 
-func (m model) getParamsHint() string {
-	op := m.operations[m.operationIdx]
-	switch op {
-	case "echo":
-		return "Enter a message"
-	case "sum":
-		return "Comma-separated numbers"
-	case "timestamp":
-		return "No parameters needed"
-	case "history":
-		return "Limit (1-100)"
-	case "status":
-		return "true/false for detailed"
-	}
-	return ""
+// TUIOptions override the settings of the TUI
+type TUIOptions struct {
+	Theme string
 }
 
-func RunTUI() error {
+func RunTUI(opts TUIOptions) error {
 	// Load configuration
 	settings, err := LoadConfig[Settings]("TUI", BaseSettings)
 	if err != nil {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
+	themes, err := newThemeCatalog(settings.App.Themes)
+	if err != nil {
+		return fmt.Errorf("invalid themes: %w", err)
+	}
+	roundTripper, err := newSettingsRoundTripper(settings)
+	if err != nil {
+		return err
+	}
+	history, err := loadOperationHistory(defaultHistoryPath())
+	if err != nil {
+		return fmt.Errorf("failed to load history: %w", err)
+	}
+	profilesPath := settings.App.ProfilesFile
+	if profilesPath == "" {
+		profilesPath = defaultProfilesPath()
+	}
+	profiles, err := loadServerProfiles(profilesPath, settings)
+	if err != nil {
+		return err
+	}
 	f, err := tea.LogToFile("debug.log", "debug")
 	if err != nil {
 		fmt.Println("fatal:", err)
@@ -978,8 +1390,17 @@ func RunTUI() error {
 
 	slog.SetDefault(logger)
 
+	m := initialModel(settings, roundTripper, profiles, history)
+	m.themes = themes
+	if err := m.setTheme(cmp.Or(opts.Theme, settings.App.Theme, DefaultThemeName)); err != nil {
+		return err
+	}
+	if err := m.useProfile(profiles.active()); err != nil {
+		return err
+	}
+
 	p := tea.NewProgram(
-		initialModel(settings),
+		m,
 		tea.WithAltScreen(),
 		tea.WithMouseCellMotion(),
 	)
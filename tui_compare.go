@@ -0,0 +1,323 @@
+package main
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// compareProtocols are the protocols the compare action runs side by side
+var compareProtocols = []string{ProtocolString, ProtocolJSON, ProtocolProtobuf}
+
+// comparisonField is one leaf of a decoded response, lists and objects are flattened into paths like
+// historico[0].operacao
+type comparisonField struct {
+	Path  string
+	Value string
+}
+
+// comparisonColumn is the outcome of the operation over one protocol
+type comparisonColumn struct {
+	Protocol      string
+	Latency       time.Duration
+	RequestBytes  int
+	ResponseBytes int
+	Request       []byte
+	Response      []byte
+	Fields        []comparisonField
+	Err           error
+}
+
+type comparisonResultMsg struct {
+	operation    string
+	params       string
+	studentID    string
+	compressions map[string]string
+	at           time.Time
+	columns      []comparisonColumn
+	// canceled is true when the comparison was cancelled before every protocol answered
+	canceled bool
+}
+
+// historyEntries records every column of the comparison as an operation of its protocol
+func (msg comparisonResultMsg) historyEntries() []historyEntry {
+	entries := make([]historyEntry, 0, len(msg.columns))
+	for _, column := range msg.columns {
+		entry := historyEntry{
+			At:            msg.at,
+			Protocol:      column.Protocol,
+			Compression:   msg.compressions[column.Protocol],
+			StudentID:     msg.studentID,
+			Operation:     msg.operation,
+			Params:        msg.params,
+			Latency:       column.Latency,
+			RequestBytes:  column.RequestBytes,
+			ResponseBytes: column.ResponseBytes,
+			RequestHex:    hex.EncodeToString(column.Request),
+			ResponseHex:   hex.EncodeToString(column.Response),
+			Success:       column.Err == nil,
+		}
+		if column.Err != nil {
+			entry.Error = column.Err.Error()
+		}
+
+		lines := make([]string, 0, len(column.Fields))
+		for _, field := range column.Fields {
+			lines = append(lines, field.Path+": "+field.Value)
+		}
+		entry.Result = strings.Join(lines, "\n")
+
+		entries = append(entries, entry)
+	}
+
+	return entries
+}
+
+// flattenFields lists the leaves of v as it encodes to JSON, sorted by path
+func flattenFields(v any) ([]comparisonField, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(strings.NewReader(string(data)))
+	decoder.UseNumber()
+
+	var tree any
+	if err := decoder.Decode(&tree); err != nil {
+		return nil, err
+	}
+
+	fields := []comparisonField{}
+	var walk func(path string, node any)
+	walk = func(path string, node any) {
+		switch n := node.(type) {
+		case map[string]any:
+			for k, child := range n {
+				if path == "" {
+					walk(k, child)
+				} else {
+					walk(path+"."+k, child)
+				}
+			}
+		case []any:
+			if len(n) == 0 {
+				fields = append(fields, comparisonField{Path: path, Value: "[]"})
+			}
+			for i, child := range n {
+				walk(path+"["+strconv.Itoa(i)+"]", child)
+			}
+		case nil:
+			fields = append(fields, comparisonField{Path: path, Value: "null"})
+		default:
+			fields = append(fields, comparisonField{Path: path, Value: fmt.Sprint(n)})
+		}
+	}
+	walk("", tree)
+
+	slices.SortFunc(fields, func(a, b comparisonField) int { return strings.Compare(a.Path, b.Path) })
+
+	return fields, nil
+}
+
+// diffFields returns the paths whose value is not the same in every successful column, a path missing
+// from a column differs too
+func diffFields(columns []comparisonColumn) map[string]bool {
+	values := map[string][]string{}
+	succeeded := 0
+	for _, column := range columns {
+		if column.Err != nil {
+			continue
+		}
+		succeeded++
+		for _, field := range column.Fields {
+			values[field.Path] = append(values[field.Path], field.Value)
+		}
+	}
+
+	diff := map[string]bool{}
+	for path, vs := range values {
+		if len(vs) != succeeded || slices.ContainsFunc(vs, func(v string) bool { return v != vs[0] }) {
+			diff[path] = true
+		}
+	}
+
+	return diff
+}
+
+// compareOperation runs req over every protocol concurrently, each in its own session, and times
+// the operation alone
+func compareOperation(ctx context.Context, settings *Settings, roundTripper RoundTripper, studentID string, req OperationRequest, compressions map[string]string) []comparisonColumn {
+	columns := make([]comparisonColumn, len(compareProtocols))
+
+	var wg sync.WaitGroup
+	for i, protocol := range compareProtocols {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			columns[i] = compareOperationOver(ctx, settings, roundTripper, studentID, req, protocol, compressions[protocol])
+		}()
+	}
+	wg.Wait()
+
+	return columns
+}
+
+func compareOperationOver(ctx context.Context, settings *Settings, roundTripper RoundTripper, studentID string, req OperationRequest, protocol string, compression string) comparisonColumn {
+	column := comparisonColumn{Protocol: protocol}
+
+	serde, _, err := newClientSerde(protocol, compression)
+	if err != nil {
+		column.Err = err
+		return column
+	}
+
+	address, err := settings.App.ServerAddress(protocol)
+	if err != nil {
+		column.Err = err
+		return column
+	}
+
+	recorder := &wireRecordingRoundTripper{inner: roundTripper}
+	client := NewAppLayerClient[OperationRequest, OperationResponse](serde, recorder, &settings.App)
+
+	authResp, err := client.Auth(ctx, address, &AuthRequest{StudentID: studentID, Timestamp: time.Now()})
+	if err != nil {
+		column.Err = fmt.Errorf("authentication failed: %w", err)
+		return column
+	}
+	defer client.Logout(context.WithoutCancel(ctx), address, &LogoutRequest{}, authResp.Token)
+
+	resp, err := NewOperationResponse(req.CommandOrOperationName())
+	if err != nil {
+		column.Err = err
+		return column
+	}
+
+	recorder.reset()
+	start := time.Now()
+	err = client.Do(ctx, address, req, resp, authResp.Token)
+	column.Latency = time.Since(start)
+	column.RequestBytes = len(recorder.request)
+	column.ResponseBytes = len(recorder.response)
+	column.Request = recorder.request
+	column.Response = recorder.response
+	if err != nil {
+		column.Err = err
+		return column
+	}
+
+	column.Fields, column.Err = flattenFields(resp)
+
+	return column
+}
+
+// operationRequest builds the request of the selected operation from its form
+func (m model) operationRequest() (OperationRequest, error) {
+	return m.form().build()
+}
+
+// executeComparison runs req over every protocol within the request timeout, compareCancel stops it
+// early
+func (m *model) executeComparison(req OperationRequest) tea.Cmd {
+	compressions := map[string]string{}
+	for i, protocol := range m.protocols {
+		compressions[protocol] = Compressions[m.compressionIdx[i]]
+	}
+
+	operation, params, studentID := m.operations[m.operationIdx], m.form().params(), m.enrollment.Value()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(m.settings.App.TCPTimeoutInSeconds)*time.Second)
+	m.compareCancel = cancel
+	settings, roundTripper := m.settings, m.roundTripper
+
+	return func() tea.Msg {
+		defer cancel()
+
+		at := time.Now()
+		columns := compareOperation(ctx, settings, roundTripper, studentID, req, compressions)
+
+		return comparisonResultMsg{
+			operation:    operation,
+			params:       params,
+			studentID:    studentID,
+			compressions: compressions,
+			at:           at,
+			columns:      columns,
+			canceled:     errors.Is(ctx.Err(), context.Canceled),
+		}
+	}
+}
+
+// cancelComparison stops the running comparison, its result still arrives as a comparisonResultMsg
+func (m model) cancelComparison() {
+	if m.compareCancel != nil {
+		m.compareCancel()
+	}
+}
+
+// renderComparison lays the columns side by side, the fields that differ between protocols are
+// highlighted
+func renderComparison(columns []comparisonColumn, width int) string {
+	diff := diffFields(columns)
+	columnWidth := width/len(columns) - 2
+
+	rendered := make([]string, 0, len(columns))
+	for _, column := range columns {
+		lines := []string{titleStyle.Render(column.Protocol)}
+
+		if column.Err != nil {
+			lines = append(lines, errorTitleStyle.Render("❌ "+column.Err.Error()))
+		} else {
+			lines = append(lines,
+				inputStyle.Render(fmt.Sprintf("rtt %s", column.Latency.Round(time.Microsecond))),
+				inputStyle.Render(fmt.Sprintf("sent %d B, received %d B", column.RequestBytes, column.ResponseBytes)),
+				"",
+			)
+			for _, field := range column.Fields {
+				line := field.Path + ": " + field.Value
+				if diff[field.Path] {
+					lines = append(lines, focusedFieldStyle.Render("≠ "+line))
+				} else {
+					lines = append(lines, inputStyle.Render("  "+line))
+				}
+			}
+		}
+
+		rendered = append(rendered, lipgloss.NewStyle().Width(columnWidth).MarginRight(2).Render(lipgloss.JoinVertical(lipgloss.Left, lines...)))
+	}
+
+	return lipgloss.JoinHorizontal(lipgloss.Top, rendered...)
+}
+
+func (m model) renderComparePanel(width int) string {
+	title := titleStyle.Render("╔═ Compare ═╗")
+
+	var content string
+	switch {
+	case m.loading:
+		content = lipgloss.JoinVertical(lipgloss.Left, "⏳ Running over "+strings.Join(compareProtocols, ", ")+"...", "", m.progress.ViewAs(0.5), "", hintStyle.Render("Press esc to cancel."))
+	case m.comparison == nil:
+		content = lipgloss.JoinVertical(
+			lipgloss.Left,
+			"No comparison yet.",
+			"Fill in the client form and press COMPARE",
+			"to run the operation over "+strings.Join(compareProtocols, ", ")+".",
+		)
+	default:
+		summary := hintStyle.Render(fmt.Sprintf("%s %s, ≠ marks the fields that differ", m.comparison.operation, m.comparison.params))
+		content = lipgloss.JoinVertical(lipgloss.Left, summary, "", m.compareViewport.View())
+	}
+
+	return panelBorderStyle.Padding(1).Width(width).Render(lipgloss.JoinVertical(lipgloss.Left, title, "", content))
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFlattenFields(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    any
+		expected []comparisonField
+	}{
+		{
+			name:  "list",
+			value: map[string]any{"numeros": []int{4, 1}, "soma": 5.5},
+			expected: []comparisonField{
+				{Path: "numeros[0]", Value: "4"},
+				{Path: "numeros[1]", Value: "1"},
+				{Path: "soma", Value: "5.5"},
+			},
+		},
+		{
+			name:  "nested",
+			value: map[string]any{"b": []any{map[string]any{"c": 1}}, "a": nil, "d": []any{}},
+			expected: []comparisonField{
+				{Path: "a", Value: "null"},
+				{Path: "b[0].c", Value: "1"},
+				{Path: "d", Value: "[]"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			actual, err := flattenFields(tt.value)
+
+			// Assert
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, actual)
+		})
+	}
+}
+
+func TestDiffFields(t *testing.T) {
+	// Arrange
+	columns := []comparisonColumn{
+		{Protocol: ProtocolString, Fields: []comparisonField{{Path: "a", Value: "1"}, {Path: "b", Value: "x"}}},
+		{Protocol: ProtocolJSON, Fields: []comparisonField{{Path: "a", Value: "1"}, {Path: "b", Value: "y"}, {Path: "c", Value: "z"}}},
+		{Protocol: ProtocolProtobuf, Err: assert.AnError},
+	}
+
+	// Act
+	diff := diffFields(columns)
+
+	// Assert
+	assert.Equal(t, map[string]bool{"b": true, "c": true}, diff, "failed columns should be ignored")
+}
+
+func TestCompareOperation(t *testing.T) {
+	// Arrange
+	settings := &Settings{}
+	roundTripper := NewLoopbackRoundTripper()
+	settings.App.StringProtocolServerAddress = LoopbackAddress(ProtocolString)
+	settings.App.JSONProtocolServerAddress = LoopbackAddress(ProtocolJSON)
+	settings.App.ProtobufProtocolServerAddress = LoopbackAddress(ProtocolProtobuf)
+	compressions := map[string]string{ProtocolJSON: CompressionGzip}
+	for _, protocol := range compareProtocols {
+		serde, err := NewSerde(protocol)
+		require.NoError(t, err)
+		if compressions[protocol] != "" {
+			serde, err = NewCompressedSerde(serde, compressions[protocol])
+			require.NoError(t, err)
+		}
+		roundTripper.Handle(LoopbackAddress(protocol), NewLoopbackServer(serde.(ServerSerde)).Handle)
+	}
+
+	// Act
+	columns := compareOperation(context.Background(), settings, roundTripper, "538349", SumRequest{Numbers: []int{4, 1, 7}}, compressions)
+
+	// Assert
+	require.Len(t, columns, len(compareProtocols))
+	for i, column := range columns {
+		require.NoError(t, column.Err, column.Protocol)
+		assert.Equal(t, compareProtocols[i], column.Protocol)
+		assert.Positive(t, column.Latency)
+		assert.Positive(t, column.RequestBytes)
+		assert.Positive(t, column.ResponseBytes)
+		assert.Contains(t, column.Fields, comparisonField{Path: "soma", Value: "12"}, column.Protocol)
+	}
+	diff := diffFields(columns)
+	assert.False(t, diff["soma"], "every protocol should decode the same sum")
+	assert.False(t, diff["numeros_originais[2]"])
+}
+
+func TestModelComparisonStops(t *testing.T) {
+	tests := []struct {
+		name     string
+		timeout  int
+		cancel   bool
+		canceled bool
+	}{
+		{name: "cancelled on esc", timeout: 60, cancel: true, canceled: true},
+		{name: "request timeout", timeout: 1, canceled: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			settings := &Settings{}
+			settings.App.TCPTimeoutInSeconds = tt.timeout
+			roundTripper := NewLoopbackRoundTripper()
+			for _, protocol := range compareProtocols {
+				roundTripper.Handle(LoopbackAddress(protocol), func(ctx context.Context, _ []byte) ([]byte, error) {
+					<-ctx.Done()
+					return nil, ctx.Err()
+				})
+			}
+			settings.App.StringProtocolServerAddress = LoopbackAddress(ProtocolString)
+			settings.App.JSONProtocolServerAddress = LoopbackAddress(ProtocolJSON)
+			settings.App.ProtobufProtocolServerAddress = LoopbackAddress(ProtocolProtobuf)
+			m := newTestModel(t, settings, roundTripper)
+
+			// Act
+			cmd := m.executeComparison(EchoRequest{Message: "ola"})
+			done := make(chan tea.Msg)
+			go func() { done <- cmd() }()
+			if tt.cancel {
+				updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+				m = updated.(model)
+			}
+			var msg tea.Msg
+			select {
+			case msg = <-done:
+			case <-time.After(10 * time.Second):
+				require.FailNow(t, "the comparison should stop")
+			}
+			updated, _ := m.Update(msg)
+			m = updated.(model)
+
+			// Assert
+			result := msg.(comparisonResultMsg)
+			assert.Equal(t, tt.canceled, result.canceled)
+			for _, column := range result.columns {
+				assert.Error(t, column.Err, column.Protocol)
+			}
+			assert.Nil(t, m.compareCancel, "the comparison should be over")
+			if tt.canceled {
+				assert.Nil(t, m.comparison, "a cancelled comparison should not be shown")
+				assert.Empty(t, m.history.store.entries, "a cancelled comparison should not be recorded")
+			} else {
+				assert.NotNil(t, m.comparison)
+				assert.Equal(t, screenCompare, m.screen)
+			}
+		})
+	}
+}
+
+func TestModelOperationRequest(t *testing.T) {
+	tests := []struct {
+		name      string
+		operation string
+		params    string
+		expected  OperationRequest
+		wantErr   bool
+	}{
+		{name: "echo", operation: OperationEcho, params: "ola", expected: EchoRequest{Message: "ola"}},
+		{name: "sum", operation: OperationSum, params: "1, 2,3", expected: SumRequest{Numbers: []int{1, 2, 3}}},
+		{name: "invalid sum", operation: OperationSum, params: "1,a", wantErr: true},
+		{name: "timestamp", operation: OperationTimestamp, expected: TimestampRequest{}},
+		{name: "history", operation: OperationHistory, params: "5", expected: HistoryRequest{Limit: 5}},
+		{name: "invalid history", operation: OperationHistory, params: "five", wantErr: true},
+		{name: "history out of bounds", operation: OperationHistory, params: "101", wantErr: true},
+		{name: "empty echo", operation: OperationEcho, params: "", wantErr: true},
+		{name: "status", operation: OperationStatus, params: "true", expected: StatusRequest{Detailed: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			m := newTestModel(t, &Settings{}, DefaultTCPRoundTripper)
+			m.operationIdx = slices.Index(m.operations, tt.operation)
+			m.form().setParams(tt.params)
+
+			// Act
+			req, err := m.operationRequest()
+
+			// Assert
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, req)
+		})
+	}
+}
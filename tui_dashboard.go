@@ -0,0 +1,607 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	// dashboardSeriesLength is how many ticks of throughput the sparklines keep
+	dashboardSeriesLength = 24
+
+	// dashboardLatencyWindow is how many of the latest latencies the live percentiles cover
+	dashboardLatencyWindow = 2048
+)
+
+var sparklineBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// sparkline draws the last width values scaled to their maximum
+func sparkline(values []float64, width int) string {
+	if len(values) > width {
+		values = values[len(values)-width:]
+	}
+
+	peak := 0.0
+	for _, v := range values {
+		peak = max(peak, v)
+	}
+
+	var sb strings.Builder
+	for _, v := range values {
+		level := 0
+		if peak > 0 {
+			level = min(int(v/peak*float64(len(sparklineBlocks)-1)+0.5), len(sparklineBlocks)-1)
+		}
+		sb.WriteRune(sparklineBlocks[level])
+	}
+
+	return sb.String()
+}
+
+// liveProtocol counts the samples of one protocol of a dashboard run
+type liveProtocol struct {
+	requests   int
+	errors     int
+	latencies  []time.Duration
+	next       int
+	ticked     int
+	throughput []float64
+}
+
+// liveStats aggregates the samples of a running benchmark, the workers add samples through
+// BenchmarkRunner.OnSample and the tick loop reads them
+type liveStats struct {
+	mu        sync.Mutex
+	order     []string
+	protocols map[string]*liveProtocol
+	lastTick  time.Time
+}
+
+// liveProtocolRow is one line of the dashboard table
+type liveProtocolRow struct {
+	Protocol   string
+	Requests   int
+	Errors     int
+	Throughput float64
+	Latency    LatencySummary
+	Series     []float64
+}
+
+func newLiveStats(protocols []string, start time.Time) *liveStats {
+	s := &liveStats{
+		order:     protocols,
+		protocols: make(map[string]*liveProtocol, len(protocols)),
+		lastTick:  start,
+	}
+	for _, protocol := range protocols {
+		s.protocols[protocol] = &liveProtocol{}
+	}
+
+	return s
+}
+
+func (s *liveStats) add(sample BenchmarkSample) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, ok := s.protocols[sample.Protocol]
+	if !ok {
+		return
+	}
+
+	p.requests++
+	if sample.Err != nil {
+		p.errors++
+		return
+	}
+
+	if len(p.latencies) < dashboardLatencyWindow {
+		p.latencies = append(p.latencies, sample.Latency)
+		return
+	}
+	p.latencies[p.next] = sample.Latency
+	p.next = (p.next + 1) % dashboardLatencyWindow
+}
+
+// tick closes the throughput interval that started at the previous tick
+func (s *liveStats) tick(now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	elapsed := now.Sub(s.lastTick)
+	if elapsed <= 0 {
+		return
+	}
+	s.lastTick = now
+
+	// Like the collector, the throughput leaves the failed requests out
+	for _, p := range s.protocols {
+		succeeded := p.requests - p.errors
+		p.throughput = append(p.throughput, throughput(succeeded-p.ticked, elapsed))
+		if len(p.throughput) > dashboardSeriesLength {
+			p.throughput = p.throughput[1:]
+		}
+		p.ticked = succeeded
+	}
+}
+
+func (s *liveStats) rows() []liveProtocolRow {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rows := make([]liveProtocolRow, 0, len(s.order))
+	for _, protocol := range s.order {
+		p := s.protocols[protocol]
+		row := liveProtocolRow{
+			Protocol: protocol,
+			Requests: p.requests,
+			Errors:   p.errors,
+			Latency:  SummarizeLatencies(p.latencies),
+			Series:   append([]float64(nil), p.throughput...),
+		}
+		if len(p.throughput) > 0 {
+			row.Throughput = p.throughput[len(p.throughput)-1]
+		}
+		rows = append(rows, row)
+	}
+
+	return rows
+}
+
+type dashboardFocus int
+
+const (
+	dashboardFocusProtocols dashboardFocus = iota
+	dashboardFocusOperations
+	dashboardFocusTransport
+	dashboardFocusDuration
+	dashboardFocusConcurrency
+	dashboardFocusActions
+	dashboardFocusCount
+)
+
+const (
+	dashboardActionStart = iota
+	dashboardActionPause
+	dashboardActionStop
+	dashboardActionExport
+	dashboardActionCount
+)
+
+// dashboardRun is a benchmark launched from the dashboard, shared by the copies of the model
+type dashboardRun struct {
+	scenario *Scenario
+	control  *RunControl
+	live     *liveStats
+	cancel   context.CancelFunc
+	started  time.Time
+	runs     int
+
+	// Set by the Update loop when the runner returns
+	done     bool
+	report   *BenchmarkReport
+	err      error
+	exported string
+}
+
+// benchmarkDoneMsg carries the report of a dashboard run
+type benchmarkDoneMsg struct {
+	run    *dashboardRun
+	report *BenchmarkReport
+	err    error
+}
+
+// dashboard is the live benchmark screen of the TUI
+type dashboard struct {
+	protocols    []bool
+	protocolIdx  int
+	operations   []bool
+	operationIdx int
+	transportIdx int // 0 is the configured address, then Transports
+	duration     textinput.Model
+	concurrency  textinput.Model
+	actionIdx    int
+	focusIndex   dashboardFocus
+	settings     *Settings
+	roundTripper RoundTripper
+	run          *dashboardRun
+	exportDir    string
+}
+
+func newDashboard(settings *Settings, roundTripper RoundTripper) dashboard {
+	duration := textinput.New()
+	duration.Placeholder = "10s"
+	duration.Width = 10
+	duration.CharLimit = 10
+	styleInput(&duration)
+	duration.SetValue("10s")
+
+	concurrency := textinput.New()
+	concurrency.Placeholder = "1"
+	concurrency.Width = 4
+	concurrency.CharLimit = 4
+	styleInput(&concurrency)
+	concurrency.SetValue("4")
+
+	protocols := make([]bool, len(Protocols))
+	for i := range protocols {
+		protocols[i] = true
+	}
+
+	operations := make([]bool, len(Operations))
+	for i := range operations {
+		operations[i] = true
+	}
+
+	return dashboard{
+		protocols:    protocols,
+		operations:   operations,
+		duration:     duration,
+		concurrency:  concurrency,
+		settings:     settings,
+		roundTripper: roundTripper,
+		exportDir:    ".",
+	}
+}
+
+// running tells whether the runner has not returned yet
+func (d dashboard) running() bool {
+	return d.run != nil && !d.run.done
+}
+
+// formScenario builds the scenario of the form, every selected operation has the same weight
+func (d dashboard) formScenario(studentID string) (*Scenario, error) {
+	scenario := &Scenario{
+		Name:      "dashboard",
+		StudentID: studentID,
+		Seed:      uint64(time.Now().UnixNano()),
+	}
+
+	for i, selected := range d.protocols {
+		if selected {
+			scenario.Protocols = append(scenario.Protocols, Protocols[i])
+		}
+	}
+	if len(scenario.Protocols) == 0 {
+		return nil, fmt.Errorf("select at least one protocol")
+	}
+
+	for i, selected := range d.operations {
+		if selected {
+			scenario.Operations = append(scenario.Operations, ScenarioOperation{Name: Operations[i], Weight: 1})
+		}
+	}
+	if len(scenario.Operations) == 0 {
+		return nil, fmt.Errorf("select at least one operation")
+	}
+
+	if d.transportIdx > 0 {
+		scenario.Transports = []string{Transports[d.transportIdx-1]}
+	}
+
+	var err error
+	scenario.Duration, err = time.ParseDuration(d.duration.Value())
+	if err != nil {
+		return nil, fmt.Errorf("duration must look like 10s or 1m30s")
+	}
+
+	scenario.Concurrency, err = strconv.Atoi(d.concurrency.Value())
+	if err != nil {
+		return nil, fmt.Errorf("concurrency must be a number")
+	}
+
+	if err := scenario.Validate(); err != nil {
+		return nil, err
+	}
+
+	return scenario, nil
+}
+
+// start launches the scenario of the form, the returned command reports its end
+func (d *dashboard) start(studentID string) (tea.Cmd, error) {
+	if d.running() {
+		return nil, fmt.Errorf("a benchmark is already running, stop it first")
+	}
+
+	scenario, err := d.formScenario(studentID)
+	if err != nil {
+		return nil, err
+	}
+
+	transports := max(len(scenario.Transports), 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	run := &dashboardRun{
+		scenario: scenario,
+		control:  &RunControl{},
+		live:     newLiveStats(scenario.Protocols, time.Now()),
+		cancel:   cancel,
+		started:  time.Now(),
+		runs:     len(scenario.Protocols) * transports,
+	}
+	d.run = run
+
+	runner := NewBenchmarkRunner(scenario, &d.settings.App, d.roundTripper)
+	runner.OnSample = run.live.add
+	runner.Control = run.control
+
+	return func() tea.Msg {
+		defer cancel()
+
+		report, err := runner.Run(ctx)
+		return benchmarkDoneMsg{run: run, report: report, err: err}
+	}, nil
+}
+
+// finish records the end of a run, messages of replaced runs are ignored
+func (d *dashboard) finish(msg benchmarkDoneMsg) error {
+	if msg.run != d.run {
+		return nil
+	}
+
+	d.run.done = true
+	d.run.report = msg.report
+	d.run.err = msg.err
+	d.run.live.tick(time.Now())
+
+	return msg.err
+}
+
+// export writes the report of a finished run as JSON and returns its path
+func (d *dashboard) export() (string, error) {
+	if d.run == nil {
+		return "", fmt.Errorf("start a benchmark before exporting it")
+	}
+	if !d.run.done {
+		return "", fmt.Errorf("stop the benchmark or let it finish before exporting it")
+	}
+	if d.run.report == nil {
+		return "", fmt.Errorf("the benchmark failed without a report")
+	}
+
+	path := filepath.Join(d.exportDir, "dashboard-"+d.run.started.Format("20060102-150405")+".json")
+	if err := WriteReportFile(path, d.run.report); err != nil {
+		return "", err
+	}
+	d.run.exported = path
+
+	return path, nil
+}
+
+func (d *dashboard) tick(now time.Time) {
+	if d.running() && !d.run.control.Paused() {
+		d.run.live.tick(now)
+	}
+}
+
+func (d *dashboard) updateFocus() {
+	d.duration.Blur()
+	d.concurrency.Blur()
+
+	switch d.focusIndex {
+	case dashboardFocusDuration:
+		d.duration.Focus()
+	case dashboardFocusConcurrency:
+		d.concurrency.Focus()
+	}
+}
+
+// update handles the keys of the dashboard screen, errors are shown in the error popup
+func (d dashboard) update(msg tea.KeyMsg, keys keyMap, studentID string) (dashboard, tea.Cmd, error) {
+	if focus, ok := cycleFocus(msg, keys, d.focusIndex, dashboardFocusCount); ok {
+		d.focusIndex = focus
+		d.updateFocus()
+		return d, nil, nil
+	}
+
+	switch {
+	case key.Matches(msg, keys.Left, keys.Right):
+		switch d.focusIndex {
+		case dashboardFocusProtocols:
+			d.protocolIdx = stepCursor(msg, keys, d.protocolIdx, len(Protocols))
+		case dashboardFocusOperations:
+			d.operationIdx = stepCursor(msg, keys, d.operationIdx, len(Operations))
+		case dashboardFocusTransport:
+			d.transportIdx = stepCursor(msg, keys, d.transportIdx, len(Transports)+1)
+		case dashboardFocusActions:
+			d.actionIdx = stepCursor(msg, keys, d.actionIdx, dashboardActionCount)
+		}
+
+	case key.Matches(msg, keys.Toggle, keys.Enter):
+		switch d.focusIndex {
+		case dashboardFocusProtocols:
+			d.protocols[d.protocolIdx] = !d.protocols[d.protocolIdx]
+		case dashboardFocusOperations:
+			d.operations[d.operationIdx] = !d.operations[d.operationIdx]
+		case dashboardFocusActions:
+			if key.Matches(msg, keys.Enter) {
+				return d.act(studentID)
+			}
+		}
+	}
+
+	var cmd tea.Cmd
+	switch d.focusIndex {
+	case dashboardFocusDuration:
+		d.duration, cmd = d.duration.Update(msg)
+	case dashboardFocusConcurrency:
+		d.concurrency, cmd = d.concurrency.Update(msg)
+	}
+
+	return d, cmd, nil
+}
+
+// act runs the focused action button
+func (d dashboard) act(studentID string) (dashboard, tea.Cmd, error) {
+	switch d.actionIdx {
+	case dashboardActionStart:
+		cmd, err := d.start(studentID)
+		return d, cmd, err
+
+	case dashboardActionPause:
+		if !d.running() {
+			return d, nil, fmt.Errorf("there is no benchmark running")
+		}
+		if d.run.control.Paused() {
+			d.run.control.Resume()
+		} else {
+			d.run.control.Pause()
+		}
+
+	case dashboardActionStop:
+		if !d.running() {
+			return d, nil, fmt.Errorf("there is no benchmark running")
+		}
+		d.run.control.Stop()
+
+	case dashboardActionExport:
+		_, err := d.export()
+		return d, nil, err
+	}
+
+	return d, nil, nil
+}
+
+// updateInputs forwards the messages that are not keys, like the cursor blink, to the focused input
+func (d dashboard) updateInputs(msg tea.Msg) (dashboard, tea.Cmd) {
+	var cmd tea.Cmd
+	switch d.focusIndex {
+	case dashboardFocusDuration:
+		d.duration, cmd = d.duration.Update(msg)
+	case dashboardFocusConcurrency:
+		d.concurrency, cmd = d.concurrency.Update(msg)
+	}
+
+	return d, cmd
+}
+
+// status describes the state of the run
+func (d dashboard) status() string {
+	switch {
+	case d.run == nil:
+		return "Idle, pick a mix and press START."
+	case d.running() && d.run.control.Stopped():
+		return "⏹ Stopping after the requests in flight..."
+	case d.running() && d.run.control.Paused():
+		return "⏸ Paused"
+	case d.running():
+		return "▶ Running"
+	case errors.Is(d.run.err, context.Canceled):
+		return "⏹ Cancelled"
+	case d.run.err != nil:
+		return "❌ Failed: " + d.run.err.Error()
+	case d.run.control.Stopped():
+		return "⏹ Stopped, the report covers what was measured"
+	default:
+		return "✅ Finished"
+	}
+}
+
+// progress is the measured share of the whole run, from 0 to 1
+func (d dashboard) progress() float64 {
+	if d.run == nil {
+		return 0
+	}
+	if d.run.done {
+		return 1
+	}
+
+	total := d.run.scenario.Duration * time.Duration(d.run.runs)
+	active := time.Since(d.run.started) - d.run.control.pausedSince(d.run.started)
+
+	return min(float64(active)/float64(total), 1)
+}
+
+func (m model) renderDashboardLeftPanel(width int) string {
+	d := m.dashboard
+	localTitleStyle := titleStyle.Width(width)
+	localFieldStyle := fieldStyle.Width(width)
+	localHintStyle := hintStyle.Width(width)
+
+	label := func(focus dashboardFocus, text string) string {
+		return focusLabel(text, d.focusIndex == focus, width)
+	}
+	toggles := func(focus dashboardFocus, names []string, selected func(i int) bool, cursor int) string {
+		return localFieldStyle.Render("  " + toggleRow(names, selected, cursor, d.focusIndex == focus))
+	}
+
+	transports := append([]string{"configured"}, Transports...)
+
+	actions := []string{"START", "PAUSE", "STOP", "EXPORT"}
+	if d.running() && d.run.control.Paused() {
+		actions[dashboardActionPause] = "RESUME"
+	}
+
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		localTitleStyle.Render("╔═ Benchmark ═╗"),
+		"",
+		label(dashboardFocusProtocols, "Protocols:"),
+		toggles(dashboardFocusProtocols, Protocols, func(i int) bool { return d.protocols[i] }, d.protocolIdx),
+		"",
+		label(dashboardFocusOperations, "Operation mix:"),
+		toggles(dashboardFocusOperations, Operations, func(i int) bool { return d.operations[i] }, d.operationIdx),
+		"",
+		label(dashboardFocusTransport, "Transport:"),
+		toggles(dashboardFocusTransport, transports, func(i int) bool { return i == d.transportIdx }, d.transportIdx),
+		"",
+		label(dashboardFocusDuration, "Duration per protocol:"),
+		localFieldStyle.Render("  "+d.duration.View()),
+		"",
+		label(dashboardFocusConcurrency, "Concurrency:"),
+		localFieldStyle.Render("  "+d.concurrency.View()),
+		"",
+		localFieldStyle.Render("  "+actionRow(actions, d.actionIdx, d.focusIndex == dashboardFocusActions)),
+		"",
+		localHintStyle.Render("  space toggles, the enrollment ID of the client screen is used"),
+	)
+}
+
+func (m model) renderDashboardRightPanel() string {
+	d := m.dashboard
+
+	lines := []string{
+		titleStyle.Render("╔═ Live ═╗"),
+		"",
+		inputStyle.Render(d.status()),
+		m.progress.ViewAs(d.progress()),
+		"",
+	}
+
+	if d.run != nil {
+		header := fmt.Sprintf("%-9s %6s %4s %7s %7s %7s %s", "protocol", "reqs", "errs", "req/s", "p50", "p99", "req/s trend")
+		lines = append(lines, hintStyle.Render(header))
+
+		for _, row := range d.run.live.rows() {
+			errs := fmt.Sprintf("%4d", row.Errors)
+			if row.Errors > 0 {
+				errs = errorTitleStyle.Render(errs)
+			}
+			lines = append(lines, fmt.Sprintf("%-9s %6d %s %7.1f %7s %7s %s",
+				row.Protocol,
+				row.Requests,
+				errs,
+				row.Throughput,
+				row.Latency.P50.Round(time.Microsecond),
+				row.Latency.P99.Round(time.Microsecond),
+				titleStyle.Render(sparkline(row.Series, 10)),
+			))
+		}
+
+		if d.run.exported != "" {
+			lines = append(lines, "", hintStyle.Render("Exported to "+d.run.exported))
+		}
+	}
+
+	return panelBorderStyle.Padding(1).Render(lipgloss.JoinVertical(lipgloss.Left, lines...))
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSparkline(t *testing.T) {
+	tests := []struct {
+		name     string
+		values   []float64
+		width    int
+		expected string
+	}{
+		{name: "empty", values: nil, width: 5, expected: ""},
+		{name: "scaled to the peak", values: []float64{0, 7, 14}, width: 5, expected: "▁▅█"},
+		{name: "all zero", values: []float64{0, 0}, width: 5, expected: "▁▁"},
+		{name: "last values", values: []float64{100, 1, 2}, width: 2, expected: "▅█"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			actual := sparkline(tt.values, tt.width)
+
+			// Assert
+			assert.Equal(t, tt.expected, actual)
+		})
+	}
+}
+
+func TestLiveStats(t *testing.T) {
+	// Arrange
+	start := time.Now()
+	live := newLiveStats([]string{ProtocolJSON, ProtocolString}, start)
+
+	// Act
+	for i := range 10 {
+		live.add(BenchmarkSample{Protocol: ProtocolJSON, Latency: time.Duration(i+1) * time.Millisecond})
+	}
+	live.add(BenchmarkSample{Protocol: ProtocolJSON, Err: errors.New("timeout")})
+	live.add(BenchmarkSample{Protocol: ProtocolProtobuf, Latency: time.Millisecond})
+	live.tick(start.Add(time.Second))
+	live.add(BenchmarkSample{Protocol: ProtocolString, Latency: time.Millisecond})
+	live.tick(start.Add(2 * time.Second))
+	rows := live.rows()
+
+	// Assert
+	require.Len(t, rows, 2, "protocols outside the run should be ignored")
+	assert.Equal(t, ProtocolJSON, rows[0].Protocol)
+	assert.Equal(t, 11, rows[0].Requests)
+	assert.Equal(t, 1, rows[0].Errors)
+	assert.Equal(t, []float64{10, 0}, rows[0].Series, "failed requests should not count in the throughput")
+	assert.Zero(t, rows[0].Throughput, "the throughput is the one of the last tick")
+	assert.Equal(t, 5*time.Millisecond, rows[0].Latency.P50)
+	assert.Equal(t, []float64{0, 1}, rows[1].Series)
+}
+
+func TestLiveStatsLatencyWindow(t *testing.T) {
+	// Arrange
+	live := newLiveStats([]string{ProtocolJSON}, time.Now())
+
+	// Act
+	for range dashboardLatencyWindow {
+		live.add(BenchmarkSample{Protocol: ProtocolJSON, Latency: time.Second})
+	}
+	for range dashboardLatencyWindow {
+		live.add(BenchmarkSample{Protocol: ProtocolJSON, Latency: time.Millisecond})
+	}
+	rows := live.rows()
+
+	// Assert
+	assert.Equal(t, 2*dashboardLatencyWindow, rows[0].Requests)
+	assert.Equal(t, time.Millisecond, rows[0].Latency.Max, "old latencies should leave the window")
+}
+
+func TestDashboardFormScenario(t *testing.T) {
+	tests := []struct {
+		name    string
+		arrange func(d *dashboard)
+		assert  func(t *testing.T, scenario *Scenario)
+		wantErr bool
+	}{
+		{
+			name:    "defaults",
+			arrange: func(d *dashboard) {},
+			assert: func(t *testing.T, scenario *Scenario) {
+				assert.Equal(t, Protocols, scenario.Protocols)
+				assert.Len(t, scenario.Operations, len(Operations))
+				assert.Empty(t, scenario.Transports, "the configured addresses are used by default")
+				assert.Equal(t, 10*time.Second, scenario.Duration)
+				assert.Equal(t, 4, scenario.Concurrency)
+				assert.Equal(t, "538349", scenario.StudentID)
+			},
+		},
+		{
+			name: "selection",
+			arrange: func(d *dashboard) {
+				d.protocols = []bool{false, true, false, false, true}
+				d.operations = []bool{true, false, false, false, false}
+				d.transportIdx = 3
+				d.duration.SetValue("1m30s")
+				d.concurrency.SetValue("16")
+			},
+			assert: func(t *testing.T, scenario *Scenario) {
+				assert.Equal(t, []string{ProtocolString, ProtocolCBOR}, scenario.Protocols)
+				assert.Equal(t, []ScenarioOperation{{Name: OperationEcho, Weight: 1}}, scenario.Operations)
+				assert.Equal(t, []string{TransportLoopback}, scenario.Transports)
+				assert.Equal(t, 90*time.Second, scenario.Duration)
+				assert.Equal(t, 16, scenario.Concurrency)
+			},
+		},
+		{name: "no protocol", arrange: func(d *dashboard) { d.protocols = make([]bool, len(Protocols)) }, wantErr: true},
+		{name: "no operation", arrange: func(d *dashboard) { d.operations = make([]bool, len(Operations)) }, wantErr: true},
+		{name: "invalid duration", arrange: func(d *dashboard) { d.duration.SetValue("ten") }, wantErr: true},
+		{name: "zero concurrency", arrange: func(d *dashboard) { d.concurrency.SetValue("0") }, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			d := newDashboard(&Settings{}, DefaultTCPRoundTripper)
+			tt.arrange(&d)
+
+			// Act
+			scenario, err := d.formScenario("538349")
+
+			// Assert
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			tt.assert(t, scenario)
+		})
+	}
+}
+
+func TestDashboardRunAndExport(t *testing.T) {
+	// Arrange
+	d := newDashboard(&Settings{}, DefaultTCPRoundTripper)
+	d.exportDir = t.TempDir()
+	d.protocols = []bool{true, false, false, false, false}
+	d.transportIdx = 3
+	d.duration.SetValue("100ms")
+	d.concurrency.SetValue("1")
+
+	_, exportErr := d.export()
+
+	// Act
+	cmd, err := d.start("538349")
+	require.NoError(t, err)
+	_, startAgainErr := d.start("538349")
+	msg := cmd().(benchmarkDoneMsg)
+	finishErr := d.finish(msg)
+	path, err := d.export()
+
+	// Assert
+	assert.Error(t, exportErr, "there is nothing to export before a run")
+	assert.Error(t, startAgainErr, "one run at a time")
+	require.NoError(t, finishErr)
+	require.NoError(t, err)
+	assert.False(t, d.running())
+	assert.Equal(t, 1.0, d.progress())
+	assert.Equal(t, filepath.Dir(path), d.exportDir)
+
+	report, err := ReadReportFile(path)
+	require.NoError(t, err)
+	require.Len(t, report.Results, 1)
+	assert.Equal(t, "json/loopback", report.Results[0].Name())
+	assert.Positive(t, d.run.live.rows()[0].Requests)
+}
@@ -0,0 +1,339 @@
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"go.yaml.in/yaml/v3"
+)
+
+// exportFormats are the formats of the saved and copied results, ctrl+x cycles through them
+var exportFormats = []string{"json", "yaml", "markdown"}
+
+// exportExtensions are the file extensions of exportFormats
+var exportExtensions = map[string]string{"json": "json", "yaml": "yaml", "markdown": "md"}
+
+// resultExport is the last operation of the client screen, as saved and copied by the export keys.
+// Params and Response are keyed by their wire names.
+type resultExport struct {
+	At          time.Time          `json:"at" yaml:"at"`
+	Protocol    string             `json:"protocol" yaml:"protocol"`
+	Compression string             `json:"compression" yaml:"compression"`
+	StudentID   string             `json:"student_id" yaml:"student-id"`
+	Operation   string             `json:"operation" yaml:"operation"`
+	Params      map[string]any     `json:"params" yaml:"params"`
+	Latency     time.Duration      `json:"latency" yaml:"latency"`
+	Success     bool               `json:"success" yaml:"success"`
+	Error       string             `json:"error,omitempty" yaml:"error,omitempty"`
+	Response    map[string]any     `json:"response,omitempty" yaml:"response,omitempty"`
+	Wire        *wireExport        `json:"wire,omitempty" yaml:"wire,omitempty"`
+	Reproduce   resultReproduction `json:"reproduce" yaml:"reproduce"`
+
+	title   string
+	capture *wireCapture
+}
+
+// wireExport is the exchange of the operation, hex encoded
+type wireExport struct {
+	Request  wireMessageExport `json:"request" yaml:"request"`
+	Response wireMessageExport `json:"response" yaml:"response"`
+}
+
+type wireMessageExport struct {
+	Bytes int    `json:"bytes" yaml:"bytes"`
+	Codec string `json:"codec,omitempty" yaml:"codec,omitempty"`
+	Hex   string `json:"hex" yaml:"hex"`
+}
+
+// resultReproduction runs the operation again outside the TUI, Python is empty when no script
+// speaks the protocol and PythonError tells why
+type resultReproduction struct {
+	CLI         string `json:"cli" yaml:"cli"`
+	Python      string `json:"python,omitempty" yaml:"python,omitempty"`
+	PythonError string `json:"python_error,omitempty" yaml:"python-error,omitempty"`
+}
+
+func newWireMessageExport(message wireMessage) wireMessageExport {
+	return wireMessageExport{Bytes: len(message.data), Codec: message.codec, Hex: hex.EncodeToString(message.data)}
+}
+
+// wireFields converts v to a map keyed by its json tags, the names it has on the wire
+func wireFields(v any) map[string]any {
+	if v == nil || reflect.ValueOf(v).Kind() == reflect.Pointer && reflect.ValueOf(v).IsNil() {
+		return nil
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return map[string]any{"error": err.Error()}
+	}
+
+	fields := map[string]any{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return map[string]any{"error": err.Error()}
+	}
+
+	return fields
+}
+
+// newResultExport records the result of an operation, settings are the ones it ran with and base
+// the loaded ones, the CLI command sets the address when they differ
+func newResultExport(msg operationResultMsg, settings *Settings, base *Settings) *resultExport {
+	e := &resultExport{
+		At:          time.Now(),
+		Protocol:    msg.protocol,
+		Compression: msg.compression,
+		StudentID:   msg.studentID,
+		Operation:   msg.request.CommandOrOperationName(),
+		Params:      wireFields(msg.request),
+		Latency:     msg.latency,
+		Success:     msg.err == nil,
+		Response:    wireFields(msg.response),
+		title:       operationTitle(msg.operation),
+		capture:     msg.wire,
+	}
+	if msg.err != nil {
+		e.Error = msg.err.Error()
+	}
+	if msg.wire != nil {
+		e.Wire = &wireExport{Request: newWireMessageExport(msg.wire.request), Response: newWireMessageExport(msg.wire.response)}
+	}
+
+	e.Reproduce.CLI = cliReproduction(msg.protocol, msg.compression, msg.studentID, msg.request, settings, base)
+
+	address, err := settings.App.ServerAddress(msg.protocol)
+	if err == nil {
+		e.Reproduce.Python, err = pythonReproduction(msg.protocol, address, msg.studentID, msg.request)
+	}
+	if err != nil {
+		e.Reproduce.PythonError = err.Error()
+	}
+
+	return e
+}
+
+// encode renders the export in one of exportFormats
+func (e *resultExport) encode(format string) ([]byte, error) {
+	switch format {
+	case "json":
+		return json.MarshalIndent(e, "", "  ")
+	case "yaml":
+		return yaml.Marshal(e)
+	case "markdown":
+		return e.markdown()
+	default:
+		return nil, fmt.Errorf("unknown export format %s", format)
+	}
+}
+
+func (e *resultExport) markdown() ([]byte, error) {
+	params, err := json.MarshalIndent(e.Params, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", e.title)
+	fmt.Fprintf(&b, "| | |\n|---|---|\n")
+	fmt.Fprintf(&b, "| Protocol | %s |\n| Compression | %s |\n| Enrollment ID | %s |\n| Operation | %s |\n| Latency | %s |\n| At | %s |\n",
+		e.Protocol, e.Compression, e.StudentID, e.Operation, e.Latency, e.At.Format(time.RFC3339))
+	if e.Success {
+		fmt.Fprintf(&b, "| Outcome | ✅ success |\n")
+	} else {
+		fmt.Fprintf(&b, "| Outcome | ❌ %s |\n", strings.ReplaceAll(e.Error, "|", `\|`))
+	}
+
+	fmt.Fprintf(&b, "\n## Request\n\n```json\n%s\n```\n", params)
+
+	if e.Response != nil {
+		response, err := json.MarshalIndent(e.Response, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(&b, "\n## Response\n\n```json\n%s\n```\n", response)
+	}
+
+	if e.capture != nil {
+		fmt.Fprintf(&b, "\n## Wire\n\n```\n%s\n```\n", renderWire(e.capture))
+	}
+
+	fmt.Fprintf(&b, "\n## Reproduce\n\n```bash\n%s\n```\n", e.Reproduce.CLI)
+	if e.Reproduce.Python != "" {
+		fmt.Fprintf(&b, "\n```bash\n%s\n```\n", e.Reproduce.Python)
+	}
+
+	return []byte(b.String()), nil
+}
+
+// save writes the export in format to dir and returns its path. The export holds the wire bytes,
+// token included, so only the user can read it.
+func (e *resultExport) save(dir string, format string) (string, error) {
+	data, err := e.encode(format)
+	if err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(dir, "result-"+e.At.Format("20060102-150405.000000")+"."+exportExtensions[format])
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return "", err
+	}
+
+	return path, nil
+}
+
+// shellQuote quotes s for a POSIX shell
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// addressVariable is the environment variable overriding the server address of protocol
+func addressVariable(protocol string) string {
+	return "TUI_APP_" + strings.ToUpper(protocol) + "PROTOCOLSERVERADDRESS"
+}
+
+// cliReproduction is the call command running req again, the address is set through the
+// environment when the server profile in use is not the one of the settings
+func cliReproduction(protocol string, compression string, studentID string, req OperationRequest, settings *Settings, base *Settings) string {
+	var args []string
+
+	address, err := settings.App.ServerAddress(protocol)
+	baseAddress, baseErr := base.App.ServerAddress(protocol)
+	if err == nil && (baseErr != nil || address != baseAddress) {
+		args = append(args, addressVariable(protocol)+"="+shellQuote(address))
+	}
+
+	args = append(args, "go", "run", ".", "call", "-protocol", protocol)
+	if compression != CompressionNone {
+		args = append(args, "-compression", compression)
+	}
+	args = append(args, "-student-id", shellQuote(studentID), "-operation", req.CommandOrOperationName())
+
+	if params, err := json.Marshal(req); err == nil && string(params) != "{}" {
+		args = append(args, "-params", shellQuote(string(params)))
+	}
+
+	return strings.Join(args, " ")
+}
+
+// pythonScripts are the scripts under scripts/ speaking each protocol, they take the host, port,
+// enrollment ID, operation and parameters as arguments
+var pythonScripts = map[string]string{
+	ProtocolJSON:     "scripts/json_requests.py",
+	ProtocolString:   "scripts/string_request.py",
+	ProtocolProtobuf: "scripts/proto_requests.py",
+}
+
+// pythonParams is the JSON object of the parameters of req given to the scripts, keyed by their json
+// tags for the JSON protocol and, like StringSerde and ProtobufSerde, as strings keyed by their
+// strings tags for the others
+func pythonParams(protocol string, req OperationRequest) ([]byte, error) {
+	if protocol == ProtocolJSON {
+		return json.Marshal(req)
+	}
+
+	value, typ := reflect.ValueOf(req), reflect.TypeOf(req)
+	params := make(map[string]string, typ.NumField())
+	for i := range typ.NumField() {
+		name, _, _ := strings.Cut(getFieldTagValue(typ.Field(i)), ",")
+		params[name] = getStrFieldRepresentation(value.Field(i))
+	}
+
+	return json.Marshal(params)
+}
+
+// pythonReproduction is a shell command running req again with the Python script of protocol, from
+// the root of the repository
+func pythonReproduction(protocol string, address string, studentID string, req OperationRequest) (string, error) {
+	script, ok := pythonScripts[protocol]
+	if !ok {
+		return "", fmt.Errorf("there is no Python script for %s, use the CLI command", protocol)
+	}
+
+	host, port, err := net.SplitHostPort(address)
+	if err != nil || strings.Contains(address, "://") {
+		return "", fmt.Errorf("the Python scripts only dial host:port addresses, not %s", address)
+	}
+	if _, err := strconv.Atoi(port); err != nil {
+		return "", fmt.Errorf("the Python scripts only dial numeric ports, not %s", port)
+	}
+
+	params, err := pythonParams(protocol, req)
+	if err != nil {
+		return "", err
+	}
+
+	args := []string{"python3", script, shellQuote(host), port, shellQuote(studentID), req.CommandOrOperationName(), shellQuote(string(params))}
+
+	return strings.Join(args, " "), nil
+}
+
+// exportResult runs the export key msg on the last result of the client screen
+func (m *model) exportResult(msg tea.KeyMsg) error {
+	if key.Matches(msg, m.keys.Format) {
+		m.exportFormat = (m.exportFormat + 1) % len(exportFormats)
+		m.exportStatus = "Results are exported as " + exportFormats[m.exportFormat]
+		return nil
+	}
+
+	if m.export == nil {
+		return fmt.Errorf("run an operation before exporting its result")
+	}
+	format := exportFormats[m.exportFormat]
+
+	copyText := func(text string, what string) error {
+		if err := m.copyText(text); err != nil {
+			return fmt.Errorf("cannot copy to the clipboard: %w", err)
+		}
+		m.exportStatus = "Copied " + what
+		return nil
+	}
+
+	switch {
+	case key.Matches(msg, m.keys.Save):
+		path, err := m.export.save(m.exportDir, format)
+		if err != nil {
+			return err
+		}
+		m.exportStatus = "Saved to " + path
+
+	case key.Matches(msg, m.keys.Copy):
+		data, err := m.export.encode(format)
+		if err != nil {
+			return err
+		}
+		return copyText(string(data), "the result as "+format)
+
+	case key.Matches(msg, m.keys.CopyCLI):
+		return copyText(m.export.Reproduce.CLI, "the CLI command")
+
+	case key.Matches(msg, m.keys.CopyPython):
+		if m.export.Reproduce.Python == "" {
+			return errors.New(m.export.Reproduce.PythonError)
+		}
+		return copyText(m.export.Reproduce.Python, "the Python command")
+	}
+
+	return nil
+}
+
+// exportHint is the line under the response, the outcome of the last export key or the keys
+func (m model) exportHint() string {
+	hint := hintStyle.Render(fmt.Sprintf("ctrl+s save %s · ctrl+y copy · ctrl+g/p CLI/Python", exportFormats[m.exportFormat]))
+	if m.exportStatus != "" {
+		hint = inputStyle.Render(m.exportStatus)
+	}
+
+	return lipgloss.NewStyle().MaxWidth(m.viewport.Width).Render(hint)
+}
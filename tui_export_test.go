@@ -0,0 +1,224 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"slices"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.yaml.in/yaml/v3"
+)
+
+func TestParseOperationRequest(t *testing.T) {
+	tests := []struct {
+		name      string
+		operation string
+		params    string
+		expected  OperationRequest
+		wantErr   string
+	}{
+		{name: "echo", operation: OperationEcho, params: `{"mensagem":"ola"}`, expected: EchoRequest{Message: "ola"}},
+		{name: "sum", operation: OperationSum, params: `{"numeros":[1,2,3]}`, expected: SumRequest{Numbers: []int{1, 2, 3}}},
+		{name: "no params", operation: OperationTimestamp, params: `{}`, expected: TimestampRequest{}},
+		{name: "status", operation: OperationStatus, params: `{"detalhado":true}`, expected: StatusRequest{Detailed: true}},
+		{name: "unknown field", operation: OperationEcho, params: `{"message":"ola"}`, wantErr: `unknown field "message"`},
+		{name: "invalid", operation: OperationHistory, params: `{"limite":500}`, wantErr: "invalid parameters of historico"},
+		{name: "unknown operation", operation: "login", params: `{}`, wantErr: "unknown operation login"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			req, err := ParseOperationRequest(tt.operation, []byte(tt.params))
+
+			// Assert
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, req)
+		})
+	}
+}
+
+func TestCLIReproduction(t *testing.T) {
+	// Arrange
+	base := newProfileSettings()
+	staging := newStagingProfile().apply(base)
+	req := EchoRequest{Message: "it's"}
+
+	// Act
+	local := cliReproduction(ProtocolJSON, CompressionNone, "538349", req, base, base)
+	remote := cliReproduction(ProtocolString, CompressionGzip, "538349", req, staging, base)
+
+	// Assert
+	assert.Equal(t, `go run . call -protocol json -student-id '538349' -operation echo -params '{"mensagem":"it'\''s"}'`, local)
+	assert.Equal(t, `TUI_APP_STRINGPROTOCOLSERVERADDRESS='10.0.0.7:9080' go run . call -protocol string -compression gzip -student-id '538349' -operation echo -params '{"mensagem":"it'\''s"}'`, remote)
+}
+
+func TestPythonReproduction(t *testing.T) {
+	tests := []struct {
+		name     string
+		protocol string
+		address  string
+		req      OperationRequest
+		expected string
+		wantErr  string
+	}{
+		{
+			name:     "json",
+			protocol: ProtocolJSON,
+			address:  "3.88.99.255:8081",
+			req:      SumRequest{Numbers: []int{1, 2, 3}},
+			expected: `python3 scripts/json_requests.py '3.88.99.255' 8081 '538349' soma '{"numeros":[1,2,3]}'`,
+		},
+		{
+			name:     "string",
+			protocol: ProtocolString,
+			address:  "localhost:8080",
+			req:      StatusRequest{Detailed: true},
+			expected: `python3 scripts/string_request.py 'localhost' 8080 '538349' status '{"detalhado":"true"}'`,
+		},
+		{
+			name:     "protobuf",
+			protocol: ProtocolProtobuf,
+			address:  "localhost:8082",
+			req:      EchoRequest{Message: "it's \"mundo\""},
+			expected: `python3 scripts/proto_requests.py 'localhost' 8082 '538349' echo '{"mensagem":"it'\''s \"mundo\""}'`,
+		},
+		{
+			name:     "no script",
+			protocol: ProtocolCBOR,
+			address:  "localhost:8084",
+			req:      TimestampRequest{},
+			wantErr:  "there is no Python script for cbor",
+		},
+		{
+			name:     "unix address",
+			protocol: ProtocolJSON,
+			address:  "unix:///run/json.sock",
+			req:      TimestampRequest{},
+			wantErr:  "only dial host:port addresses",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			script, err := pythonReproduction(tt.protocol, tt.address, "538349", tt.req)
+
+			// Assert
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, script)
+		})
+	}
+}
+
+func TestModelExportResult(t *testing.T) {
+	// Arrange
+	m, _ := newSessionModel(t)
+	m.exportDir = t.TempDir()
+	var copied []string
+	m.copyText = func(text string) error {
+		copied = append(copied, text)
+		return nil
+	}
+	m.operationIdx = slices.Index(m.operations, OperationEcho)
+	m.form().setParams("ola")
+	press := func(k tea.KeyType) {
+		updated, _ := m.Update(tea.KeyMsg{Type: k})
+		m = updated.(model)
+		require.False(t, m.showError, m.errorMsg)
+	}
+
+	// Act
+	m = runCmd(t, m, m.executeOperation())
+	press(tea.KeyCtrlS)
+	saved := m.exportStatus
+	press(tea.KeyCtrlX)
+	press(tea.KeyCtrlY)
+	press(tea.KeyCtrlG)
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyCtrlP})
+	m = updated.(model)
+
+	// Assert
+	require.NotNil(t, m.export)
+	require.Len(t, copied, 2)
+
+	info, err := os.Stat(saved[len("Saved to "):])
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm(), "the export holds the token")
+	data, err := os.ReadFile(saved[len("Saved to "):])
+	require.NoError(t, err)
+	var exported map[string]any
+	require.NoError(t, json.Unmarshal(data, &exported))
+	assert.Equal(t, "echo", exported["operation"])
+	assert.Equal(t, map[string]any{"mensagem": "ola"}, exported["params"])
+	assert.Equal(t, "ola", exported["response"].(map[string]any)["mensagem_original"])
+	assert.NotEmpty(t, exported["wire"].(map[string]any)["request"].(map[string]any)["hex"])
+
+	var yamlExport map[string]any
+	require.NoError(t, yaml.Unmarshal([]byte(copied[0]), &yamlExport), "the second format should be yaml")
+	assert.Equal(t, "538349", yamlExport["student-id"])
+
+	assert.Equal(t, `go run . call -protocol json -student-id '538349' -operation echo -params '{"mensagem":"ola"}'`, copied[1])
+	assert.True(t, m.showError)
+	assert.Contains(t, m.errorMsg, "the Python scripts only dial host:port addresses, not loopback://json")
+}
+
+func TestModelExportResultErrors(t *testing.T) {
+	// Arrange
+	m, _ := newSessionModel(t)
+	m.copyText = func(string) error { return errors.New("no clipboard utility") }
+
+	// Act
+	err := m.exportResult(tea.KeyMsg{Type: tea.KeyCtrlS})
+	m.export = &resultExport{Reproduce: resultReproduction{CLI: "go run . call", PythonError: "there is no Python script for cbor, use the CLI command"}}
+	copyErr := m.exportResult(tea.KeyMsg{Type: tea.KeyCtrlG})
+	pythonErr := m.exportResult(tea.KeyMsg{Type: tea.KeyCtrlP})
+
+	// Assert
+	assert.ErrorContains(t, err, "run an operation before exporting its result")
+	assert.ErrorContains(t, copyErr, "cannot copy to the clipboard: no clipboard utility")
+	assert.ErrorContains(t, pythonErr, "there is no Python script for cbor")
+}
+
+func TestResultExportMarkdown(t *testing.T) {
+	// Arrange
+	capture := &wireCapture{protocol: ProtocolJSON, request: wireMessage{data: []byte(`{"tipo":"operacao"}`)}}
+	capture.request.body = capture.request.data
+	msg := operationResultMsg{
+		operation:   OperationSum,
+		protocol:    ProtocolJSON,
+		compression: CompressionNone,
+		studentID:   "538349",
+		request:     SumRequest{Numbers: []int{1, 2}},
+		wire:        capture,
+		err:         errors.New("operation failed: bad | request"),
+	}
+	settings := newProfileSettings()
+
+	// Act
+	data, err := newResultExport(msg, settings, settings).encode("markdown")
+
+	// Assert
+	require.NoError(t, err)
+	markdown := string(data)
+	assert.Contains(t, markdown, "# Sum Response")
+	assert.Contains(t, markdown, `| Outcome | ❌ operation failed: bad \| request |`)
+	assert.Contains(t, markdown, "\"numeros\": [\n    1,\n    2\n  ]")
+	assert.NotContains(t, markdown, "## Response")
+	assert.Contains(t, markdown, "▶ Request, 19 bytes")
+	assert.Contains(t, markdown, "-operation soma -params '{\"numeros\":[1,2]}'")
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// cycleFocus returns the group after focus on tab and the one before it on shift+tab, wrapping
+// around the count groups of a screen. It is false for other keys.
+func cycleFocus[F ~int](msg tea.KeyMsg, keys keyMap, focus F, count F) (F, bool) {
+	switch {
+	case key.Matches(msg, keys.Tab):
+		return (focus + 1) % count, true
+	case key.Matches(msg, keys.ShiftTab):
+		return (focus - 1 + count) % count, true
+	}
+
+	return focus, false
+}
+
+// stepCursor moves cursor over n buttons with left and right, it stops at the first and last
+func stepCursor(msg tea.KeyMsg, keys keyMap, cursor int, n int) int {
+	switch {
+	case key.Matches(msg, keys.Left):
+		return max(cursor-1, 0)
+	case key.Matches(msg, keys.Right):
+		return min(cursor+1, n-1)
+	}
+
+	return cursor
+}
+
+// focusLabel renders the label of a group, marked while the group is focused
+func focusLabel(text string, focused bool, width int) string {
+	if focused {
+		return focusedFieldStyle.Width(width).Render("▸ " + text)
+	}
+
+	return fieldStyle.Width(width).Render("  " + text)
+}
+
+// toggleRow renders names as buttons highlighted when selected, the cursor is underlined while the
+// row is focused
+func toggleRow(names []string, selected func(i int) bool, cursor int, focused bool) string {
+	buttons := make([]string, 0, len(names))
+	for i, name := range names {
+		style := buttonBlurredStyle
+		if selected(i) {
+			style = buttonFocusedStyle
+		}
+		if focused && i == cursor {
+			style = style.Underline(true)
+		}
+		buttons = append(buttons, style.Render(name))
+	}
+
+	return lipgloss.JoinHorizontal(lipgloss.Top, buttons...)
+}
+
+// actionRow renders names as action buttons, the one under the cursor is highlighted while the row
+// is focused
+func actionRow(names []string, cursor int, focused bool) string {
+	buttons := make([]string, 0, len(names))
+	for i, name := range names {
+		style := buttonBlurredStyle
+		if focused && i == cursor {
+			style = buttonFocusedStyle
+		}
+		buttons = append(buttons, style.Render(name))
+	}
+
+	return lipgloss.JoinHorizontal(lipgloss.Top, buttons...)
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCycleFocus(t *testing.T) {
+	tests := []struct {
+		name     string
+		msg      tea.KeyMsg
+		focus    historyFocus
+		expected historyFocus
+		ok       bool
+	}{
+		{name: "tab", msg: tea.KeyMsg{Type: tea.KeyTab}, focus: historyFocusFilter, expected: historyFocusList, ok: true},
+		{name: "tab wraps", msg: tea.KeyMsg{Type: tea.KeyTab}, focus: historyFocusActions, expected: historyFocusFilter, ok: true},
+		{name: "shift tab wraps", msg: tea.KeyMsg{Type: tea.KeyShiftTab}, focus: historyFocusFilter, expected: historyFocusActions, ok: true},
+		{name: "other keys", msg: tea.KeyMsg{Type: tea.KeyRight}, focus: historyFocusList, expected: historyFocusList},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			actual, ok := cycleFocus(tt.msg, keys, tt.focus, historyFocusCount)
+
+			// Assert
+			assert.Equal(t, tt.expected, actual)
+			assert.Equal(t, tt.ok, ok)
+		})
+	}
+}
+
+func TestStepCursor(t *testing.T) {
+	tests := []struct {
+		name     string
+		msg      tea.KeyMsg
+		cursor   int
+		expected int
+	}{
+		{name: "left", msg: tea.KeyMsg{Type: tea.KeyLeft}, cursor: 2, expected: 1},
+		{name: "left stops at the first", msg: tea.KeyMsg{Type: tea.KeyLeft}, cursor: 0, expected: 0},
+		{name: "right", msg: tea.KeyMsg{Type: tea.KeyRight}, cursor: 1, expected: 2},
+		{name: "right stops at the last", msg: tea.KeyMsg{Type: tea.KeyRight}, cursor: 3, expected: 3},
+		{name: "other keys", msg: tea.KeyMsg{Type: tea.KeyUp}, cursor: 1, expected: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			actual := stepCursor(tt.msg, keys, tt.cursor, 4)
+
+			// Assert
+			assert.Equal(t, tt.expected, actual)
+		})
+	}
+}
@@ -0,0 +1,415 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/go-playground/validator/v10"
+)
+
+// operationRequests are the requests of the TUI operations, in the order of model.operations
+var operationRequests = []OperationRequest{EchoRequest{}, SumRequest{}, TimestampRequest{}, HistoryRequest{}, StatusRequest{}}
+
+var formValidator = validator.New(validator.WithRequiredStructEnabled())
+
+type formFieldKind int
+
+const (
+	formFieldText formFieldKind = iota
+	formFieldNumberList
+	formFieldNumber
+	formFieldToggle
+)
+
+// formField edits one field of a request, min and max come from its validate tag and bound the
+// number, or the length of the text and of the list. Errors are shown once the field is touched.
+type formField struct {
+	name    string
+	kind    formFieldKind
+	min     int
+	max     int
+	hasMin  bool
+	hasMax  bool
+	input   textinput.Model
+	enabled bool
+	touched bool
+	err     error
+}
+
+// operationForm is the parameter form of an operation, generated from the fields of its request
+type operationForm struct {
+	request  OperationRequest
+	fields   []formField
+	focusIdx int
+}
+
+func newOperationForm(req OperationRequest) operationForm {
+	form := operationForm{request: req}
+
+	typ := reflect.TypeOf(req)
+	for i := range typ.NumField() {
+		structField := typ.Field(i)
+
+		field := formField{name: structField.Name}
+		switch structField.Type.Kind() {
+		case reflect.String:
+			field.kind = formFieldText
+		case reflect.Slice:
+			field.kind = formFieldNumberList
+		case reflect.Int:
+			field.kind = formFieldNumber
+		case reflect.Bool:
+			field.kind = formFieldToggle
+		default:
+			panic(fmt.Sprintf("no form field for %s.%s of type %s", typ.Name(), structField.Name, structField.Type))
+		}
+
+		for _, rule := range strings.Split(structField.Tag.Get("validate"), ",") {
+			name, param, _ := strings.Cut(rule, "=")
+			n, err := strconv.Atoi(param)
+			switch {
+			case name == "min" && err == nil:
+				field.min, field.hasMin = n, true
+			case name == "max" && err == nil:
+				field.max, field.hasMax = n, true
+			}
+		}
+
+		field.input = textinput.New()
+		field.input.Width = 40
+		field.input.CharLimit = 200
+		styleInput(&field.input)
+		field.input.Placeholder = field.placeholder()
+
+		form.fields = append(form.fields, field)
+	}
+
+	form.check()
+
+	return form
+}
+
+func (f formField) placeholder() string {
+	switch f.kind {
+	case formFieldNumberList:
+		return "4, 8, 15"
+	case formFieldNumber:
+		if f.hasMin {
+			return strconv.Itoa(f.min)
+		}
+		return "0"
+	default:
+		return "Enter " + strings.ToLower(f.name)
+	}
+}
+
+// hint describes what the field takes
+func (f formField) hint() string {
+	bounds := func(unit string) string {
+		switch {
+		case f.hasMin && f.hasMax:
+			return fmt.Sprintf(", %d to %d%s", f.min, f.max, unit)
+		case f.hasMin:
+			return fmt.Sprintf(", at least %d%s", f.min, unit)
+		case f.hasMax:
+			return fmt.Sprintf(", at most %d%s", f.max, unit)
+		}
+		return ""
+	}
+
+	switch f.kind {
+	case formFieldNumberList:
+		return "Whole numbers separated by commas or spaces" + bounds(" of them")
+	case formFieldNumber:
+		return "Whole number" + bounds("") + ", ←/→ to step"
+	case formFieldToggle:
+		return "Space or ←/→ to toggle"
+	default:
+		return "Text" + bounds(" characters")
+	}
+}
+
+// value parses the input of the field into the type of its request field
+func (f formField) value() (any, error) {
+	text := strings.TrimSpace(f.input.Value())
+
+	switch f.kind {
+	case formFieldNumberList:
+		items := strings.FieldsFunc(text, func(r rune) bool { return r == ',' || unicode.IsSpace(r) })
+		numbers := make([]int, 0, len(items))
+		for i, item := range items {
+			n, err := strconv.Atoi(item)
+			if err != nil {
+				return nil, fmt.Errorf("%s: item %d, %q, is not a whole number", f.name, i+1, item)
+			}
+			numbers = append(numbers, n)
+		}
+		return numbers, nil
+
+	case formFieldNumber:
+		if text == "" {
+			return 0, nil
+		}
+		n, err := strconv.Atoi(text)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %q is not a whole number", f.name, text)
+		}
+		return n, nil
+
+	case formFieldToggle:
+		return f.enabled, nil
+
+	default:
+		return f.input.Value(), nil
+	}
+}
+
+// fieldErrorMessage words a failed validate tag of field
+func fieldErrorMessage(field formField, fe validator.FieldError) error {
+	unit := " character"
+	if field.kind == formFieldNumberList {
+		unit = " number"
+	}
+	if fe.Param() != "1" {
+		unit += "s"
+	}
+
+	switch fe.Tag() {
+	case "required":
+		return fmt.Errorf("%s is required", field.name)
+	case "min":
+		if field.kind == formFieldNumber {
+			return fmt.Errorf("%s must be at least %s", field.name, fe.Param())
+		}
+		return fmt.Errorf("%s needs at least %s%s", field.name, fe.Param(), unit)
+	case "max":
+		if field.kind == formFieldNumber {
+			return fmt.Errorf("%s must be at most %s", field.name, fe.Param())
+		}
+		return fmt.Errorf("%s takes at most %s%s", field.name, fe.Param(), unit)
+	default:
+		return fmt.Errorf("%s fails the %s rule", field.name, fe.Tag())
+	}
+}
+
+// build parses and validates the fields into the request of the form, field errors are also kept
+// on the fields to show them inline
+func (f *operationForm) build() (OperationRequest, error) {
+	req := reflect.New(reflect.TypeOf(f.request)).Elem()
+
+	var errs []error
+	for i := range f.fields {
+		field := &f.fields[i]
+		field.err = nil
+
+		value, err := field.value()
+		if err != nil {
+			field.err = err
+			errs = append(errs, err)
+			continue
+		}
+		req.FieldByName(field.name).Set(reflect.ValueOf(value))
+	}
+
+	var validationErrs validator.ValidationErrors
+	if err := formValidator.Struct(req.Interface()); errors.As(err, &validationErrs) {
+		for _, fe := range validationErrs {
+			for i := range f.fields {
+				field := &f.fields[i]
+				if field.name == fe.StructField() && field.err == nil {
+					field.err = fieldErrorMessage(*field, fe)
+					errs = append(errs, field.err)
+				}
+			}
+		}
+	} else if err != nil {
+		errs = append(errs, err)
+	}
+
+	if len(errs) > 0 {
+		return nil, errs[0]
+	}
+
+	return req.Interface().(OperationRequest), nil
+}
+
+// check refreshes the inline errors of the fields
+func (f *operationForm) check() {
+	_, _ = f.build()
+}
+
+// touch shows the errors of every field, once the form is submitted
+func (f *operationForm) touch() {
+	for i := range f.fields {
+		f.fields[i].touched = true
+	}
+}
+
+// params summarizes the values of the form, as kept in the history
+func (f operationForm) params() string {
+	values := make([]string, 0, len(f.fields))
+	for _, field := range f.fields {
+		if field.kind == formFieldToggle {
+			values = append(values, strconv.FormatBool(field.enabled))
+		} else {
+			values = append(values, field.input.Value())
+		}
+	}
+
+	return strings.Join(values, "; ")
+}
+
+// setParams fills the form from a params summary
+func (f *operationForm) setParams(params string) {
+	values := []string{params}
+	if len(f.fields) > 1 {
+		values = strings.SplitN(params, "; ", len(f.fields))
+	}
+
+	for i, value := range values[:min(len(values), len(f.fields))] {
+		field := &f.fields[i]
+		if field.kind == formFieldToggle {
+			field.enabled = value == "true"
+		} else {
+			field.input.SetValue(value)
+		}
+		field.touched = true
+	}
+
+	f.check()
+}
+
+// focus moves the focus to the first or the last field
+func (f *operationForm) focus(last bool) {
+	f.focusIdx = 0
+	if last {
+		f.focusIdx = max(len(f.fields)-1, 0)
+	}
+	f.updateFocus(true)
+}
+
+func (f *operationForm) blur() {
+	f.updateFocus(false)
+}
+
+func (f *operationForm) updateFocus(focused bool) {
+	for i := range f.fields {
+		f.fields[i].input.Blur()
+		if focused && i == f.focusIdx {
+			f.fields[i].input.Focus()
+		}
+	}
+}
+
+// next focuses the following field, it is false past the last field
+func (f *operationForm) next() bool {
+	if f.focusIdx+1 >= len(f.fields) {
+		return false
+	}
+	f.focusIdx++
+	f.updateFocus(true)
+
+	return true
+}
+
+// prev focuses the previous field, it is false before the first field
+func (f *operationForm) prev() bool {
+	if f.focusIdx <= 0 {
+		return false
+	}
+	f.focusIdx--
+	f.updateFocus(true)
+
+	return true
+}
+
+// update edits the focused field, numbers step and toggles flip with the arrows
+func (f *operationForm) update(msg tea.Msg, keys keyMap) tea.Cmd {
+	if f.focusIdx >= len(f.fields) {
+		return nil
+	}
+	field := &f.fields[f.focusIdx]
+
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		field.touched = true
+
+		switch field.kind {
+		case formFieldToggle:
+			if key.Matches(msg, keys.Toggle, keys.Left, keys.Right) {
+				field.enabled = !field.enabled
+				f.check()
+			}
+			return nil
+
+		case formFieldNumber:
+			step := 0
+			switch {
+			case key.Matches(msg, keys.Left):
+				step = -1
+			case key.Matches(msg, keys.Right):
+				step = 1
+			}
+			if step != 0 {
+				n, _ := strconv.Atoi(strings.TrimSpace(field.input.Value()))
+				n += step
+				if field.hasMin {
+					n = max(n, field.min)
+				}
+				if field.hasMax {
+					n = min(n, field.max)
+				}
+				field.input.SetValue(strconv.Itoa(n))
+				f.check()
+				return nil
+			}
+		}
+	}
+
+	var cmd tea.Cmd
+	field.input, cmd = field.input.Update(msg)
+	f.check()
+
+	return cmd
+}
+
+func (f operationForm) view(width int, focused bool) string {
+	if len(f.fields) == 0 {
+		return hintStyle.Width(width).Render("  No parameters needed")
+	}
+
+	lines := []string{}
+	for i, field := range f.fields {
+		label := fieldStyle.Render("    " + field.name + ":")
+		if focused && i == f.focusIdx {
+			label = focusedFieldStyle.Render("  ▸ " + field.name + ":")
+		}
+
+		value := field.input.View()
+		if field.kind == formFieldToggle {
+			on, off := buttonBlurredStyle, buttonBlurredStyle
+			if field.enabled {
+				on = buttonFocusedStyle
+			} else {
+				off = buttonFocusedStyle
+			}
+			value = lipgloss.JoinHorizontal(lipgloss.Top, off.Render("off"), on.Render("on"))
+		}
+
+		lines = append(lines, lipgloss.JoinHorizontal(lipgloss.Top, label, " ", value))
+		if field.err != nil && field.touched {
+			lines = append(lines, errorTitleStyle.Render("    ✗ "+field.err.Error()))
+		} else {
+			lines = append(lines, hintStyle.Render("    "+field.hint()))
+		}
+	}
+
+	return lipgloss.NewStyle().Width(width).Render(lipgloss.JoinVertical(lipgloss.Left, lines...))
+}
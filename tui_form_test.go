@@ -0,0 +1,160 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewOperationForm(t *testing.T) {
+	tests := []struct {
+		name     string
+		request  OperationRequest
+		expected []formField
+	}{
+		{name: "echo", request: EchoRequest{}, expected: []formField{{name: "Message", kind: formFieldText}}},
+		{name: "sum", request: SumRequest{}, expected: []formField{{name: "Numbers", kind: formFieldNumberList, min: 1, max: 1000, hasMin: true, hasMax: true}}},
+		{name: "timestamp", request: TimestampRequest{}, expected: nil},
+		{name: "history", request: HistoryRequest{}, expected: []formField{{name: "Limit", kind: formFieldNumber, min: 1, max: 100, hasMin: true, hasMax: true}}},
+		{name: "status", request: StatusRequest{}, expected: []formField{{name: "Detailed", kind: formFieldToggle}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			form := newOperationForm(tt.request)
+
+			// Assert
+			require.Len(t, form.fields, len(tt.expected))
+			for i, expected := range tt.expected {
+				actual := form.fields[i]
+				assert.Equal(t, expected.name, actual.name)
+				assert.Equal(t, expected.kind, actual.kind)
+				assert.Equal(t, expected.min, actual.min)
+				assert.Equal(t, expected.max, actual.max)
+				assert.Equal(t, expected.hasMin, actual.hasMin)
+				assert.Equal(t, expected.hasMax, actual.hasMax)
+				assert.False(t, actual.touched, "errors should wait for the user")
+			}
+		})
+	}
+}
+
+func TestOperationFormBuild(t *testing.T) {
+	tests := []struct {
+		name     string
+		request  OperationRequest
+		params   string
+		expected OperationRequest
+		err      string
+	}{
+		{name: "echo", request: EchoRequest{}, params: "ola mundo", expected: EchoRequest{Message: "ola mundo"}},
+		{name: "empty echo", request: EchoRequest{}, params: "", err: "Message is required"},
+		{name: "sum with commas and spaces", request: SumRequest{}, params: "4, 8 15,", expected: SumRequest{Numbers: []int{4, 8, 15}}},
+		{name: "sum with a word", request: SumRequest{}, params: "4, oito", err: `Numbers: item 2, "oito", is not a whole number`},
+		{name: "empty sum", request: SumRequest{}, params: " , ", err: "Numbers needs at least 1 number"},
+		{name: "timestamp", request: TimestampRequest{}, params: "", expected: TimestampRequest{}},
+		{name: "history", request: HistoryRequest{}, params: "10", expected: HistoryRequest{Limit: 10}},
+		{name: "history above max", request: HistoryRequest{}, params: "101", err: "Limit must be at most 100"},
+		{name: "history not a number", request: HistoryRequest{}, params: "ten", err: `Limit: "ten" is not a whole number`},
+		{name: "empty history", request: HistoryRequest{}, params: "", err: "Limit is required"},
+		{name: "detailed status", request: StatusRequest{}, params: "true", expected: StatusRequest{Detailed: true}},
+		{name: "status", request: StatusRequest{}, params: "false", expected: StatusRequest{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			form := newOperationForm(tt.request)
+			form.setParams(tt.params)
+
+			// Act
+			req, err := form.build()
+
+			// Assert
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				require.Len(t, form.fields, 1)
+				assert.EqualError(t, form.fields[0].err, tt.err, "the error should be shown on the field")
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, req)
+			for _, field := range form.fields {
+				assert.NoError(t, field.err)
+			}
+		})
+	}
+}
+
+func TestOperationFormParams(t *testing.T) {
+	// Arrange
+	form := newOperationForm(SumRequest{})
+	form.setParams("1,2,3")
+	status := newOperationForm(StatusRequest{})
+
+	// Act
+	params := form.params()
+	statusParams := status.params()
+
+	// Assert
+	assert.Equal(t, "1,2,3", params)
+	assert.Equal(t, "false", statusParams)
+}
+
+func TestOperationFormUpdate(t *testing.T) {
+	// Arrange
+	history := newOperationForm(HistoryRequest{})
+	history.focus(false)
+	status := newOperationForm(StatusRequest{})
+	status.focus(false)
+	echo := newOperationForm(EchoRequest{})
+	echo.focus(false)
+
+	// Act
+	history.update(tea.KeyMsg{Type: tea.KeyLeft}, keys)
+	afterLeft := history.fields[0].input.Value()
+	history.setParams("99")
+	history.update(tea.KeyMsg{Type: tea.KeyRight}, keys)
+	history.update(tea.KeyMsg{Type: tea.KeyRight}, keys)
+
+	status.update(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune(" ")}, keys)
+
+	echo.update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("o")}, keys)
+	typedErr := echo.fields[0].err
+	echo.update(tea.KeyMsg{Type: tea.KeyBackspace}, keys)
+
+	// Assert
+	assert.Equal(t, "1", afterLeft, "stepping should stay above the minimum")
+	assert.Equal(t, "100", history.fields[0].input.Value(), "stepping should stay below the maximum")
+	assert.True(t, status.fields[0].enabled)
+	assert.Equal(t, "true", status.params())
+	assert.NoError(t, typedErr)
+	assert.True(t, echo.fields[0].touched)
+	assert.EqualError(t, echo.fields[0].err, "Message is required")
+	assert.Contains(t, echo.view(60, true), "Message is required", "touched fields should show their error")
+	assert.NotContains(t, newOperationForm(EchoRequest{}).view(60, false), "required", "untouched fields should show their hint")
+}
+
+func TestOperationFormFocus(t *testing.T) {
+	// Arrange
+	form := operationForm{fields: []formField{{name: "A", input: textinput.New()}, {name: "B", input: textinput.New()}}}
+
+	// Act
+	form.focus(true)
+	last := form.focusIdx
+	prev := form.prev()
+	beforeFirst := form.prev()
+	next := form.next()
+	pastLast := form.next()
+
+	// Assert
+	assert.Equal(t, 1, last)
+	assert.True(t, prev)
+	assert.False(t, beforeFirst)
+	assert.True(t, next)
+	assert.False(t, pastLast)
+}
@@ -0,0 +1,505 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// historyFile is the name of the file in the user config directory where the TUI keeps its
+// operations, one JSON entry per line
+const historyFile = "tui-history.jsonl"
+
+const (
+	// historyPageSize is the number of entries the history list shows at once
+	historyPageSize = 15
+	// historyRecent is the number of entries shown under the client form
+	historyRecent = 5
+	// historyResultLines bounds the result body shown in the details of an entry
+	historyResultLines = 15
+	// maxHistoryLineSize bounds an entry of the history file, wire bytes included
+	maxHistoryLineSize = 16 << 20
+	// historyLimit is the number of latest entries kept, the file is compacted to them once it
+	// holds twice as many
+	historyLimit = 1000
+)
+
+// historyEntry is one operation of the TUI, compared operations have an entry per protocol. The
+// request and response are the bytes on the wire, hex encoded like in result exports.
+type historyEntry struct {
+	At            time.Time     `json:"at"`
+	Protocol      string        `json:"protocol"`
+	Compression   string        `json:"compression"`
+	StudentID     string        `json:"student_id"`
+	Operation     string        `json:"operation"`
+	Params        string        `json:"params"`
+	Latency       time.Duration `json:"latency"`
+	RequestBytes  int           `json:"request_bytes"`
+	ResponseBytes int           `json:"response_bytes"`
+	RequestHex    string        `json:"request_hex,omitempty"`
+	ResponseHex   string        `json:"response_hex,omitempty"`
+	Success       bool          `json:"success"`
+	Error         string        `json:"error,omitempty"`
+	Result        string        `json:"result,omitempty"`
+}
+
+// matches tells whether every word of query is found, ignoring case, in the protocol, operation,
+// params, student or error of the entry
+func (e historyEntry) matches(query string) bool {
+	text := strings.ToLower(strings.Join([]string{e.Protocol, e.Compression, e.Operation, operationDisplayName(e.Operation), e.Params, e.StudentID, e.Error}, " "))
+	for _, word := range strings.Fields(strings.ToLower(query)) {
+		if !strings.Contains(text, word) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// operationHistory holds the operations of the TUI, appended to path when it is set. The entries
+// hold tokens, so the file is only readable by the user.
+type operationHistory struct {
+	path    string
+	entries []historyEntry
+	// stored is the number of entries in the file
+	stored int
+
+	// query and filtered are the last filter, add clears them
+	query    string
+	filtered []historyEntry
+}
+
+// defaultHistoryPath is the history file in the user config directory, next to the server
+// profiles, or in the working directory when there is no user config directory
+func defaultHistoryPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return historyFile
+	}
+
+	return filepath.Join(dir, "triprotocol-benchmark", historyFile)
+}
+
+// loadOperationHistory reads the entries saved at path, a missing file is an empty history. Lines
+// that are not entries, like one cut short by a crash, are logged and skipped.
+func loadOperationHistory(path string) (*operationHistory, error) {
+	history := &operationHistory{path: path}
+
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return history, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, maxHistoryLineSize)
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+
+		var entry historyEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			slog.Warn("Skipping invalid history entry", slog.String("path", path), slog.Int("line", line), slog.String("error", err.Error()))
+			continue
+		}
+		history.entries = append(history.entries, entry)
+	}
+	history.stored = len(history.entries)
+	history.trim()
+
+	return history, scanner.Err()
+}
+
+// add records entries, and appends them to the history file when there is one
+func (h *operationHistory) add(entries ...historyEntry) error {
+	h.entries = append(h.entries, entries...)
+	h.trim()
+	h.filtered = nil
+
+	if h.path == "" {
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(h.path), 0o700); err != nil {
+		return err
+	}
+
+	if h.stored+len(entries) > 2*historyLimit {
+		return h.compact()
+	}
+
+	f, err := os.OpenFile(h.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	encoder := json.NewEncoder(f)
+	for _, entry := range entries {
+		if err := encoder.Encode(entry); err != nil {
+			return err
+		}
+		h.stored++
+	}
+
+	return nil
+}
+
+// trim drops the entries older than the latest historyLimit
+func (h *operationHistory) trim() {
+	if over := len(h.entries) - historyLimit; over > 0 {
+		h.entries = slices.Clone(h.entries[over:])
+	}
+}
+
+// compact rewrites the history file with the kept entries only, through a temporary file so a
+// crash leaves the old file in place
+func (h *operationHistory) compact() error {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	for _, entry := range h.entries {
+		if err := encoder.Encode(entry); err != nil {
+			return err
+		}
+	}
+
+	tmp := h.path + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0o600); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, h.path); err != nil {
+		return err
+	}
+	h.stored = len(h.entries)
+
+	return nil
+}
+
+// filter returns the entries matching query, latest first. The result is kept until the query
+// changes or entries are added, the screen asks for it on every render.
+func (h *operationHistory) filter(query string) []historyEntry {
+	if h.filtered != nil && h.query == query {
+		return h.filtered
+	}
+
+	entries := make([]historyEntry, 0, len(h.entries))
+	for _, entry := range slices.Backward(h.entries) {
+		if entry.matches(query) {
+			entries = append(entries, entry)
+		}
+	}
+	h.query, h.filtered = query, entries
+
+	return entries
+}
+
+// recent returns the latest n entries, latest first
+func (h *operationHistory) recent(n int) []historyEntry {
+	entries := make([]historyEntry, 0, min(n, len(h.entries)))
+	for _, entry := range slices.Backward(h.entries) {
+		if len(entries) == n {
+			break
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries
+}
+
+type historyFocus int
+
+const (
+	historyFocusFilter historyFocus = iota
+	historyFocusList
+	historyFocusActions
+	historyFocusCount
+)
+
+const (
+	historyActionRerun = iota
+	historyActionExport
+	historyActionCount
+)
+
+// historyRerunMsg asks the client screen to run entry again
+type historyRerunMsg struct {
+	entry historyEntry
+}
+
+// historyPane is the history screen of the TUI
+type historyPane struct {
+	store      *operationHistory
+	filter     textinput.Model
+	cursor     int
+	actionIdx  int
+	focusIndex historyFocus
+	exportDir  string
+	exported   string
+}
+
+func newHistoryPane(store *operationHistory) historyPane {
+	filter := textinput.New()
+	filter.Placeholder = "protocol, operation, params..."
+	filter.Width = 40
+	filter.CharLimit = 100
+	styleInput(&filter)
+	filter.Focus()
+
+	return historyPane{
+		store:     store,
+		filter:    filter,
+		exportDir: ".",
+	}
+}
+
+func (h historyPane) entries() []historyEntry {
+	return h.store.filter(h.filter.Value())
+}
+
+// selected returns the entry under the cursor
+func (h historyPane) selected() (historyEntry, bool) {
+	entries := h.entries()
+	if h.cursor >= len(entries) {
+		return historyEntry{}, false
+	}
+
+	return entries[h.cursor], true
+}
+
+// export writes the selected entry to its own JSON file
+func (h *historyPane) export() (string, error) {
+	entry, ok := h.selected()
+	if !ok {
+		return "", fmt.Errorf("there is no history entry to export")
+	}
+
+	data, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	// The wire bytes hold the token, like the history file the export is only readable by the user
+	path := filepath.Join(h.exportDir, "history-"+entry.At.Format("20060102-150405.000000")+".json")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return "", err
+	}
+	h.exported = path
+
+	return path, nil
+}
+
+func (h *historyPane) updateFocus() {
+	h.filter.Blur()
+	if h.focusIndex == historyFocusFilter {
+		h.filter.Focus()
+	}
+}
+
+// update filters and browses the history, a failed action returns the error to show in the popup
+func (h historyPane) update(msg tea.KeyMsg, keys keyMap) (historyPane, tea.Cmd, error) {
+	if focus, ok := cycleFocus(msg, keys, h.focusIndex, historyFocusCount); ok {
+		h.focusIndex = focus
+		h.updateFocus()
+		return h, nil, nil
+	}
+
+	switch {
+	case key.Matches(msg, keys.Up):
+		if h.focusIndex == historyFocusList {
+			h.cursor = max(h.cursor-1, 0)
+		}
+
+	case key.Matches(msg, keys.Down):
+		if h.focusIndex == historyFocusList {
+			h.cursor = max(min(h.cursor+1, len(h.entries())-1), 0)
+		}
+
+	case key.Matches(msg, keys.Left, keys.Right):
+		if h.focusIndex == historyFocusActions {
+			h.actionIdx = stepCursor(msg, keys, h.actionIdx, historyActionCount)
+		}
+
+	case key.Matches(msg, keys.Enter):
+		if h.focusIndex == historyFocusActions {
+			return h.act()
+		}
+	}
+
+	if h.focusIndex != historyFocusFilter {
+		return h, nil, nil
+	}
+
+	var cmd tea.Cmd
+	query := h.filter.Value()
+	h.filter, cmd = h.filter.Update(msg)
+	if h.filter.Value() != query {
+		h.cursor = 0
+	}
+
+	return h, cmd, nil
+}
+
+// act re-runs or exports the entry under the cursor
+func (h historyPane) act() (historyPane, tea.Cmd, error) {
+	entry, ok := h.selected()
+	if !ok {
+		return h, nil, fmt.Errorf("there is no history entry selected")
+	}
+
+	switch h.actionIdx {
+	case historyActionRerun:
+		return h, func() tea.Msg { return historyRerunMsg{entry: entry} }, nil
+
+	case historyActionExport:
+		_, err := h.export()
+		return h, nil, err
+	}
+
+	return h, nil, nil
+}
+
+// updateInputs forwards the messages that are not keys, like the cursor blink, to the filter
+func (h historyPane) updateInputs(msg tea.Msg) (historyPane, tea.Cmd) {
+	var cmd tea.Cmd
+	if h.focusIndex == historyFocusFilter {
+		h.filter, cmd = h.filter.Update(msg)
+	}
+
+	return h, cmd
+}
+
+// loadHistoryEntry fills the client form with the operation of entry
+func (m *model) loadHistoryEntry(entry historyEntry) error {
+	protocolIdx := slices.Index(m.protocols, entry.Protocol)
+	if protocolIdx < 0 {
+		return fmt.Errorf("unknown protocol %s", entry.Protocol)
+	}
+
+	operationIdx := slices.Index(m.operations, entry.Operation)
+	if operationIdx < 0 {
+		return fmt.Errorf("%s operations cannot be run again", entry.Operation)
+	}
+
+	m.protocolIdx = protocolIdx
+	m.compressionIdx[protocolIdx] = max(slices.Index(Compressions, entry.Compression), 0)
+	m.operationIdx = operationIdx
+	m.forms[operationIdx].setParams(entry.Params)
+	if entry.StudentID != "" {
+		m.enrollment.SetValue(entry.StudentID)
+	}
+
+	return nil
+}
+
+// recordHistory adds entries to the history, a history file that cannot be written is logged only
+func (m model) recordHistory(entries ...historyEntry) {
+	if err := m.history.store.add(entries...); err != nil {
+		slog.Error("Failed to save the operation history", slog.String("error", err.Error()))
+	}
+}
+
+func (m model) renderHistoryLeftPanel(width int) string {
+	h := m.history
+	localFieldStyle := fieldStyle.Width(width)
+
+	label := func(focus historyFocus, text string) string {
+		return focusLabel(text, h.focusIndex == focus, width)
+	}
+
+	entries := h.entries()
+	start := max(0, min(h.cursor-historyPageSize/2, len(entries)-historyPageSize))
+
+	rows := []string{}
+	for i, entry := range entries[start:min(start+historyPageSize, len(entries))] {
+		status := "✅"
+		if !entry.Success {
+			status = "❌"
+		}
+
+		row := fmt.Sprintf("%s %s %-8s %-9s %s", status, entry.At.Format("01-02 15:04:05"), entry.Protocol, entry.Operation, entry.Params)
+		row = lipgloss.NewStyle().MaxWidth(width - 4).Render(row)
+		if start+i == h.cursor {
+			style := inputStyle
+			if h.focusIndex == historyFocusList {
+				style = focusedFieldStyle
+			}
+			rows = append(rows, style.Render("▸ "+row))
+		} else {
+			rows = append(rows, inputStyle.Render("  "+row))
+		}
+	}
+	if len(rows) == 0 {
+		rows = append(rows, hintStyle.Render("  No operations found."))
+	}
+
+	actions := actionRow([]string{"RE-RUN", "EXPORT"}, h.actionIdx, h.focusIndex == historyFocusActions)
+
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		titleStyle.Width(width).Render("╔═ History ═╗"),
+		"",
+		label(historyFocusFilter, "Filter:"),
+		localFieldStyle.Render("  "+h.filter.View()),
+		"",
+		label(historyFocusList, fmt.Sprintf("Operations (%d of %d):", len(entries), len(h.store.entries))),
+		lipgloss.JoinVertical(lipgloss.Left, rows...),
+		"",
+		localFieldStyle.Render("  "+actions),
+	)
+}
+
+func (m model) renderHistoryRightPanel(width int) string {
+	h := m.history
+	lines := []string{titleStyle.Render("╔═ Entry ═╗"), ""}
+
+	entry, ok := h.selected()
+	if !ok {
+		lines = append(lines, "Operations of the client and compare", "screens show up here.")
+	} else {
+		status := "✅ succeeded"
+		if !entry.Success {
+			status = "❌ failed"
+		}
+
+		lines = append(lines,
+			inputStyle.Render(fmt.Sprintf("%s %s over %s (%s)", entry.Operation, status, entry.Protocol, entry.Compression)),
+			hintStyle.Render(entry.At.Format(time.DateTime)+", student "+entry.StudentID),
+			inputStyle.Render("params: "+entry.Params),
+			inputStyle.Render(fmt.Sprintf("rtt %s, sent %d B, received %d B", entry.Latency.Round(time.Microsecond), entry.RequestBytes, entry.ResponseBytes)),
+			"",
+		)
+
+		if entry.Error != "" {
+			lines = append(lines, errorTitleStyle.Render(entry.Error))
+		}
+
+		result := strings.Split(entry.Result, "\n")
+		if len(result) > historyResultLines {
+			result = append(result[:historyResultLines], hintStyle.Render(fmt.Sprintf("... %d more lines, export the entry to see them", len(result)-historyResultLines)))
+		}
+		lines = append(lines, result...)
+	}
+
+	if h.exported != "" {
+		lines = append(lines, "", hintStyle.Render("Exported to "+h.exported))
+	}
+
+	return panelBorderStyle.Padding(1).Width(width - 2).Render(lipgloss.JoinVertical(lipgloss.Left, lines...))
+}
@@ -0,0 +1,291 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newHistoryEntries() []historyEntry {
+	at := time.Date(2025, 3, 14, 15, 9, 26, 0, time.UTC)
+	return []historyEntry{
+		{At: at, Protocol: ProtocolJSON, Compression: CompressionNone, StudentID: "538349", Operation: OperationEcho, Params: "ola", Latency: time.Millisecond, RequestBytes: 3, ResponseBytes: 2, RequestHex: "7b7d0a", ResponseHex: "7b7d", Success: true, Result: "Echo Response"},
+		{At: at.Add(time.Second), Protocol: ProtocolString, Compression: CompressionGzip, StudentID: "538349", Operation: OperationSum, Params: "1,2", Success: false, Error: "operation failed: timeout"},
+		{At: at.Add(2 * time.Second), Protocol: ProtocolProtobuf, Compression: CompressionNone, StudentID: "538349", Operation: OperationHistory, Params: "5", Success: true},
+	}
+}
+
+func TestHistoryEntryMatches(t *testing.T) {
+	entry := newHistoryEntries()[1]
+
+	tests := []struct {
+		name     string
+		query    string
+		expected bool
+	}{
+		{name: "empty", query: "", expected: true},
+		{name: "protocol ignoring case", query: "STRING", expected: true},
+		{name: "every word", query: "sum timeout", expected: true},
+		{name: "compression", query: "gzip", expected: true},
+		{name: "missing word", query: "sum json", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			actual := entry.matches(tt.query)
+
+			// Assert
+			assert.Equal(t, tt.expected, actual)
+		})
+	}
+}
+
+func TestOperationHistoryPersistence(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), "triprotocol-benchmark", historyFile)
+	entries := newHistoryEntries()
+
+	// Act
+	history, loadErr := loadOperationHistory(path)
+	require.NoError(t, loadErr, "a missing file should be an empty history")
+	firstErr := history.add(entries[0])
+	restErr := history.add(entries[1:]...)
+	reloaded, reloadErr := loadOperationHistory(path)
+
+	// Assert
+	require.NoError(t, firstErr)
+	require.NoError(t, restErr)
+	require.NoError(t, reloadErr)
+	assert.Equal(t, entries, reloaded.entries)
+	assert.Equal(t, history.entries, reloaded.entries)
+
+	dir, err := os.Stat(filepath.Dir(path))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o700), dir.Mode().Perm(), "the history directory should be private")
+	file, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), file.Mode().Perm(), "the history holds tokens")
+}
+
+func TestOperationHistoryLimit(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), historyFile)
+	history, err := loadOperationHistory(path)
+	require.NoError(t, err)
+	entries := make([]historyEntry, 2*historyLimit+1)
+	for i := range entries {
+		entries[i] = historyEntry{Protocol: ProtocolJSON, Operation: OperationEcho, Params: strconv.Itoa(i)}
+	}
+
+	// Act
+	appendErr := history.add(entries[:2*historyLimit]...)
+	appended, appendedErr := loadOperationHistory(path)
+	compactErr := history.add(entries[2*historyLimit])
+	compacted, compactedErr := loadOperationHistory(path)
+
+	// Assert
+	require.NoError(t, appendErr)
+	require.NoError(t, appendedErr)
+	require.NoError(t, compactErr)
+	require.NoError(t, compactedErr)
+	assert.Equal(t, entries[historyLimit+1:], history.entries, "only the latest entries should be kept")
+	assert.Equal(t, entries[historyLimit:2*historyLimit], appended.entries, "the file should be appended to until it holds twice the limit")
+	assert.Equal(t, 2*historyLimit, appended.stored)
+	assert.Equal(t, entries[historyLimit+1:], compacted.entries, "the file should be compacted to the kept entries")
+	assert.Equal(t, historyLimit, compacted.stored)
+}
+
+func TestLoadOperationHistorySkipsInvalidLines(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), historyFile)
+	entries := newHistoryEntries()
+	var data []byte
+	for _, entry := range entries {
+		line, err := json.Marshal(entry)
+		require.NoError(t, err)
+		data = append(append(data, line...), '\n')
+	}
+	data = append(data, []byte("not json\n\n")...)
+	data = append(data, []byte(`{"at": "2025-03-14T15:09`)...)
+	require.NoError(t, os.WriteFile(path, data, 0o644))
+
+	// Act
+	history, err := loadOperationHistory(path)
+
+	// Assert
+	require.NoError(t, err, "a truncated line should not lose the history")
+	assert.Equal(t, entries, history.entries)
+}
+
+func TestOperationHistoryFilter(t *testing.T) {
+	// Arrange
+	history := &operationHistory{}
+	require.NoError(t, history.add(newHistoryEntries()...))
+
+	// Act
+	all := history.filter("")
+	filtered := history.filter("json")
+	recent := history.recent(2)
+
+	// Assert
+	require.Len(t, all, 3)
+	assert.Equal(t, OperationHistory, all[0].Operation, "the latest entry should come first")
+	require.Len(t, filtered, 1)
+	assert.Equal(t, OperationEcho, filtered[0].Operation)
+	assert.Equal(t, all[:2], recent)
+	assert.Empty(t, (&operationHistory{}).recent(historyRecent))
+}
+
+func TestOperationHistoryFilterCache(t *testing.T) {
+	// Arrange
+	history := &operationHistory{}
+	entries := newHistoryEntries()
+	require.NoError(t, history.add(entries[:2]...))
+
+	// Act
+	first := history.filter("json")
+	cached := history.filter("json")
+	require.NoError(t, history.add(entries[2]))
+	added := history.filter("")
+
+	// Assert
+	assert.Same(t, &first[0], &cached[0], "the same query should not filter again")
+	assert.Len(t, added, 3, "added entries should clear the cache")
+}
+
+func TestHistoryPaneUpdate(t *testing.T) {
+	// Arrange
+	history := &operationHistory{}
+	require.NoError(t, history.add(newHistoryEntries()...))
+	h := newHistoryPane(history)
+	h.exportDir = t.TempDir()
+
+	press := func(keyType tea.KeyType) {
+		t.Helper()
+		var err error
+		h, _, err = h.update(tea.KeyMsg{Type: keyType}, keys)
+		require.NoError(t, err)
+	}
+
+	// Act
+	h, _, _ = h.update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("j")}, keys)
+	filteredCursor := h.cursor
+	filtered := len(h.entries())
+	press(tea.KeyBackspace)
+
+	press(tea.KeyTab)
+	press(tea.KeyDown)
+	press(tea.KeyDown)
+	press(tea.KeyDown)
+	selected, _ := h.selected()
+
+	press(tea.KeyTab)
+	_, rerun, rerunErr := h.update(tea.KeyMsg{Type: tea.KeyEnter}, keys)
+	press(tea.KeyRight)
+	h, _, exportErr := h.update(tea.KeyMsg{Type: tea.KeyEnter}, keys)
+
+	// Assert
+	assert.Zero(t, filteredCursor)
+	assert.Equal(t, 1, filtered, "typing in the filter should narrow the list")
+	assert.Equal(t, "echo", selected.Operation, "the cursor should stop at the last entry")
+	require.NoError(t, rerunErr)
+	require.NotNil(t, rerun)
+	assert.Equal(t, historyRerunMsg{entry: selected}, rerun())
+	require.NoError(t, exportErr)
+	require.NotEmpty(t, h.exported)
+
+	info, err := os.Stat(h.exported)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm(), "the export holds the token")
+	data, err := os.ReadFile(h.exported)
+	require.NoError(t, err)
+	var exported historyEntry
+	require.NoError(t, json.Unmarshal(data, &exported))
+	assert.Equal(t, selected, exported)
+}
+
+func TestHistoryPaneEmpty(t *testing.T) {
+	// Arrange
+	h := newHistoryPane(&operationHistory{})
+	h.focusIndex = historyFocusActions
+
+	// Act
+	_, _, err := h.update(tea.KeyMsg{Type: tea.KeyEnter}, keys)
+	_, exportErr := h.export()
+
+	// Assert
+	assert.Error(t, err)
+	assert.Error(t, exportErr)
+}
+
+func TestModelLoadHistoryEntry(t *testing.T) {
+	tests := []struct {
+		name    string
+		entry   historyEntry
+		wantErr bool
+	}{
+		{name: "operation", entry: newHistoryEntries()[1]},
+		{name: "auth", entry: historyEntry{Protocol: ProtocolJSON, Operation: "auth"}, wantErr: true},
+		{name: "unknown protocol", entry: historyEntry{Protocol: "xml", Operation: OperationEcho}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			m := newTestModel(t, &Settings{}, DefaultTCPRoundTripper)
+
+			// Act
+			err := m.loadHistoryEntry(tt.entry)
+
+			// Assert
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.entry.Protocol, m.protocols[m.protocolIdx])
+			assert.Equal(t, tt.entry.Compression, Compressions[m.compressionIdx[m.protocolIdx]])
+			assert.Equal(t, tt.entry.Operation, m.operations[m.operationIdx])
+			assert.Equal(t, tt.entry.Params, m.form().params())
+			assert.Equal(t, tt.entry.StudentID, m.enrollment.Value())
+			assert.NoError(t, m.validate())
+		})
+	}
+}
+
+func TestComparisonResultHistoryEntries(t *testing.T) {
+	// Arrange
+	msg := comparisonResultMsg{
+		operation:    OperationSum,
+		params:       "1,2",
+		studentID:    "538349",
+		compressions: map[string]string{ProtocolString: CompressionNone, ProtocolJSON: CompressionZstd},
+		at:           time.Now(),
+		columns: []comparisonColumn{
+			{Protocol: ProtocolString, Latency: time.Millisecond, RequestBytes: 2, ResponseBytes: 3, Request: []byte("1\n"), Response: []byte("OK\n"), Fields: []comparisonField{{Path: "soma", Value: "3"}, {Path: "media", Value: "1.5"}}},
+			{Protocol: ProtocolJSON, Err: errors.New("timeout")},
+		},
+	}
+
+	// Act
+	entries := msg.historyEntries()
+
+	// Assert
+	require.Len(t, entries, 2)
+	assert.Equal(t, historyEntry{
+		At: msg.at, Protocol: ProtocolString, Compression: CompressionNone, StudentID: "538349", Operation: OperationSum, Params: "1,2",
+		Latency: time.Millisecond, RequestBytes: 2, ResponseBytes: 3, RequestHex: "310a", ResponseHex: "4f4b0a", Success: true, Result: "soma: 3\nmedia: 1.5",
+	}, entries[0])
+	assert.False(t, entries[1].Success)
+	assert.Equal(t, CompressionZstd, entries[1].Compression)
+	assert.Equal(t, "timeout", entries[1].Error)
+}
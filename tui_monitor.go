@@ -0,0 +1,620 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	// monitorSeriesLength is how many polls the monitor charts keep
+	monitorSeriesLength = 60
+
+	// monitorAlertsLength is how many alerts the monitor keeps
+	monitorAlertsLength = 50
+
+	// defaultMonitorInterval is the poll interval when the settings leave it out
+	defaultMonitorInterval = 5 * time.Second
+)
+
+// monitorSeries is one charted value of the status responses
+type monitorSeries struct {
+	name      string
+	format    string  // of a value, like "%.1f%%"
+	threshold float64 // alerts fire above it, 0 is off
+	values    []float64
+	breached  bool
+}
+
+func (s *monitorSeries) push(value float64) {
+	s.values = append(s.values, value)
+	if len(s.values) > monitorSeriesLength {
+		s.values = s.values[1:]
+	}
+}
+
+func (s *monitorSeries) last() float64 {
+	if len(s.values) == 0 {
+		return 0
+	}
+
+	return s.values[len(s.values)-1]
+}
+
+// monitorAlert is a threshold crossed by a series, or a failing poll
+type monitorAlert struct {
+	at        time.Time
+	message   string
+	recovered bool
+}
+
+// monitorConfig is what a monitor run polls and when it alerts
+type monitorConfig struct {
+	protocol  string
+	studentID string
+	interval  time.Duration
+	cpu       float64
+	memory    float64
+	latency   float64
+	sessions  float64
+}
+
+// monitorRun polls the status of the server from START to STOP, shared by the copies of the model
+type monitorRun struct {
+	config  monitorConfig
+	started time.Time
+
+	// Set by the Update loop
+	session  *clientSession // logged in once and reused by the polls
+	polling  bool
+	stopped  bool
+	polls    int
+	failures int
+	failing  bool
+	lastErr  error
+	last     *StatusResponse
+	lastAt   time.Time
+	series   []*monitorSeries // operations, sessions, cpu, memory, latency and round trip
+	types    []*monitorSeries // per operation type, named after operacoes_por_tipo
+	alerts   []monitorAlert
+}
+
+func newMonitorRun(config monitorConfig) *monitorRun {
+	return &monitorRun{
+		config:  config,
+		started: time.Now(),
+		series: []*monitorSeries{
+			{name: "operations", format: "+%.0f"},
+			{name: "sessions", format: "%.0f", threshold: config.sessions},
+			{name: "cpu", format: "%.1f%%", threshold: config.cpu},
+			{name: "memory", format: "%.1f%%", threshold: config.memory},
+			{name: "latency", format: "%.0fms", threshold: config.latency},
+			{name: "round trip", format: "%.1fms"},
+		},
+	}
+}
+
+// operationCount is a counter of operacoes_por_tipo
+type operationCount struct {
+	name  string
+	count int
+}
+
+// operationsPerType lists the counters of operacoes_por_tipo by their wire names
+func operationsPerType(stats *StatusDatabaseStatistics) []operationCount {
+	if stats == nil {
+		return nil
+	}
+
+	ops := stats.OperationsPerType
+	return []operationCount{
+		{"autenticacao", ops.Authentication},
+		{"echo", ops.Echo},
+		{"historico", ops.History},
+		{"soma", ops.Sum},
+		{"status", ops.Status},
+		{"timestamp", ops.Timestamp},
+	}
+}
+
+// alert records an alert, the oldest ones are dropped
+func (r *monitorRun) alert(at time.Time, recovered bool, format string, args ...any) {
+	r.alerts = append(r.alerts, monitorAlert{at: at, message: fmt.Sprintf(format, args...), recovered: recovered})
+	if len(r.alerts) > monitorAlertsLength {
+		r.alerts = r.alerts[1:]
+	}
+}
+
+// add charts a status response, counters are charted as their increase since the previous poll
+func (r *monitorRun) add(resp *StatusResponse, rtt time.Duration, at time.Time) {
+	processed := 0.0
+	if r.last != nil {
+		processed = float64(max(resp.OperationsProcessed-r.last.OperationsProcessed, 0))
+	}
+
+	values := []float64{
+		processed,
+		float64(resp.ActiveSessions),
+		resp.Metrics.SimulatedCPU,
+		resp.Metrics.SimulatedMemory,
+		resp.Metrics.LatencySimulated,
+		float64(rtt.Microseconds()) / 1000,
+	}
+	for i, s := range r.series {
+		s.push(values[i])
+		if s.threshold <= 0 || (values[i] > s.threshold) == s.breached {
+			continue
+		}
+		s.breached = !s.breached
+		if s.breached {
+			r.alert(at, false, "%s is %s, above %s", s.name, fmt.Sprintf(s.format, values[i]), fmt.Sprintf(s.format, s.threshold))
+		} else {
+			r.alert(at, true, "%s is back to %s", s.name, fmt.Sprintf(s.format, values[i]))
+		}
+	}
+
+	previous := map[string]int{}
+	if r.last != nil {
+		for _, counter := range operationsPerType(r.last.DatabaseStatistics) {
+			previous[counter.name] = counter.count
+		}
+	}
+	for _, counter := range operationsPerType(resp.DatabaseStatistics) {
+		i := slices.IndexFunc(r.types, func(s *monitorSeries) bool { return s.name == counter.name })
+		if i < 0 {
+			r.types = append(r.types, &monitorSeries{name: counter.name, format: "+%.0f"})
+			i = len(r.types) - 1
+		}
+		increase := 0
+		if r.last != nil {
+			increase = max(counter.count-previous[counter.name], 0)
+		}
+		r.types[i].push(float64(increase))
+	}
+
+	r.last, r.lastAt = resp, at
+}
+
+// fail records a failed poll, only the first of a row of failures raises an alert
+func (r *monitorRun) fail(err error, at time.Time) {
+	r.failures++
+	r.lastErr = err
+	if !r.failing {
+		r.alert(at, false, "%s", err)
+	}
+	r.failing = true
+}
+
+// monitorPollMsg asks for the next poll of run
+type monitorPollMsg struct {
+	run *monitorRun
+}
+
+// monitorSampleMsg carries a status response, the session is nil when the poll had to drop it
+type monitorSampleMsg struct {
+	run     *monitorRun
+	session *clientSession
+	resp    *StatusResponse
+	rtt     time.Duration
+	at      time.Time
+	err     error
+}
+
+// monitorStoppedMsg carries the logout of a stopped run
+type monitorStoppedMsg struct {
+	run *monitorRun
+	err error
+}
+
+type monitorFocus int
+
+const (
+	monitorFocusProtocol monitorFocus = iota
+	monitorFocusInterval
+	monitorFocusCPU
+	monitorFocusMemory
+	monitorFocusLatency
+	monitorFocusSessions
+	monitorFocusActions
+	monitorFocusCount
+)
+
+const (
+	monitorActionStart = iota
+	monitorActionStop
+	monitorActionCount
+)
+
+// monitorFieldLabels label the inputs of the monitor form, in focus order from monitorFocusInterval
+var monitorFieldLabels = []string{"Interval:", "CPU alert above %:", "Memory alert above %:", "Latency alert above ms:", "Sessions alert above:"}
+
+// monitorPane is the server status monitor screen of the TUI
+type monitorPane struct {
+	protocolIdx  int
+	fields       []textinput.Model // interval, then the cpu, memory, latency and sessions thresholds
+	actionIdx    int
+	focusIndex   monitorFocus
+	settings     *Settings
+	roundTripper RoundTripper
+	run          *monitorRun
+}
+
+func newMonitorPane(settings *Settings, roundTripper RoundTripper) monitorPane {
+	monitor := settings.App.Monitor
+
+	interval := defaultMonitorInterval
+	if monitor.IntervalInSeconds > 0 {
+		interval = time.Duration(monitor.IntervalInSeconds) * time.Second
+	}
+
+	threshold := func(value float64) string {
+		if value <= 0 {
+			return ""
+		}
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	}
+
+	values := []string{
+		interval.String(),
+		threshold(monitor.CPUThreshold),
+		threshold(monitor.MemoryThreshold),
+		threshold(monitor.LatencyThreshold),
+		threshold(float64(monitor.SessionsThreshold)),
+	}
+	placeholders := []string{"5s", "off", "off", "off", "off"}
+
+	fields := make([]textinput.Model, len(values))
+	for i, value := range values {
+		fields[i] = textinput.New()
+		fields[i].Placeholder = placeholders[i]
+		fields[i].CharLimit = 10
+		fields[i].Width = 10
+		styleInput(&fields[i])
+		fields[i].SetValue(value)
+	}
+
+	return monitorPane{
+		protocolIdx:  max(slices.Index(Protocols, monitor.Protocol), 0),
+		fields:       fields,
+		settings:     settings,
+		roundTripper: roundTripper,
+	}
+}
+
+// running tells whether the monitor polls
+func (p monitorPane) running() bool {
+	return p.run != nil && !p.run.stopped
+}
+
+// input is the focused input, nil when the focus is not on one
+func (p *monitorPane) input() *textinput.Model {
+	if p.focusIndex < monitorFocusInterval || p.focusIndex > monitorFocusSessions {
+		return nil
+	}
+
+	return &p.fields[p.focusIndex-monitorFocusInterval]
+}
+
+func (p *monitorPane) updateFocus() {
+	for i := range p.fields {
+		p.fields[i].Blur()
+	}
+	if input := p.input(); input != nil {
+		input.Focus()
+	}
+}
+
+// formConfig reads the form, empty thresholds are off
+func (p monitorPane) formConfig(studentID string) (monitorConfig, error) {
+	config := monitorConfig{protocol: Protocols[p.protocolIdx], studentID: studentID}
+	if studentID == "" {
+		return config, fmt.Errorf("enter an enrollment ID on the client screen to log in")
+	}
+
+	interval, err := time.ParseDuration(p.fields[0].Value())
+	if err != nil {
+		return config, fmt.Errorf("interval must look like 5s or 1m")
+	}
+	if interval < time.Second {
+		return config, fmt.Errorf("interval must be at least 1s")
+	}
+	config.interval = interval
+
+	thresholds := []*float64{&config.cpu, &config.memory, &config.latency, &config.sessions}
+	for i, threshold := range thresholds {
+		value := strings.TrimSpace(p.fields[i+1].Value())
+		if value == "" {
+			continue
+		}
+		*threshold, err = strconv.ParseFloat(value, 64)
+		if err != nil || *threshold < 0 {
+			return config, fmt.Errorf("%s must be a positive number, or empty to turn the alert off", strings.TrimSuffix(monitorFieldLabels[i+1], ":"))
+		}
+	}
+
+	return config, nil
+}
+
+// start launches a run with the form, the returned command polls right away
+func (p *monitorPane) start(studentID string) (tea.Cmd, error) {
+	if p.running() {
+		return nil, fmt.Errorf("the monitor is already running, stop it first")
+	}
+
+	config, err := p.formConfig(studentID)
+	if err != nil {
+		return nil, err
+	}
+	p.run = newMonitorRun(config)
+
+	return p.poll(p.run), nil
+}
+
+// stop ends the run, the session is logged out now or after the poll in flight
+func (p *monitorPane) stop() (tea.Cmd, error) {
+	if !p.running() {
+		return nil, fmt.Errorf("the monitor is not running")
+	}
+
+	p.run.stopped = true
+	if p.run.polling || p.run.session == nil {
+		return nil, nil
+	}
+
+	session := p.run.session
+	p.run.session = nil
+
+	return p.logout(p.run, session), nil
+}
+
+// poll logs in when the run has no session and asks for the detailed status
+func (p *monitorPane) poll(run *monitorRun) tea.Cmd {
+	run.polling = true
+	settings, roundTripper, session := p.settings, p.roundTripper, run.session
+
+	return func() tea.Msg {
+		ctx := context.Background()
+		msg := monitorSampleMsg{run: run, session: session}
+
+		if msg.session == nil {
+			var err error
+			msg.session, _, err = openSession(ctx, settings, roundTripper, run.config.protocol, CompressionNone, run.config.studentID)
+			if err != nil {
+				msg.session, msg.at, msg.err = nil, time.Now(), fmt.Errorf("login failed: %w", err)
+				return msg
+			}
+		}
+
+		client := NewAppLayerClient[OperationRequest, OperationResponse](msg.session.serde, roundTripper, &settings.App)
+		resp := &StatusResponse{}
+		start := time.Now()
+		err := client.Do(ctx, msg.session.address, StatusRequest{Detailed: true}, resp, msg.session.token)
+		msg.rtt, msg.at = time.Since(start), time.Now()
+		if err != nil {
+			// The token may have expired, the next poll logs in again
+			_, _ = closeSession(ctx, settings, roundTripper, msg.session)
+			msg.session, msg.err = nil, fmt.Errorf("status failed: %w", err)
+			return msg
+		}
+		msg.resp = resp
+
+		return msg
+	}
+}
+
+// logout ends the session of a stopped run
+func (p monitorPane) logout(run *monitorRun, session *clientSession) tea.Cmd {
+	settings, roundTripper := p.settings, p.roundTripper
+
+	return func() tea.Msg {
+		_, err := closeSession(context.Background(), settings, roundTripper, session)
+		return monitorStoppedMsg{run: run, err: err}
+	}
+}
+
+// finish records a poll and schedules the next one, the sessions of stopped runs are logged out
+func (p *monitorPane) finish(msg monitorSampleMsg) tea.Cmd {
+	run := msg.run
+	run.polling = false
+	if run != p.run || run.stopped {
+		if msg.session != nil {
+			return p.logout(run, msg.session)
+		}
+		return nil
+	}
+
+	run.polls++
+	run.session = msg.session
+	if msg.err != nil {
+		run.fail(msg.err, msg.at)
+	} else {
+		if run.failing {
+			run.alert(msg.at, true, "the server answers again")
+		}
+		run.failing = false
+		run.add(msg.resp, msg.rtt, msg.at)
+	}
+
+	return tea.Tick(run.config.interval, func(time.Time) tea.Msg {
+		return monitorPollMsg{run: run}
+	})
+}
+
+// next polls again unless the run was stopped or replaced
+func (p *monitorPane) next(msg monitorPollMsg) tea.Cmd {
+	if msg.run != p.run || msg.run.stopped {
+		return nil
+	}
+
+	return p.poll(msg.run)
+}
+
+// stopped records the logout of a stopped run
+func (p *monitorPane) stopped(msg monitorStoppedMsg) {
+	if msg.err != nil {
+		msg.run.alert(time.Now(), false, "logout failed: %s", msg.err)
+	}
+}
+
+// update edits the monitor form, enter on START or STOP fails with the error to show in the popup
+func (p monitorPane) update(msg tea.KeyMsg, keys keyMap, studentID string) (monitorPane, tea.Cmd, error) {
+	if focus, ok := cycleFocus(msg, keys, p.focusIndex, monitorFocusCount); ok {
+		p.focusIndex = focus
+		p.updateFocus()
+		return p, nil, nil
+	}
+
+	switch {
+	case key.Matches(msg, keys.Left, keys.Right):
+		switch p.focusIndex {
+		case monitorFocusProtocol:
+			p.protocolIdx = stepCursor(msg, keys, p.protocolIdx, len(Protocols))
+		case monitorFocusActions:
+			p.actionIdx = stepCursor(msg, keys, p.actionIdx, monitorActionCount)
+		}
+
+	case key.Matches(msg, keys.Enter) && p.focusIndex == monitorFocusActions:
+		var cmd tea.Cmd
+		var err error
+		switch p.actionIdx {
+		case monitorActionStart:
+			cmd, err = p.start(studentID)
+		case monitorActionStop:
+			cmd, err = p.stop()
+		}
+		return p, cmd, err
+	}
+
+	var cmd tea.Cmd
+	if input := p.input(); input != nil {
+		*input, cmd = input.Update(msg)
+	}
+
+	return p, cmd, nil
+}
+
+// updateInputs passes the cursor blink to the interval or threshold input being edited
+func (p monitorPane) updateInputs(msg tea.Msg) (monitorPane, tea.Cmd) {
+	var cmd tea.Cmd
+	if input := p.input(); input != nil {
+		*input, cmd = input.Update(msg)
+	}
+
+	return p, cmd
+}
+
+// status sums up the polling, shown above the last server status
+func (p monitorPane) status() string {
+	run := p.run
+	switch {
+	case run == nil:
+		return "Idle, pick a protocol and press START."
+	case run.stopped && run.polling:
+		return "⏹ Stopping after the poll in flight..."
+	case run.stopped:
+		return fmt.Sprintf("⏹ Stopped after %d polls, %d failed", run.polls, run.failures)
+	case run.failing:
+		return fmt.Sprintf("❌ Polling %s every %s, failing: %s", run.config.protocol, run.config.interval, run.lastErr)
+	default:
+		return fmt.Sprintf("▶ Polling %s every %s, %d polls, %d failed", run.config.protocol, run.config.interval, run.polls, run.failures)
+	}
+}
+
+func (m model) renderMonitorLeftPanel(width int) string {
+	p := m.monitor
+	localTitleStyle := titleStyle.Width(width)
+	localFieldStyle := fieldStyle.Width(width)
+	localHintStyle := hintStyle.Width(width)
+
+	protocols := toggleRow(Protocols, func(i int) bool { return i == p.protocolIdx }, p.protocolIdx, p.focusIndex == monitorFocusProtocol)
+	lines := []string{
+		localTitleStyle.Render("╔═ Monitor ═╗"),
+		"",
+		focusLabel("Protocol:", p.focusIndex == monitorFocusProtocol, width),
+		localFieldStyle.Render("  " + protocols),
+		"",
+	}
+	for i, text := range monitorFieldLabels {
+		focus := monitorFocusInterval + monitorFocus(i)
+		row := lipgloss.JoinHorizontal(lipgloss.Top, lipgloss.NewStyle().Width(26).Render(focusLabel(text, p.focusIndex == focus, width)), p.fields[i].View())
+		lines = append(lines, localFieldStyle.Render(row))
+	}
+
+	actions := []string{"START", "STOP"}
+	lines = append(lines,
+		"",
+		localFieldStyle.Render("  "+actionRow(actions, p.actionIdx, p.focusIndex == monitorFocusActions)),
+		"",
+		localHintStyle.Render("  changes apply on START, the client enrollment logs in"),
+	)
+
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
+
+func (m model) renderMonitorRightPanel(width int) string {
+	p := m.monitor
+	lines := []string{
+		titleStyle.Render("╔═ Server Status ═╗"),
+		"",
+		inputStyle.Render(p.status()),
+	}
+
+	run := p.run
+	if run == nil {
+		return panelBorderStyle.Padding(1).Width(width - 2).Render(lipgloss.JoinVertical(lipgloss.Left, lines...))
+	}
+
+	if run.last != nil {
+		lines = append(lines, hintStyle.Render(fmt.Sprintf("%s, version %s, %d operations processed, polled at %s",
+			run.last.Status, run.last.Version, run.last.OperationsProcessed, run.lastAt.Format(time.TimeOnly))))
+	}
+
+	table := func(series []*monitorSeries) {
+		for _, s := range series {
+			low, high := 0.0, 0.0
+			if len(s.values) > 0 {
+				low, high = slices.Min(s.values), slices.Max(s.values)
+			}
+			limit := "-"
+			if s.threshold > 0 {
+				limit = fmt.Sprintf(s.format, s.threshold)
+			}
+			now := fmt.Sprintf("%7s", fmt.Sprintf(s.format, s.last()))
+			if s.breached {
+				now = errorTitleStyle.Render(now)
+			}
+			lines = append(lines, fmt.Sprintf("%-12s %s %7s %7s %7s %s",
+				s.name, now, fmt.Sprintf(s.format, low), fmt.Sprintf(s.format, high), limit, titleStyle.Render(sparkline(s.values, 10))))
+		}
+	}
+
+	header := fmt.Sprintf("%-12s %7s %7s %7s %7s %s", "metric", "now", "min", "max", "alert", "trend")
+	lines = append(lines, "", hintStyle.Render(header))
+	table(run.series)
+	if len(run.types) > 0 {
+		lines = append(lines, "", hintStyle.Render(fmt.Sprintf("%-12s %7s %7s %7s %7s %s", "per type", "now", "min", "max", "", "trend")))
+		table(run.types)
+	}
+
+	if len(run.alerts) > 0 {
+		lines = append(lines, "", hintStyle.Render("Alerts"))
+		for _, alert := range slices.Backward(run.alerts[max(len(run.alerts)-6, 0):]) {
+			text := alert.at.Format(time.TimeOnly) + " " + alert.message
+			if alert.recovered {
+				lines = append(lines, inputStyle.Render("✅ "+text))
+			} else {
+				lines = append(lines, errorTitleStyle.Render("⚠ "+text))
+			}
+		}
+	}
+
+	return panelBorderStyle.Padding(1).Width(width - 2).Render(lipgloss.JoinVertical(lipgloss.Left, lines...))
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMonitorPaneFormConfig(t *testing.T) {
+	tests := []struct {
+		name      string
+		values    []string
+		studentID string
+		expected  monitorConfig
+		wantErr   string
+	}{
+		{
+			name:      "valid",
+			values:    []string{"2s", "75", "", "250.5", "3"},
+			studentID: "538349",
+			expected:  monitorConfig{protocol: ProtocolJSON, studentID: "538349", interval: 2 * time.Second, cpu: 75, latency: 250.5, sessions: 3},
+		},
+		{
+			name:      "invalid interval",
+			values:    []string{"often", "", "", "", ""},
+			studentID: "538349",
+			wantErr:   "interval must look like 5s or 1m",
+		},
+		{
+			name:      "short interval",
+			values:    []string{"100ms", "", "", "", ""},
+			studentID: "538349",
+			wantErr:   "interval must be at least 1s",
+		},
+		{
+			name:      "negative threshold",
+			values:    []string{"5s", "", "-1", "", ""},
+			studentID: "538349",
+			wantErr:   "Memory alert above % must be a positive number",
+		},
+		{
+			name:    "no enrollment",
+			values:  []string{"5s", "", "", "", ""},
+			wantErr: "enter an enrollment ID",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			pane := newMonitorPane(&Settings{}, NewLoopbackRoundTripper())
+			for i, value := range tt.values {
+				pane.fields[i].SetValue(value)
+			}
+
+			// Act
+			config, err := pane.formConfig(tt.studentID)
+
+			// Assert
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, config)
+		})
+	}
+}
+
+func TestMonitorRunAdd(t *testing.T) {
+	// Arrange
+	run := newMonitorRun(monitorConfig{cpu: 80, sessions: 2})
+	status := func(processed, sessions int, cpu float64, echoes int) *StatusResponse {
+		return &StatusResponse{
+			OperationsProcessed: processed,
+			ActiveSessions:      sessions,
+			Metrics:             StatusResponseMetrics{SimulatedCPU: cpu},
+			DatabaseStatistics:  &StatusDatabaseStatistics{OperationsPerType: StatusDatabaseOperationType{Echo: echoes}},
+		}
+	}
+	now := time.Now()
+
+	// Act
+	run.add(status(10, 1, 50, 4), time.Millisecond, now)
+	run.add(status(15, 3, 90, 7), time.Millisecond, now)
+	run.add(status(15, 1, 85, 7), time.Millisecond, now)
+	run.add(status(21, 1, 40, 7), time.Millisecond, now)
+	run.fail(errors.New("status failed"), now)
+	run.fail(errors.New("status failed"), now)
+
+	// Assert
+	assert.Equal(t, []float64{0, 5, 0, 6}, run.series[0].values, "processed operations should be charted per poll")
+	assert.Equal(t, []float64{1, 3, 1, 1}, run.series[1].values)
+	assert.Equal(t, "echo", run.types[1].name)
+	assert.Equal(t, []float64{0, 3, 0, 0}, run.types[1].values)
+
+	messages := []string{}
+	for _, alert := range run.alerts {
+		messages = append(messages, alert.message)
+	}
+	assert.Equal(t, []string{
+		"sessions is 3, above 2",
+		"cpu is 90.0%, above 80.0%",
+		"sessions is back to 1",
+		"cpu is back to 40.0%",
+		"status failed",
+	}, messages, "an alert should fire once per crossing and once per row of failures")
+	assert.Equal(t, 2, run.failures)
+}
+
+func TestModelMonitor(t *testing.T) {
+	// Arrange
+	m, server := newSessionModel(t)
+	m.screen = screenMonitor
+	m.monitor.fields[0].SetValue("1s")
+	m.monitor.fields[2].SetValue("0.5")
+
+	// Act
+	poll, err := m.monitor.start(m.enrollment.Value())
+	require.NoError(t, err)
+	m = runCmd(t, m, poll)
+	m = runCmd(t, m, m.monitor.next(monitorPollMsg{run: m.monitor.run}))
+	run := m.monitor.run
+	stop, err := m.monitor.stop()
+	require.NoError(t, err)
+	m = runCmd(t, m, stop)
+
+	// Assert
+	assert.Equal(t, 2, run.polls)
+	assert.Zero(t, run.failures, run.lastErr)
+	assert.Equal(t, 1, server.issued, "the polls should reuse the login")
+	assert.Empty(t, server.sessions, "stopping should log out")
+	require.NotNil(t, run.last)
+	assert.Equal(t, "ATIVO", run.last.Status)
+	assert.Equal(t, []float64{1, 1}, run.series[1].values)
+	assert.Equal(t, []float64{0, 1}, run.types[4].values, "the first poll should count as a status operation")
+	assert.Nil(t, m.monitor.next(monitorPollMsg{run: run}), "a stopped run should not poll")
+	assert.Contains(t, m.monitor.status(), "Stopped after 2 polls")
+	assert.Contains(t, m.View(), "Server Status")
+}
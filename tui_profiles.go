@@ -0,0 +1,765 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/go-playground/validator/v10"
+	"github.com/spf13/viper"
+	"go.yaml.in/yaml/v3"
+)
+
+// profilesFile is the name of the server profiles file in the user config directory
+const profilesFile = "tui-profiles.yaml"
+
+// baseProfileName names the profile of base.yaml and the TUI_ environment variables
+const baseProfileName = "base"
+
+const (
+	// profileHealthTimeout bounds the dial of a health check, below the timeout of slow profiles
+	profileHealthTimeout = 3 * time.Second
+	// profileHealthInterval is how often the selected profile is checked again on the servers screen
+	profileHealthInterval = 30 * time.Second
+)
+
+// serverProfile is a named server to run the TUI against, the msgpack and cbor addresses are the
+// ones of base.yaml when empty and an empty student ID keeps the enrollment of the client form
+type serverProfile struct {
+	Name             string `mapstructure:"name" yaml:"name" validate:"required,ne=base"`
+	StringAddress    string `mapstructure:"string-address" yaml:"string-address" validate:"required,server_address"`
+	JSONAddress      string `mapstructure:"json-address" yaml:"json-address" validate:"required,server_address"`
+	ProtobufAddress  string `mapstructure:"protobuf-address" yaml:"protobuf-address" validate:"required,server_address"`
+	MsgpackAddress   string `mapstructure:"msgpack-address" yaml:"msgpack-address,omitempty" validate:"omitempty,server_address"`
+	CBORAddress      string `mapstructure:"cbor-address" yaml:"cbor-address,omitempty" validate:"omitempty,server_address"`
+	TimeoutInSeconds int    `mapstructure:"timeout-in-seconds" yaml:"timeout-in-seconds" validate:"gte=1"`
+	StudentID        string `mapstructure:"student-id" yaml:"student-id,omitempty" validate:"omitempty,numeric"`
+}
+
+// baseProfile is the profile of the loaded settings
+func baseProfile(settings *Settings) serverProfile {
+	return serverProfile{
+		Name:             baseProfileName,
+		StringAddress:    settings.App.StringProtocolServerAddress,
+		JSONAddress:      settings.App.JSONProtocolServerAddress,
+		ProtobufAddress:  settings.App.ProtobufProtocolServerAddress,
+		MsgpackAddress:   settings.App.MsgpackProtocolServerAddress,
+		CBORAddress:      settings.App.CBORProtocolServerAddress,
+		TimeoutInSeconds: settings.App.TCPTimeoutInSeconds,
+	}
+}
+
+// apply returns a copy of settings reaching the servers of the profile
+func (p serverProfile) apply(settings *Settings) *Settings {
+	applied := *settings
+	applied.App.StringProtocolServerAddress = p.StringAddress
+	applied.App.JSONProtocolServerAddress = p.JSONAddress
+	applied.App.ProtobufProtocolServerAddress = p.ProtobufAddress
+	if p.MsgpackAddress != "" {
+		applied.App.MsgpackProtocolServerAddress = p.MsgpackAddress
+	}
+	if p.CBORAddress != "" {
+		applied.App.CBORProtocolServerAddress = p.CBORAddress
+	}
+	applied.App.TCPTimeoutInSeconds = p.TimeoutInSeconds
+
+	return &applied
+}
+
+// profileFieldLabels name the fields of serverProfile in the editor and its errors
+var profileFieldLabels = map[string]string{
+	"Name":             "Name",
+	"StringAddress":    "String address",
+	"JSONAddress":      "JSON address",
+	"ProtobufAddress":  "Protobuf address",
+	"MsgpackAddress":   "MessagePack address",
+	"CBORAddress":      "CBOR address",
+	"TimeoutInSeconds": "Timeout",
+	"StudentID":        "Student ID",
+}
+
+// profileErrorMessage words a failed validate tag of a profile
+func profileErrorMessage(fe validator.FieldError) error {
+	label := profileFieldLabels[fe.StructField()]
+
+	switch fe.Tag() {
+	case "required":
+		return fmt.Errorf("%s is required", label)
+	case "ne":
+		return fmt.Errorf("%s %q is reserved for the profile of base.yaml", label, fe.Value())
+	case "server_address":
+		return fmt.Errorf("%s %q is not a host:port or unix:// address", label, fe.Value())
+	case "gte":
+		return fmt.Errorf("%s must be at least %s second", label, fe.Param())
+	case "numeric":
+		return fmt.Errorf("%s %q is not a number", label, fe.Value())
+	default:
+		return fmt.Errorf("%s fails the %s rule", label, fe.Tag())
+	}
+}
+
+// serverProfiles are the profiles saved at path, along with the one in use
+type serverProfiles struct {
+	path     string
+	base     serverProfile
+	validate *validator.Validate
+
+	Active   string          `mapstructure:"active" yaml:"active,omitempty"`
+	Profiles []serverProfile `mapstructure:"profiles" yaml:"profiles"`
+}
+
+// defaultProfilesPath is the profiles file in the user config directory, or in the working
+// directory when there is no user config directory
+func defaultProfilesPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return profilesFile
+	}
+
+	return filepath.Join(dir, "triprotocol-benchmark", profilesFile)
+}
+
+// loadServerProfiles reads the profiles saved at path, a missing file has no profiles but the base
+// one. Profiles are not saved when path is empty.
+func loadServerProfiles(path string, settings *Settings) (*serverProfiles, error) {
+	validate, err := newSettingsValidator()
+	if err != nil {
+		return nil, err
+	}
+
+	profiles := &serverProfiles{path: path, base: baseProfile(settings), validate: validate}
+	if path == "" {
+		return profiles, nil
+	}
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		return profiles, nil
+	}
+
+	v := viper.New()
+	v.SetConfigFile(path)
+	if err := v.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("failed to read server profiles %s: %w", path, err)
+	}
+	if err := v.Unmarshal(profiles); err != nil {
+		return nil, fmt.Errorf("invalid server profiles in %s: %w", path, err)
+	}
+
+	for i, profile := range profiles.Profiles {
+		if err := profiles.check(profile); err != nil {
+			return nil, fmt.Errorf("invalid server profile %d in %s: %w", i+1, path, err)
+		}
+		if slices.IndexFunc(profiles.Profiles[:i], func(p serverProfile) bool { return p.Name == profile.Name }) >= 0 {
+			return nil, fmt.Errorf("invalid server profile %d in %s: %s is defined twice", i+1, path, profile.Name)
+		}
+	}
+	if _, ok := profiles.find(profiles.Active); !ok {
+		return nil, fmt.Errorf("invalid server profiles in %s: active profile %s is not defined", path, profiles.Active)
+	}
+
+	return profiles, nil
+}
+
+// check validates profile, with the error of its first failed field
+func (s *serverProfiles) check(profile serverProfile) error {
+	var validationErrs validator.ValidationErrors
+	if err := s.validate.Struct(profile); errors.As(err, &validationErrs) {
+		return profileErrorMessage(validationErrs[0])
+	} else if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// all returns the base profile followed by the saved ones
+func (s *serverProfiles) all() []serverProfile {
+	return append([]serverProfile{s.base}, s.Profiles...)
+}
+
+// find returns the profile named name, the base profile for an empty name
+func (s *serverProfiles) find(name string) (serverProfile, bool) {
+	if name == "" || name == baseProfileName {
+		return s.base, true
+	}
+
+	i := slices.IndexFunc(s.Profiles, func(p serverProfile) bool { return p.Name == name })
+	if i < 0 {
+		return serverProfile{}, false
+	}
+
+	return s.Profiles[i], true
+}
+
+// active returns the profile in use
+func (s *serverProfiles) active() serverProfile {
+	profile, _ := s.find(s.Active)
+	return profile
+}
+
+// save adds profile, or replaces the profile of the same name, and writes the profiles file
+func (s *serverProfiles) save(profile serverProfile) error {
+	if err := s.check(profile); err != nil {
+		return err
+	}
+
+	i := slices.IndexFunc(s.Profiles, func(p serverProfile) bool { return p.Name == profile.Name })
+	if i < 0 {
+		s.Profiles = append(s.Profiles, profile)
+	} else {
+		s.Profiles[i] = profile
+	}
+
+	return s.write()
+}
+
+// remove deletes the profile named name, the base profile is used again when it was active
+func (s *serverProfiles) remove(name string) error {
+	if name == baseProfileName {
+		return fmt.Errorf("the base profile comes from base.yaml and cannot be deleted")
+	}
+
+	i := slices.IndexFunc(s.Profiles, func(p serverProfile) bool { return p.Name == name })
+	if i < 0 {
+		return fmt.Errorf("unknown server profile %s", name)
+	}
+	s.Profiles = slices.Delete(s.Profiles, i, i+1)
+	if s.Active == name {
+		s.Active = ""
+	}
+
+	return s.write()
+}
+
+// use makes the profile named name the active one, also on the next start
+func (s *serverProfiles) use(name string) error {
+	if _, ok := s.find(name); !ok {
+		return fmt.Errorf("unknown server profile %s", name)
+	}
+
+	s.Active = name
+	if name == baseProfileName {
+		s.Active = ""
+	}
+
+	return s.write()
+}
+
+func (s *serverProfiles) write() error {
+	if s.path == "" {
+		return nil
+	}
+
+	data, err := yaml.Marshal(s)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return err
+	}
+
+	return os.WriteFile(s.path, data, 0o644)
+}
+
+// addressHealth is the outcome of dialing the server of a protocol
+type addressHealth struct {
+	protocol string
+	address  string
+	latency  time.Duration
+	err      error
+}
+
+// profileHealth is the last check of the addresses of a profile
+type profileHealth struct {
+	addresses []addressHealth
+	checking  bool
+	at        time.Time
+}
+
+type profileHealthMsg struct {
+	profile   string
+	addresses []addressHealth
+	at        time.Time
+}
+
+// checkProfileHealth dials the server of every protocol of settings concurrently
+func checkProfileHealth(name string, settings *Settings) tea.Cmd {
+	return func() tea.Msg {
+		timeout := min(time.Duration(settings.App.TCPTimeoutInSeconds)*time.Second, profileHealthTimeout)
+
+		addresses := make([]addressHealth, len(Protocols))
+		var wg sync.WaitGroup
+		for i, protocol := range Protocols {
+			address, _ := settings.App.ServerAddress(protocol)
+			addresses[i] = addressHealth{protocol: protocol, address: address}
+
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				start := time.Now()
+				conn, err := dial(address, timeout)
+				addresses[i].latency = time.Since(start)
+				addresses[i].err = err
+				if err == nil {
+					conn.Close()
+				}
+			}()
+		}
+		wg.Wait()
+
+		return profileHealthMsg{profile: name, addresses: addresses, at: time.Now()}
+	}
+}
+
+// healthIcon sums up a check, the first failure wins
+func healthIcon(health *profileHealth) string {
+	switch {
+	case health == nil:
+		return "·"
+	case health.checking && health.at.IsZero():
+		return "…"
+	}
+	for _, address := range health.addresses {
+		if address.err != nil {
+			return "❌"
+		}
+	}
+
+	return "✅"
+}
+
+type profileFocus int
+
+const (
+	profileFocusList profileFocus = iota
+	profileFocusEditor
+	profileFocusActions
+	profileFocusCount
+)
+
+const (
+	profileActionUse = iota
+	profileActionCheck
+	profileActionSave
+	profileActionNew
+	profileActionDelete
+	profileActionCount
+)
+
+// profileEditorFields are the fields of serverProfile edited on the servers screen, in order
+var profileEditorFields = []string{"Name", "StringAddress", "JSONAddress", "ProtobufAddress", "MsgpackAddress", "CBORAddress", "TimeoutInSeconds", "StudentID"}
+
+// profileUseMsg asks the model to run against profile
+type profileUseMsg struct {
+	profile serverProfile
+}
+
+// profilesPane is the servers screen of the TUI
+type profilesPane struct {
+	store      *serverProfiles
+	settings   *Settings // as loaded, profiles apply over them
+	cursor     int
+	fields     []textinput.Model
+	fieldIdx   int
+	actionIdx  int
+	focusIndex profileFocus
+	health     map[string]*profileHealth
+	status     string
+}
+
+func newProfilesPane(store *serverProfiles, settings *Settings) profilesPane {
+	fields := make([]textinput.Model, len(profileEditorFields))
+	for i := range fields {
+		fields[i] = textinput.New()
+		fields[i].CharLimit = 100
+		fields[i].Width = 18
+		styleInput(&fields[i])
+	}
+	fields[0].Placeholder = "staging"
+	fields[4].Placeholder = "base.yaml address"
+	fields[5].Placeholder = "base.yaml address"
+	fields[7].Placeholder = "client form enrollment"
+
+	p := profilesPane{
+		store:    store,
+		settings: settings,
+		fields:   fields,
+		health:   map[string]*profileHealth{},
+	}
+	p.cursor = max(slices.IndexFunc(store.all(), func(profile serverProfile) bool { return profile.Name == store.active().Name }), 0)
+	p.load(p.selected())
+
+	return p
+}
+
+// selected returns the profile under the cursor
+func (p profilesPane) selected() serverProfile {
+	profiles := p.store.all()
+	return profiles[min(p.cursor, len(profiles)-1)]
+}
+
+// load fills the editor with profile
+func (p *profilesPane) load(profile serverProfile) {
+	values := []string{
+		profile.Name,
+		profile.StringAddress,
+		profile.JSONAddress,
+		profile.ProtobufAddress,
+		profile.MsgpackAddress,
+		profile.CBORAddress,
+		strconv.Itoa(profile.TimeoutInSeconds),
+		profile.StudentID,
+	}
+	for i, value := range values {
+		p.fields[i].SetValue(value)
+	}
+}
+
+// edited returns the profile of the editor
+func (p profilesPane) edited() (serverProfile, error) {
+	value := func(i int) string {
+		return strings.TrimSpace(p.fields[i].Value())
+	}
+
+	timeout, err := strconv.Atoi(value(6))
+	if err != nil {
+		return serverProfile{}, fmt.Errorf("Timeout %q is not a whole number of seconds", value(6))
+	}
+
+	return serverProfile{
+		Name:             value(0),
+		StringAddress:    value(1),
+		JSONAddress:      value(2),
+		ProtobufAddress:  value(3),
+		MsgpackAddress:   value(4),
+		CBORAddress:      value(5),
+		TimeoutInSeconds: timeout,
+		StudentID:        value(7),
+	}, nil
+}
+
+// check dials the servers of profile, unless they are being dialed already
+func (p profilesPane) check(profile serverProfile) tea.Cmd {
+	health := p.health[profile.Name]
+	if health == nil {
+		health = &profileHealth{}
+		p.health[profile.Name] = health
+	}
+	if health.checking {
+		return nil
+	}
+	health.checking = true
+
+	return checkProfileHealth(profile.Name, profile.apply(p.settings))
+}
+
+// refresh checks the selected profile again once its last check is older than profileHealthInterval
+func (p profilesPane) refresh(now time.Time) tea.Cmd {
+	profile := p.selected()
+	if health := p.health[profile.Name]; health != nil && now.Sub(health.at) < profileHealthInterval {
+		return nil
+	}
+
+	return p.check(profile)
+}
+
+// finish keeps the outcome of a health check
+func (p profilesPane) finish(msg profileHealthMsg) {
+	p.health[msg.profile] = &profileHealth{addresses: msg.addresses, at: msg.at}
+}
+
+func (p *profilesPane) updateFocus() {
+	for i := range p.fields {
+		p.fields[i].Blur()
+	}
+	if p.focusIndex == profileFocusEditor {
+		p.fields[p.fieldIdx].Focus()
+	}
+}
+
+// update handles the keys of the servers screen, errors are shown in the error popup
+func (p profilesPane) update(msg tea.KeyMsg, keys keyMap) (profilesPane, tea.Cmd, error) {
+	switch {
+	case key.Matches(msg, keys.Tab):
+		if p.focusIndex == profileFocusEditor && p.fieldIdx < len(p.fields)-1 {
+			p.fieldIdx++
+		} else {
+			p.focusIndex, _ = cycleFocus(msg, keys, p.focusIndex, profileFocusCount)
+			p.fieldIdx = 0
+		}
+		p.updateFocus()
+		return p, nil, nil
+
+	case key.Matches(msg, keys.ShiftTab):
+		if p.focusIndex == profileFocusEditor && p.fieldIdx > 0 {
+			p.fieldIdx--
+		} else {
+			p.focusIndex, _ = cycleFocus(msg, keys, p.focusIndex, profileFocusCount)
+			p.fieldIdx = len(p.fields) - 1
+		}
+		p.updateFocus()
+		return p, nil, nil
+	}
+
+	switch p.focusIndex {
+	case profileFocusList:
+		cursor := p.cursor
+		switch {
+		case key.Matches(msg, keys.Up):
+			p.cursor = max(p.cursor-1, 0)
+		case key.Matches(msg, keys.Down):
+			p.cursor = min(p.cursor+1, len(p.store.all())-1)
+		case key.Matches(msg, keys.Enter):
+			p.actionIdx = profileActionUse
+			return p.act()
+		}
+		if p.cursor != cursor {
+			p.status = ""
+			p.load(p.selected())
+			return p, p.refresh(time.Now()), nil
+		}
+
+	case profileFocusActions:
+		switch {
+		case key.Matches(msg, keys.Left, keys.Right):
+			p.actionIdx = stepCursor(msg, keys, p.actionIdx, profileActionCount)
+		case key.Matches(msg, keys.Enter):
+			return p.act()
+		}
+
+	case profileFocusEditor:
+		var cmd tea.Cmd
+		p.fields[p.fieldIdx], cmd = p.fields[p.fieldIdx].Update(msg)
+		return p, cmd, nil
+	}
+
+	return p, nil, nil
+}
+
+// act applies the button under the action cursor to the selected or the edited profile
+func (p profilesPane) act() (profilesPane, tea.Cmd, error) {
+	selected := p.selected()
+
+	switch p.actionIdx {
+	case profileActionUse:
+		return p, func() tea.Msg { return profileUseMsg{profile: selected} }, nil
+
+	case profileActionCheck:
+		return p, p.check(selected), nil
+
+	case profileActionSave:
+		profile, err := p.edited()
+		if err != nil {
+			return p, nil, err
+		}
+		if err := p.store.save(profile); err != nil {
+			return p, nil, err
+		}
+		p.cursor = slices.IndexFunc(p.store.all(), func(saved serverProfile) bool { return saved.Name == profile.Name })
+		p.status = "Saved " + profile.Name
+		delete(p.health, profile.Name)
+		if p.store.active().Name == profile.Name {
+			// Run against the saved addresses right away
+			return p, func() tea.Msg { return profileUseMsg{profile: profile} }, nil
+		}
+		return p, p.check(profile), nil
+
+	case profileActionNew:
+		p.load(serverProfile{TimeoutInSeconds: p.store.base.TimeoutInSeconds})
+		p.focusIndex, p.fieldIdx = profileFocusEditor, 0
+		p.updateFocus()
+		p.status = "Fill in the new profile and SAVE it"
+
+	case profileActionDelete:
+		if err := p.store.remove(selected.Name); err != nil {
+			return p, nil, err
+		}
+		p.cursor = min(p.cursor, len(p.store.all())-1)
+		p.load(p.selected())
+		p.status = "Deleted " + selected.Name
+		if p.store.Active == "" {
+			// The deleted profile may have been in use
+			return p, func() tea.Msg { return profileUseMsg{profile: p.store.active()} }, nil
+		}
+	}
+
+	return p, nil, nil
+}
+
+// updateInputs forwards the messages that are not keys, like the cursor blink, to the editor
+func (p profilesPane) updateInputs(msg tea.Msg) (profilesPane, tea.Cmd) {
+	var cmd tea.Cmd
+	if p.focusIndex == profileFocusEditor {
+		p.fields[p.fieldIdx], cmd = p.fields[p.fieldIdx].Update(msg)
+	}
+
+	return p, cmd
+}
+
+// useProfile runs the client and the benchmark against profile
+func (m *model) useProfile(profile serverProfile) error {
+	if m.dashboard.running() {
+		return fmt.Errorf("stop the benchmark before switching servers")
+	}
+	if m.session != nil {
+		return fmt.Errorf("log out before switching servers")
+	}
+	if m.monitor.running() {
+		return fmt.Errorf("stop the monitor before switching servers")
+	}
+
+	settings := profile.apply(m.profiles.settings)
+	roundTripper, err := newSettingsRoundTripper(settings)
+	if err != nil {
+		return err
+	}
+	if err := m.profiles.store.use(profile.Name); err != nil {
+		return err
+	}
+
+	m.settings = settings
+	m.roundTripper = roundTripper
+	m.dashboard.settings = settings
+	m.dashboard.roundTripper = roundTripper
+	m.monitor.settings = settings
+	m.monitor.roundTripper = roundTripper
+	if profile.StudentID != "" {
+		m.enrollment.SetValue(profile.StudentID)
+	}
+
+	return nil
+}
+
+// serverStatus tells the profile in use and how its server of the selected protocol answered
+func (m model) serverStatus() string {
+	profile := m.profiles.store.active()
+	status := "server " + profile.Name
+
+	health := m.profiles.health[profile.Name]
+	if health == nil || health.at.IsZero() {
+		return status
+	}
+	for _, address := range health.addresses {
+		if address.protocol == m.protocols[m.protocolIdx] {
+			icon := "✅"
+			if address.err != nil {
+				icon = "❌"
+			}
+			status += fmt.Sprintf(" %s %s", icon, address.address)
+		}
+	}
+
+	return status
+}
+
+func (m model) renderProfilesLeftPanel(width int) string {
+	p := m.profiles
+	label := focusLabel("Profiles:", p.focusIndex == profileFocusList, width)
+
+	active := p.store.active().Name
+	rows := []string{}
+	for i, profile := range p.store.all() {
+		marker := " "
+		if profile.Name == active {
+			marker = "●"
+		}
+
+		row := fmt.Sprintf("%s %s %s", marker, healthIcon(p.health[profile.Name]), profile.Name)
+		row = lipgloss.NewStyle().MaxWidth(width - 4).Render(row)
+		if i == p.cursor {
+			style := inputStyle
+			if p.focusIndex == profileFocusList {
+				style = focusedFieldStyle
+			}
+			rows = append(rows, style.Render("▸ "+row))
+		} else {
+			rows = append(rows, inputStyle.Render("  "+row))
+		}
+	}
+
+	path := p.store.path
+	if path == "" {
+		path = "not saved"
+	}
+
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		titleStyle.Width(width).Render("╔═ Servers ═╗"),
+		"",
+		label,
+		lipgloss.JoinVertical(lipgloss.Left, rows...),
+		"",
+		hintStyle.Render("  ● in use, enter to use the selected profile"),
+		hintStyle.Render("  profiles file: "+path),
+	)
+}
+
+func (m model) renderProfilesRightPanel(width int) string {
+	p := m.profiles
+	lines := []string{titleStyle.Render("╔═ Profile ═╗"), ""}
+
+	health := p.health[p.selected().Name]
+	addressHealthOf := func(protocol string) string {
+		if health == nil || health.at.IsZero() {
+			if health != nil && health.checking {
+				return hintStyle.Render("checking...")
+			}
+			return ""
+		}
+		for _, address := range health.addresses {
+			if address.protocol != protocol {
+				continue
+			}
+			if address.err != nil {
+				return errorTitleStyle.Render("❌")
+			}
+			return inputStyle.Render(fmt.Sprintf("✅ %s", address.latency.Round(time.Millisecond)))
+		}
+		return ""
+	}
+	protocolOf := map[string]string{
+		"StringAddress":   ProtocolString,
+		"JSONAddress":     ProtocolJSON,
+		"ProtobufAddress": ProtocolProtobuf,
+		"MsgpackAddress":  ProtocolMsgpack,
+		"CBORAddress":     ProtocolCBOR,
+	}
+
+	for i, name := range profileEditorFields {
+		label := focusLabel(profileFieldLabels[name]+":", p.focusIndex == profileFocusEditor && i == p.fieldIdx, 24)
+
+		input := lipgloss.NewStyle().Width(21).Render(p.fields[i].View())
+		row := lipgloss.JoinHorizontal(lipgloss.Top, label, input)
+		if protocol, ok := protocolOf[name]; ok {
+			row = lipgloss.JoinHorizontal(lipgloss.Top, row, " ", addressHealthOf(protocol))
+		}
+		lines = append(lines, row)
+	}
+
+	actions := actionRow([]string{"USE", "CHECK", "SAVE", "NEW", "DELETE"}, p.actionIdx, p.focusIndex == profileFocusActions)
+	lines = append(lines, "", "  "+actions, "")
+
+	if health != nil && !health.at.IsZero() {
+		lines = append(lines, hintStyle.Render("checked at "+health.at.Format(time.TimeOnly)))
+		for _, address := range health.addresses {
+			if address.err != nil {
+				lines = append(lines, errorTitleStyle.Render(fmt.Sprintf("%s: %s", address.protocol, address.err)))
+			}
+		}
+	}
+	if p.status != "" {
+		lines = append(lines, hintStyle.Render(p.status))
+	}
+
+	return panelBorderStyle.Padding(1).Width(width - 2).Render(lipgloss.JoinVertical(lipgloss.Left, lines...))
+}
@@ -0,0 +1,246 @@
+package main
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newProfileSettings() *Settings {
+	settings := &Settings{}
+	settings.App.StringProtocolServerAddress = "3.88.99.255:8080"
+	settings.App.JSONProtocolServerAddress = "3.88.99.255:8081"
+	settings.App.ProtobufProtocolServerAddress = "3.88.99.255:8082"
+	settings.App.MsgpackProtocolServerAddress = "localhost:8083"
+	settings.App.CBORProtocolServerAddress = "localhost:8084"
+	settings.App.TCPTimeoutInSeconds = 5
+
+	return settings
+}
+
+func newStagingProfile() serverProfile {
+	return serverProfile{
+		Name:             "staging",
+		StringAddress:    "10.0.0.7:9080",
+		JSONAddress:      "10.0.0.7:9081",
+		ProtobufAddress:  "unix:///run/staging/protobuf.sock",
+		TimeoutInSeconds: 2,
+		StudentID:        "123456",
+	}
+}
+
+func TestServerProfileApply(t *testing.T) {
+	// Arrange
+	settings := newProfileSettings()
+
+	// Act
+	applied := newStagingProfile().apply(settings)
+
+	// Assert
+	assert.Equal(t, "10.0.0.7:9080", applied.App.StringProtocolServerAddress)
+	assert.Equal(t, "10.0.0.7:9081", applied.App.JSONProtocolServerAddress)
+	assert.Equal(t, "unix:///run/staging/protobuf.sock", applied.App.ProtobufProtocolServerAddress)
+	assert.Equal(t, "localhost:8083", applied.App.MsgpackProtocolServerAddress, "empty addresses should keep the base ones")
+	assert.Equal(t, 2, applied.App.TCPTimeoutInSeconds)
+	assert.Equal(t, "3.88.99.255:8080", settings.App.StringProtocolServerAddress, "the loaded settings should not change")
+	assert.Equal(t, *settings, *baseProfile(settings).apply(settings))
+}
+
+func TestLoadServerProfiles(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected []string
+		active   string
+		wantErr  string
+	}{
+		{
+			name:     "valid",
+			content:  "active: local\nprofiles:\n  - name: local\n    string-address: localhost:8080\n    json-address: localhost:8081\n    protobuf-address: localhost:8082\n    timeout-in-seconds: 1\n",
+			expected: []string{baseProfileName, "local"},
+			active:   "local",
+		},
+		{
+			name:     "empty",
+			content:  "",
+			expected: []string{baseProfileName},
+			active:   baseProfileName,
+		},
+		{
+			name:    "invalid address",
+			content: "profiles:\n  - name: local\n    string-address: localhost\n    json-address: localhost:8081\n    protobuf-address: localhost:8082\n    timeout-in-seconds: 1\n",
+			wantErr: `String address "localhost" is not a host:port or unix:// address`,
+		},
+		{
+			name:    "reserved name",
+			content: "profiles:\n  - name: base\n    string-address: localhost:8080\n    json-address: localhost:8081\n    protobuf-address: localhost:8082\n    timeout-in-seconds: 1\n",
+			wantErr: "reserved",
+		},
+		{
+			name:    "duplicate",
+			content: "profiles:\n  - name: a\n    string-address: localhost:8080\n    json-address: localhost:8081\n    protobuf-address: localhost:8082\n    timeout-in-seconds: 1\n  - name: a\n    string-address: localhost:8080\n    json-address: localhost:8081\n    protobuf-address: localhost:8082\n    timeout-in-seconds: 1\n",
+			wantErr: "a is defined twice",
+		},
+		{
+			name:    "unknown active",
+			content: "active: staging\n",
+			wantErr: "active profile staging is not defined",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			path := filepath.Join(t.TempDir(), profilesFile)
+			require.NoError(t, os.WriteFile(path, []byte(tt.content), 0o644))
+
+			// Act
+			profiles, err := loadServerProfiles(path, newProfileSettings())
+
+			// Assert
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			names := []string{}
+			for _, profile := range profiles.all() {
+				names = append(names, profile.Name)
+			}
+			assert.Equal(t, tt.expected, names)
+			assert.Equal(t, tt.active, profiles.active().Name)
+		})
+	}
+}
+
+func TestServerProfilesPersistence(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), "config", profilesFile)
+	settings := newProfileSettings()
+	staging := newStagingProfile()
+	local := serverProfile{Name: "local", StringAddress: "localhost:8080", JSONAddress: "localhost:8081", ProtobufAddress: "localhost:8082", TimeoutInSeconds: 1}
+
+	// Act
+	profiles, err := loadServerProfiles(path, settings)
+	require.NoError(t, err, "a missing file should only have the base profile")
+	require.NoError(t, profiles.save(staging))
+	require.NoError(t, profiles.save(local))
+	staging.TimeoutInSeconds = 10
+	require.NoError(t, profiles.save(staging))
+	require.NoError(t, profiles.use("staging"))
+	require.NoError(t, profiles.remove("local"))
+	reloaded, reloadErr := loadServerProfiles(path, settings)
+
+	// Assert
+	require.NoError(t, reloadErr)
+	assert.Equal(t, []serverProfile{staging}, reloaded.Profiles, "saving a known name should replace the profile")
+	assert.Equal(t, staging, reloaded.active())
+	assert.ErrorContains(t, profiles.save(serverProfile{Name: "broken", StringAddress: "localhost:1", JSONAddress: "localhost:2", ProtobufAddress: "localhost:3"}), "Timeout must be at least 1 second")
+	assert.Error(t, profiles.remove(baseProfileName))
+	assert.Error(t, profiles.use("missing"))
+
+	require.NoError(t, profiles.remove("staging"))
+	assert.Equal(t, baseProfileName, profiles.active().Name, "deleting the active profile should use the base one")
+}
+
+func TestCheckProfileHealth(t *testing.T) {
+	// Arrange
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	closedAddress := closed.Addr().String()
+	closed.Close()
+
+	settings := newProfileSettings()
+	settings.App.StringProtocolServerAddress = listener.Addr().String()
+	settings.App.JSONProtocolServerAddress = closedAddress
+	settings.App.TCPTimeoutInSeconds = 1
+
+	// Act
+	msg := checkProfileHealth("local", settings)()
+
+	// Assert
+	health, ok := msg.(profileHealthMsg)
+	require.True(t, ok)
+	assert.Equal(t, "local", health.profile)
+	require.Len(t, health.addresses, len(Protocols))
+	for _, address := range health.addresses {
+		switch address.protocol {
+		case ProtocolString:
+			assert.NoError(t, address.err)
+			assert.Equal(t, listener.Addr().String(), address.address)
+		case ProtocolJSON:
+			assert.Error(t, address.err)
+		}
+	}
+}
+
+func TestProfilesPaneUpdate(t *testing.T) {
+	// Arrange
+	settings := newProfileSettings()
+	profiles, err := loadServerProfiles(filepath.Join(t.TempDir(), profilesFile), settings)
+	require.NoError(t, err)
+	pane := newProfilesPane(profiles, settings)
+	press := func(msg tea.KeyMsg) {
+		var err error
+		pane, _, err = pane.update(msg, keys)
+		require.NoError(t, err)
+	}
+	typeText := func(text string) {
+		for _, r := range text {
+			press(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+		}
+	}
+
+	// Act
+	pane.focusIndex, pane.actionIdx = profileFocusActions, profileActionNew
+	press(tea.KeyMsg{Type: tea.KeyEnter})
+	typeText("local")
+	for _, address := range []string{"localhost:8080", "localhost:8081", "localhost:8082"} {
+		press(tea.KeyMsg{Type: tea.KeyTab})
+		typeText(address)
+	}
+	pane.focusIndex, pane.actionIdx = profileFocusActions, profileActionSave
+	press(tea.KeyMsg{Type: tea.KeyEnter})
+
+	// Assert
+	require.Len(t, profiles.Profiles, 1)
+	assert.Equal(t, serverProfile{Name: "local", StringAddress: "localhost:8080", JSONAddress: "localhost:8081", ProtobufAddress: "localhost:8082", TimeoutInSeconds: 5}, profiles.Profiles[0])
+	assert.Equal(t, "local", pane.selected().Name, "the saved profile should be selected")
+	assert.True(t, pane.health["local"].checking, "saving should check the profile")
+
+	pane.focusIndex = profileFocusList
+	press(tea.KeyMsg{Type: tea.KeyUp})
+	assert.Equal(t, baseProfileName, pane.selected().Name)
+	assert.Equal(t, "3.88.99.255:8080", pane.fields[1].Value(), "moving the cursor should load the profile")
+}
+
+func TestModelUseProfile(t *testing.T) {
+	// Arrange
+	settings := newProfileSettings()
+	m := newTestModel(t, settings, DefaultTCPRoundTripper)
+	staging := newStagingProfile()
+	require.NoError(t, m.profiles.store.save(staging))
+
+	// Act
+	updated, _ := m.Update(profileUseMsg{profile: staging})
+	m = updated.(model)
+
+	// Assert
+	assert.False(t, m.showError, m.errorMsg)
+	assert.Equal(t, "10.0.0.7:9080", m.settings.App.StringProtocolServerAddress)
+	assert.Same(t, m.settings, m.dashboard.settings, "the benchmark should run against the profile too")
+	assert.Equal(t, "123456", m.enrollment.Value())
+	assert.Equal(t, "staging", m.profiles.store.active().Name)
+	assert.Contains(t, m.serverStatus(), "server staging")
+	assert.Equal(t, "3.88.99.255:8080", settings.App.StringProtocolServerAddress)
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// clientSession is a login of the client screen, held across operations until LOGOUT
+type clientSession struct {
+	protocol    string
+	compression string
+	studentID   string
+	name        string
+	address     string
+	serde       Serde
+	compressed  *CompressedSerde
+	token       string
+	loggedInAt  time.Time
+	operations  int
+	failures    int
+}
+
+// shortToken is the start of the token, enough to tell sessions apart
+func (s *clientSession) shortToken() string {
+	if len(s.token) <= 8 {
+		return s.token
+	}
+
+	return s.token[:8] + "…"
+}
+
+// age is the time since the login, to the second
+func (s *clientSession) age(now time.Time) time.Duration {
+	return now.Sub(s.loggedInAt).Truncate(time.Second)
+}
+
+type loginResultMsg struct {
+	session *clientSession
+	result  string
+	latency time.Duration
+	err     error
+}
+
+type logoutResultMsg struct {
+	session *clientSession
+	result  string
+	latency time.Duration
+	err     error
+}
+
+// openSession logs studentID in over protocol, the session is returned along with the login
+// response even when the login fails
+func openSession(ctx context.Context, settings *Settings, roundTripper RoundTripper, protocol string, compression string, studentID string) (*clientSession, *AuthResponse, error) {
+	session := &clientSession{protocol: protocol, compression: compression, studentID: studentID}
+
+	var err error
+	session.serde, session.compressed, err = newClientSerde(protocol, compression)
+	if err != nil {
+		return session, nil, err
+	}
+	session.address, err = settings.App.ServerAddress(protocol)
+	if err != nil {
+		return session, nil, err
+	}
+
+	client := NewAppLayerClient[*AuthRequest, *AuthResponse](session.serde, roundTripper, &settings.App)
+	resp, err := client.Auth(ctx, session.address, &AuthRequest{StudentID: studentID, Timestamp: time.Now()})
+	if err != nil {
+		return session, nil, err
+	}
+	session.token, session.name, session.loggedInAt = resp.Token, resp.Name, time.Now()
+
+	return session, resp, nil
+}
+
+// closeSession logs the session out
+func closeSession(ctx context.Context, settings *Settings, roundTripper RoundTripper, session *clientSession) (*LogoutResponse, error) {
+	client := NewAppLayerClient[*LogoutRequest, *LogoutResponse](session.serde, roundTripper, &settings.App)
+
+	return client.Logout(ctx, session.address, &LogoutRequest{}, session.token)
+}
+
+// login authenticates with the protocol, compression and enrollment of the form, for a session
+func (m model) login() tea.Cmd {
+	protocol, compression, studentID := m.protocols[m.protocolIdx], Compressions[m.compressionIdx[m.protocolIdx]], m.enrollment.Value()
+
+	return func() tea.Msg {
+		start := time.Now()
+		session, resp, err := openSession(context.Background(), m.settings, m.roundTripper, protocol, compression, studentID)
+		msg := loginResultMsg{session: session, latency: time.Since(start)}
+		if err != nil {
+			msg.err = fmt.Errorf("login failed: %w", err)
+			return msg
+		}
+		msg.result = formatResponse("Login Response", resp)
+
+		return msg
+	}
+}
+
+// logout ends the session, which is dropped even when the server does not answer
+func (m model) logout() tea.Cmd {
+	session := m.session
+
+	return func() tea.Msg {
+		start := time.Now()
+		resp, err := closeSession(context.Background(), m.settings, m.roundTripper, session)
+		msg := logoutResultMsg{session: session, latency: time.Since(start)}
+		if err != nil {
+			msg.err = fmt.Errorf("logout failed, the session was dropped: %w", err)
+			return msg
+		}
+
+		msg.result = formatResponse("Logout Response", resp) + fmt.Sprintf("\n\nSession of %s: %d operations, %d failed", session.age(time.Now()), session.operations, session.failures) + compressionSummary(session.compressed, "this session")
+
+		return msg
+	}
+}
+
+// checkSession tells whether the form can run on the session, which is bound to its protocol
+func (m model) checkSession() error {
+	if m.session == nil {
+		return nil
+	}
+
+	protocol, compression := m.protocols[m.protocolIdx], Compressions[m.compressionIdx[m.protocolIdx]]
+	if protocol != m.session.protocol || compression != m.session.compression {
+		return fmt.Errorf("the session is on %s with %s compression, log out to use %s with %s compression", m.session.protocol, m.session.compression, protocol, compression)
+	}
+
+	return nil
+}
+
+// sessionHistoryEntry records a login or a logout of session
+func sessionHistoryEntry(operation string, session *clientSession, result string, latency time.Duration, err error) historyEntry {
+	entry := historyEntry{
+		At:          time.Now(),
+		Protocol:    session.protocol,
+		Compression: session.compression,
+		StudentID:   session.studentID,
+		Operation:   operation,
+		Params:      session.studentID,
+		Latency:     latency,
+		Success:     err == nil,
+		Result:      result,
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+
+	return entry
+}
+
+// renderSession describes the session under the client buttons
+func (m model) renderSession(width int) string {
+	localFieldStyle := fieldStyle.Width(width)
+
+	s := m.session
+	if s == nil {
+		return localFieldStyle.Render(hintStyle.Render("  No session, SUBMIT logs in and out around each operation"))
+	}
+
+	student := s.studentID
+	if s.name != "" {
+		student = s.name + " (" + s.studentID + ")"
+	}
+
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		localFieldStyle.Render(inputStyle.Render(fmt.Sprintf("  🔑 %s session of %s, token %s", s.protocol, student, s.shortToken()))),
+		localFieldStyle.Render(hintStyle.Render(fmt.Sprintf("  %s compression, %s old, %d operations, %d failed", s.compression, s.age(time.Now()), s.operations, s.failures))),
+	)
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"slices"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestModel returns the initial model with in-memory profiles and history
+func newTestModel(t *testing.T, settings *Settings, roundTripper RoundTripper) model {
+	t.Helper()
+
+	profiles, err := loadServerProfiles("", settings)
+	require.NoError(t, err)
+
+	return initialModel(settings, roundTripper, profiles, &operationHistory{})
+}
+
+// newSessionModel returns a model whose JSON server is the returned loopback server
+func newSessionModel(t *testing.T) (model, *LoopbackServer) {
+	t.Helper()
+
+	serde, err := NewSerde(ProtocolJSON)
+	require.NoError(t, err)
+	server := NewLoopbackServer(serde.(ServerSerde))
+	roundTripper := NewLoopbackRoundTripper()
+	roundTripper.Handle(LoopbackAddress(ProtocolJSON), server.Handle)
+
+	settings := &Settings{}
+	settings.App.JSONProtocolServerAddress = LoopbackAddress(ProtocolJSON)
+
+	m := newTestModel(t, settings, roundTripper)
+	m.protocolIdx = slices.Index(m.protocols, ProtocolJSON)
+
+	return m, server
+}
+
+// runCmd sends the message of cmd to the model
+func runCmd(t *testing.T, m model, cmd tea.Cmd) model {
+	t.Helper()
+
+	updated, _ := m.Update(cmd())
+	return updated.(model)
+}
+
+func TestModelSession(t *testing.T) {
+	// Arrange
+	m, server := newSessionModel(t)
+	m.operationIdx = slices.Index(m.operations, OperationEcho)
+	m.form().setParams("ola")
+
+	// Act
+	m = runCmd(t, m, m.login())
+	require.NotNil(t, m.session, m.errorMsg)
+	session := m.session
+	m = runCmd(t, m, m.executeOperation())
+	m = runCmd(t, m, m.executeOperation())
+
+	// Assert
+	assert.False(t, m.showError, m.errorMsg)
+	assert.Equal(t, 1, server.issued, "the operations should reuse the login")
+	assert.Len(t, server.sessions, 1)
+	assert.Equal(t, 2, session.operations)
+	assert.Equal(t, "ALUNO "+defaultEnrollmentID, session.name)
+	assert.Contains(t, m.result, "Echo Response")
+	require.NotNil(t, m.wire)
+	assert.Contains(t, string(m.wire.request.data), session.token)
+
+	m.protocolIdx = slices.Index(m.protocols, ProtocolString)
+	assert.ErrorContains(t, m.checkSession(), "the session is on json with none compression")
+	assert.ErrorContains(t, m.useProfile(m.profiles.store.base), "log out before switching servers")
+	m.protocolIdx = slices.Index(m.protocols, ProtocolJSON)
+
+	m = runCmd(t, m, m.logout())
+	assert.False(t, m.showError, m.errorMsg)
+	assert.Nil(t, m.session)
+	assert.Empty(t, server.sessions, "the server should end the session")
+	assert.Contains(t, m.result, "Session of")
+
+	operations := []string{}
+	for _, entry := range m.history.store.entries {
+		operations = append(operations, entry.Operation)
+	}
+	assert.Equal(t, []string{"login", "echo", "echo", "logout"}, operations)
+}
+
+func TestModelSessionExpired(t *testing.T) {
+	// Arrange
+	m, server := newSessionModel(t)
+	m.operationIdx = slices.Index(m.operations, OperationTimestamp)
+	m = runCmd(t, m, m.login())
+	require.NotNil(t, m.session, m.errorMsg)
+	clear(server.sessions)
+
+	// Act
+	m = runCmd(t, m, m.executeOperation())
+	session := m.session
+	m = runCmd(t, m, m.logout())
+
+	// Assert
+	assert.True(t, m.showError)
+	assert.Equal(t, 1, session.failures)
+	assert.Nil(t, m.session, "a failed logout should still drop the session")
+	assert.Contains(t, m.errorMsg, "logout failed, the session was dropped")
+}
+
+func TestModelOperationWithoutSession(t *testing.T) {
+	// Arrange
+	m, server := newSessionModel(t)
+	m.operationIdx = slices.Index(m.operations, OperationTimestamp)
+
+	// Act
+	m = runCmd(t, m, m.executeOperation())
+	m = runCmd(t, m, m.executeOperation())
+
+	// Assert
+	assert.False(t, m.showError, m.errorMsg)
+	assert.Equal(t, 2, server.issued, "every operation should log in")
+	assert.Empty(t, server.sessions, "every operation should log out")
+	assert.Nil(t, m.session)
+}
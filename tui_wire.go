@@ -0,0 +1,156 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/taldoflemis/triprotocol-benchmark/protogenerated"
+	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/proto"
+)
+
+// hexDumpWidth is the number of bytes per hex dump line, narrow enough for the right panel
+const hexDumpWidth = 8
+
+// wireMessage is one message of an exchange as it went over the wire, body is the message behind the
+// compression header when the exchange was compressed
+type wireMessage struct {
+	data  []byte
+	body  []byte
+	codec string
+	err   error
+}
+
+// wireCapture is the exchange of the last operation, without the auth and logout around it
+type wireCapture struct {
+	protocol string
+	request  wireMessage
+	response wireMessage
+}
+
+func newWireCapture(protocol string, compressed *CompressedSerde, recorder *wireRecordingRoundTripper) *wireCapture {
+	return &wireCapture{
+		protocol: protocol,
+		request:  newWireMessage(compressed, recorder.request),
+		response: newWireMessage(compressed, recorder.response),
+	}
+}
+
+func newWireMessage(compressed *CompressedSerde, data []byte) wireMessage {
+	data = bytes.Clone(data)
+	message := wireMessage{data: data, body: data}
+	if compressed == nil || len(data) == 0 {
+		return message
+	}
+
+	message.codec = compressed.Codec
+	message.body, message.err = compressed.decompress(data)
+
+	return message
+}
+
+// renderWire shows both messages of the capture the way their protocol reads best
+func renderWire(capture *wireCapture) string {
+	if capture == nil {
+		return "No exchange captured yet."
+	}
+
+	return strings.Join([]string{
+		renderWireMessage("▶ Request", capture.protocol, capture.request, &protogenerated.Requisicao{}),
+		renderWireMessage("◀ Response", capture.protocol, capture.response, &protogenerated.Resposta{}),
+	}, "\n\n")
+}
+
+// renderWireMessage renders the text protocols as text and the binary ones as a hex dump, protobuf
+// messages are decoded into msg and shown as a tree too
+func renderWireMessage(title string, protocol string, message wireMessage, msg proto.Message) string {
+	lines := []string{fmt.Sprintf("%s, %d bytes", title, len(message.data))}
+
+	if len(message.data) == 0 {
+		return lines[0] + "\nnothing on the wire"
+	}
+
+	if message.codec != "" && len(message.data) < compressionHeaderSize {
+		return strings.Join(append(lines,
+			fmt.Sprintf("truncated compression header, %d of %d bytes", len(message.data), compressionHeaderSize),
+			hexDump(message.data),
+		), "\n")
+	}
+
+	if message.codec != "" {
+		lines = append(lines,
+			fmt.Sprintf("compression header, 5 bytes: flag %d (%s), body of %d bytes", message.data[0], message.codec, binary.BigEndian.Uint32(message.data[1:compressionHeaderSize])),
+			hexDump(message.data),
+		)
+		if message.err != nil {
+			return strings.Join(append(lines, "cannot decompress: "+message.err.Error()), "\n")
+		}
+		lines = append(lines, fmt.Sprintf("decompressed, %d bytes:", len(message.body)))
+	}
+
+	body := message.body
+	switch protocol {
+	case ProtocolString:
+		text, terminated := strings.CutSuffix(string(body), "\n")
+		lines = append(lines, text)
+		if terminated {
+			lines = append(lines, `terminated by "\n", 1 byte`)
+		}
+
+	case ProtocolJSON:
+		var pretty bytes.Buffer
+		if err := json.Indent(&pretty, bytes.TrimSpace(body), "", "  "); err != nil {
+			lines = append(lines, string(body), "invalid JSON: "+err.Error())
+			break
+		}
+		lines = append(lines, pretty.String())
+
+	default:
+		payload, err := unframeMessage(body)
+		if err != nil {
+			lines = append(lines, hexDump(body), err.Error())
+			break
+		}
+
+		lines = append(lines,
+			fmt.Sprintf("length prefix % x = %d bytes", body[:4], len(payload)),
+			hexDump(payload),
+		)
+		if trailing := len(body) - 4 - len(payload); trailing > 0 {
+			lines = append(lines, fmt.Sprintf("%d trailing bytes", trailing))
+		}
+
+		if protocol == ProtocolProtobuf {
+			if err := proto.Unmarshal(payload, msg); err != nil {
+				lines = append(lines, "cannot decode protobuf: "+err.Error())
+				break
+			}
+			lines = append(lines, "decoded "+string(msg.ProtoReflect().Descriptor().Name())+":", prototext.MarshalOptions{Multiline: true, Indent: "  "}.Format(msg))
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// hexDump lists data hexDumpWidth bytes per line, with the offset and the printable characters
+func hexDump(data []byte) string {
+	var b strings.Builder
+	for offset := 0; offset < len(data); offset += hexDumpWidth {
+		line := data[offset:min(offset+hexDumpWidth, len(data))]
+
+		printable := make([]byte, len(line))
+		for i, c := range line {
+			printable[i] = '.'
+			if c >= 0x20 && c < 0x7f {
+				printable[i] = c
+			}
+		}
+
+		fmt.Fprintf(&b, "%04x  %-*s %s\n", offset, hexDumpWidth*3, fmt.Sprintf("% x", line), printable)
+	}
+
+	return strings.TrimSuffix(b.String(), "\n")
+}
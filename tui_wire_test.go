@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHexDump(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     []byte
+		expected string
+	}{
+		{name: "empty", data: nil, expected: ""},
+		{name: "short line", data: []byte("ab\x00"), expected: "0000  61 62 00" + strings.Repeat(" ", 17) + "ab."},
+		{
+			name:     "two lines",
+			data:     []byte("soma|1|2|FIM"),
+			expected: "0000  73 6f 6d 61 7c 31 7c 32  soma|1|2\n0008  7c 46 49 4d              |FIM",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			actual := hexDump(tt.data)
+
+			// Assert
+			assert.Equal(t, tt.expected, actual)
+		})
+	}
+}
+
+func TestWireCapture(t *testing.T) {
+	tests := []struct {
+		protocol    string
+		compression string
+		contains    []string
+	}{
+		{protocol: ProtocolString, contains: []string{"mensagem=ola wire", `terminated by "\n", 1 byte`}},
+		{protocol: ProtocolJSON, contains: []string{"{\n  \"", `"mensagem": "ola wire"`}},
+		{protocol: ProtocolProtobuf, contains: []string{"length prefix 00 00 00", "decoded Requisicao:", "decoded Resposta:", "ola wire"}},
+		{protocol: ProtocolCBOR, contains: []string{"length prefix 00 00 00", "0000  "}},
+		{protocol: ProtocolJSON, compression: CompressionGzip, contains: []string{"compression header, 5 bytes: flag 1 (gzip)", "decompressed, ", `"mensagem": "ola wire"`}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.protocol+"/"+tt.compression, func(t *testing.T) {
+			// Arrange
+			serde, err := NewSerde(tt.protocol)
+			require.NoError(t, err)
+			serverSerde, err := NewSerde(tt.protocol)
+			require.NoError(t, err)
+
+			var compressed *CompressedSerde
+			if tt.compression != "" {
+				compressed, err = NewCompressedSerde(serde, tt.compression)
+				require.NoError(t, err)
+				serde = compressed
+				serverSerde, err = NewCompressedSerde(serverSerde, tt.compression)
+				require.NoError(t, err)
+			}
+
+			address := LoopbackAddress(tt.protocol)
+			roundTripper := NewLoopbackRoundTripper()
+			roundTripper.Handle(address, NewLoopbackServer(serverSerde.(ServerSerde)).Handle)
+			recorder := &wireRecordingRoundTripper{inner: roundTripper}
+			client := NewAppLayerClient[OperationRequest, OperationResponse](serde, recorder, &AppSettings{})
+
+			ctx := context.Background()
+			authResp, err := client.Auth(ctx, address, &AuthRequest{StudentID: "538349", Timestamp: time.Now()})
+			require.NoError(t, err)
+			recorder.reset()
+			require.NoError(t, client.Do(ctx, address, EchoRequest{Message: "ola wire"}, &EchoResponse{}, authResp.Token))
+
+			// Act
+			capture := newWireCapture(tt.protocol, compressed, recorder)
+			rendered := renderWire(capture)
+
+			// Assert
+			assert.Equal(t, recorder.request, capture.request.data)
+			assert.Equal(t, recorder.response, capture.response.data)
+			assert.Contains(t, rendered, "▶ Request, ")
+			assert.Contains(t, rendered, "◀ Response, ")
+			for _, s := range tt.contains {
+				assert.Contains(t, rendered, s)
+			}
+		})
+	}
+}
+
+func TestRenderWireMessageTruncatedCompressionHeader(t *testing.T) {
+	// Arrange
+	// The spare capacity holds what a header read past the message would find
+	data := append(make([]byte, 0, 8), 1, 2)
+	message := wireMessage{data: data, body: data, codec: CompressionGzip, err: errors.New("data too small")}
+
+	// Act
+	rendered := renderWireMessage("◀ Response", ProtocolJSON, message, nil)
+	exact := renderWireMessage("◀ Response", ProtocolJSON, wireMessage{data: []byte{1, 2}, codec: CompressionGzip}, nil)
+
+	// Assert
+	assert.Contains(t, rendered, "truncated compression header, 2 of 5 bytes")
+	assert.Contains(t, rendered, "0000  01 02")
+	assert.NotContains(t, rendered, "body of")
+	assert.Equal(t, rendered, exact, "a message without spare capacity should render the same")
+}
+
+func TestRenderWireMessageMalformed(t *testing.T) {
+	// Act
+	rendered := renderWireMessage("◀ Response", ProtocolProtobuf, wireMessage{data: []byte{0, 0}, body: []byte{0, 0}}, nil)
+
+	// Assert
+	assert.Contains(t, rendered, "◀ Response, 2 bytes")
+	assert.Contains(t, rendered, "0000  00 00")
+	assert.Contains(t, rendered, "data too small")
+}
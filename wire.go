@@ -0,0 +1,675 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// WireBreakdown splits an encoded message in the bytes spent on framing (length prefixes, terminators),
+// on the envelope (keys, separators and control fields such as tipo, operacao and token) and on the
+// payload, the values the operation actually carries
+type WireBreakdown struct {
+	Framing  int `json:"framing"`
+	Envelope int `json:"envelope"`
+	Payload  int `json:"payload"`
+}
+
+func (w WireBreakdown) Total() int {
+	return w.Framing + w.Envelope + w.Payload
+}
+
+// Overhead is the fraction of the message that is not payload
+func (w WireBreakdown) Overhead() float64 {
+	if w.Total() == 0 {
+		return 0
+	}
+
+	return float64(w.Framing+w.Envelope) / float64(w.Total())
+}
+
+func (w WireBreakdown) Add(other WireBreakdown) WireBreakdown {
+	return WireBreakdown{
+		Framing:  w.Framing + other.Framing,
+		Envelope: w.Envelope + other.Envelope,
+		Payload:  w.Payload + other.Payload,
+	}
+}
+
+// Times is the breakdown of n messages like w
+func (w WireBreakdown) Times(n int) WireBreakdown {
+	return WireBreakdown{
+		Framing:  w.Framing * n,
+		Envelope: w.Envelope * n,
+		Payload:  w.Payload * n,
+	}
+}
+
+// WireAccountant is implemented by serdes able to break down their own encoded messages
+type WireAccountant interface {
+	AccountRequest(data []byte) (WireBreakdown, error)
+	AccountResponse(data []byte) (WireBreakdown, error)
+}
+
+var (
+	_ WireAccountant = (*StringSerde)(nil)
+	_ WireAccountant = (*JSONSerde)(nil)
+	_ WireAccountant = (*ProtobufSerde)(nil)
+	_ WireAccountant = (*MsgpackSerde)(nil)
+	_ WireAccountant = (*CBORSerde)(nil)
+)
+
+// wireControlFields are the fields that drive the protocol instead of carrying operation data
+var wireControlFields = map[string]bool{
+	"tipo":     true,
+	"operacao": true,
+	"token":    true,
+	"sucesso":  true,
+	"comando":  true,
+}
+
+// AccountExchange breaks down a request and its response, ok is false when serde is not a WireAccountant
+// or one of the messages could not be accounted
+func AccountExchange(serde Serde, request, response []byte) (sent WireBreakdown, received WireBreakdown, ok bool) {
+	accountant, isAccountant := serde.(WireAccountant)
+	if !isAccountant {
+		return WireBreakdown{}, WireBreakdown{}, false
+	}
+
+	sent, err := accountant.AccountRequest(request)
+	if err != nil {
+		return WireBreakdown{}, WireBreakdown{}, false
+	}
+
+	received, err = accountant.AccountResponse(response)
+	if err != nil {
+		return WireBreakdown{}, WireBreakdown{}, false
+	}
+
+	return sent, received, true
+}
+
+// accountJSONDocument walks a JSON document assigning keys, delimiters and separators to the envelope.
+// Leaf values are payload unless they sit below one of the controlFields top level keys,
+// string quotes are always envelope.
+func accountJSONDocument(data []byte, controlFields map[string]bool) (WireBreakdown, error) {
+	type frame struct {
+		object    bool
+		expectKey bool
+		key       string
+	}
+
+	var breakdown WireBreakdown
+	stack := []*frame{}
+	offset := 0
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	for {
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return WireBreakdown{}, err
+		}
+
+		end := int(dec.InputOffset())
+		raw := data[offset:end]
+		offset = end
+
+		token := bytes.TrimLeft(raw, " \t\r\n,:")
+		breakdown.Envelope += len(raw) - len(token)
+
+		var parent *frame
+		if len(stack) > 0 {
+			parent = stack[len(stack)-1]
+		}
+
+		if delim, ok := tok.(json.Delim); ok {
+			breakdown.Envelope += len(token)
+
+			switch delim {
+			case '{', '[':
+				if parent != nil && parent.object {
+					parent.expectKey = true
+				}
+				stack = append(stack, &frame{object: delim == '{', expectKey: delim == '{'})
+			default:
+				stack = stack[:len(stack)-1]
+			}
+
+			continue
+		}
+
+		if parent != nil && parent.object && parent.expectKey {
+			parent.key, _ = tok.(string)
+			parent.expectKey = false
+			breakdown.Envelope += len(token)
+			continue
+		}
+
+		if parent != nil && parent.object {
+			parent.expectKey = true
+		}
+
+		if len(stack) > 0 && stack[0].object && controlFields[stack[0].key] {
+			breakdown.Envelope += len(token)
+			continue
+		}
+
+		if _, ok := tok.(string); ok {
+			breakdown.Envelope += 2
+			breakdown.Payload += len(token) - 2
+			continue
+		}
+
+		breakdown.Payload += len(token)
+	}
+
+	if len(stack) > 0 {
+		return WireBreakdown{}, io.ErrUnexpectedEOF
+	}
+
+	breakdown.Envelope += len(data) - offset
+
+	return breakdown, nil
+}
+
+// accountMsgpackMessage breaks down a length prefixed MessagePack document like accountJSONDocument does:
+// type codes, lengths and keys are envelope, values are payload unless they sit below one of the
+// controlFields top level keys
+func accountMsgpackMessage(data []byte, controlFields map[string]bool) (WireBreakdown, error) {
+	body, err := unframeMessage(data)
+	if err != nil {
+		return WireBreakdown{}, err
+	}
+
+	w := documentWalker{data: body, controlFields: controlFields}
+	if _, err := w.msgpackValue(false, true); err != nil {
+		return WireBreakdown{}, err
+	}
+
+	breakdown := w.breakdown
+	breakdown.Envelope += len(body) - w.pos
+	// The length prefix and anything trailing the message
+	breakdown.Framing = len(data) - len(body)
+
+	return breakdown, nil
+}
+
+// documentWalker walks the binary documents of the MessagePack and CBOR protocols
+type documentWalker struct {
+	data          []byte
+	pos           int
+	controlFields map[string]bool
+	breakdown     WireBreakdown
+}
+
+func (w *documentWalker) take(n int) ([]byte, error) {
+	if n < 0 || n > len(w.data)-w.pos {
+		return nil, io.ErrUnexpectedEOF
+	}
+
+	b := w.data[w.pos : w.pos+n]
+	w.pos += n
+
+	return b, nil
+}
+
+// length reads a big endian length of n bytes
+func (w *documentWalker) length(n int) (int, error) {
+	b, err := w.take(n)
+	if err != nil {
+		return 0, err
+	}
+
+	length := 0
+	for _, c := range b {
+		length = length<<8 | int(c)
+	}
+
+	return length, nil
+}
+
+// msgpackValue walks one MessagePack value and returns its bytes when it is a string or binary, top is set
+// for the document root
+func (w *documentWalker) msgpackValue(control, top bool) (string, error) {
+	start := w.pos
+
+	code, err := w.take(1)
+	if err != nil {
+		return "", err
+	}
+
+	// size is the payload of scalars, count the entries of containers
+	size, count, isMap := 0, -1, false
+
+	switch c := code[0]; {
+	case c <= 0x7f || c >= 0xe0 || c == 0xc0 || c == 0xc2 || c == 0xc3:
+		// Fixints, nil and booleans carry their value in the code
+		w.account(control, 0, 1)
+		return "", nil
+	case c <= 0x8f:
+		count, isMap = int(c&0x0f), true
+	case c <= 0x9f:
+		count = int(c & 0x0f)
+	case c <= 0xbf:
+		size = int(c & 0x1f)
+	case c == 0xc4 || c == 0xd9:
+		size, err = w.length(1)
+	case c == 0xc5 || c == 0xda:
+		size, err = w.length(2)
+	case c == 0xc6 || c == 0xdb:
+		size, err = w.length(4)
+	case c >= 0xc7 && c <= 0xc9:
+		size, err = w.length(1 << (c - 0xc7))
+		if err == nil {
+			// The extension type
+			_, err = w.take(1)
+		}
+	case c == 0xca:
+		size = 4
+	case c == 0xcb:
+		size = 8
+	case c >= 0xcc && c <= 0xcf:
+		size = 1 << (c - 0xcc)
+	case c >= 0xd0 && c <= 0xd3:
+		size = 1 << (c - 0xd0)
+	case c >= 0xd4 && c <= 0xd8:
+		size = 1 << (c - 0xd4)
+		_, err = w.take(1)
+	case c == 0xdc:
+		count, err = w.length(2)
+	case c == 0xdd:
+		count, err = w.length(4)
+	case c == 0xde:
+		isMap = true
+		count, err = w.length(2)
+	case c == 0xdf:
+		isMap = true
+		count, err = w.length(4)
+	default:
+		return "", fmt.Errorf("invalid MessagePack code 0x%x at offset %d", c, start)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	header := w.pos - start
+
+	if count < 0 {
+		value, err := w.take(size)
+		if err != nil {
+			return "", err
+		}
+
+		w.account(control, header, size)
+		return string(value), nil
+	}
+
+	w.breakdown.Envelope += header
+
+	for range count {
+		valueControl := control
+		if isMap {
+			key, err := w.msgpackValue(true, false)
+			if err != nil {
+				return "", err
+			}
+			valueControl = control || (top && w.controlFields[key])
+		}
+
+		if _, err := w.msgpackValue(valueControl, false); err != nil {
+			return "", err
+		}
+	}
+
+	return "", nil
+}
+
+func (w *documentWalker) account(control bool, envelope, payload int) {
+	if control {
+		w.breakdown.Envelope += envelope + payload
+		return
+	}
+
+	w.breakdown.Envelope += envelope
+	w.breakdown.Payload += payload
+}
+
+// accountCBORMessage breaks down a length prefixed CBOR document like accountMsgpackMessage does, tags
+// and the break codes of indefinite length items are envelope too
+func accountCBORMessage(data []byte, controlFields map[string]bool) (WireBreakdown, error) {
+	body, err := unframeMessage(data)
+	if err != nil {
+		return WireBreakdown{}, err
+	}
+
+	w := documentWalker{data: body, controlFields: controlFields}
+	if _, err := w.cborValue(false, true); err != nil {
+		return WireBreakdown{}, err
+	}
+
+	breakdown := w.breakdown
+	breakdown.Envelope += len(body) - w.pos
+	breakdown.Framing = len(data) - len(body)
+
+	return breakdown, nil
+}
+
+// cborBreak ends the indefinite length strings, arrays and maps of CBOR
+const cborBreak = 0xff
+
+// cborValue walks one CBOR data item and returns its bytes when it is a text or byte string, top is set
+// for the document root
+func (w *documentWalker) cborValue(control, top bool) (string, error) {
+	for {
+		start := w.pos
+
+		code, err := w.take(1)
+		if err != nil {
+			return "", err
+		}
+
+		major, info := code[0]>>5, code[0]&0x1f
+
+		if info == 31 && major >= 2 && major <= 5 {
+			w.breakdown.Envelope++
+			return w.cborItems(major, -1, control, top)
+		}
+		if info > 27 {
+			return "", fmt.Errorf("invalid CBOR initial byte 0x%x at offset %d", code[0], start)
+		}
+
+		// The argument follows the initial byte unless it is small enough to fit in it
+		size := 0
+		if info >= 24 {
+			size = 1 << (info - 24)
+		}
+
+		switch major {
+		case 0, 1, 7:
+			// Integers, simple values and floats
+			if _, err := w.take(size); err != nil {
+				return "", err
+			}
+
+			if size == 0 {
+				w.account(control, 0, 1)
+			} else {
+				w.account(control, 1, size)
+			}
+			return "", nil
+		case 6:
+			// Tags qualify the data item that follows
+			if _, err := w.take(size); err != nil {
+				return "", err
+			}
+
+			w.breakdown.Envelope += 1 + size
+			continue
+		}
+
+		count := int(info)
+		if size > 0 {
+			b, err := w.take(size)
+			if err != nil {
+				return "", err
+			}
+
+			var argument uint64
+			for _, c := range b {
+				argument = argument<<8 | uint64(c)
+			}
+			// Every string byte and container item takes at least one byte
+			if argument > uint64(len(w.data)-w.pos) {
+				return "", io.ErrUnexpectedEOF
+			}
+			count = int(argument)
+		}
+
+		w.breakdown.Envelope += w.pos - start
+
+		if major >= 4 {
+			return w.cborItems(major, count, control, top)
+		}
+
+		value, err := w.take(count)
+		if err != nil {
+			return "", err
+		}
+
+		w.account(control, 0, count)
+		return string(value), nil
+	}
+}
+
+// cborItems walks the chunks of a string, the items of an array or the entries of a map, up to the break
+// code when count is negative, and returns the string the chunks make up
+func (w *documentWalker) cborItems(major byte, count int, control, top bool) (string, error) {
+	var chunks strings.Builder
+
+	for i := 0; count < 0 || i < count; i++ {
+		if count < 0 {
+			if w.pos >= len(w.data) {
+				return "", io.ErrUnexpectedEOF
+			}
+			if w.data[w.pos] == cborBreak {
+				w.pos++
+				w.breakdown.Envelope++
+				break
+			}
+		}
+
+		valueControl := control
+		if major == 5 {
+			key, err := w.cborValue(true, false)
+			if err != nil {
+				return "", err
+			}
+			valueControl = control || (top && w.controlFields[key])
+		}
+
+		value, err := w.cborValue(valueControl, false)
+		if err != nil {
+			return "", err
+		}
+
+		if major < 4 {
+			chunks.WriteString(value)
+		}
+	}
+
+	return chunks.String(), nil
+}
+
+// accountFieldValue breaks down the value of a String or Protobuf field. Those values are either plain
+// text or Python literals such as dicts, lists and tuples, whose structure counts as envelope like in JSON.
+func accountFieldValue(value string) WireBreakdown {
+	candidate := value
+	wrapped := false
+
+	if !strings.HasPrefix(value, "{") && !strings.HasPrefix(value, "[") && !strings.HasPrefix(value, "(") {
+		if !strings.Contains(value, ",") {
+			return WireBreakdown{Payload: len(value)}
+		}
+
+		// Lists of numbers are sent without brackets
+		candidate = "[" + value + "]"
+		wrapped = true
+	}
+
+	// The conversion keeps the length, so offsets still match the original value
+	converted := convertPythonDictTOJSONDict(candidate)
+	if len(converted) != len(candidate) {
+		return WireBreakdown{Payload: len(value)}
+	}
+
+	breakdown, err := accountJSONDocument([]byte(converted), nil)
+	if err != nil {
+		return WireBreakdown{Payload: len(value)}
+	}
+
+	if wrapped {
+		breakdown.Envelope -= 2
+	}
+
+	return breakdown
+}
+
+// accountStringMessage breaks down a String protocol message, both requests and responses share the
+// COMMAND|key=value|...|FIM layout
+func accountStringMessage(data []byte) (WireBreakdown, error) {
+	var breakdown WireBreakdown
+
+	message := string(data)
+	trimmed := strings.TrimRight(message, "\r\n")
+	breakdown.Framing += len(message) - len(trimmed)
+
+	body, ok := strings.CutSuffix(trimmed, "|FIM")
+	if !ok {
+		return WireBreakdown{}, fmt.Errorf("missing FIM terminator")
+	}
+	breakdown.Framing += len("|FIM")
+
+	args := strings.Split(body, "|")
+	// The command or status and the separators between the arguments
+	breakdown.Envelope += len(args[0]) + len(args) - 1
+
+	for _, arg := range args[1:] {
+		key, value, ok := strings.Cut(arg, "=")
+		if !ok {
+			breakdown.Payload += len(arg)
+			continue
+		}
+
+		breakdown.Envelope += len(key) + 1
+
+		if wireControlFields[key] {
+			breakdown.Envelope += len(value)
+			continue
+		}
+
+		breakdown = breakdown.Add(accountFieldValue(value))
+	}
+
+	return breakdown, nil
+}
+
+// accountProtoPayload sums the payload bytes of a protobuf message, tags, lengths, map keys and
+// control fields are left to the envelope
+func accountProtoPayload(msg protoreflect.Message) WireBreakdown {
+	var breakdown WireBreakdown
+
+	msg.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		switch {
+		case fd.IsMap():
+			v.Map().Range(func(_ protoreflect.MapKey, value protoreflect.Value) bool {
+				breakdown = breakdown.Add(accountProtoValue(fd.MapValue(), value))
+				return true
+			})
+		case fd.IsList():
+			list := v.List()
+			for i := range list.Len() {
+				breakdown = breakdown.Add(accountProtoValue(fd, list.Get(i)))
+			}
+		default:
+			breakdown = breakdown.Add(accountProtoValue(fd, v))
+		}
+		return true
+	})
+
+	return breakdown
+}
+
+// accountProtoValue sums the payload bytes of one value of fd, an element when fd is repeated
+func accountProtoValue(fd protoreflect.FieldDescriptor, v protoreflect.Value) WireBreakdown {
+	switch {
+	case fd.Kind() == protoreflect.MessageKind, fd.Kind() == protoreflect.GroupKind:
+		return accountProtoPayload(v.Message())
+	case wireControlFields[string(fd.Name())]:
+		return WireBreakdown{}
+	}
+
+	switch fd.Kind() {
+	case protoreflect.StringKind:
+		return accountFieldValue(v.String())
+	case protoreflect.BytesKind:
+		return WireBreakdown{Payload: len(v.Bytes())}
+	case protoreflect.BoolKind:
+		return WireBreakdown{Payload: 1}
+	case protoreflect.DoubleKind, protoreflect.Fixed64Kind, protoreflect.Sfixed64Kind:
+		return WireBreakdown{Payload: 8}
+	case protoreflect.FloatKind, protoreflect.Fixed32Kind, protoreflect.Sfixed32Kind:
+		return WireBreakdown{Payload: 4}
+	case protoreflect.EnumKind:
+		return WireBreakdown{Payload: protowire.SizeVarint(uint64(v.Enum()))}
+	case protoreflect.Uint32Kind, protoreflect.Uint64Kind:
+		return WireBreakdown{Payload: protowire.SizeVarint(v.Uint())}
+	case protoreflect.Sint32Kind, protoreflect.Sint64Kind:
+		return WireBreakdown{Payload: protowire.SizeVarint(protowire.EncodeZigZag(v.Int()))}
+	default:
+		return WireBreakdown{Payload: protowire.SizeVarint(uint64(v.Int()))}
+	}
+}
+
+// WireOperationSummary is the average breakdown of the messages of one operation
+type WireOperationSummary struct {
+	Protocol  string        `json:"protocol"`
+	Operation string        `json:"operation"`
+	Messages  int           `json:"messages"`
+	Sent      WireBreakdown `json:"sent"`
+	Received  WireBreakdown `json:"received"`
+}
+
+func (s WireOperationSummary) average(total WireBreakdown) WireBreakdown {
+	if s.Messages == 0 {
+		return WireBreakdown{}
+	}
+
+	return WireBreakdown{
+		Framing:  total.Framing / s.Messages,
+		Envelope: total.Envelope / s.Messages,
+		Payload:  total.Payload / s.Messages,
+	}
+}
+
+// WriteWireText writes the average envelope versus payload breakdown of every summary, one row per direction
+func WriteWireText(w io.Writer, summaries []WireOperationSummary) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "protocol\toperation\tdirection\tmessages\tbytes\tframing\tenvelope\tpayload\toverhead\t")
+
+	for _, s := range summaries {
+		for _, direction := range []struct {
+			name  string
+			total WireBreakdown
+		}{
+			{name: "request", total: s.Sent},
+			{name: "response", total: s.Received},
+		} {
+			avg := s.average(direction.total)
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%d\t%d\t%d\t%d\t%.1f%%\t\n",
+				s.Protocol,
+				s.Operation,
+				direction.name,
+				s.Messages,
+				avg.Total(),
+				avg.Framing,
+				avg.Envelope,
+				avg.Payload,
+				direction.total.Overhead()*100,
+			)
+		}
+	}
+
+	return tw.Flush()
+}
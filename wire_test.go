@@ -0,0 +1,212 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/taldoflemis/triprotocol-benchmark/protogenerated"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestAccountRequest(t *testing.T) {
+	req := PresentationLayerRequest{
+		Token: "abc",
+		Body:  SumRequest{Numbers: []int{1, 22, 333}},
+	}
+
+	tests := []struct {
+		protocol string
+		expected WireBreakdown
+	}{
+		{
+			// {"tipo":"operacao","operacao":"soma","token":"abc","parametros":{"numeros":[1,22,333]}}
+			protocol: ProtocolJSON,
+			expected: WireBreakdown{Framing: 0, Envelope: 81, Payload: 6},
+		},
+		{
+			// OP|token=abc|operacao=soma|nums=1,22,333|FIM\n
+			protocol: ProtocolString,
+			expected: WireBreakdown{Framing: 5, Envelope: 34, Payload: 6},
+		},
+		{
+			protocol: ProtocolProtobuf,
+			expected: WireBreakdown{Framing: 4, Envelope: 25, Payload: 6},
+		},
+		{
+			// The JSON document above, 333 is a uint16 whose type code is envelope
+			protocol: ProtocolMsgpack,
+			expected: WireBreakdown{Framing: 4, Envelope: 61, Payload: 4},
+		},
+		{
+			// Like MessagePack, 22 fits in its initial byte and 333 takes a uint16 argument
+			protocol: ProtocolCBOR,
+			expected: WireBreakdown{Framing: 4, Envelope: 61, Payload: 4},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.protocol, func(t *testing.T) {
+			serde, err := NewSerde(tt.protocol)
+			require.NoError(t, err)
+
+			data, err := serde.Marshal(req)
+			require.NoError(t, err)
+
+			breakdown, err := serde.(WireAccountant).AccountRequest(data)
+			require.NoError(t, err, "AccountRequest should not return an error")
+			assert.Equal(t, tt.expected, breakdown)
+			assert.Equal(t, len(data), breakdown.Total(), "breakdown should cover every byte")
+		})
+	}
+}
+
+func TestAccountProtoPayload(t *testing.T) {
+	tests := []struct {
+		name     string
+		msg      proto.Message
+		expected WireBreakdown
+	}{
+		{
+			// aluno_id, the map values and timestamp of the operation and total, operacao and sucesso are control fields
+			name: "history",
+			msg: &protogenerated.HistoricoAluno{
+				AlunoId: "538349",
+				Operacoes: []*protogenerated.HistoricoOperacao{
+					{
+						Operacao:   OperationEcho,
+						Parametros: map[string]string{"mensagem": "ola"},
+						Resultado:  map[string]string{"mensagem_original": "ola"},
+						Timestamp:  "2025-10-30T18:16:04",
+						Sucesso:    true,
+					},
+				},
+				Total: 1,
+			},
+			expected: WireBreakdown{Payload: 6 + 3 + 3 + 19 + 1},
+		},
+		{
+			// Three doubles of numeros_originais, a varint and four doubles, and the timestamp
+			name: "sum",
+			msg: &protogenerated.ResultadoSoma{
+				NumerosOriginais: []float64{1, 2, 3},
+				Quantidade:       3,
+				Soma:             6,
+				Media:            2,
+				Maximo:           3,
+				Minimo:           1,
+				TimestampCalculo: "2025-10-30T18:16:04",
+			},
+			expected: WireBreakdown{Payload: 3*8 + 1 + 4*8 + 19},
+		},
+		{
+			// The status, the map<string,double> metrics are 8 bytes each whatever their text
+			name: "status metrics",
+			msg: &protogenerated.StatusServidor{
+				Status:   "ativo",
+				Metricas: map[string]float64{"cpu": 0.125, "latencia_media_ms": 12.75},
+			},
+			expected: WireBreakdown{Payload: 5 + 2*8},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			breakdown := accountProtoPayload(tt.msg.ProtoReflect())
+
+			// Assert
+			assert.Equal(t, tt.expected, breakdown)
+		})
+	}
+}
+
+func TestAccountSweepMessages(t *testing.T) {
+	for _, protocol := range Protocols {
+		serde, err := NewSerde(protocol)
+		require.NoError(t, err)
+		accountant := serde.(WireAccountant)
+
+		for _, c := range SweepRequestCases() {
+			t.Run("request/"+c.Name()+"/"+protocol, func(t *testing.T) {
+				data, err := serde.Marshal(c.Request)
+				require.NoError(t, err)
+
+				breakdown, err := accountant.AccountRequest(data)
+				require.NoError(t, err, "AccountRequest should not return an error")
+				assert.Equal(t, len(data), breakdown.Total(), "breakdown should cover every byte")
+				assert.Positive(t, breakdown.Payload)
+			})
+		}
+
+		for _, c := range SweepResponseCases() {
+			t.Run("response/"+c.Name()+"/"+protocol, func(t *testing.T) {
+				data, err := encodeSweepResponse(protocol, c)
+				require.NoError(t, err)
+
+				breakdown, err := accountant.AccountResponse(data)
+				require.NoError(t, err, "AccountResponse should not return an error")
+				assert.Equal(t, len(data), breakdown.Total(), "breakdown should cover every byte")
+				assert.Positive(t, breakdown.Payload)
+			})
+		}
+	}
+}
+
+func TestAccountMalformedMessages(t *testing.T) {
+	tests := []struct {
+		protocol string
+		data     []byte
+	}{
+		{protocol: ProtocolJSON, data: []byte(`{"tipo":`)},
+		{protocol: ProtocolString, data: []byte("OK|msg=missing terminator")},
+		{protocol: ProtocolProtobuf, data: []byte{0, 0, 0, 10, 1}},
+		{protocol: ProtocolMsgpack, data: []byte{0, 0, 0, 2, 0x81, 0xa4}},
+		{protocol: ProtocolMsgpack, data: []byte{0, 0, 0, 1, 0xc1}},
+		{protocol: ProtocolCBOR, data: []byte{0, 0, 0, 2, 0xa1, 0x64}},
+		{protocol: ProtocolCBOR, data: []byte{0, 0, 0, 1, 0x1c}},
+		{protocol: ProtocolCBOR, data: []byte{0, 0, 0, 2, 0x9f, 0x01}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.protocol, func(t *testing.T) {
+			serde, err := NewSerde(tt.protocol)
+			require.NoError(t, err)
+
+			_, err = serde.(WireAccountant).AccountResponse(tt.data)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestWriteWireText(t *testing.T) {
+	report := &BenchmarkReport{
+		Results: []ProtocolResult{
+			{
+				Protocol: ProtocolString,
+				Operations: []OperationResult{
+					{
+						Operation:    OperationEcho,
+						WireMessages: 2,
+						Sent:         WireBreakdown{Framing: 10, Envelope: 70, Payload: 20},
+						Received:     WireBreakdown{Framing: 10, Envelope: 150, Payload: 40},
+					},
+					{Operation: OperationSum},
+				},
+			},
+		},
+	}
+
+	summaries := report.WireSummaries()
+	require.Len(t, summaries, 1, "operations without accounted messages should be skipped")
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteWireText(&buf, summaries))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 3)
+	assert.Equal(t, []string{"string", "echo", "request", "2", "50", "5", "35", "10", "80.0%"}, strings.Fields(lines[1]))
+	assert.Equal(t, []string{"string", "echo", "response", "2", "100", "5", "75", "20", "80.0%"}, strings.Fields(lines[2]))
+}